@@ -0,0 +1,21 @@
+// Command admin serves the admin endpoints (/api/v1/admin/...), so they can
+// be deployed apart from the public API.
+//
+// ADMIN_* variables override the shared BOILERPLATE_* ones for this binary.
+// `admin config print` shows the resulting configuration (see app.Main).
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/deppfellow/go-boilerplate/internal/app"
+	"github.com/deppfellow/go-boilerplate/internal/config"
+)
+
+func main() {
+	if err := app.Main(config.ServiceAdmin, os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,21 @@
+// Command api serves the public API.
+//
+// Shared settings come from BOILERPLATE_* variables; API_* variables
+// override them for this binary only (e.g. API_SERVER_PORT).
+// `api config print` shows the resulting configuration (see app.Main).
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/deppfellow/go-boilerplate/internal/app"
+	"github.com/deppfellow/go-boilerplate/internal/config"
+)
+
+func main() {
+	if err := app.Main(config.ServiceAPI, os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,21 @@
+// Command worker processes background jobs (emails, audit writes,
+// backfills). Its HTTP server only exposes the system routes for health probes.
+//
+// WORKER_* variables override the shared BOILERPLATE_* ones for this binary.
+// `worker config print` shows the resulting configuration (see app.Main).
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/deppfellow/go-boilerplate/internal/app"
+	"github.com/deppfellow/go-boilerplate/internal/config"
+)
+
+func main() {
+	if err := app.Main(config.ServiceWorker, os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
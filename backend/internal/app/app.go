@@ -0,0 +1,130 @@
+// Package app boots one service binary of the platform.
+//
+// Every cmd/<service>/main.go is a one-liner calling Run: the services share
+// the internal packages and the startup sequence, and only differ in the
+// service name, which selects the per-service config prefix (API_, WORKER_,
+// ADMIN_), the router (see router.ForService) and whether background jobs
+// are processed in-process (see config.Primary.RunsJobs).
+//
+// Startup sequence:
+//  1. config (shared BOILERPLATE_ vars, then the service's own overrides)
+//  2. logger + New Relic
+//  3. migrations (api / single binary only, outside local env)
+//  4. server (DB, Redis, jobs...), repositories, services, handlers, router
+//  5. HTTP server, until SIGINT/SIGTERM triggers a graceful shutdown
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/database"
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/handler"
+	"github.com/deppfellow/go-boilerplate/internal/logger"
+	"github.com/deppfellow/go-boilerplate/internal/repository"
+	"github.com/deppfellow/go-boilerplate/internal/router"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/deppfellow/go-boilerplate/internal/service"
+)
+
+// ShutdownTimeout bounds the graceful shutdown (in-flight requests, hooks,
+// job workers finishing their current task).
+const ShutdownTimeout = 30 * time.Second
+
+// Run starts the named service (config.ServiceAPI, ...; "" runs everything
+// in one process) and blocks until it has shut down.
+func Run(name string) error {
+	newRouter, err := router.ForService(name)
+	if err != nil {
+		return err
+	}
+
+	// Canceled on SIGINT/SIGTERM: aborts startup steps and triggers shutdown.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// A *config.ValidationError lists every bad setting, one per line.
+	cfg, err := config.Load(ctx, config.WithService(name))
+	if err != nil {
+		return err
+	}
+
+	// New Relic application (no-op when disabled) and the base logger.
+	loggerService := logger.NewLoggerService(cfg.Observability)
+	defer loggerService.Shutdown()
+
+	log := logger.NewLoggerWithService(cfg.Observability, loggerService)
+
+	if path := os.Getenv(config.ConfigFileEnv); path != "" {
+		log.Info().Str("path", path).Msg("loaded config file")
+	}
+
+	// Checked by every service, also where migrations don't run: a binary
+	// built with misnamed or clashing migration files must not deploy.
+	if err := database.ValidateMigrations(); err != nil {
+		return err
+	}
+
+	// Likewise for error codes declared twice with different meanings: the
+	// catalog is the contract with API clients.
+	if err := errs.ValidateCatalog(); err != nil {
+		return err
+	}
+
+	// One service owns the schema, otherwise every binary would race to
+	// migrate on deploy.
+	if cfg.Primary.Env != "local" && (name == "" || name == config.ServiceAPI) {
+		if err := database.Migrate(ctx, &log, cfg); err != nil {
+			return fmt.Errorf("failed to migrate database: %w", err)
+		}
+	}
+
+	srv, err := server.New(cfg, &log, loggerService)
+	if err != nil {
+		return fmt.Errorf("failed to initialize server: %w", err)
+	}
+
+	repos := repository.NewRepositories(srv)
+
+	services, err := service.NewService(srv, repos)
+	if err != nil {
+		return fmt.Errorf("could not create services: %w", err)
+	}
+
+	handlers := handler.NewHandlers(srv, services)
+	srv.SetupHTTPServer(newRouter(srv, handlers, services))
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Start()
+	}()
+
+	select {
+	case err := <-serveErr:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("failed to start server: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	log.Info().Msg("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("server forced to shutdown: %w", err)
+	}
+
+	log.Info().Msg("server exited properly")
+	return nil
+}
@@ -0,0 +1,135 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/deppfellow/go-boilerplate/internal/buildinfo"
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"gopkg.in/yaml.v3"
+)
+
+// usage lists the subcommands understood by Main.
+const usage = `usage: %[1]s [command]
+
+Without a command the service starts.
+
+commands:
+  config print [--json]   print the effective configuration, secrets masked
+  errors list [--json]    print the error code catalog
+  migrate new <name> [--dir <dir>]
+                          create a timestamped migration file
+  migrate status [--json] list applied and pending database migrations
+  migrate up              apply every pending migration
+  migrate to <version>    migrate up or down to version (0 reverts all)
+  migrate rollback [n]    revert the last n migrations (default 1)
+  version                 print the build information
+`
+
+// Main is the entrypoint of every cmd/<service>: it runs the service, or
+// the subcommand given in args (os.Args[1:]).
+func Main(name string, args []string) error {
+	if len(args) == 0 {
+		return Run(name)
+	}
+
+	switch strings.Join(args[:min(2, len(args))], " ") {
+	case "config print":
+		return PrintConfig(os.Stdout, name, args[2:])
+	case "errors list":
+		return PrintErrorCatalog(os.Stdout, args[2:])
+	case "migrate new":
+		return NewMigration(os.Stdout, args[2:])
+	case "migrate status", "migrate up", "migrate to", "migrate rollback":
+		return RunMigrate(os.Stdout, name, args[1], args[2:])
+	case "version", "--version":
+		fmt.Fprintln(os.Stdout, buildinfo.Get())
+		return nil
+	case "help", "-h", "--help":
+		fmt.Fprintf(os.Stdout, usage, os.Args[0])
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q\n%s", strings.Join(args, " "), fmt.Sprintf(usage, os.Args[0]))
+	}
+}
+
+// PrintConfig writes the configuration the named service would run with
+// (profile defaults, config file, shared and per-service env vars) to w,
+// as YAML in the config file layout, or JSON with --json. Secrets are masked
+// (see config.Redacted).
+//
+// It needs no database or Redis, so it also works to debug a service that
+// fails to start; an invalid config is reported like at startup.
+func PrintConfig(w io.Writer, name string, flags []string) error {
+	asJSON := false
+	for _, flag := range flags {
+		switch flag {
+		case "--json":
+			asJSON = true
+		default:
+			return fmt.Errorf("unknown flag %q for config print", flag)
+		}
+	}
+
+	cfg, err := config.Load(context.Background(), config.WithService(name))
+	if err != nil {
+		return err
+	}
+
+	redacted := config.Redacted(cfg)
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(redacted)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(redacted); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// PrintErrorCatalog writes the error code catalog (see errs.Registry) to w,
+// as a table or JSON with --json, for frontend teams and contract checks in
+// CI. Colliding declarations fail the command like they fail startup.
+func PrintErrorCatalog(w io.Writer, flags []string) error {
+	asJSON := false
+	for _, flag := range flags {
+		switch flag {
+		case "--json":
+			asJSON = true
+		default:
+			return fmt.Errorf("unknown flag %q for errors list", flag)
+		}
+	}
+
+	if err := errs.ValidateCatalog(); err != nil {
+		return err
+	}
+
+	catalog := errs.Catalog()
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(catalog)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CODE\tSTATUS\tDESCRIPTION")
+	for _, entry := range catalog {
+		description := entry.Description
+		if description == "" {
+			description = entry.Message
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", entry.Code, entry.Status, description)
+	}
+	return tw.Flush()
+}
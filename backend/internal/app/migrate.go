@@ -0,0 +1,146 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/database"
+	"github.com/deppfellow/go-boilerplate/internal/logger"
+)
+
+// RunMigrate runs a `migrate <command>` subcommand against the primary
+// database of the named service:
+//
+//	migrate status [--json]    applied vs pending migrations
+//	migrate up                 apply every pending migration
+//	migrate to <version>       migrate up or down to version (0 reverts all)
+//	migrate rollback [n]       revert the last n migrations (default 1)
+//
+// Going down runs the down part of each migration (below the
+// "---- create above / drop below ----" line); the command refuses to start
+// when one of them has none.
+func RunMigrate(w io.Writer, name, command string, args []string) error {
+	ctx := context.Background()
+
+	cfg, err := config.Load(ctx, config.WithService(name))
+	if err != nil {
+		return err
+	}
+	// No New Relic forwarding: progress goes to the console only.
+	log := logger.NewLoggerWithConfig(cfg.Observability)
+
+	switch command {
+	case "status":
+		asJSON := false
+		for _, flag := range args {
+			switch flag {
+			case "--json":
+				asJSON = true
+			default:
+				return fmt.Errorf("unknown flag %q for migrate status", flag)
+			}
+		}
+
+		status, err := database.Status(ctx, &log, cfg)
+		if err != nil {
+			return err
+		}
+		if asJSON {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(status)
+		}
+		return printMigrationStatus(w, status)
+
+	case "up":
+		if len(args) > 0 {
+			return fmt.Errorf("migrate up takes no arguments")
+		}
+		return database.Migrate(ctx, &log, cfg)
+
+	case "to":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: migrate to <version>")
+		}
+		version, err := strconv.ParseInt(args[0], 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid version %q: %w", args[0], err)
+		}
+		return database.MigrateTo(ctx, &log, cfg, int32(version))
+
+	case "rollback":
+		n := 1
+		switch len(args) {
+		case 0:
+		case 1:
+			if n, err = strconv.Atoi(args[0]); err != nil {
+				return fmt.Errorf("invalid rollback count %q: %w", args[0], err)
+			}
+		default:
+			return fmt.Errorf("usage: migrate rollback [n]")
+		}
+		return database.Rollback(ctx, &log, cfg, n)
+
+	default:
+		return fmt.Errorf("unknown migrate command %q", command)
+	}
+}
+
+// NewMigration creates an empty timestamped migration in the source tree
+// (`migrate new <name> [--dir internal/database/migrations]`) and prints its
+// path. It loads no config: it only writes a file.
+func NewMigration(w io.Writer, args []string) error {
+	dir := "internal/database/migrations"
+	var name string
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--dir" && i+1 < len(args):
+			i++
+			dir = args[i]
+		case name == "" && args[i] != "--dir":
+			name = args[i]
+		default:
+			return fmt.Errorf("usage: migrate new <name> [--dir <dir>]")
+		}
+	}
+
+	path, err := database.NewMigration(dir, name, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "created", path)
+	return nil
+}
+
+// printMigrationStatus writes status as a table, e.g.
+//
+//	VERSION  NAME                        STATUS   REVERSIBLE
+//	1        001_setup.sql               applied  no
+//	2        002_updated_at_trigger.sql  pending  yes
+func printMigrationStatus(w io.Writer, status database.MigrationStatus) error {
+	fmt.Fprintf(w, "current version %d, latest %d, %d pending\n", status.Current, status.Latest, status.Pending())
+	if status.Current > status.Latest {
+		fmt.Fprintln(w, "warning: the database was migrated by a newer binary")
+	}
+	fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tNAME\tSTATUS\tREVERSIBLE")
+	for _, m := range status.Migrations {
+		applied, reversible := "pending", "no"
+		if m.Applied {
+			applied = "applied"
+		}
+		if m.Reversible {
+			reversible = "yes"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", m.Version, m.Name, applied, reversible)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,102 @@
+// Package buildinfo tells what is deployed: the version, commit and build
+// time of the running binary.
+//
+// The values are stamped at build time with -ldflags (see the build task in
+// Taskfile.yml):
+//
+//	go build -ldflags "\
+//	  -X github.com/deppfellow/go-boilerplate/internal/buildinfo.Version=v1.4.0 \
+//	  -X github.com/deppfellow/go-boilerplate/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/deppfellow/go-boilerplate/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" \
+//	  ./cmd/api
+//
+// Without ldflags, Commit and BuildTime fall back to the VCS information the
+// Go toolchain embeds when building inside a git checkout, so a plain
+// `go build` still reports something useful (`go run` embeds none).
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// Set with -ldflags "-X ...". Version stays "dev" for unversioned builds.
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Info is the build information reported by /version, /status and the logs.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	GoVersion string `json:"go_version"`
+
+	// Modified is set when the binary was built from a checkout with
+	// uncommitted changes (VCS fallback only).
+	Modified bool `json:"modified,omitempty"`
+}
+
+var (
+	once sync.Once
+	info Info
+)
+
+// Get returns the build information of the running binary.
+func Get() Info {
+	once.Do(func() {
+		info = Info{
+			Version:   Version,
+			Commit:    Commit,
+			BuildTime: BuildTime,
+			GoVersion: runtime.Version(),
+		}
+
+		bi, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	})
+	return info
+}
+
+// ShortCommit returns the first 12 characters of the commit, for log fields
+// and labels.
+func (i Info) ShortCommit() string {
+	if len(i.Commit) > 12 {
+		return i.Commit[:12]
+	}
+	return i.Commit
+}
+
+// String formats the information on one line, e.g.
+// "v1.4.0 (commit 3f2a9c1b7d4e, built 2025-01-02T15:04:05Z, go1.25.0)".
+func (i Info) String() string {
+	details := make([]string, 0, 3)
+	if i.Commit != "" {
+		details = append(details, "commit "+i.ShortCommit())
+	}
+	if i.BuildTime != "" {
+		details = append(details, "built "+i.BuildTime)
+	}
+	details = append(details, i.GoVersion)
+	return i.Version + " (" + strings.Join(details, ", ") + ")"
+}
@@ -0,0 +1,19 @@
+package config
+
+// AuditConfig controls the audit trail of mutating requests
+// (POST/PUT/PATCH/DELETE) written by middleware.AuditMiddleware.
+type AuditConfig struct {
+	// Enabled turns audit logging on.
+	Enabled bool `koanf:"enabled"`
+
+	// ExcludePaths are route templates never audited (e.g. high-volume,
+	// low-value endpoints like client telemetry ingestion).
+	ExcludePaths []string `koanf:"exclude_paths"`
+}
+
+// DefaultAuditConfig enables auditing for every mutating route.
+func DefaultAuditConfig() *AuditConfig {
+	return &AuditConfig{
+		Enabled: true,
+	}
+}
@@ -6,24 +6,29 @@
 // can be reused accross the application runtime.
 //
 // Responsibilities:
-//   - Load environment variables (optionally from a `.env` file).
+//   - Load environment variables (optionally from a `.env` file), on top of
+//     an optional YAML/TOML config file (CONFIG_FILE).
 //   - Map env vars into a structured Go config (structs).
 //   - Validate required values so the app fails fast on bad/missing config.
-//   - Provide sane defaults for optional config blocks (e.g. observability).
+//   - Provide per-environment defaults (profiles, see DefaultConfigFor).
 package config
 
 import (
-	"os"
-	"strings"
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
 
-	"github.com/go-playground/validator/v10"
 	// Side-effect import: triggers godotenv's autoload feature.
 	// That means: if a `.env` file exists, it gets loaded into process env
 	// *before* your code reads env vars. No explicit call needed.
+	"github.com/go-viper/mapstructure/v2"
 	_ "github.com/joho/godotenv/autoload"
 	"github.com/knadh/koanf/providers/env"
 	"github.com/knadh/koanf/v2"
-	"github.com/rs/zerolog"
 )
 
 /*
@@ -33,7 +38,8 @@ import (
 
 	Key idea in this file:
 	- Env vars are read using a prefix: BOILERPLATE_
-	- Keys are normalized (lowercased, prefix removed)
+	- Keys are normalized (prefix removed, underscores resolved against the
+	  Config fields, see env.go)
 	- Nested struct fields are mapped via "dot notation" using the "." delimiter
 	  e.g. BOILERPLATE_SERVER_PORT -> server.port -> Config.Server.Port
 */
@@ -44,8 +50,11 @@ import (
 // The `validate:"required"` tags are used by go-playground/validator
 // to enforce that the config is present and populated.
 //
-// Observability is a pointer because it is optional. If not provided,
-// we inject defaults at runtime.
+// The pointer blocks (Observability, GeoIP, Docs, RateLimit, CSRF, Paths,
+// RequestID, Maintenance, Timeouts, Proxy, Audit, QueryBudget, Quota, Locale,
+// RPC, Search, Embedding, Tenancy, Encryption, ErrorResponse) are optional.
+// If not provided, the defaults of the environment profile apply (see
+// DefaultConfigFor).
 type Config struct {
 	Primary       Primary              `koanf:"primary" validate:"required"`
 	Server        ServerConfig         `koanf:"server" validate:"required"`
@@ -54,12 +63,41 @@ type Config struct {
 	Integration   IntegrationConfig    `koanf:"integration" validate:"required"`
 	Auth          AuthConfig           `koanf:"auth" validate:"required"`
 	Observability *ObservabilityConfig `koanf:"observability"`
+	GeoIP         *GeoIPConfig         `koanf:"geoip"`
+	Docs          *DocsConfig          `koanf:"docs"`
+	RateLimit     *RateLimitConfig     `koanf:"rate_limit"`
+	CSRF          *CSRFConfig          `koanf:"csrf"`
+	Paths         *PathConfig          `koanf:"paths"`
+	RequestID     *RequestIDConfig     `koanf:"request_id"`
+	Maintenance   *MaintenanceConfig   `koanf:"maintenance"`
+	Timeouts      *TimeoutConfig       `koanf:"timeouts"`
+	Proxy         *ProxyConfig         `koanf:"proxy"`
+	Audit         *AuditConfig         `koanf:"audit"`
+	QueryBudget   *QueryBudgetConfig   `koanf:"query_budget"`
+	Quota         *QuotaConfig         `koanf:"quota"`
+	Locale        *LocaleConfig        `koanf:"locale"`
+	RPC           *RPCConfig           `koanf:"rpc"`
+	Search        *SearchConfig        `koanf:"search"`
+	Embedding     *EmbeddingConfig     `koanf:"embedding"`
+	Tenancy       *TenancyConfig       `koanf:"tenancy"`
+	Encryption    *EncryptionConfig    `koanf:"encryption"`
+	ErrorResponse *ErrorResponseConfig `koanf:"error_response"`
 }
 
 // Primary holds top-level information about the runtime environment.
 // Usually used to tag logs/traces and switch behavior based on env.
 type Primary struct {
 	Env string `koanf:"env" validate:"required"`
+
+	// Service is the running binary (ServiceAPI, ServiceWorker, ServiceAdmin),
+	// set by Load (WithService); empty when one binary runs everything.
+	Service string `koanf:"service"`
+
+	// ServiceName names the application in logs, New Relic and health
+	// responses. Optional: it falls back to observability.service_name, then
+	// to "boilerplate". Set it per project (BOILERPLATE_PRIMARY_SERVICE_NAME),
+	// or per binary with the service prefix (API_PRIMARY_SERVICE_NAME).
+	ServiceName string `koanf:"service_name"`
 }
 
 // ServerConfig groups settings for the HTTP server runtime.
@@ -72,20 +110,232 @@ type ServerConfig struct {
 	WriteTimeout       int      `koanf:"write_timeout" validate:"required"`
 	IdleTimeout        int      `koanf:"idle_timeout" validate:"required"`
 	CORSAllowedOrigins []string `koanf:"cors_allowed_origins" validate:"required"`
+
+	// CORSAllowedMethods lists the methods allowed in preflight responses.
+	// Optional: empty answers with the methods the route actually supports.
+	CORSAllowedMethods []string `koanf:"cors_allowed_methods"`
+
+	// CORSAllowedHeaders lists the request headers browsers may send.
+	// Optional: empty allows whatever headers the preflight asks for.
+	CORSAllowedHeaders []string `koanf:"cors_allowed_headers"`
+
+	// CORSExposedHeaders are response headers scripts may read, on top of
+	// the correlation headers (X-Request-ID, ...) which are always exposed.
+	CORSExposedHeaders []string `koanf:"cors_exposed_headers"`
+
+	// CORSAllowCredentials lets browsers send cookies and HTTP auth to the
+	// API. It cannot be combined with the "*" origin.
+	CORSAllowCredentials bool `koanf:"cors_allow_credentials"`
+
+	// CORSMaxAge is how long browsers may cache a preflight response.
+	// 0 sends no caching hint (every request is preflighted again).
+	CORSMaxAge time.Duration `koanf:"cors_max_age"`
+
+	// MaxBodyBytes is the default maximum request body size (in bytes) accepted
+	// by typed handlers. Optional: 0 means DefaultMaxBodyBytes.
+	// Individual routes can override it with handler.WithBodyLimit.
+	MaxBodyBytes int64 `koanf:"max_body_bytes"`
+
+	// MaxConcurrentRequests caps in-flight requests per instance.
+	// Optional: 0 disables load shedding.
+	MaxConcurrentRequests int `koanf:"max_concurrent_requests"`
+
+	// ConcurrencyQueueWait is how long a request may wait for a free slot
+	// before being shed with 503. 0 means "don't wait at all".
+	ConcurrencyQueueWait time.Duration `koanf:"concurrency_queue_wait"`
+
+	// SecurityHeaders configures CSP, HSTS, Referrer-Policy, etc.
+	// Optional: every field has a safe default.
+	SecurityHeaders SecurityHeadersConfig `koanf:"security_headers"`
+
+	// TLS serves HTTPS directly (see TLSConfig). Optional: off by default,
+	// for deployments behind a TLS-terminating proxy.
+	TLS TLSConfig `koanf:"tls"`
+
+	// Diagnostics serves pprof/expvar on a separate port (see
+	// DiagnosticsConfig). Optional: off by default.
+	Diagnostics DiagnosticsConfig `koanf:"diagnostics"`
+}
+
+// DefaultMaxBodyBytes is used when ServerConfig.MaxBodyBytes is not set (4 MiB).
+const DefaultMaxBodyBytes int64 = 4 << 20
+
+// GetMaxBodyBytes returns the effective default body limit.
+func (c ServerConfig) GetMaxBodyBytes() int64 {
+	if c.MaxBodyBytes <= 0 {
+		return DefaultMaxBodyBytes
+	}
+	return c.MaxBodyBytes
 }
 
 // DatabaseConfig contains PostgreSQL connection parameters and pool tuning.
+//
+// The primary is either described field by field (Host, Port, ...) or by a
+// single DSN; read replicas are always given as DSNs.
 type DatabaseConfig struct {
-	Host            string `koanf:"host" validate:"required"`
+	Host            string `koanf:"host" validate:"required_without=DSN"`
 	Port            int    `koanf:"port" validate:"required"`
-	User            string `koanf:"user" validate:"required"`
-	Password        string `koanf:"password" validate:"required"`
-	Name            string `koanf:"name" validate:"required"`
-	SSLMode         string `koanf:"ssl_mode" validate:"required"`
+	User            string `koanf:"user" validate:"required_without=DSN"`
+	Password        string `koanf:"password" validate:"required_without=DSN"`
+	Name            string `koanf:"name" validate:"required_without=DSN"`
+	SSLMode         string `koanf:"ssl_mode" validate:"required_without=DSN"`
 	MaxOpenConns    int    `koanf:"max_open_conns" validate:"required"`
 	MaxIdleConns    int    `koanf:"max_idle_conns" validate:"required"`
 	ConnMaxLifetime int    `koanf:"conn_max_lifetime" validate:"required"`
 	ConnMaxIdleTime int    `koanf:"conn_max_idle_time" validate:"required"`
+
+	// DSN is the primary's connection string (postgres://... URL or
+	// key=value form). When set it replaces Host, Port, User, Password, Name
+	// and SSLMode. Optional.
+	DSN string `koanf:"dsn"`
+
+	// ReplicaDSNs are read replicas (comma-separated in env vars). Plain
+	// SELECTs outside transactions are spread over the healthy ones; writes
+	// and transactions always use the primary (see database.Database).
+	ReplicaDSNs []string `koanf:"replica_dsns"`
+
+	// ReplicaHealthInterval is how often replicas are pinged; an unreachable
+	// one is skipped until it answers again. Defaults to 5s.
+	ReplicaHealthInterval time.Duration `koanf:"replica_health_interval"`
+
+	// ReplicaMaxLag marks a replica unhealthy while its replay lag exceeds
+	// it. Optional: 0 ignores lag.
+	ReplicaMaxLag time.Duration `koanf:"replica_max_lag"`
+
+	// MigrationLockTimeout bounds how long startup waits for another
+	// instance that is migrating (the lock is a Postgres advisory lock).
+	// Defaults to 2m.
+	MigrationLockTimeout time.Duration `koanf:"migration_lock_timeout"`
+
+	// MigrationSkipIfLocked starts the instance without waiting when another
+	// one is migrating. It may then briefly run against the old schema, so
+	// only enable it when releases are backward compatible with it.
+	MigrationSkipIfLocked bool `koanf:"migration_skip_if_locked"`
+
+	// QueryTimeout bounds every repository statement (see
+	// repository.Base.Querier); a request or job deadline that ends sooner
+	// wins. Defaults to 30s, negative disables it.
+	QueryTimeout time.Duration `koanf:"query_timeout"`
+
+	// StatementTimeout is set as statement_timeout on every pool connection,
+	// so Postgres itself cancels a statement whose client went away or never
+	// set a deadline. Defaults to 60s, negative disables it.
+	StatementTimeout time.Duration `koanf:"statement_timeout"`
+
+	// RetryMaxAttempts is how many times a transaction (TxManager.WithinTx)
+	// or a Base.WithRetry operation runs when it fails with a transient
+	// error: serialization failure, deadlock, dropped connection. Defaults
+	// to 3; 1 disables retries.
+	RetryMaxAttempts int `koanf:"retry_max_attempts"`
+
+	// RetryBaseBackoff is the wait before the first retry, doubled for each
+	// further one (with jitter) up to RetryMaxBackoff. Default to 50ms and 1s.
+	RetryBaseBackoff time.Duration `koanf:"retry_base_backoff"`
+	RetryMaxBackoff  time.Duration `koanf:"retry_max_backoff"`
+
+	// Listen subscribes to NOTIFY channels (see ListenConfig). Optional.
+	Listen ListenConfig `koanf:"listen"`
+
+	// Health sets the latency, pool and lag thresholds of the /status
+	// database check (see DatabaseHealthConfig). Optional.
+	Health DatabaseHealthConfig `koanf:"health"`
+}
+
+// DefaultReplicaHealthInterval is used when ReplicaHealthInterval is not set.
+const DefaultReplicaHealthInterval = 5 * time.Second
+
+// Defaults for QueryTimeout and StatementTimeout.
+const (
+	DefaultQueryTimeout     = 30 * time.Second
+	DefaultStatementTimeout = 60 * time.Second
+)
+
+// Defaults for the transient error retries.
+const (
+	DefaultRetryMaxAttempts = 3
+	DefaultRetryBaseBackoff = 50 * time.Millisecond
+	DefaultRetryMaxBackoff  = time.Second
+)
+
+// DefaultMigrationLockTimeout is used when MigrationLockTimeout is not set.
+const DefaultMigrationLockTimeout = 2 * time.Minute
+
+// PrimaryDSN returns the primary's connection string: DSN when set,
+// otherwise one built from the individual fields (password URL-escaped).
+func (c DatabaseConfig) PrimaryDSN() string {
+	if c.DSN != "" {
+		return c.DSN
+	}
+
+	return fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=%s",
+		c.User,
+		url.QueryEscape(c.Password),
+		net.JoinHostPort(c.Host, strconv.Itoa(c.Port)),
+		c.Name,
+		c.SSLMode,
+	)
+}
+
+// GetReplicaHealthInterval returns the effective replica probe interval.
+func (c DatabaseConfig) GetReplicaHealthInterval() time.Duration {
+	if c.ReplicaHealthInterval <= 0 {
+		return DefaultReplicaHealthInterval
+	}
+	return c.ReplicaHealthInterval
+}
+
+// GetQueryTimeout returns the effective per-statement context timeout,
+// 0 when disabled.
+func (c DatabaseConfig) GetQueryTimeout() time.Duration {
+	switch {
+	case c.QueryTimeout < 0:
+		return 0
+	case c.QueryTimeout == 0:
+		return DefaultQueryTimeout
+	default:
+		return c.QueryTimeout
+	}
+}
+
+// GetStatementTimeout returns the effective statement_timeout, 0 when
+// disabled.
+func (c DatabaseConfig) GetStatementTimeout() time.Duration {
+	switch {
+	case c.StatementTimeout < 0:
+		return 0
+	case c.StatementTimeout == 0:
+		return DefaultStatementTimeout
+	default:
+		return c.StatementTimeout
+	}
+}
+
+// GetRetryMaxAttempts returns the effective number of attempts (>= 1).
+func (c DatabaseConfig) GetRetryMaxAttempts() int {
+	if c.RetryMaxAttempts <= 0 {
+		return DefaultRetryMaxAttempts
+	}
+	return c.RetryMaxAttempts
+}
+
+// GetRetryBackoff returns the effective base and maximum retry waits.
+func (c DatabaseConfig) GetRetryBackoff() (base, maxBackoff time.Duration) {
+	base, maxBackoff = c.RetryBaseBackoff, c.RetryMaxBackoff
+	if base <= 0 {
+		base = DefaultRetryBaseBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = max(DefaultRetryMaxBackoff, base)
+	}
+	return base, maxBackoff
+}
+
+// GetMigrationLockTimeout returns the effective migration lock timeout.
+func (c DatabaseConfig) GetMigrationLockTimeout() time.Duration {
+	if c.MigrationLockTimeout <= 0 {
+		return DefaultMigrationLockTimeout
+	}
+	return c.MigrationLockTimeout
 }
 
 // RedisConfig contains Redis connection details.
@@ -107,34 +357,66 @@ type AuthConfig struct {
 	SecretKey string `koanf:"secret_key" validate:"required"`
 }
 
-// loadConfig loads configuration from environment variables, unmarshals it into
+// Load loads configuration from environment variables, unmarshals it into
 // Config structs, validates it, applies defaults, and returns the resulting config.
 //
 // Behavior summary:
-//   - Loads env vars with prefix BOILERPLATE_
-//   - Converts env keys into koanf keys using "." nesting
+//   - Starts from the defaults of the primary.env profile (DefaultConfigFor)
+//   - Loads the optional YAML/TOML file named by CONFIG_FILE
+//   - Loads env vars with prefix BOILERPLATE_ (overriding file values)
+//   - Loads the service's own env vars, e.g. API_ for WithService("api")
+//     (overriding the shared ones)
+//   - Converts env keys into koanf keys using "." nesting (see newEnvKeyMapper)
 //   - Unmarshals into Config
 //   - Validates required config blocks/fields
-//   - Sets default observability if missing
 //   - Overrides observability service name + environment
 //   - Validates observability config as well
 //
-// NOTE: This function *logs fatally* on many errors. That means it will exit
-// the process immediately. It only returns an error on the happy path currently,
-// which is… a design choice.
-func loadConfig() (*Config, error) {
-	// Create a logger that writes in a human-friendly console format to STDERR.
-	//
-	// - zerolog.New(...) builds a base logger
-	// - With().Timestamp() adds a timestamp field to each log entry
-	// - Logger() finalizes it
-	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).With().Timestamp().Logger()
+// Errors are returned, never logged fatally, so cmd/ and tests decide what
+// to do with them. Validation doesn't stop at the first problem: the error
+// is a *ValidationError listing every missing/invalid setting.
+func Load(ctx context.Context, opts ...LoadOption) (*Config, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	o := loadOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return readConfig(o.service)
+}
+
+// LoadOption customizes Load.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	service string
+}
+
+// WithService loads the config of one service binary (config.ServiceAPI, ...):
+// its own env prefix is applied and Primary.Service is set.
+func WithService(name string) LoadOption {
+	return func(o *loadOptions) {
+		o.service = name
+	}
+}
 
+// readConfig does the work of Load. The reload Watcher calls it directly
+// with the service of the running config.
+func readConfig(service string) (*Config, error) {
 	// Create a new koanf instance.
 	// The "." is the key-path delimiter koanf uses to represent nesting.
 	// e.g. "server.port" means Config.Server.Port
 	k := koanf.New(".")
 
+	// Load the optional config file (CONFIG_FILE) first, so the environment
+	// variables loaded next override it.
+	if _, err := loadConfigFile(k); err != nil {
+		return nil, err
+	}
+
 	// Load environment variables into koanf.
 	//
 	// env.Provider parameters:
@@ -142,28 +424,37 @@ func loadConfig() (*Config, error) {
 	//   2) delimiter: "." tells koanf how to interpret nested keys
 	//   3) key-mapping func: transforms raw env var names into koanf keys
 	//
-	// The mapping function:
-	//   - strings.TrimPrefix(s, "BOILERPLATE_") removes the prefix
-	//   - strings.ToLower(...) normalizes to lowercase
+	// The mapping func (see newEnvKeyMapper in env.go) knows every field of
+	// Config, so plain SCREAMING_SNAKE names resolve to nested keys:
 	//
-	// Example:
-	//   BOILERPLATE_DATABASE_HOST -> "database_host" (after trim + lower)
+	//   BOILERPLATE_DATABASE_HOST           -> "database.host"
+	//   BOILERPLATE_DATABASE_MAX_OPEN_CONNS -> "database.max_open_conns"
 	//
-	// BUT WAIT: how do we get "database.host" instead of "database_host"?
-	// That depends on how your env vars are named. If you use:
-	//   BOILERPLATE_DATABASE.HOST
-	// it becomes "database.host".
-	//
-	// If you use underscores, koanf won't magically convert "_" to "." here.
-	// Some boilerplates do that conversion in the mapping function. This one doesn't.
-	// So your env key format must match what koanf expects.
-	err := k.Load(env.Provider("BOILERPLATE_", ".", func(s string) string {
-		return strings.ToLower(strings.TrimPrefix(s, "BOILERPLATE_"))
-	}), nil)
+	// A blind "_" -> "." replacement would turn the latter into
+	// "database.max.open.conns", hence the lookup table (plus envAliases
+	// for alternative spellings like BOILERPLATE_DB_HOST).
+	err := k.Load(env.Provider(envPrefix, ".", newEnvKeyMapper(envPrefix, reflect.TypeOf(Config{}))), nil)
 	if err != nil {
-		// Fatal logs the error and exits the program.
-		logger.Fatal().Err(err).Msg("Could not load initial env variables.")
+		return nil, fmt.Errorf("could not load env variables: %w", err)
+	}
+
+	// Per-service overrides last (API_SERVER_PORT beats BOILERPLATE_SERVER_PORT
+	// in cmd/api only), see ServiceEnvPrefix.
+	if err := loadServiceEnv(k, service); err != nil {
+		return nil, err
+	}
+
+	// Profile defaults go underneath everything loaded so far. The profile
+	// is picked by primary.env, which is why it can only be added now: the
+	// merge order stays defaults < file < env < service env.
+	defaults := koanf.New(".")
+	if err := defaults.Load(defaultsProvider{cfg: DefaultConfigFor(k.String("primary.env"))}, nil); err != nil {
+		return nil, fmt.Errorf("could not load config defaults: %w", err)
 	}
+	if err := defaults.Merge(k); err != nil {
+		return nil, fmt.Errorf("could not merge config over defaults: %w", err)
+	}
+	k = defaults
 
 	// mainConfig will hold the decoded configuration.
 	mainConfig := &Config{}
@@ -172,46 +463,93 @@ func loadConfig() (*Config, error) {
 	//
 	// The first argument is the key path to unmarshal from.
 	// Using "" means "unmarshal everything from the root".
-	err = k.Unmarshal("", mainConfig)
+	//
+	// The decoder is koanf's default plus comma splitting, so list settings
+	// can be set from a single env var:
+	// BOILERPLATE_SERVER_CORS_ALLOWED_ORIGINS=https://a.example,https://b.example
+	err = k.UnmarshalWithConf("", mainConfig, koanf.UnmarshalConf{
+		DecoderConfig: &mapstructure.DecoderConfig{
+			DecodeHook: mapstructure.ComposeDecodeHookFunc(
+				mapstructure.StringToTimeDurationHookFunc(),
+				mapstructure.StringToSliceHookFunc(","),
+				mapstructure.TextUnmarshallerHookFunc(),
+			),
+			WeaklyTypedInput: true,
+		},
+	})
 	if err != nil {
-		logger.Fatal().Err(err).Msg("Could not unmarshal main config.")
+		return nil, fmt.Errorf("could not unmarshal main config: %w", err)
 	}
 
+	// The binary decides which service it is, not the environment.
+	mainConfig.Primary.Service = service
+
+	// Every problem found from here on is collected, not returned right away.
+	problems := &ValidationError{}
+
 	// Create a new validator instance.
-	// This validator reads `validate:"required"` tags on struct fields.
-	validate := validator.New()
+	// This validator reads `validate:"required"` tags on struct fields,
+	// and names fields by their koanf keys (see newConfigValidator).
+	validate := newConfigValidator()
 
 	// Validate the entire config struct recursively.
 	//
 	// Any missing required field triggers an error.
 	// Because many structs have validate:"required", it effectively enforces
 	// that those blocks exist and have values.
-	err = validate.Struct(mainConfig)
-	if err != nil {
-		logger.Fatal().Err(err).Msg("Config validation failed.")
-	}
+	problems.add("", validate.Struct(mainConfig))
 
-	// Set default observability config if not provided
-	// If observability config wasn't provided, inject a default.
-	// It's a pointer field, so nil means "missing".
-	if mainConfig.Observability == nil {
-		mainConfig.Observability = DefaultObservabilityConfig()
+	// Resolve the service name and environment used by tracing/logging:
+	//
+	// - ServiceName: primary.service_name wins when set, otherwise
+	//   observability.service_name (whose profile default is "boilerplate");
+	//   both fields end up holding the same value
+	// - Environment is always derived from Primary.Env
+	if mainConfig.Primary.ServiceName != "" {
+		mainConfig.Observability.ServiceName = mainConfig.Primary.ServiceName
+	} else {
+		mainConfig.Primary.ServiceName = mainConfig.Observability.ServiceName
 	}
+	mainConfig.Observability.Environment = mainConfig.Primary.Env
 
-	// Override service name and environment from primary config.
-	// Force service name and environment values regardless of what user set.
-	// This ensures tracing/logging sees consistent service naming.
+	// Validate the optional blocks using their own validation logic.
+	// This is separate from go-playground/validator tags and enforces
+	// constraints like "endpoint must be set", "api key required", etc.
 	//
-	// - ServiceName is hardcoded to "boilerplate"
-	// - Environment is derived from Primary.Env
-	mainConfig.Observability.ServiceName = "boilerplate"
-	mainConfig.Observability.Environment = mainConfig.Primary.Env
+	// The blocks are never nil here: DefaultConfigFor supplies every one of
+	// them, and whatever the file or env set is merged on top.
+	problems.add("database.listen", mainConfig.Database.Listen.Validate())
+	problems.add("database.health", mainConfig.Database.Health.Validate())
+	problems.add("server.tls", mainConfig.Server.TLS.Validate())
+	problems.add("server.diagnostics", mainConfig.Server.Diagnostics.Validate())
+	if diag := mainConfig.Server.Diagnostics; diag.Enabled && diag.GetPort() == mainConfig.Server.Port {
+		problems.addf("server.diagnostics.port", "must differ from server.port (%s)", mainConfig.Server.Port)
+	}
+	problems.add("observability", mainConfig.Observability.Validate())
+	problems.add("geoip", mainConfig.GeoIP.Validate())
+	problems.add("docs", mainConfig.Docs.Validate())
+	problems.add("rate_limit", mainConfig.RateLimit.Validate())
+	problems.add("csrf", mainConfig.CSRF.Validate())
+	problems.add("paths", mainConfig.Paths.Validate())
+	problems.add("request_id", mainConfig.RequestID.Validate())
+	problems.add("maintenance", mainConfig.Maintenance.Validate())
+	problems.add("timeouts", mainConfig.Timeouts.Validate())
+	problems.add("proxy", mainConfig.Proxy.Validate())
+	problems.add("query_budget", mainConfig.QueryBudget.Validate())
+	problems.add("quota", mainConfig.Quota.Validate())
+	problems.add("locale", mainConfig.Locale.Validate())
+	problems.add("rpc", mainConfig.RPC.Validate())
+	problems.add("search", mainConfig.Search.Validate())
+	problems.add("embedding", mainConfig.Embedding.Validate())
+	problems.add("tenancy", mainConfig.Tenancy.Validate())
+	problems.add("encryption", mainConfig.Encryption.Validate())
+	problems.add("error_response", mainConfig.ErrorResponse.Validate())
+
+	// Settings that depend on each other, across blocks.
+	problems.add("", mainConfig.Validate())
 
-	// Validate observability config using its own validation logic.
-	// This is separate from go-playground/validator tags and is likely
-	// enforcing constraints like "endpoint must be set", "api key required", etc.
-	if err := mainConfig.Observability.Validate(); err != nil {
-		logger.Fatal().Err(err).Msg("invalid observability config")
+	if err := problems.orNil(); err != nil {
+		return nil, err
 	}
 
 	return mainConfig, nil
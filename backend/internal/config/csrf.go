@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CSRF SameSite modes accepted by CSRFConfig.SameSite.
+const (
+	CSRFSameSiteLax    = "lax"
+	CSRFSameSiteStrict = "strict"
+	CSRFSameSiteNone   = "none"
+)
+
+// CSRFConfig controls CSRF protection for cookie-authenticated browser clients.
+//
+// The middleware uses the double-submit cookie pattern: a random token is set
+// in a cookie and unsafe requests (POST/PUT/PATCH/DELETE) must echo it back in
+// a header. A cross-site attacker can make the browser send the cookie, but
+// cannot read it to fill the header.
+//
+// Requests carrying an Authorization bearer token or an API key are exempt:
+// those credentials are never sent automatically by browsers, so CSRF does
+// not apply to them.
+type CSRFConfig struct {
+	// Enabled toggles the middleware. Off by default because a pure
+	// bearer-token API doesn't need it.
+	Enabled bool `koanf:"enabled"`
+
+	// CookieName is the cookie holding the token (default "_csrf").
+	CookieName string `koanf:"cookie_name"`
+
+	// HeaderName is the request header the client copies the token into
+	// (default "X-CSRF-Token").
+	HeaderName string `koanf:"header_name"`
+
+	// CookieDomain optionally scopes the cookie (e.g. ".example.com").
+	CookieDomain string `koanf:"cookie_domain"`
+
+	// CookieSecure restricts the cookie to HTTPS.
+	CookieSecure bool `koanf:"cookie_secure"`
+
+	// SameSite is one of "lax" (default), "strict" or "none".
+	// "none" requires CookieSecure.
+	SameSite string `koanf:"same_site"`
+
+	// ExemptPaths are route templates (e.g. "/api/v1/webhooks/stripe") that
+	// skip CSRF checks, typically server-to-server callbacks.
+	ExemptPaths []string `koanf:"exempt_paths"`
+}
+
+// DefaultCSRFConfig returns a disabled CSRF configuration.
+func DefaultCSRFConfig() *CSRFConfig {
+	return &CSRFConfig{
+		Enabled: false,
+	}
+}
+
+// Validate fills in defaults and rejects inconsistent cookie settings.
+func (c *CSRFConfig) Validate() error {
+	if c.CookieName == "" {
+		c.CookieName = "_csrf"
+	}
+	if c.HeaderName == "" {
+		c.HeaderName = "X-CSRF-Token"
+	}
+	if c.SameSite == "" {
+		c.SameSite = CSRFSameSiteLax
+	}
+
+	c.SameSite = strings.ToLower(c.SameSite)
+	switch c.SameSite {
+	case CSRFSameSiteLax, CSRFSameSiteStrict:
+	case CSRFSameSiteNone:
+		if c.Enabled && !c.CookieSecure {
+			return fmt.Errorf("csrf same_site=none requires cookie_secure=true")
+		}
+	default:
+		return fmt.Errorf("invalid csrf same_site: %s (must be one of: lax, strict, none)", c.SameSite)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package config
+
+import "time"
+
+// Defaults for DatabaseHealthConfig.
+const (
+	DefaultDegradedLatency         = 200 * time.Millisecond
+	DefaultDegradedPoolUtilization = 0.9
+	DefaultDegradedReplicaLag      = 30 * time.Second
+)
+
+// DatabaseHealthConfig sets when the database check of /status reports
+// "degraded" rather than "healthy", at database.health. A degraded
+// database still serves (the endpoint answers 200) but is worth an alert
+// before it turns into an outage.
+type DatabaseHealthConfig struct {
+	// DegradedLatency is the round trip of the probe query above which the
+	// database is degraded. Defaults to 200ms.
+	DegradedLatency time.Duration `koanf:"degraded_latency"`
+
+	// UnhealthyLatency makes a slower probe count as a failure (503), so
+	// load balancers stop routing to an instance stuck behind a saturated
+	// database. 0 (default) disables it.
+	UnhealthyLatency time.Duration `koanf:"unhealthy_latency"`
+
+	// DegradedPoolUtilization is the share of acquired pool connections
+	// (0-1) from which the pool counts as saturated. Defaults to 0.9.
+	DegradedPoolUtilization float64 `koanf:"degraded_pool_utilization"`
+
+	// DegradedReplicaLag is the replication lag above which a replica
+	// degrades the database. Defaults to database.replica_max_lag when set
+	// (such replicas are out of rotation too), otherwise 30s.
+	DegradedReplicaLag time.Duration `koanf:"degraded_replica_lag"`
+}
+
+// GetDegradedLatency returns the effective latency threshold.
+func (c DatabaseHealthConfig) GetDegradedLatency() time.Duration {
+	if c.DegradedLatency <= 0 {
+		return DefaultDegradedLatency
+	}
+	return c.DegradedLatency
+}
+
+// GetDegradedPoolUtilization returns the effective saturation threshold.
+func (c DatabaseHealthConfig) GetDegradedPoolUtilization() float64 {
+	if c.DegradedPoolUtilization <= 0 {
+		return DefaultDegradedPoolUtilization
+	}
+	return c.DegradedPoolUtilization
+}
+
+// GetDegradedReplicaLag returns the effective lag threshold, given the
+// database's replica_max_lag.
+func (c DatabaseHealthConfig) GetDegradedReplicaLag(replicaMaxLag time.Duration) time.Duration {
+	switch {
+	case c.DegradedReplicaLag > 0:
+		return c.DegradedReplicaLag
+	case replicaMaxLag > 0:
+		return replicaMaxLag
+	default:
+		return DefaultDegradedReplicaLag
+	}
+}
+
+// Validate checks that the thresholds are consistent.
+func (c DatabaseHealthConfig) Validate() error {
+	problems := &ValidationError{}
+	if c.DegradedPoolUtilization > 1 {
+		problems.addf("degraded_pool_utilization", "must be between 0 and 1")
+	}
+	if c.UnhealthyLatency > 0 && c.UnhealthyLatency <= c.GetDegradedLatency() {
+		problems.addf("unhealthy_latency", "must be above degraded_latency (%s)", c.GetDegradedLatency())
+	}
+	return problems.orNil()
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"net"
+)
+
+// DefaultDiagnosticsPort is the diagnostics listener port when none is set
+// (the port used by most Go pprof examples).
+const DefaultDiagnosticsPort = "6060"
+
+// DiagnosticsConfig runs a separate listener with runtime diagnostics:
+// net/http/pprof, expvar and goroutine/heap dumps (see server/diagnostics.go).
+//
+// It is off by default. Profiles expose internals (stack traces, command
+// line, memory contents of the heap profile) and can cost CPU, so the
+// listener is never part of the public router and every request must pass
+// AllowedCIDRs and/or basic auth.
+type DiagnosticsConfig struct {
+	// Enabled starts the diagnostics listener on Port.
+	Enabled bool `koanf:"enabled"`
+
+	// Port is the listener port. Defaults to DefaultDiagnosticsPort; must
+	// differ from server.port.
+	Port string `koanf:"port"`
+
+	// AllowedCIDRs are the networks allowed to connect, checked against the
+	// direct peer address (proxy headers are ignored here). Empty with no
+	// basic auth means loopback only (kubectl port-forward, SSH tunnel).
+	AllowedCIDRs []string `koanf:"allowed_cidrs"`
+
+	// Username and Password require HTTP basic auth. Set both or neither.
+	Username string `koanf:"username"`
+	Password string `koanf:"password"`
+}
+
+// loopbackCIDRs is the allowlist used without any configured protection.
+var loopbackCIDRs = []string{"127.0.0.0/8", "::1/128"}
+
+// GetPort returns the listener port.
+func (c DiagnosticsConfig) GetPort() string {
+	if c.Port == "" {
+		return DefaultDiagnosticsPort
+	}
+	return c.Port
+}
+
+// BasicAuth reports whether basic auth is configured.
+func (c DiagnosticsConfig) BasicAuth() bool {
+	return c.Username != "" && c.Password != ""
+}
+
+// ParsedAllowedCIDRs returns the allowlist as networks; nil means "any
+// address" (basic auth alone protects the listener).
+func (c DiagnosticsConfig) ParsedAllowedCIDRs() ([]*net.IPNet, error) {
+	cidrs := c.AllowedCIDRs
+	if len(cidrs) == 0 {
+		if c.BasicAuth() {
+			return nil, nil
+		}
+		cidrs = loopbackCIDRs
+	}
+
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// Validate checks the CIDRs and that basic auth is complete.
+func (c DiagnosticsConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	problems := &ValidationError{}
+	if (c.Username == "") != (c.Password == "") {
+		problems.addf("password", "username and password must be set together")
+	}
+	if _, err := c.ParsedAllowedCIDRs(); err != nil {
+		problems.addf("allowed_cidrs", "%v", err)
+	}
+	return problems.orNil()
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Supported API docs UIs.
+const (
+	DocsUIScalar  = "scalar"
+	DocsUISwagger = "swagger"
+	DocsUIRedoc   = "redoc"
+)
+
+// DocsConfig controls the embedded API documentation UI served at /docs.
+type DocsConfig struct {
+	// Enabled toggles the /docs and /static routes.
+	// Defaults to false in production so the API surface isn't advertised.
+	Enabled bool `koanf:"enabled"`
+
+	// UI selects the documentation renderer: swagger, scalar or redoc.
+	// Only Swagger UI's bundle is vendored; the others need theirs added
+	// to static/docs/vendor first (see its README), or Swagger UI is served.
+	UI string `koanf:"ui"`
+
+	// ValidateResponses checks every JSON response against the OpenAPI spec
+	// and the response struct's validate tags before it is written, logging
+	// mismatches. Meant for development; never active in production.
+	ValidateResponses bool `koanf:"validate_responses"`
+
+	// ErrorDocsURL is the template of the "docs_url" link added to error
+	// responses for cataloged codes; "{code}" is replaced by the error code.
+	// Empty disables the links. Defaults to the built-in /docs/errors page
+	// when docs are enabled.
+	ErrorDocsURL string `koanf:"error_docs_url"`
+
+	// ErrorDocsOverrides maps individual codes to a specific page, e.g. a
+	// guide for QUOTA_EXCEEDED. Overrides apply to uncataloged codes too.
+	ErrorDocsOverrides map[string]string `koanf:"error_docs_overrides"`
+}
+
+// DefaultDocsConfig returns docs defaults for the given environment.
+func DefaultDocsConfig(env string) *DocsConfig {
+	enabled := env != "production"
+
+	errorDocsURL := ""
+	if enabled {
+		errorDocsURL = "/docs/errors#{code}"
+	}
+
+	return &DocsConfig{
+		Enabled:           enabled,
+		UI:                DocsUISwagger,
+		ValidateResponses: env == "local" || env == "development",
+		ErrorDocsURL:      errorDocsURL,
+	}
+}
+
+// DocsURLFor returns the documentation link for an error code ("" for none).
+// cataloged tells whether the code is documented by the error catalog; only
+// those get the templated link.
+func (c *DocsConfig) DocsURLFor(code string, cataloged bool) string {
+	if url, ok := c.ErrorDocsOverrides[code]; ok {
+		return url
+	}
+	// Keys set through env vars arrive lower-cased (see newEnvKeyMapper).
+	if url, ok := c.ErrorDocsOverrides[strings.ToLower(code)]; ok {
+		return url
+	}
+	if !cataloged || c.ErrorDocsURL == "" {
+		return ""
+	}
+	return strings.ReplaceAll(c.ErrorDocsURL, "{code}", code)
+}
+
+// Validate rejects unknown UI names. An empty UI falls back to Swagger UI.
+func (c *DocsConfig) Validate() error {
+	switch c.UI {
+	case "":
+		c.UI = DocsUISwagger
+	case DocsUIScalar, DocsUISwagger, DocsUIRedoc:
+	default:
+		return fmt.Errorf("invalid docs ui: %s (must be one of: scalar, swagger, redoc)", c.UI)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Embedding providers supported by lib/embedding.
+const (
+	EmbeddingProviderOpenAI = "openai"
+	EmbeddingProviderLocal  = "local"
+)
+
+// EmbeddingConfig configures vector embeddings (see lib/embedding). Vector
+// search needs the pgvector extension in Postgres.
+type EmbeddingConfig struct {
+	// Enabled creates server.Embeddings and processes embedding jobs.
+	Enabled bool `koanf:"enabled"`
+
+	// Provider is "openai" (or any OpenAI-compatible API) or "local"
+	// (an Ollama server).
+	Provider string `koanf:"provider"`
+
+	// URL is the base URL of the provider. Defaults to https://api.openai.com
+	// for openai and http://localhost:11434 for local.
+	URL string `koanf:"url"`
+
+	// APIKey authenticates against the provider (openai only).
+	APIKey string `koanf:"api_key"`
+
+	// Model names the embedding model, e.g. "text-embedding-3-small".
+	Model string `koanf:"model"`
+
+	// Dimensions is the vector size of Model. It must match the vector(N)
+	// columns the embeddings are stored in.
+	Dimensions int `koanf:"dimensions"`
+
+	// BatchSize is the number of texts embedded per provider request.
+	BatchSize int `koanf:"batch_size"`
+
+	// Timeout bounds each request to the provider.
+	Timeout time.Duration `koanf:"timeout"`
+}
+
+// DefaultEmbeddingConfig keeps embeddings disabled, with the settings of
+// OpenAI's small embedding model ready for when they are turned on.
+func DefaultEmbeddingConfig() *EmbeddingConfig {
+	return &EmbeddingConfig{
+		Enabled:    false,
+		Provider:   EmbeddingProviderOpenAI,
+		Model:      "text-embedding-3-small",
+		Dimensions: 1536,
+		BatchSize:  100,
+		Timeout:    30 * time.Second,
+	}
+}
+
+// Validate checks the provider settings when embeddings are enabled.
+func (c *EmbeddingConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	switch c.Provider {
+	case EmbeddingProviderOpenAI:
+		if c.APIKey == "" {
+			return fmt.Errorf("embedding api_key is required for the openai provider")
+		}
+	case EmbeddingProviderLocal:
+	default:
+		return fmt.Errorf("embedding provider must be %q or %q", EmbeddingProviderOpenAI, EmbeddingProviderLocal)
+	}
+	if c.URL != "" {
+		u, err := url.Parse(c.URL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("embedding url %q must be an absolute URL", c.URL)
+		}
+	}
+	if c.Model == "" {
+		return fmt.Errorf("embedding model is required")
+	}
+	if c.Dimensions <= 0 {
+		return fmt.Errorf("embedding dimensions must be positive")
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("embedding batch_size must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// EncryptionKeyLength is the key size of field encryption (AES-256).
+const EncryptionKeyLength = 32
+
+// EncryptionConfig holds the keys of field-level encryption (see
+// lib/crypto), for PII columns encrypted at rest.
+type EncryptionConfig struct {
+	// Enabled loads the keyring at startup. Reading or writing an encrypted
+	// column without it fails.
+	Enabled bool `koanf:"enabled"`
+
+	// SecretKeys are "id:base64key" pairs (comma-separated in env vars), the
+	// key being 32 random bytes, e.g. from `openssl rand -base64 32`. Every
+	// key that encrypted stored values must stay listed until those values
+	// are re-encrypted with the primary key.
+	SecretKeys []string `koanf:"secret_keys"`
+
+	// PrimaryKeyID names the key new values are encrypted with. To rotate,
+	// add a key, make it primary, then re-encrypt old rows (e.g. with a
+	// backfill, see crypto.Keyring.NeedsRotation).
+	PrimaryKeyID string `koanf:"primary_key_id"`
+}
+
+// DefaultEncryptionConfig keeps field encryption off until keys are provided.
+func DefaultEncryptionConfig() *EncryptionConfig {
+	return &EncryptionConfig{Enabled: false}
+}
+
+// DecodeKeys parses SecretKeys into key bytes by ID.
+func (c *EncryptionConfig) DecodeKeys() (map[string][]byte, error) {
+	keys := make(map[string][]byte, len(c.SecretKeys))
+	for i, pair := range c.SecretKeys {
+		id, encoded, ok := strings.Cut(pair, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("key %d must be formatted as id:base64key", i)
+		}
+		if _, exists := keys[id]; exists {
+			return nil, fmt.Errorf("key ID %q is listed twice", id)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %q is not valid base64", id)
+		}
+		if len(key) != EncryptionKeyLength {
+			return nil, fmt.Errorf("key %q must be %d bytes long, got %d", id, EncryptionKeyLength, len(key))
+		}
+		keys[id] = key
+	}
+	return keys, nil
+}
+
+// Validate requires well-formed keys including the primary one.
+func (c *EncryptionConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	problems := &ValidationError{}
+	keys, err := c.DecodeKeys()
+	switch {
+	case err != nil:
+		// The error names key IDs only, never key material.
+		problems.add("secret_keys", err)
+	case len(keys) == 0:
+		problems.addf("secret_keys", "at least one key is required")
+	case keys[c.PrimaryKeyID] == nil:
+		problems.addf("primary_key_id", "must name one of the secret_keys")
+	}
+	return problems.orNil()
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// envPrefix is stripped from every environment variable read into Config.
+const envPrefix = "BOILERPLATE_"
+
+// envAliases maps alternative env names (prefix stripped) to koanf keys.
+//
+// The canonical names are derived from the Config struct (see newEnvKeyMapper),
+// so only spellings that can't be derived belong here: common abbreviations
+// and the names other tools use for the same setting.
+var envAliases = map[string]string{
+	"ENV":                           "primary.env",
+	"SERVICE_NAME":                  "primary.service_name",
+	"PORT":                          "server.port",
+	"DB_HOST":                       "database.host",
+	"DB_PORT":                       "database.port",
+	"DB_USER":                       "database.user",
+	"DB_PASSWORD":                   "database.password",
+	"DB_NAME":                       "database.name",
+	"DB_SSL_MODE":                   "database.ssl_mode",
+	"DATABASE_SSLMODE":              "database.ssl_mode",
+	"DATABASE_MAX_OPEN_CONNECTIONS": "database.max_open_conns",
+	"DATABASE_MAX_IDLE_CONNECTIONS": "database.max_idle_conns",
+	"REDIS_ADDR":                    "redis.address",
+	"RESEND_API_KEY":                "integration.resend_api_key",
+	"CLERK_SECRET_KEY":              "auth.secret_key",
+	"NEW_RELIC_LICENSE_KEY":         "observability.new_relic.license_key",
+	"OTEL_EXPORTER_OTLP_ENDPOINT":   "observability.tracing.otlp.endpoint",
+}
+
+// envMapField is a map-typed Config field: its keys are free-form, so env
+// names below it can only be split with the help of the value type.
+type envMapField struct {
+	envName string // e.g. "QUOTA_PLANS"
+	key     string // e.g. "quota.plans"
+
+	// values holds the leaf names of a struct value type (nil for scalars).
+	values map[string]string
+}
+
+// newEnvKeyMapper returns the env.Provider key callback translating
+// SCREAMING_SNAKE env names into koanf keys for the struct type root:
+//
+//	BOILERPLATE_DATABASE_MAX_OPEN_CONNS -> database.max_open_conns
+//	BOILERPLATE_SERVER_SECURITY_HEADERS_HSTS_MAX_AGE -> server.security_headers.hsts_max_age
+//	BOILERPLATE_QUOTA_PLANS_FREE_DAILY -> quota.plans.free.daily
+//
+// Underscores are ambiguous (they separate both path segments and words
+// inside a field name), so the mapping is a lookup table built from the
+// koanf tags rather than a blind "_" -> "." replacement. Lookups fall back
+// to envAliases, then to the plain lower-cased name. Names already written
+// with dots (BOILERPLATE_DATABASE.HOST) keep working unchanged.
+//
+// prefix is the provider's prefix: envPrefix for the shared variables, or a
+// service prefix such as "API_" (see ServiceEnvPrefix).
+func newEnvKeyMapper(prefix string, root reflect.Type) func(string) string {
+	keys := make(map[string]string)
+	var maps []envMapField
+	collectEnvKeys(root, "", "", keys, &maps)
+
+	// Longest prefix first, so nested maps win over their parents.
+	sort.Slice(maps, func(i, j int) bool { return len(maps[i].envName) > len(maps[j].envName) })
+
+	return func(s string) string {
+		name := strings.TrimPrefix(s, prefix)
+
+		if strings.Contains(name, ".") {
+			return strings.ToLower(name)
+		}
+
+		name = strings.ToUpper(name)
+		if key, ok := keys[name]; ok {
+			return key
+		}
+		if key, ok := envAliases[name]; ok {
+			return key
+		}
+
+		for _, m := range maps {
+			rest, ok := strings.CutPrefix(name, m.envName+"_")
+			if !ok || rest == "" {
+				continue
+			}
+
+			if m.values == nil {
+				return m.key + "." + strings.ToLower(rest)
+			}
+			for leafEnv, leafKey := range m.values {
+				if mapKey, ok := strings.CutSuffix(rest, "_"+leafEnv); ok && mapKey != "" {
+					return m.key + "." + strings.ToLower(mapKey) + "." + leafKey
+				}
+			}
+		}
+
+		return strings.ToLower(name)
+	}
+}
+
+// collectEnvKeys walks t and records the env name -> koanf key of every leaf.
+func collectEnvKeys(t reflect.Type, envPath, keyPath string, keys map[string]string, maps *[]envMapField) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := strings.Split(field.Tag.Get("koanf"), ",")[0]
+		if tag == "" {
+			// koanf matches untagged fields by name, case-insensitively.
+			tag = strings.ToLower(field.Name)
+		}
+
+		envName := strings.ToUpper(tag)
+		key := tag
+		if envPath != "" {
+			envName = envPath + "_" + envName
+			key = keyPath + "." + key
+		}
+
+		ft := field.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		switch {
+		case ft.Kind() == reflect.Map:
+			m := envMapField{envName: envName, key: key}
+			if elem := derefType(ft.Elem()); isConfigStruct(elem) {
+				m.values = make(map[string]string)
+				collectEnvKeys(elem, "", "", m.values, maps)
+			}
+			*maps = append(*maps, m)
+
+		case isConfigStruct(ft):
+			collectEnvKeys(ft, envName, key, keys, maps)
+
+		default:
+			keys[envName] = key
+		}
+	}
+}
+
+// isConfigStruct reports whether t is a struct to descend into (as opposed
+// to struct-shaped values like time.Time).
+func isConfigStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{})
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,65 @@
+package config
+
+import "strings"
+
+// Error response formats.
+const (
+	// ErrorFormatJSON is the API's own error envelope (errs.HTTPError).
+	ErrorFormatJSON = "json"
+
+	// ErrorFormatProblem is RFC 7807 application/problem+json (errs.Problem).
+	ErrorFormatProblem = "problem"
+
+	// ErrorFormatNegotiate answers with problem+json to clients whose Accept
+	// header asks for it, and the JSON envelope to everyone else.
+	ErrorFormatNegotiate = "negotiate"
+)
+
+// ErrorResponseConfig controls the shape of error responses written by the
+// global error handler.
+type ErrorResponseConfig struct {
+	// Format is json, problem or negotiate. Defaults to json.
+	Format string `koanf:"format"`
+
+	// ProblemTypeURL is the template of the problem "type" URI; "{code}" is
+	// replaced by the error code, e.g. "https://api.example.com/problems/{code}".
+	// Empty uses the code's docs link (see DocsConfig.ErrorDocsURL), or
+	// "about:blank" for codes without one.
+	ProblemTypeURL string `koanf:"problem_type_url"`
+}
+
+// DefaultErrorResponseConfig keeps the JSON envelope existing clients parse.
+func DefaultErrorResponseConfig() *ErrorResponseConfig {
+	return &ErrorResponseConfig{Format: ErrorFormatJSON}
+}
+
+// GetFormat returns Format, defaulting to json.
+func (c *ErrorResponseConfig) GetFormat() string {
+	if c == nil || c.Format == "" {
+		return ErrorFormatJSON
+	}
+	return c.Format
+}
+
+// ProblemTypeFor returns the problem "type" URI of code; docsURL is the
+// code's documentation link, if any.
+func (c *ErrorResponseConfig) ProblemTypeFor(code, docsURL string) string {
+	switch {
+	case c != nil && c.ProblemTypeURL != "":
+		return strings.ReplaceAll(c.ProblemTypeURL, "{code}", code)
+	case docsURL != "":
+		return docsURL
+	}
+	return "about:blank"
+}
+
+// Validate rejects unknown formats.
+func (c *ErrorResponseConfig) Validate() error {
+	problems := &ValidationError{}
+	switch c.GetFormat() {
+	case ErrorFormatJSON, ErrorFormatProblem, ErrorFormatNegotiate:
+	default:
+		problems.addf("format", "must be %s, %s or %s, got %q", ErrorFormatJSON, ErrorFormatProblem, ErrorFormatNegotiate, c.Format)
+	}
+	return problems.orNil()
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Problem is one missing or invalid setting found by Load.
+type Problem struct {
+	// Key is the koanf key ("database.host") or, for problems reported by a
+	// block's Validate method, the block ("rate_limit").
+	Key string
+
+	// Message says what is wrong ("is required").
+	Message string
+}
+
+// EnvName is the shared env variable setting Key, e.g. BOILERPLATE_DATABASE_HOST.
+func (p Problem) EnvName() string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(p.Key, ".", "_"))
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s (%s): %s", p.Key, p.EnvName(), p.Message)
+}
+
+// ValidationError lists every problem found while validating the config, so
+// a broken deployment is fixed in one round instead of one field per restart.
+//
+// Use errors.As to get at the individual problems:
+//
+//	var verr *config.ValidationError
+//	if errors.As(err, &verr) {
+//		for _, p := range verr.Problems { ... }
+//	}
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid config (%d problem", len(e.Problems))
+	if len(e.Problems) != 1 {
+		b.WriteString("s")
+	}
+	b.WriteString("):")
+	for _, p := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(p.String())
+	}
+	return b.String()
+}
+
+// add records err under key. validator.ValidationErrors are split into one
+// problem per field, and the problems of a nested *ValidationError (from a
+// block's Validate) are kept with their keys prefixed by key; anything else
+// is a single problem.
+func (e *ValidationError) add(key string, err error) {
+	if err == nil {
+		return
+	}
+
+	var nested *ValidationError
+	if errors.As(err, &nested) {
+		for _, p := range nested.Problems {
+			if key != "" {
+				p.Key = key + "." + p.Key
+			}
+			e.Problems = append(e.Problems, p)
+		}
+		return
+	}
+
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		e.Problems = append(e.Problems, Problem{Key: key, Message: err.Error()})
+		return
+	}
+
+	for _, fe := range fieldErrors {
+		// Namespace is "Config.database.host" (see newConfigValidator); the
+		// root type name is dropped, and key prefixes nested struct checks.
+		path := fe.Namespace()
+		if _, rest, ok := strings.Cut(path, "."); ok {
+			path = rest
+		}
+		if key != "" {
+			path = key + "." + path
+		}
+
+		e.Problems = append(e.Problems, Problem{Key: path, Message: describeFieldError(fe)})
+	}
+}
+
+// addf records a problem under key (relative to the block being validated).
+func (e *ValidationError) addf(key, format string, args ...any) {
+	e.Problems = append(e.Problems, Problem{Key: key, Message: fmt.Sprintf(format, args...)})
+}
+
+// orNil returns e, or nil when no problem was recorded.
+func (e *ValidationError) orNil() error {
+	if len(e.Problems) == 0 {
+		return nil
+	}
+	return e
+}
+
+// newConfigValidator returns a validator reporting fields by their koanf
+// names, so problems name the keys people actually set.
+func newConfigValidator() *validator.Validate {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.Split(field.Tag.Get("koanf"), ",")[0]
+		if name == "" || name == "-" {
+			return strings.ToLower(field.Name)
+		}
+		return name
+	})
+	return validate
+}
+
+// describeFieldError translates a validator tag into a readable message.
+func describeFieldError(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return "must be at least " + fe.Param()
+	case "max":
+		return "must not exceed " + fe.Param()
+	case "oneof":
+		return "must be one of: " + fe.Param()
+	case "url":
+		return "must be a valid URL"
+	default:
+		if fe.Param() != "" {
+			return fmt.Sprintf("failed %q (%s)", fe.Tag(), fe.Param())
+		}
+		return fmt.Sprintf("failed %q", fe.Tag())
+	}
+}
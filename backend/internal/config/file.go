@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/knadh/koanf/parsers/toml/v2"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+)
+
+// ConfigFileEnv names the env var pointing at an optional config file.
+//
+// It deliberately has no BOILERPLATE_ prefix: it says where config lives
+// rather than being part of it.
+const ConfigFileEnv = "CONFIG_FILE"
+
+// loadConfigFile loads the YAML or TOML file named by CONFIG_FILE into k.
+//
+// The file uses the same keys as the koanf tags:
+//
+//	server:
+//	  port: "8080"
+//	  cors_allowed_origins: ["https://app.example.com"]
+//	database:
+//	  host: localhost
+//	  max_open_conns: 25
+//
+// It is loaded before the environment, so env vars override file values key
+// by key: keep structure in the file and secrets (passwords, API keys) in env.
+//
+// Without CONFIG_FILE nothing is loaded. A set but unreadable file is an
+// error rather than silently falling back to env-only config.
+func loadConfigFile(k *koanf.Koanf) (string, error) {
+	path := os.Getenv(ConfigFileEnv)
+	if path == "" {
+		return "", nil
+	}
+
+	var parser koanf.Parser
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		parser = yaml.Parser()
+	case ".toml":
+		parser = toml.Parser()
+	default:
+		return path, fmt.Errorf("unsupported config file format %q (use .yaml, .yml or .toml)", filepath.Ext(path))
+	}
+
+	if err := k.Load(file.Provider(path), parser); err != nil {
+		return path, fmt.Errorf("failed to load config file %s: %w", path, err)
+	}
+
+	return path, nil
+}
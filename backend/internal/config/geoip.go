@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GeoIPConfig controls optional GeoIP enrichment of incoming requests.
+//
+// When enabled, every request IP is looked up in a local MaxMind database
+// (GeoLite2-City / GeoIP2-City .mmdb file) and the resolved country/region
+// is attached to logs, traces and auth records.
+//
+// It is optional at the root level (pointer in Config). If omitted, GeoIP is
+// disabled and the middleware becomes a pass-through.
+type GeoIPConfig struct {
+	// Enabled toggles GeoIP lookups entirely.
+	Enabled bool `koanf:"enabled"`
+
+	// DatabasePath is the filesystem path to the MaxMind .mmdb file.
+	// Required when Enabled is true.
+	DatabasePath string `koanf:"database_path"`
+
+	// AllowedCountries is an optional allow list of ISO 3166-1 alpha-2 codes
+	// (e.g. "US", "DE"). If non-empty, requests from any other country are rejected.
+	AllowedCountries []string `koanf:"allowed_countries"`
+
+	// DeniedCountries is an optional deny list of ISO 3166-1 alpha-2 codes.
+	// It is checked after AllowedCountries.
+	DeniedCountries []string `koanf:"denied_countries"`
+
+	// BlockUnknown rejects requests whose country could not be resolved
+	// (private IPs, missing DB entries) when country rules are configured.
+	BlockUnknown bool `koanf:"block_unknown"`
+}
+
+// DefaultGeoIPConfig returns a disabled GeoIP configuration.
+func DefaultGeoIPConfig() *GeoIPConfig {
+	return &GeoIPConfig{
+		Enabled: false,
+	}
+}
+
+// Validate checks that an enabled GeoIP config points at a database
+// and that country codes look like ISO alpha-2 codes.
+func (c *GeoIPConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.DatabasePath == "" {
+		return fmt.Errorf("geoip database_path is required when geoip is enabled")
+	}
+
+	for _, code := range append(append([]string{}, c.AllowedCountries...), c.DeniedCountries...) {
+		if len(strings.TrimSpace(code)) != 2 {
+			return fmt.Errorf("invalid geoip country code: %q (must be ISO 3166-1 alpha-2)", code)
+		}
+	}
+
+	return nil
+}
+
+// HasCountryRules reports whether any allow/deny rule is configured.
+func (c *GeoIPConfig) HasCountryRules() bool {
+	return len(c.AllowedCountries) > 0 || len(c.DeniedCountries) > 0
+}
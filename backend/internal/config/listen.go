@@ -0,0 +1,66 @@
+package config
+
+import "time"
+
+// Defaults for the reconnect backoff of the LISTEN connection.
+const (
+	DefaultListenMinBackoff = 500 * time.Millisecond
+	DefaultListenMaxBackoff = 30 * time.Second
+)
+
+// maxChannelLength is Postgres' identifier limit (NAMEDATALEN - 1); longer
+// channel names are silently truncated by the server.
+const maxChannelLength = 63
+
+// ListenConfig subscribes to Postgres NOTIFY channels on a dedicated
+// connection (see database.Listener), at database.listen.
+//
+// Channels registered in code with Listener.Handle are subscribed anyway;
+// Channels adds ones without a handler of their own, e.g. to watch them in
+// the debug log.
+type ListenConfig struct {
+	// Enabled opens the LISTEN connection at startup.
+	Enabled bool `koanf:"enabled"`
+
+	// Channels are extra channels to LISTEN on (comma-separated in env vars).
+	Channels []string `koanf:"channels"`
+
+	// MinBackoff and MaxBackoff bound the wait between reconnect attempts,
+	// doubled after every failed one. Default to 500ms and 30s.
+	MinBackoff time.Duration `koanf:"min_backoff"`
+	MaxBackoff time.Duration `koanf:"max_backoff"`
+}
+
+// GetMinBackoff returns the effective first reconnect wait.
+func (c ListenConfig) GetMinBackoff() time.Duration {
+	if c.MinBackoff <= 0 {
+		return DefaultListenMinBackoff
+	}
+	return c.MinBackoff
+}
+
+// GetMaxBackoff returns the effective longest reconnect wait.
+func (c ListenConfig) GetMaxBackoff() time.Duration {
+	if c.MaxBackoff <= 0 {
+		return max(DefaultListenMaxBackoff, c.GetMinBackoff())
+	}
+	return c.MaxBackoff
+}
+
+// Validate checks the channel names and the backoff bounds.
+func (c ListenConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	problems := &ValidationError{}
+	for i, channel := range c.Channels {
+		if channel == "" || len(channel) > maxChannelLength {
+			problems.addf("channels", "channel %d must be 1 to %d bytes long", i, maxChannelLength)
+		}
+	}
+	if c.MaxBackoff > 0 && c.MaxBackoff < c.GetMinBackoff() {
+		problems.addf("max_backoff", "must not be below min_backoff (%s)", c.GetMinBackoff())
+	}
+	return problems.orNil()
+}
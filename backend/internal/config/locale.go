@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+
+	"golang.org/x/text/language"
+)
+
+// LocaleConfig controls locale detection (see middleware.LocaleMiddleware).
+type LocaleConfig struct {
+	// Default is the locale used when nothing the client asks for is supported.
+	Default string `koanf:"default"`
+
+	// Supported lists the BCP 47 tags the API can answer in (e.g. "en", "de", "pt-BR").
+	// Error and validation messages are translated for the tags with a
+	// message file in lib/i18n/locales; others get English.
+	Supported []string `koanf:"supported"`
+
+	// QueryParam names the query parameter overriding Accept-Language
+	// (e.g. ?lang=de), handy for links and quick testing. Empty disables it.
+	QueryParam string `koanf:"query_param"`
+}
+
+// DefaultLocaleConfig supports English only, with the ?lang override.
+func DefaultLocaleConfig() *LocaleConfig {
+	return &LocaleConfig{
+		Default:    "en",
+		Supported:  []string{"en"},
+		QueryParam: "lang",
+	}
+}
+
+// Validate requires well-formed tags and a supported default.
+func (c *LocaleConfig) Validate() error {
+	for _, tag := range append([]string{c.Default}, c.Supported...) {
+		if _, err := language.Parse(tag); err != nil {
+			return fmt.Errorf("invalid locale %q: %w", tag, err)
+		}
+	}
+	if !slices.Contains(c.Supported, c.Default) {
+		return fmt.Errorf("default locale %q must be one of the supported locales", c.Default)
+	}
+	return nil
+}
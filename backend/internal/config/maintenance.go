@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaintenanceConfig controls the Redis-backed maintenance mode.
+//
+// The on/off switch itself lives in Redis (toggled via the admin endpoint);
+// this block only configures how the middleware behaves while it is on.
+type MaintenanceConfig struct {
+	// AllowedPaths are route templates that keep working during maintenance
+	// (health checks, docs...). The admin toggle endpoint is always allowed.
+	AllowedPaths []string `koanf:"allowed_paths"`
+
+	// DefaultRetryAfter is the Retry-After (seconds) used when the toggle
+	// request doesn't specify one.
+	DefaultRetryAfter int `koanf:"default_retry_after"`
+
+	// CacheTTL is how long each instance caches the Redis flag.
+	CacheTTL time.Duration `koanf:"cache_ttl"`
+}
+
+// DefaultMaintenanceConfig keeps /status reachable so load balancers don't
+// evict instances during a freeze, and /metrics so dashboards keep working.
+func DefaultMaintenanceConfig() *MaintenanceConfig {
+	return &MaintenanceConfig{
+		AllowedPaths:      []string{"/status", "/version", "/metrics"},
+		DefaultRetryAfter: 300,
+		CacheTTL:          2 * time.Second,
+	}
+}
+
+// Validate rejects negative durations.
+func (c *MaintenanceConfig) Validate() error {
+	if c.DefaultRetryAfter < 0 {
+		return fmt.Errorf("maintenance default_retry_after must be non-negative")
+	}
+	if c.CacheTTL < 0 {
+		return fmt.Errorf("maintenance cache_ttl must be non-negative")
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// MetricsConfig configures the Prometheus endpoint and the RED (rate,
+// errors, duration) request metrics (see server/metrics.go).
+type MetricsConfig struct {
+	// Enabled records request metrics and serves them on Path.
+	Enabled bool `koanf:"enabled"`
+
+	// Path is where the metrics are served, outside /api so it is never
+	// rate limited per user or versioned.
+	Path string `koanf:"path"`
+
+	// Secret, when set, must be sent as "Authorization: Bearer <secret>".
+	// Leave it empty when the endpoint is only reachable from the cluster
+	// network (the usual Prometheus setup).
+	Secret string `koanf:"secret"`
+
+	// DurationBuckets are the request latency histogram buckets, in seconds.
+	DurationBuckets []float64 `koanf:"duration_buckets"`
+
+	// PoolStatsInterval is how often database/Redis pool statistics are
+	// pushed to New Relic as custom metrics (Prometheus reads them at scrape
+	// time instead). 0 disables it. Independent of Enabled.
+	PoolStatsInterval time.Duration `koanf:"pool_stats_interval"`
+}
+
+// DefaultMetricsConfig serves /metrics with Prometheus' default buckets.
+func DefaultMetricsConfig() MetricsConfig {
+	return MetricsConfig{
+		Enabled:         true,
+		Path:            "/metrics",
+		DurationBuckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+
+		PoolStatsInterval: 30 * time.Second,
+	}
+}
+
+// Validate checks the path, that buckets are positive and ascending, and
+// the pool stats interval.
+func (c MetricsConfig) Validate() error {
+	problems := &ValidationError{}
+	if c.PoolStatsInterval < 0 {
+		problems.addf("pool_stats_interval", "must not be negative")
+	}
+	if !c.Enabled {
+		return problems.orNil()
+	}
+
+	if !strings.HasPrefix(c.Path, "/") {
+		problems.addf("path", "must start with /")
+	}
+	if len(c.DurationBuckets) == 0 {
+		problems.addf("duration_buckets", "must not be empty")
+	} else if c.DurationBuckets[0] <= 0 || !slices.IsSorted(c.DurationBuckets) || len(slices.Compact(slices.Clone(c.DurationBuckets))) != len(c.DurationBuckets) {
+		problems.addf("duration_buckets", "must be positive and strictly ascending, got %s", fmt.Sprint(c.DurationBuckets))
+	}
+	return problems.orNil()
+}
@@ -1,7 +1,9 @@
 package config
 
 import (
-	"fmt"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -9,14 +11,15 @@ import (
 //
 // This typically includes:
 //   - logging settings (format, level, thresholds)
-//   - APM/tracing provider settings (New Relic here)
+//   - APM/tracing provider settings (New Relic or OTLP)
+//   - Prometheus metrics
 //   - health check settings (liveness/readiness style checks)
 //
 // It is intended to be embedded under Config.Observability and can be optional
 // at the root-level (pointer in Config). If omitted, defaults are injected.
 type ObservabilityConfig struct {
-	// ServiceName identifies this service in logs/traces/APM dashboards.
-	// Usually hardcoded per service to avoid people "configuring" it into chaos.
+	// ServiceName identifies this service in logs/traces/APM dashboards
+	// (and is the New Relic app name). Primary.ServiceName overrides it.
 	ServiceName string `koanf:"service_name" validate:"required"`
 
 	// Environment is a label used to split telemetry by environment
@@ -29,8 +32,17 @@ type ObservabilityConfig struct {
 	// NewRelic config controls APM and tracing features.
 	NewRelic NewRelicConfig `koanf:"new_relic" validate:"required"`
 
+	// Tracing selects where request/job/query traces are sent.
+	Tracing TracingConfig `koanf:"tracing"`
+
+	// Metrics configures the Prometheus endpoint (see metrics.go).
+	Metrics MetricsConfig `koanf:"metrics"`
+
 	// HealthChecks config controls periodic dependency health checks.
 	HealthChecks HealthChecksConfig `koanf:"health_checks" validate:"required"`
+
+	// Tenants controls how tenant/organization IDs are attached to telemetry.
+	Tenants TenantTelemetryConfig `koanf:"tenants"`
 }
 
 // LoggingConfig holds application logging configuration.
@@ -45,19 +57,84 @@ type LoggingConfig struct {
 
 	// SlowQueryThreshold is a duration beyond which queries are considered slow
 	// and should be logged/flagged. Optional, defaults can be set.
+	// The database logs (and records on the trace) every query at least this
+	// slow, in all environments; 0 disables it, failed queries are still logged.
 	//
 	// Type is time.Duration, so env/config should supply parseable duration
 	// strings like "100ms", "1s", "250ms". If you supply "100" it will not mean
 	// 100ms; it will mean 100ns if parsed incorrectly elsewhere.
 	SlowQueryThreshold time.Duration `koanf:"slow_query_threshold"`
+
+	// SlowRequestThreshold flags HTTP requests taking at least this long:
+	// their request log is raised to warn and tagged slow_request=true, and a
+	// "SlowRequest" event is recorded on the trace (a New Relic custom event,
+	// for alert conditions). 0 disables it.
+	SlowRequestThreshold time.Duration `koanf:"slow_request_threshold"`
+
+	// Sampling thins out high-volume debug/info logs (see logger/sampling.go).
+	Sampling LogSamplingConfig `koanf:"sampling"`
+
+	// Output is where logs go: stdout (default), file, or both. Files always
+	// get JSON lines, whatever Format says for stdout.
+	Output string `koanf:"output"`
+
+	// File configures the log file used by the file and both outputs.
+	File LogFileConfig `koanf:"file"`
+}
+
+// Log outputs (LoggingConfig.Output).
+const (
+	LogOutputStdout = "stdout"
+	LogOutputFile   = "file"
+	LogOutputBoth   = "both"
+)
+
+// LogFileConfig configures the rotating log file (see logger.RotatingFile).
+type LogFileConfig struct {
+	// Path of the current log file; rotated files are written next to it.
+	Path string `koanf:"path"`
+
+	// MaxSizeMB is the size at which the file is rotated. 0 never rotates.
+	MaxSizeMB int `koanf:"max_size_mb"`
+
+	// MaxAge deletes rotated files older than this. 0 keeps them.
+	MaxAge time.Duration `koanf:"max_age"`
+
+	// MaxBackups is the number of rotated files kept. 0 keeps them all.
+	MaxBackups int `koanf:"max_backups"`
+}
+
+// LogSamplingConfig keeps 1 in N debug/info log events. Warn and error
+// events are never sampled.
+//
+// Rates are per message, so the noisy lines can be thinned without losing
+// the rare ones. For example, to keep every tenth request log ("API") and
+// every other remaining info log:
+//
+//	BOILERPLATE_OBSERVABILITY_LOGGING_SAMPLING_ENABLED=true
+//	BOILERPLATE_OBSERVABILITY_LOGGING_SAMPLING_EVERY=2
+//	BOILERPLATE_OBSERVABILITY_LOGGING_SAMPLING_EVENTS_API=10
+type LogSamplingConfig struct {
+	// Enabled turns sampling on.
+	Enabled bool `koanf:"enabled"`
+
+	// Every keeps 1 in Every debug/info events whose message has no entry
+	// in Events. 0 and 1 keep them all.
+	Every uint32 `koanf:"every"`
+
+	// Events sets the rate per log message, matched case-insensitively
+	// ("api" matches the request log). 0 and 1 keep them all.
+	Events map[string]uint32 `koanf:"events"`
 }
 
 // NewRelicConfig holds configuration for New Relic APM and tracing.
 //
 // LicenseKey is required if New Relic is actually used. Others are feature toggles.
 type NewRelicConfig struct {
-	// LicenseKey is the New Relic ingest key. Empty means "not configured".
-	LicenseKey string `koanf:"license_key" validate:"required"`
+	// LicenseKey is the New Relic ingest key. Empty means "not configured"
+	// (New Relic is skipped), so it is not required: every profile includes
+	// this block.
+	LicenseKey string `koanf:"license_key"`
 
 	// AppLogForwardingEnabled enables forwarding of application logs to New Relic
 	// (if the agent supports it and is configured).
@@ -72,6 +149,45 @@ type NewRelicConfig struct {
 	DebugLogging bool `koanf:"debug_logging"`
 }
 
+// Tracing providers (TracingConfig.Provider).
+const (
+	TracingNewRelic = "newrelic"
+	TracingOTLP     = "otlp"
+	TracingNone     = "none"
+)
+
+// TracingConfig selects the APM/tracing backend (see lib/tracing).
+//
+// "newrelic" keeps the New Relic agent (a no-op without a license key);
+// "otlp" exports OpenTelemetry spans over OTLP/HTTP, which Jaeger, Grafana
+// Tempo, the Datadog agent and any OpenTelemetry Collector accept.
+// Log forwarding stays a New Relic feature either way.
+type TracingConfig struct {
+	// Provider is one of newrelic, otlp or none.
+	Provider string `koanf:"provider"`
+
+	// OTLP configures the exporter used by the otlp provider.
+	OTLP OTLPConfig `koanf:"otlp"`
+}
+
+// OTLPConfig configures OTLP/HTTP (protobuf) span export.
+type OTLPConfig struct {
+	// Endpoint is the collector base URL, e.g. http://localhost:4318;
+	// spans are POSTed to Endpoint + "/v1/traces".
+	Endpoint string `koanf:"endpoint"`
+
+	// Headers are added to every export request, typically authentication
+	// (Authorization, dd-api-key, ...). Values are redacted in config print.
+	Headers map[string]string `koanf:"headers"`
+
+	// SampleRatio is the share of new traces recorded, from 0 to 1. Requests
+	// continuing a trace (traceparent header) follow the caller's decision.
+	SampleRatio float64 `koanf:"sample_ratio"`
+
+	// Timeout bounds one export request.
+	Timeout time.Duration `koanf:"timeout"`
+}
+
 // HealthChecksConfig controls periodic checks for dependencies.
 //
 // This is typically used for:
@@ -91,18 +207,47 @@ type HealthChecksConfig struct {
 
 	// Checks is a list of check names to run (e.g. database, redis).
 	// The code elsewhere likely maps these strings to actual check functions.
+	// Names must be in HealthCheckNames.
 	Checks []string `koanf:"checks"`
 }
 
-// DefaultObservabilityConfig provides a safe set of defaults.
+// HealthCheckNames are the dependencies the server knows how to check
+// (the server.Dependency* names).
+var HealthCheckNames = []string{"database", "redis", "email", "search"}
+
+// TenantTelemetryConfig guards metric/trace cardinality for tenant dimensions.
 //
-// Used when Config.Observability is nil (not provided via env/config).
+// Logs always carry the raw tenant_id (logs are cheap to index by value).
+// Metrics and trace attributes, however, are billed/stored per unique value,
+// so only TrackedTenants are reported verbatim; every other tenant is hashed
+// into one of Buckets stable buckets ("bucket-07").
+type TenantTelemetryConfig struct {
+	// TrackedTenants are tenant IDs reported as-is in metrics/traces
+	// (typically your largest or most important customers).
+	TrackedTenants []string `koanf:"tracked_tenants"`
+
+	// Buckets is the number of hash buckets for untracked tenants.
+	// 0 means DefaultTenantBuckets.
+	Buckets int `koanf:"buckets"`
+}
+
+// DefaultTenantBuckets is used when TenantTelemetryConfig.Buckets is not set.
+const DefaultTenantBuckets = 64
+
+// GetBuckets returns the effective bucket count.
+func (c TenantTelemetryConfig) GetBuckets() int {
+	if c.Buckets <= 0 {
+		return DefaultTenantBuckets
+	}
+	return c.Buckets
+}
+
+// DefaultObservabilityConfig provides a safe set of defaults.
 // Defaults aim to be sensible for local dev, while not breaking production.
 func DefaultObservabilityConfig() *ObservabilityConfig {
 	return &ObservabilityConfig{
-		// Default service/environment are overwritten in loadConfig()
-		// in your config.go: ServiceName forced to "boilerplate",
-		// Environment derived from primary.env.
+		// Default service/environment; Load() lets primary.service_name
+		// override ServiceName and derives Environment from primary.env.
 		ServiceName: "boilerplate",
 		Environment: "development",
 
@@ -111,22 +256,49 @@ func DefaultObservabilityConfig() *ObservabilityConfig {
 		// - json format works well in log aggregators
 		// - 100ms threshold is a common "hmm maybe slow" boundary
 		Logging: LoggingConfig{
-			Level:              "info",
-			Format:             "json",
-			SlowQueryThreshold: 100 * time.Millisecond,
+			Level:                "info",
+			Format:               "json",
+			SlowQueryThreshold:   100 * time.Millisecond,
+			SlowRequestThreshold: time.Second,
+			Sampling: LogSamplingConfig{
+				Enabled: false,
+				Every:   1,
+				Events:  map[string]uint32{},
+			},
+			Output: LogOutputStdout,
+			File: LogFileConfig{
+				Path:       "logs/app.log",
+				MaxSizeMB:  100,
+				MaxAge:     7 * 24 * time.Hour,
+				MaxBackups: 5,
+			},
 		},
 
 		// New Relic defaults:
-		// - LicenseKey empty (but note: validate tags + Validate() can conflict with this)
-		// - app log forwarding + distributed tracing enabled by default
+		// - LicenseKey empty, i.e. New Relic off until a key is set
+		// - distributed tracing enabled by default
+		// - app log forwarding off: it requires a license key (see Validate),
+		//   so turn it on together with the key
 		// - debug off to prevent mixed log formats/noise
 		NewRelic: NewRelicConfig{
 			LicenseKey:                "",
-			AppLogForwardingEnabled:   true,
+			AppLogForwardingEnabled:   false,
 			DistributedTracingEnabled: true,
 			DebugLogging:              false, // Disabled by default to avoid mixed log formats
 		},
 
+		// Tracing defaults: New Relic, as before there was a choice. The OTLP
+		// settings only apply once provider is switched to "otlp".
+		Tracing: TracingConfig{
+			Provider: TracingNewRelic,
+			OTLP: OTLPConfig{
+				SampleRatio: 1,
+				Timeout:     10 * time.Second,
+			},
+		},
+
+		Metrics: DefaultMetricsConfig(),
+
 		// Health checks defaults:
 		// - enabled
 		// - check every 30 seconds, allow 5 seconds per run
@@ -137,6 +309,12 @@ func DefaultObservabilityConfig() *ObservabilityConfig {
 			Timeout:  5 * time.Second,
 			Checks:   []string{"database", "redis"},
 		},
+
+		// Tenant telemetry defaults:
+		// - no tenants tracked verbatim, everything is bucketed
+		Tenants: TenantTelemetryConfig{
+			Buckets: DefaultTenantBuckets,
+		},
 	}
 }
 
@@ -147,12 +325,14 @@ func DefaultObservabilityConfig() *ObservabilityConfig {
 //
 // Returns:
 //   - nil if configuration is valid
-//   - an error describing the first validation failure
+//   - a *ValidationError listing every failure (keys relative to the block)
 func (c *ObservabilityConfig) Validate() error {
+	problems := &ValidationError{}
+
 	// ServiceName must not be empty. This is partially redundant with validate:"required",
 	// but needed if you ever bypass the struct-tag validator or set values manually.
 	if c.ServiceName == "" {
-		return fmt.Errorf("service_name is required")
+		problems.addf("service_name", "is required")
 	}
 
 	// Validate log levels: enforce a strict set of allowed values.
@@ -167,16 +347,76 @@ func (c *ObservabilityConfig) Validate() error {
 	// If the configured level isn't in the map, reject it.
 	// This prevents typos like "inf" silently degrading into nonsense.
 	if !validLevels[c.Logging.Level] {
-		return fmt.Errorf("invalid logging level: %s (must be one of: debug, info, warn, error)", c.Logging.Level)
+		problems.addf("logging.level", "invalid logging level %q (must be one of: debug, info, warn, error)", c.Logging.Level)
 	}
 
 	// Validate slow query threshold:
 	// duration < 0 makes no sense (you can’t be slower than negative time).
 	if c.Logging.SlowQueryThreshold < 0 {
-		return fmt.Errorf("logging slow_query_threshold mus be non-negative")
+		problems.addf("logging.slow_query_threshold", "must be non-negative")
+	}
+	if c.Logging.SlowRequestThreshold < 0 {
+		problems.addf("logging.slow_request_threshold", "must be non-negative")
+	}
+
+	switch c.Logging.Output {
+	case LogOutputStdout, "":
+	case LogOutputFile, LogOutputBoth:
+		if c.Logging.File.Path == "" {
+			problems.addf("logging.file.path", "is required for the %s output", c.Logging.Output)
+		}
+		if c.Logging.File.MaxSizeMB < 0 || c.Logging.File.MaxAge < 0 || c.Logging.File.MaxBackups < 0 {
+			problems.addf("logging.file", "max_size_mb, max_age and max_backups must be non-negative")
+		}
+	default:
+		problems.addf("logging.output", "invalid output %q (must be one of: %s, %s, %s)",
+			c.Logging.Output, LogOutputStdout, LogOutputFile, LogOutputBoth)
+	}
+
+	// New Relic toggles only mean something with a license key: without one
+	// the agent is never started, and forwarded logs would silently go nowhere.
+	if c.NewRelic.LicenseKey == "" {
+		if c.NewRelic.AppLogForwardingEnabled {
+			problems.addf("new_relic.app_log_forwarding_enabled", "requires new_relic.license_key")
+		}
+		if c.NewRelic.DebugLogging {
+			problems.addf("new_relic.debug_logging", "requires new_relic.license_key")
+		}
+	}
+
+	switch c.Tracing.Provider {
+	case TracingNewRelic, TracingNone:
+	case TracingOTLP:
+		if c.Tracing.OTLP.Endpoint == "" {
+			problems.addf("tracing.otlp.endpoint", "is required for the otlp provider")
+		} else if u, err := url.Parse(c.Tracing.OTLP.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			problems.addf("tracing.otlp.endpoint", "must be an absolute URL (e.g. http://localhost:4318)")
+		}
+		if c.Tracing.OTLP.SampleRatio < 0 || c.Tracing.OTLP.SampleRatio > 1 {
+			problems.addf("tracing.otlp.sample_ratio", "must be between 0 and 1")
+		}
+		if c.Tracing.OTLP.Timeout <= 0 {
+			problems.addf("tracing.otlp.timeout", "must be positive")
+		}
+	default:
+		problems.addf("tracing.provider", "unknown provider %q (must be one of: %s, %s, %s)",
+			c.Tracing.Provider, TracingNewRelic, TracingOTLP, TracingNone)
+	}
+
+	problems.add("metrics", c.Metrics.Validate())
+
+	// A check that may run as long as the interval would overlap the next run.
+	if c.HealthChecks.Enabled && c.HealthChecks.Timeout >= c.HealthChecks.Interval {
+		problems.addf("health_checks.timeout", "must be shorter than health_checks.interval (%s)", c.HealthChecks.Interval)
+	}
+
+	for _, name := range c.HealthChecks.Checks {
+		if !slices.Contains(HealthCheckNames, name) {
+			problems.addf("health_checks.checks", "unknown check %q (must be one of: %s)", name, strings.Join(HealthCheckNames, ", "))
+		}
 	}
 
-	return nil
+	return problems.orNil()
 }
 
 // GetLogLevel returns the effective log level to use at runtime.
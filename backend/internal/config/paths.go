@@ -0,0 +1,69 @@
+package config
+
+import "fmt"
+
+// Trailing slash policies accepted by PathConfig.TrailingSlash.
+const (
+	TrailingSlashStrip = "strip"
+	TrailingSlashAdd   = "add"
+	TrailingSlashKeep  = "keep"
+)
+
+// Path normalization modes accepted by PathConfig.Mode.
+const (
+	PathModeRedirect = "redirect"
+	PathModeRewrite  = "rewrite"
+)
+
+// PathConfig controls request path normalization.
+//
+// Without normalization "/users", "/users/" and "//users" are different cache
+// keys and tempt people into registering the same route twice. The middleware
+// canonicalizes the path before routing so only one form ever reaches handlers.
+type PathConfig struct {
+	// TrailingSlash is "strip" (default), "add" or "keep".
+	TrailingSlash string `koanf:"trailing_slash"`
+
+	// CollapseSlashes turns "//a///b" into "/a/b". Leading slashes are
+	// collapsed even when it is off (see middleware.normalizePath).
+	CollapseSlashes bool `koanf:"collapse_slashes"`
+
+	// LowercaseHost lowercases the Host header (hostnames are case-insensitive).
+	LowercaseHost bool `koanf:"lowercase_host"`
+
+	// Mode is "redirect" (default: 308 to the canonical URL, so clients and
+	// caches learn it) or "rewrite" (serve the canonical route transparently).
+	Mode string `koanf:"mode"`
+}
+
+// DefaultPathConfig strips trailing slashes, collapses duplicate slashes and
+// redirects with 308 (which, unlike 301, preserves the method and body).
+func DefaultPathConfig() *PathConfig {
+	return &PathConfig{
+		TrailingSlash:   TrailingSlashStrip,
+		CollapseSlashes: true,
+		LowercaseHost:   true,
+		Mode:            PathModeRedirect,
+	}
+}
+
+// Validate checks the enum fields, defaulting empty values.
+func (c *PathConfig) Validate() error {
+	switch c.TrailingSlash {
+	case "":
+		c.TrailingSlash = TrailingSlashStrip
+	case TrailingSlashStrip, TrailingSlashAdd, TrailingSlashKeep:
+	default:
+		return fmt.Errorf("invalid paths trailing_slash: %s (must be one of: strip, add, keep)", c.TrailingSlash)
+	}
+
+	switch c.Mode {
+	case "":
+		c.Mode = PathModeRedirect
+	case PathModeRedirect, PathModeRewrite:
+	default:
+		return fmt.Errorf("invalid paths mode: %s (must be one of: redirect, rewrite)", c.Mode)
+	}
+
+	return nil
+}
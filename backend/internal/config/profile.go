@@ -0,0 +1,179 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Environment profiles recognized by DefaultConfigFor (primary.env).
+const (
+	EnvLocal       = "local"
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// DefaultConfigFor returns the defaults of the profile named env.
+//
+// Load layers them under the config file and env vars, so a deployment only
+// sets what differs from its profile. Locally that is little more than the
+// secrets:
+//
+//	BOILERPLATE_PRIMARY_ENV=local
+//	BOILERPLATE_INTEGRATION_RESEND_API_KEY=...
+//	BOILERPLATE_AUTH_SECRET_KEY=...
+//
+// local and development point at services on localhost with verbose console
+// logs and relaxed rate limits. staging and production leave connection
+// settings empty (still required) and use TLS to the database and JSON logs;
+// production also gets a larger pool (applied by database.New). Unknown
+// environments get the shared defaults only.
+func DefaultConfigFor(env string) *Config {
+	cfg := &Config{
+		Primary: Primary{Env: env},
+		Server: ServerConfig{
+			Port:         "8080",
+			ReadTimeout:  30,
+			WriteTimeout: 30,
+			IdleTimeout:  60,
+			CORSMaxAge:   10 * time.Minute,
+		},
+		Database: DatabaseConfig{
+			Port:            5432,
+			MaxOpenConns:    10,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: int((30 * time.Minute).Seconds()),
+			ConnMaxIdleTime: int((5 * time.Minute).Seconds()),
+		},
+		Observability: DefaultObservabilityConfig(),
+		GeoIP:         DefaultGeoIPConfig(),
+		Docs:          DefaultDocsConfig(env),
+		RateLimit:     DefaultRateLimitConfig(),
+		CSRF:          DefaultCSRFConfig(),
+		Paths:         DefaultPathConfig(),
+		RequestID:     DefaultRequestIDConfig(),
+		Maintenance:   DefaultMaintenanceConfig(),
+		Timeouts:      DefaultTimeoutConfig(),
+		Proxy:         DefaultProxyConfig(),
+		Audit:         DefaultAuditConfig(),
+		QueryBudget:   DefaultQueryBudgetConfig(),
+		Quota:         DefaultQuotaConfig(),
+		Locale:        DefaultLocaleConfig(),
+		RPC:           DefaultRPCConfig(),
+		Search:        DefaultSearchConfig(),
+		Embedding:     DefaultEmbeddingConfig(),
+		Tenancy:       DefaultTenancyConfig(),
+		Encryption:    DefaultEncryptionConfig(),
+		ErrorResponse: DefaultErrorResponseConfig(),
+	}
+
+	switch env {
+	case EnvLocal, EnvDevelopment:
+		cfg.Server.CORSAllowedOrigins = []string{"http://localhost:3000"}
+		cfg.Database.Host = "localhost"
+		cfg.Database.User = "postgres"
+		cfg.Database.Password = "postgres"
+		cfg.Database.Name = "boilerplate"
+		cfg.Database.SSLMode = "disable"
+		cfg.Redis.Address = "localhost:6379"
+
+		cfg.Observability.Logging.Level = "debug"
+		cfg.Observability.Logging.Format = "console"
+
+		// One developer clicking around (plus hot reloads) shouldn't hit 429s.
+		cfg.RateLimit.AnonymousRPS = 200
+		cfg.RateLimit.AuthenticatedRPS = 500
+
+	case EnvStaging:
+		cfg.Database.SSLMode = "require"
+
+	case EnvProduction:
+		cfg.Database.SSLMode = "require"
+		cfg.Database.MaxOpenConns = 25
+		cfg.Database.MaxIdleConns = 25
+	}
+
+	return cfg
+}
+
+// defaultsProvider is a koanf.Provider serving a Config as koanf keys, so
+// profile defaults can be the lowest layer of Load.
+type defaultsProvider struct {
+	cfg *Config
+}
+
+func (p defaultsProvider) ReadBytes() ([]byte, error) {
+	return nil, errors.New("defaults provider does not support ReadBytes()")
+}
+
+func (p defaultsProvider) Read() (map[string]interface{}, error) {
+	return structToKeys(reflect.ValueOf(p.cfg)), nil
+}
+
+// structToKeys converts a config struct into the nested map koanf holds,
+// keyed by koanf tags. Nil blocks, maps and slices are left out; zero
+// scalars are kept so that every block of the profile exists after Unmarshal.
+func structToKeys(v reflect.Value) map[string]interface{} {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	out := make(map[string]interface{})
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := strings.Split(field.Tag.Get("koanf"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+		if tag == "" {
+			tag = strings.ToLower(field.Name)
+		}
+
+		if value := keyValue(v.Field(i)); value != nil {
+			out[tag] = value
+		}
+	}
+	return out
+}
+
+// keyValue returns the koanf value of one field, or nil when it is unset.
+func keyValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+	}
+
+	ft := derefType(v.Type())
+	switch {
+	case isConfigStruct(ft):
+		return structToKeys(v)
+
+	case ft.Kind() == reflect.Map:
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if isConfigStruct(derefType(iter.Value().Type())) {
+				m[key] = structToKeys(iter.Value())
+			} else {
+				m[key] = iter.Value().Interface()
+			}
+		}
+		return m
+
+	default:
+		return v.Interface()
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Client IP headers accepted by ProxyConfig.IPHeader.
+const (
+	IPHeaderXForwardedFor  = "x-forwarded-for"
+	IPHeaderXRealIP        = "x-real-ip"
+	IPHeaderCFConnectingIP = "cf-connecting-ip"
+	IPHeaderNone           = "none"
+)
+
+// ProxyConfig controls how the client IP (c.RealIP()) is derived.
+//
+// Logging, rate limiting, GeoIP and tracing all key on c.RealIP(). Echo's
+// default trusts X-Forwarded-For from anyone, so any client could pick its
+// own IP (and its own rate limit bucket). Here forwarding headers are only
+// honored when the direct peer is a trusted proxy.
+type ProxyConfig struct {
+	// TrustedProxies are CIDRs of load balancers/proxies in front of the app.
+	// Empty means "trust loopback and private networks" (typical for a
+	// single LB in a VPC); once set, only these ranges (plus loopback) are trusted.
+	TrustedProxies []string `koanf:"trusted_proxies"`
+
+	// IPHeader selects where the client IP comes from:
+	//   "x-forwarded-for" (default), "x-real-ip", "cf-connecting-ip" (Cloudflare),
+	//   or "none" (use the TCP peer address, for apps exposed directly).
+	IPHeader string `koanf:"ip_header"`
+}
+
+// DefaultProxyConfig reads X-Forwarded-For from loopback/private proxies.
+func DefaultProxyConfig() *ProxyConfig {
+	return &ProxyConfig{
+		IPHeader: IPHeaderXForwardedFor,
+	}
+}
+
+// ParsedTrustedProxies returns TrustedProxies as networks.
+func (c *ProxyConfig) ParsedTrustedProxies() ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(c.TrustedProxies))
+	for _, cidr := range c.TrustedProxies {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy trusted proxy %q: %w", cidr, err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// Validate checks the header choice and CIDRs.
+func (c *ProxyConfig) Validate() error {
+	c.IPHeader = strings.ToLower(c.IPHeader)
+
+	switch c.IPHeader {
+	case "":
+		c.IPHeader = IPHeaderXForwardedFor
+	case IPHeaderXForwardedFor, IPHeaderXRealIP, IPHeaderCFConnectingIP, IPHeaderNone:
+	default:
+		return fmt.Errorf("invalid proxy ip_header: %s (must be one of: x-forwarded-for, x-real-ip, cf-connecting-ip, none)", c.IPHeader)
+	}
+
+	_, err := c.ParsedTrustedProxies()
+	return err
+}
@@ -0,0 +1,42 @@
+package config
+
+import "fmt"
+
+// QueryBudgetConfig configures the per-request database statement budget
+// (see database.QueryBudget and middleware.QueryBudgetMiddleware).
+type QueryBudgetConfig struct {
+	// Enabled turns query counting on.
+	Enabled bool `koanf:"enabled"`
+
+	// MaxQueries is the number of statements a single request may issue
+	// before it is logged as over budget.
+	MaxQueries int `koanf:"max_queries"`
+
+	// RepeatThreshold is how many times the same statement may run within one
+	// request before it is reported as a likely N+1 pattern.
+	RepeatThreshold int `koanf:"repeat_threshold"`
+}
+
+// DefaultQueryBudgetConfig allows 25 statements per request and flags any
+// statement executed 5 times or more.
+func DefaultQueryBudgetConfig() *QueryBudgetConfig {
+	return &QueryBudgetConfig{
+		Enabled:         true,
+		MaxQueries:      25,
+		RepeatThreshold: 5,
+	}
+}
+
+// Validate rejects non-positive limits when the budget is enabled.
+func (c *QueryBudgetConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.MaxQueries <= 0 {
+		return fmt.Errorf("query_budget max_queries must be positive")
+	}
+	if c.RepeatThreshold <= 1 {
+		return fmt.Errorf("query_budget repeat_threshold must be greater than 1")
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/deppfellow/go-boilerplate/internal/lib/quota"
+)
+
+// QuotaConfig configures per-plan usage quotas (see lib/quota).
+type QuotaConfig struct {
+	// Enabled turns quota enforcement on for routes declared with
+	// handler.Route(h).Quota(). The usage endpoint works either way.
+	Enabled bool `koanf:"enabled"`
+
+	// DefaultPlan applies to callers without an explicit plan.
+	DefaultPlan string `koanf:"default_plan"`
+
+	// Plans maps plan names to their limits (0 = unlimited).
+	Plans map[string]quota.Limits `koanf:"plans"`
+}
+
+// DefaultQuotaConfig defines "free" and "pro" plans, with enforcement off.
+func DefaultQuotaConfig() *QuotaConfig {
+	return &QuotaConfig{
+		Enabled:     false,
+		DefaultPlan: "free",
+		Plans: map[string]quota.Limits{
+			"free": {Daily: 1_000, Monthly: 20_000},
+			"pro":  {Daily: 100_000, Monthly: 2_000_000},
+		},
+	}
+}
+
+// LimitsFor returns the limits of plan, falling back to DefaultPlan.
+func (c *QuotaConfig) LimitsFor(plan string) (string, quota.Limits) {
+	if limits, ok := c.Plans[plan]; ok {
+		return plan, limits
+	}
+	return c.DefaultPlan, c.Plans[c.DefaultPlan]
+}
+
+// Validate requires the default plan to exist and limits to be non-negative.
+func (c *QuotaConfig) Validate() error {
+	if _, ok := c.Plans[c.DefaultPlan]; !ok {
+		return fmt.Errorf("quota default_plan %q is not defined in plans", c.DefaultPlan)
+	}
+	for name, limits := range c.Plans {
+		if limits.Daily < 0 || limits.Monthly < 0 {
+			return fmt.Errorf("quota plan %q: limits must be non-negative", name)
+		}
+	}
+	return nil
+}
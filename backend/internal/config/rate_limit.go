@@ -0,0 +1,36 @@
+package config
+
+import "fmt"
+
+// RateLimitConfig controls the global request rate limiter.
+//
+// Budgets are per identity: authenticated traffic is keyed by user ID or API
+// key, anonymous traffic by client IP, and each class has its own budget.
+// Requests with credentials are also capped per IP at the authenticated
+// budget before authentication (see middleware.RateLimitMiddleware.Global).
+type RateLimitConfig struct {
+	// AnonymousRPS is the requests/second budget per client IP.
+	AnonymousRPS float64 `koanf:"anonymous_rps"`
+
+	// AuthenticatedRPS is the requests/second budget per user ID / API key.
+	AuthenticatedRPS float64 `koanf:"authenticated_rps"`
+}
+
+// DefaultRateLimitConfig returns the default budgets.
+func DefaultRateLimitConfig() *RateLimitConfig {
+	return &RateLimitConfig{
+		AnonymousRPS:     20,
+		AuthenticatedRPS: 50,
+	}
+}
+
+// Validate ensures both budgets are positive.
+func (c *RateLimitConfig) Validate() error {
+	if c.AnonymousRPS <= 0 {
+		return fmt.Errorf("rate_limit anonymous_rps must be positive")
+	}
+	if c.AuthenticatedRPS <= 0 {
+		return fmt.Errorf("rate_limit authenticated_rps must be positive")
+	}
+	return nil
+}
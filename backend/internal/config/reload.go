@@ -0,0 +1,208 @@
+package config
+
+import (
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// reloadPollInterval is how often the watcher checks CONFIG_FILE for changes.
+const reloadPollInterval = 5 * time.Second
+
+// Change describes a successful reload.
+type Change struct {
+	// Old and New are the config before and after. New is Old with the
+	// reloadable settings replaced; everything else still needs a restart.
+	Old, New *Config
+
+	// Keys lists the reloadable settings that changed (e.g. "rate_limit").
+	Keys []string
+}
+
+// Has reports whether key is among the changed settings.
+func (c Change) Has(key string) bool {
+	return slices.Contains(c.Keys, key)
+}
+
+// Reloadable setting keys, as reported in Change.Keys.
+const (
+	ReloadLogLevel    = "observability.logging.level"
+	ReloadRateLimit   = "rate_limit"
+	ReloadCORSOrigins = "server.cors_allowed_origins"
+	ReloadQuota       = "quota"
+)
+
+// Subscriber is notified after each reload that changed something.
+type Subscriber func(Change)
+
+// Watcher reloads the non-critical settings of a running process on SIGHUP
+// or when CONFIG_FILE changes, and notifies subscribers.
+//
+// Only a few settings are reloadable: the log level, rate limits, CORS
+// origins and the quota block (enforcement flag and plan limits). They are
+// safe to change between two requests; database, Redis or port changes are
+// ignored until the next restart. A reload that fails to load or validate is
+// logged and the current config stays in place.
+type Watcher struct {
+	mu          sync.RWMutex
+	current     *Config
+	subscribers []Subscriber
+	logger      *zerolog.Logger
+
+	fileModTime time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewWatcher creates a Watcher starting from cfg.
+func NewWatcher(cfg *Config, logger *zerolog.Logger) *Watcher {
+	w := &Watcher{current: cfg, logger: logger}
+	w.fileModTime, _ = configFileModTime()
+	return w
+}
+
+// Current returns the latest config. Code reading reloadable settings per
+// request should go through it rather than a *Config captured at startup.
+func (w *Watcher) Current() *Config {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.current
+}
+
+// Subscribe registers fn for change notifications. Subscribers run
+// synchronously, in registration order, on the watcher's goroutine.
+func (w *Watcher) Subscribe(fn Subscriber) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Reload re-reads the config and applies the reloadable settings.
+func (w *Watcher) Reload() error {
+	next, err := readConfig(w.Current().Primary.Service)
+	if err != nil {
+		w.logger.Error().Err(err).Msg("config reload failed, keeping current config")
+		return err
+	}
+
+	w.mu.Lock()
+	old := w.current
+	updated, keys := applyReloadable(old, next)
+	if len(keys) == 0 {
+		w.mu.Unlock()
+		w.logger.Info().Msg("config reloaded, no reloadable setting changed")
+		return nil
+	}
+	w.current = updated
+	subscribers := append([]Subscriber(nil), w.subscribers...)
+	w.mu.Unlock()
+
+	w.logger.Info().Strs("changed", keys).Msg("config reloaded")
+
+	change := Change{Old: old, New: updated, Keys: keys}
+	for _, fn := range subscribers {
+		fn(change)
+	}
+
+	return nil
+}
+
+// applyReloadable returns a copy of old carrying next's reloadable settings,
+// and the keys that differ.
+func applyReloadable(old, next *Config) (*Config, []string) {
+	updated := *old
+	var keys []string
+
+	if old.Observability != nil && next.Observability != nil &&
+		old.Observability.Logging.Level != next.Observability.Logging.Level {
+		observability := *old.Observability
+		observability.Logging.Level = next.Observability.Logging.Level
+		updated.Observability = &observability
+		keys = append(keys, ReloadLogLevel)
+	}
+
+	if !reflect.DeepEqual(old.RateLimit, next.RateLimit) {
+		updated.RateLimit = next.RateLimit
+		keys = append(keys, ReloadRateLimit)
+	}
+
+	if !reflect.DeepEqual(old.Server.CORSAllowedOrigins, next.Server.CORSAllowedOrigins) {
+		updated.Server.CORSAllowedOrigins = next.Server.CORSAllowedOrigins
+		keys = append(keys, ReloadCORSOrigins)
+	}
+
+	if !reflect.DeepEqual(old.Quota, next.Quota) {
+		updated.Quota = next.Quota
+		keys = append(keys, ReloadQuota)
+	}
+
+	return &updated, keys
+}
+
+// Start listens for SIGHUP and polls CONFIG_FILE until Stop.
+func (w *Watcher) Start() {
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	go func() {
+		defer close(w.done)
+		defer signal.Stop(hup)
+
+		ticker := time.NewTicker(reloadPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-w.stop:
+				return
+
+			case <-hup:
+				w.logger.Info().Msg("SIGHUP received, reloading config")
+				_ = w.Reload()
+
+			case <-ticker.C:
+				modTime, ok := configFileModTime()
+				if !ok || !modTime.After(w.fileModTime) {
+					continue
+				}
+				w.fileModTime = modTime
+				w.logger.Info().Msg("config file changed, reloading config")
+				_ = w.Reload()
+			}
+		}
+	}()
+}
+
+// Stop ends the watcher started by Start.
+func (w *Watcher) Stop() {
+	if w.stop == nil {
+		return
+	}
+	close(w.stop)
+	<-w.done
+	w.stop = nil
+}
+
+// configFileModTime returns the modification time of CONFIG_FILE, if any.
+func configFileModTime() (time.Time, bool) {
+	path := os.Getenv(ConfigFileEnv)
+	if path == "" {
+		return time.Time{}, false
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return info.ModTime(), true
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"net"
+)
+
+// DefaultRequestIDMaxLength caps inbound X-Request-ID values. Long enough for
+// UUIDs, ULIDs and most tracing IDs, short enough to keep logs sane.
+const DefaultRequestIDMaxLength = 128
+
+// RequestIDConfig controls how inbound X-Request-ID headers are trusted.
+//
+// The header is client-controlled, so without checks anyone can inject
+// arbitrary strings (newlines, fake JSON, megabytes of junk) into every log
+// line of a request, or reuse someone else's ID to confuse an investigation.
+type RequestIDConfig struct {
+	// MaxLength is the maximum accepted header length.
+	// 0 means DefaultRequestIDMaxLength.
+	MaxLength int `koanf:"max_length"`
+
+	// TrustedProxies is an optional list of CIDRs (e.g. "10.0.0.0/8").
+	// When set, the inbound header is only honored if the direct peer
+	// (RemoteAddr) is inside one of them; otherwise a fresh ID is generated.
+	// Empty means the header is accepted from anyone (after validation).
+	TrustedProxies []string `koanf:"trusted_proxies"`
+}
+
+// DefaultRequestIDConfig validates the header but trusts any peer.
+func DefaultRequestIDConfig() *RequestIDConfig {
+	return &RequestIDConfig{
+		MaxLength: DefaultRequestIDMaxLength,
+	}
+}
+
+// GetMaxLength returns the effective maximum header length.
+func (c *RequestIDConfig) GetMaxLength() int {
+	if c.MaxLength <= 0 {
+		return DefaultRequestIDMaxLength
+	}
+	return c.MaxLength
+}
+
+// ParsedTrustedProxies returns TrustedProxies as networks.
+func (c *RequestIDConfig) ParsedTrustedProxies() ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(c.TrustedProxies))
+	for _, cidr := range c.TrustedProxies {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid request_id trusted proxy %q: %w", cidr, err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// Validate checks that the trusted proxy CIDRs parse.
+func (c *RequestIDConfig) Validate() error {
+	if c.MaxLength < 0 {
+		return fmt.Errorf("request_id max_length must be non-negative")
+	}
+	_, err := c.ParsedTrustedProxies()
+	return err
+}
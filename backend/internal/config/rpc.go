@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// MinRPCSecretLength is the minimum length of RPCConfig.Secret (32 bytes
+// matches the HMAC-SHA256 output size).
+const MinRPCSecretLength = 32
+
+// RPCConfig configures internal RPC between services (see lib/rpc).
+type RPCConfig struct {
+	// Enabled mounts the /internal/rpc routes and creates server.RPC.
+	Enabled bool `koanf:"enabled"`
+
+	// Secret signs and verifies service tokens. Every service of one
+	// deployment shares it.
+	Secret string `koanf:"secret"`
+
+	// Services maps service names to base URLs, e.g.
+	// BOILERPLATE_RPC_SERVICES_WORKER=http://worker:8081.
+	Services map[string]string `koanf:"services"`
+
+	// Timeout bounds each attempt of a call.
+	Timeout time.Duration `koanf:"timeout"`
+
+	// MaxRetries is the number of retries on transient failures.
+	MaxRetries int `koanf:"max_retries"`
+
+	// TokenTTL is the lifetime of the per-call service tokens.
+	TokenTTL time.Duration `koanf:"token_ttl"`
+}
+
+// DefaultRPCConfig keeps RPC disabled (the single binary has nobody to call).
+func DefaultRPCConfig() *RPCConfig {
+	return &RPCConfig{
+		Enabled:    false,
+		Services:   map[string]string{},
+		Timeout:    5 * time.Second,
+		MaxRetries: 2,
+		TokenTTL:   time.Minute,
+	}
+}
+
+// Validate requires a strong secret and absolute service URLs when enabled.
+func (c *RPCConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if len(c.Secret) < MinRPCSecretLength {
+		return fmt.Errorf("rpc secret must be at least %d characters", MinRPCSecretLength)
+	}
+	for name, raw := range c.Services {
+		u, err := url.Parse(raw)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("rpc service %q: invalid base URL %q", name, raw)
+		}
+	}
+	if c.Timeout < 0 || c.MaxRetries < 0 || c.TokenTTL < 0 {
+		return fmt.Errorf("rpc timeout, max_retries and token_ttl must be non-negative")
+	}
+	return nil
+}
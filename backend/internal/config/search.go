@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Search providers supported by lib/search.
+const (
+	SearchProviderMeilisearch   = "meilisearch"
+	SearchProviderElasticsearch = "elasticsearch"
+)
+
+// SearchConfig configures the external search index (see lib/search).
+type SearchConfig struct {
+	// Enabled creates server.Search and processes search indexing jobs.
+	Enabled bool `koanf:"enabled"`
+
+	// Provider is "meilisearch" or "elasticsearch".
+	Provider string `koanf:"provider"`
+
+	// URL is the base URL of the search server, e.g. http://meilisearch:7700.
+	URL string `koanf:"url"`
+
+	// APIKey authenticates against the search server (Meilisearch master/API
+	// key, or an Elasticsearch API key). Optional for local servers.
+	APIKey string `koanf:"api_key"`
+
+	// IndexPrefix is prepended to every index name, so environments can
+	// share one search cluster (e.g. "staging_").
+	IndexPrefix string `koanf:"index_prefix"`
+
+	// BatchSize is the number of documents sent per request by full reindexes.
+	BatchSize int `koanf:"batch_size"`
+
+	// Timeout bounds each request to the search server.
+	Timeout time.Duration `koanf:"timeout"`
+}
+
+// DefaultSearchConfig keeps search disabled (Postgres full-text search is
+// enough until it isn't).
+func DefaultSearchConfig() *SearchConfig {
+	return &SearchConfig{
+		Enabled:   false,
+		Provider:  SearchProviderMeilisearch,
+		BatchSize: 500,
+		Timeout:   10 * time.Second,
+	}
+}
+
+// Validate requires a known provider and an absolute URL when enabled.
+func (c *SearchConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Provider != SearchProviderMeilisearch && c.Provider != SearchProviderElasticsearch {
+		return fmt.Errorf("search provider must be %q or %q", SearchProviderMeilisearch, SearchProviderElasticsearch)
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("search url %q must be an absolute URL", c.URL)
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("search batch_size must be positive")
+	}
+	return nil
+}
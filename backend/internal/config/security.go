@@ -0,0 +1,145 @@
+package config
+
+import "fmt"
+
+// SecurityHeadersConfig controls the security response headers.
+//
+// Every field is optional; empty values fall back to the defaults documented
+// on the getters below, which are strict for the JSON API and relaxed just
+// enough for the docs UI (which loads scripts/styles from a CDN).
+type SecurityHeadersConfig struct {
+	// ContentSecurityPolicy for API responses.
+	ContentSecurityPolicy string `koanf:"content_security_policy"`
+
+	// DocsContentSecurityPolicy for /docs and /static (the docs UI).
+	DocsContentSecurityPolicy string `koanf:"docs_content_security_policy"`
+
+	// DashboardContentSecurityPolicy for the admin dashboard page (/admin).
+	DashboardContentSecurityPolicy string `koanf:"dashboard_content_security_policy"`
+
+	// CSPReportOnly sends CSPs as Content-Security-Policy-Report-Only,
+	// handy while tightening a policy without breaking pages.
+	CSPReportOnly bool `koanf:"csp_report_only"`
+
+	// HSTSMaxAge is the Strict-Transport-Security max-age in seconds.
+	// 0 means "environment default": one year in production, off elsewhere
+	// (HSTS on localhost is a famously annoying thing to undo). Negative disables it.
+	HSTSMaxAge int `koanf:"hsts_max_age"`
+
+	// HSTSIncludeSubdomains adds includeSubDomains to HSTS.
+	HSTSIncludeSubdomains bool `koanf:"hsts_include_subdomains"`
+
+	// HSTSPreload adds preload to HSTS (only do this if you mean it).
+	HSTSPreload bool `koanf:"hsts_preload"`
+
+	// ReferrerPolicy defaults to "strict-origin-when-cross-origin".
+	ReferrerPolicy string `koanf:"referrer_policy"`
+
+	// PermissionsPolicy defaults to disabling powerful browser features.
+	PermissionsPolicy string `koanf:"permissions_policy"`
+
+	// FrameOptions is X-Frame-Options, defaults to "DENY".
+	FrameOptions string `koanf:"frame_options"`
+}
+
+const (
+	// DefaultAPIContentSecurityPolicy forbids everything: JSON responses never
+	// need to load anything, and this neuters any HTML an attacker manages
+	// to get reflected.
+	DefaultAPIContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'"
+
+	// DefaultDocsContentSecurityPolicy only allows this origin: the docs UI
+	// bundles are vendored (static/docs/vendor) and boot without inline
+	// scripts. Inline styles stay allowed, as the renderers set them at runtime.
+	DefaultDocsContentSecurityPolicy = "default-src 'self'; " +
+		"script-src 'self'; " +
+		"style-src 'self' 'unsafe-inline'; " +
+		"font-src 'self' data:; " +
+		"img-src 'self' data:; " +
+		"connect-src 'self'; " +
+		"frame-ancestors 'none'"
+
+	// DefaultDashboardContentSecurityPolicy allows the dashboard's own inline
+	// script and style and calls to this origin only; it loads nothing else.
+	DefaultDashboardContentSecurityPolicy = "default-src 'none'; " +
+		"script-src 'unsafe-inline'; " +
+		"style-src 'unsafe-inline'; " +
+		"connect-src 'self'; " +
+		"frame-ancestors 'none'"
+
+	DefaultReferrerPolicy    = "strict-origin-when-cross-origin"
+	DefaultPermissionsPolicy = "camera=(), microphone=(), geolocation=(), payment=(), usb=()"
+	DefaultFrameOptions      = "DENY"
+
+	// productionHSTSMaxAge is one year, the usual minimum for HSTS preload lists.
+	productionHSTSMaxAge = 31536000
+)
+
+// GetContentSecurityPolicy returns the API CSP.
+func (c SecurityHeadersConfig) GetContentSecurityPolicy() string {
+	if c.ContentSecurityPolicy == "" {
+		return DefaultAPIContentSecurityPolicy
+	}
+	return c.ContentSecurityPolicy
+}
+
+// GetDocsContentSecurityPolicy returns the docs UI CSP.
+func (c SecurityHeadersConfig) GetDocsContentSecurityPolicy() string {
+	if c.DocsContentSecurityPolicy == "" {
+		return DefaultDocsContentSecurityPolicy
+	}
+	return c.DocsContentSecurityPolicy
+}
+
+// GetDashboardContentSecurityPolicy returns the admin dashboard CSP.
+func (c SecurityHeadersConfig) GetDashboardContentSecurityPolicy() string {
+	if c.DashboardContentSecurityPolicy == "" {
+		return DefaultDashboardContentSecurityPolicy
+	}
+	return c.DashboardContentSecurityPolicy
+}
+
+// GetReferrerPolicy returns the Referrer-Policy value.
+func (c SecurityHeadersConfig) GetReferrerPolicy() string {
+	if c.ReferrerPolicy == "" {
+		return DefaultReferrerPolicy
+	}
+	return c.ReferrerPolicy
+}
+
+// GetPermissionsPolicy returns the Permissions-Policy value.
+func (c SecurityHeadersConfig) GetPermissionsPolicy() string {
+	if c.PermissionsPolicy == "" {
+		return DefaultPermissionsPolicy
+	}
+	return c.PermissionsPolicy
+}
+
+// GetFrameOptions returns the X-Frame-Options value.
+func (c SecurityHeadersConfig) GetFrameOptions() string {
+	if c.FrameOptions == "" {
+		return DefaultFrameOptions
+	}
+	return c.FrameOptions
+}
+
+// HSTSHeader returns the Strict-Transport-Security value for env, or "" when
+// HSTS should not be sent.
+func (c SecurityHeadersConfig) HSTSHeader(env string) string {
+	maxAge := c.HSTSMaxAge
+	if maxAge == 0 && env == "production" {
+		maxAge = productionHSTSMaxAge
+	}
+	if maxAge <= 0 {
+		return ""
+	}
+
+	value := fmt.Sprintf("max-age=%d", maxAge)
+	if c.HSTSIncludeSubdomains {
+		value += "; includeSubDomains"
+	}
+	if c.HSTSPreload {
+		value += "; preload"
+	}
+	return value
+}
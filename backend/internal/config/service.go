@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/knadh/koanf/providers/env"
+	"github.com/knadh/koanf/v2"
+)
+
+// Service names of the binaries built from cmd/<name>.
+//
+// All of them share the internal packages and the BOILERPLATE_ config; what
+// differs is which routes they serve and whether they process background jobs.
+const (
+	// ServiceAPI serves the public API (cmd/api).
+	ServiceAPI = "api"
+
+	// ServiceWorker processes background jobs (cmd/worker). Its HTTP server
+	// only exposes the system routes, for health probes.
+	ServiceWorker = "worker"
+
+	// ServiceAdmin serves the admin endpoints (cmd/admin), so they can sit
+	// on a separate port/network from the public API.
+	ServiceAdmin = "admin"
+)
+
+// ServiceEnvPrefix returns the env prefix of a service's own overrides:
+// "api" -> "API_". Empty for the single-binary setup (no service).
+//
+// Service variables are loaded after the shared BOILERPLATE_ ones and use
+// the same names without the shared prefix, so one .env can run every binary:
+//
+//	BOILERPLATE_SERVER_PORT=8080   # shared default
+//	WORKER_SERVER_PORT=8081        # only cmd/worker
+//	ADMIN_SERVER_PORT=8082         # only cmd/admin
+func ServiceEnvPrefix(service string) string {
+	if service == "" {
+		return ""
+	}
+	return strings.ToUpper(service) + "_"
+}
+
+// RunsJobs reports whether this binary processes background jobs.
+//
+// The worker does; the api and admin services only enqueue. Without a
+// service name (one binary doing everything) jobs run in-process as before.
+func (p Primary) RunsJobs() bool {
+	return p.Service == "" || p.Service == ServiceWorker
+}
+
+// loadServiceEnv layers the service's own env variables over k.
+func loadServiceEnv(k *koanf.Koanf, service string) error {
+	prefix := ServiceEnvPrefix(service)
+	if prefix == "" {
+		return nil
+	}
+
+	mapper := newEnvKeyMapper(prefix, reflect.TypeOf(Config{}))
+	if err := k.Load(env.Provider(prefix, ".", mapper), nil); err != nil {
+		return fmt.Errorf("could not load %s env variables: %w", service, err)
+	}
+
+	return nil
+}
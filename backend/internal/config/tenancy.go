@@ -0,0 +1,158 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Tenancy modes: how tenants' data is separated in the database.
+const (
+	// TenancySharedSchema keeps all tenants in the same tables, told apart
+	// by a tenant_id column (optionally enforced by row-level security).
+	TenancySharedSchema = "shared_schema"
+
+	// TenancySchemaPerTenant gives every tenant its own Postgres schema
+	// with the same tables; statements run with search_path set to it.
+	TenancySchemaPerTenant = "schema_per_tenant"
+)
+
+// Tenant sources, in TenancyConfig.Sources.
+const (
+	// TenantSourceClaims is the active organization of the session token.
+	TenantSourceClaims = "claims"
+	// TenantSourceHeader is the TenancyConfig.Header request header.
+	TenantSourceHeader = "header"
+	// TenantSourceSubdomain is the first label of the host below BaseDomain
+	// (acme.example.com => "acme").
+	TenantSourceSubdomain = "subdomain"
+)
+
+// maxIdentifierLength is Postgres' identifier limit (NAMEDATALEN - 1).
+const maxIdentifierLength = 63
+
+// tenantIDPattern restricts tenant IDs to characters that are safe in
+// headers, host labels, setting values and schema names.
+var tenantIDPattern = regexp.MustCompile(`\A[A-Za-z0-9][A-Za-z0-9_-]*\z`)
+
+// TenancyConfig controls how requests are mapped to tenants and how
+// repositories isolate their data (see middleware.TenancyMiddleware and
+// repository/tenant.go).
+type TenancyConfig struct {
+	// Enabled turns on header/subdomain resolution and tenant scoping in
+	// repositories. Without it the Clerk organization is still recorded as
+	// the tenant for telemetry.
+	Enabled bool `koanf:"enabled"`
+
+	// Mode is TenancySharedSchema (default) or TenancySchemaPerTenant.
+	Mode string `koanf:"mode"`
+
+	// RLS sets app.tenant_id for every statement in shared_schema mode, so
+	// row-level security policies can read it:
+	//
+	//	CREATE POLICY tenant_isolation ON todos
+	//	    USING (tenant_id = current_setting('app.tenant_id', true));
+	RLS bool `koanf:"rls"`
+
+	// Sources are tried in order; the first one yielding a tenant wins.
+	// Defaults to claims only.
+	Sources []string `koanf:"sources"`
+
+	// Header names the header source. Defaults to X-Tenant-ID.
+	Header string `koanf:"header"`
+
+	// BaseDomain is the domain tenants are subdomains of (e.g.
+	// "example.com"), required by the subdomain source.
+	BaseDomain string `koanf:"base_domain"`
+
+	// SchemaPrefix is prepended to the tenant ID to name its schema in
+	// schema_per_tenant mode. Defaults to "tenant_".
+	SchemaPrefix string `koanf:"schema_prefix"`
+}
+
+// DefaultTenancyConfig leaves tenancy off, resolving from claims once enabled.
+func DefaultTenancyConfig() *TenancyConfig {
+	return &TenancyConfig{
+		Mode:         TenancySharedSchema,
+		Sources:      []string{TenantSourceClaims},
+		Header:       "X-Tenant-ID",
+		SchemaPrefix: "tenant_",
+	}
+}
+
+// GetMode returns the effective mode.
+func (c *TenancyConfig) GetMode() string {
+	if c == nil || c.Mode == "" {
+		return TenancySharedSchema
+	}
+	return c.Mode
+}
+
+// SessionScoped reports whether statements need per-tenant session settings
+// (app.tenant_id or search_path) rather than only a tenant_id filter.
+func (c *TenancyConfig) SessionScoped() bool {
+	if c == nil || !c.Enabled {
+		return false
+	}
+	return c.GetMode() == TenancySchemaPerTenant || c.RLS
+}
+
+// ValidateTenantID rejects IDs that are empty, contain anything but ASCII
+// letters, digits, '_' and '-', or would make too long a schema name.
+func (c *TenancyConfig) ValidateTenantID(tenantID string) error {
+	if !tenantIDPattern.MatchString(tenantID) {
+		return fmt.Errorf("tenant ID %q may only contain letters, digits, '_' and '-'", tenantID)
+	}
+
+	limit := maxIdentifierLength
+	if c.GetMode() == TenancySchemaPerTenant {
+		limit -= len(c.SchemaPrefix)
+	}
+	if len(tenantID) > limit {
+		return fmt.Errorf("tenant ID %q is longer than %d characters", tenantID, limit)
+	}
+	return nil
+}
+
+// SchemaName returns the schema of a tenant in schema_per_tenant mode
+// (unquoted; quote it with pgx.Identifier).
+func (c *TenancyConfig) SchemaName(tenantID string) string {
+	return c.SchemaPrefix + tenantID
+}
+
+// Validate checks the mode, the sources and what they depend on.
+func (c *TenancyConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	problems := &ValidationError{}
+
+	mode := c.GetMode()
+	if mode != TenancySharedSchema && mode != TenancySchemaPerTenant {
+		problems.addf("mode", "must be %q or %q", TenancySharedSchema, TenancySchemaPerTenant)
+	}
+	if c.RLS && mode != TenancySharedSchema {
+		problems.addf("rls", "only applies to the %q mode", TenancySharedSchema)
+	}
+	if mode == TenancySchemaPerTenant && len(c.SchemaPrefix) >= maxIdentifierLength {
+		problems.addf("schema_prefix", "must be shorter than %d characters", maxIdentifierLength)
+	}
+
+	if len(c.Sources) == 0 {
+		problems.addf("sources", "must list at least one source")
+	}
+	for _, source := range c.Sources {
+		if !slices.Contains([]string{TenantSourceClaims, TenantSourceHeader, TenantSourceSubdomain}, source) {
+			problems.addf("sources", "unknown source %q", source)
+		}
+	}
+	if slices.Contains(c.Sources, TenantSourceHeader) && c.Header == "" {
+		problems.addf("header", "is required by the header source")
+	}
+	if slices.Contains(c.Sources, TenantSourceSubdomain) && strings.Trim(c.BaseDomain, ".") == "" {
+		problems.addf("base_domain", "is required by the subdomain source")
+	}
+	return problems.orNil()
+}
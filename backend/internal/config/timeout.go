@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeoutConfig controls per-request deadlines.
+//
+// The deadline is applied to the request context, so everything that honors
+// context cancellation (pgx queries, Redis commands, outbound HTTP) gives up
+// when it expires instead of piling up goroutines behind a slow dependency.
+type TimeoutConfig struct {
+	// Default is the deadline for every request. 0 disables the middleware.
+	Default time.Duration `koanf:"default"`
+
+	// Groups overrides Default per route-group prefix, matched against the
+	// route template (longest prefix wins), e.g.
+	//   "/api/v1/reports": "60s"
+	//   "/api/v1/admin":   "30s"
+	Groups map[string]time.Duration `koanf:"groups"`
+}
+
+// DefaultTimeoutConfig gives every request 30 seconds.
+func DefaultTimeoutConfig() *TimeoutConfig {
+	return &TimeoutConfig{
+		Default: 30 * time.Second,
+	}
+}
+
+// Validate rejects negative deadlines.
+func (c *TimeoutConfig) Validate() error {
+	if c.Default < 0 {
+		return fmt.Errorf("timeouts default must be non-negative")
+	}
+	for prefix, d := range c.Groups {
+		if d < 0 {
+			return fmt.Errorf("timeouts group %q must be non-negative", prefix)
+		}
+	}
+	return nil
+}
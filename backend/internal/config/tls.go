@@ -0,0 +1,117 @@
+package config
+
+import (
+	"crypto/tls"
+	"os"
+)
+
+// TLSConfig lets the server terminate HTTPS itself, for deployments without
+// a TLS-terminating proxy or load balancer in front.
+//
+// Certificates come either from files (CertFile/KeyFile, reloaded only on
+// restart) or from Let's Encrypt via ACME (Autocert).
+type TLSConfig struct {
+	// Enabled serves HTTPS on server.port instead of plain HTTP.
+	Enabled bool `koanf:"enabled"`
+
+	// CertFile and KeyFile are PEM files (the certificate file may hold the
+	// full chain). Leave both empty when using Autocert.
+	CertFile string `koanf:"cert_file"`
+	KeyFile  string `koanf:"key_file"`
+
+	// MinVersion is "1.2" (default) or "1.3".
+	MinVersion string `koanf:"min_version"`
+
+	// Autocert obtains and renews certificates automatically.
+	Autocert AutocertConfig `koanf:"autocert"`
+
+	// RedirectHTTP starts a second listener on HTTPPort redirecting every
+	// request to HTTPS. With Autocert it also answers ACME http-01 challenges.
+	RedirectHTTP bool `koanf:"redirect_http"`
+
+	// HTTPPort is the plain HTTP port of RedirectHTTP. Defaults to "80".
+	HTTPPort string `koanf:"http_port"`
+}
+
+// AutocertConfig configures ACME (Let's Encrypt) certificates.
+type AutocertConfig struct {
+	Enabled bool `koanf:"enabled"`
+
+	// Hosts is the whitelist of domain names certificates are requested for.
+	// Required: without it anyone pointing a domain at the server could make
+	// it request certificates (and exhaust the ACME rate limits).
+	Hosts []string `koanf:"hosts"`
+
+	// CacheDir stores issued certificates across restarts. Required, as
+	// re-issuing on every start quickly hits Let's Encrypt rate limits.
+	CacheDir string `koanf:"cache_dir"`
+
+	// Email is the ACME account contact for expiry notices. Optional.
+	Email string `koanf:"email"`
+}
+
+// GetHTTPPort returns the redirect listener port.
+func (c TLSConfig) GetHTTPPort() string {
+	if c.HTTPPort == "" {
+		return "80"
+	}
+	return c.HTTPPort
+}
+
+// GetMinVersion returns the minimum TLS version as a crypto/tls constant.
+func (c TLSConfig) GetMinVersion() uint16 {
+	if c.MinVersion == "1.3" {
+		return tls.VersionTLS13
+	}
+	return tls.VersionTLS12
+}
+
+// Validate requires exactly one certificate source when TLS is enabled,
+// readable certificate files, and a host whitelist and cache for Autocert.
+func (c TLSConfig) Validate() error {
+	problems := &ValidationError{}
+
+	if c.MinVersion != "" && c.MinVersion != "1.2" && c.MinVersion != "1.3" {
+		problems.addf("min_version", `must be "1.2" or "1.3"`)
+	}
+
+	if !c.Enabled {
+		if c.RedirectHTTP {
+			problems.addf("redirect_http", "requires server.tls.enabled")
+		}
+		return problems.orNil()
+	}
+
+	files := c.CertFile != "" || c.KeyFile != ""
+	switch {
+	case files && c.Autocert.Enabled:
+		problems.addf("autocert.enabled", "cannot be combined with cert_file/key_file; use one certificate source")
+	case c.Autocert.Enabled:
+		if len(c.Autocert.Hosts) == 0 {
+			problems.addf("autocert.hosts", "is required with autocert (the domains to request certificates for)")
+		}
+		if c.Autocert.CacheDir == "" {
+			problems.addf("autocert.cache_dir", "is required with autocert")
+		}
+	case files:
+		for key, path := range map[string]string{"cert_file": c.CertFile, "key_file": c.KeyFile} {
+			if path == "" {
+				problems.addf(key, "is required when the other certificate file is set")
+			} else if _, err := os.Stat(path); err != nil {
+				problems.addf(key, "cannot be read: %v", err)
+			}
+		}
+	default:
+		problems.addf("cert_file", "is required when TLS is enabled (or enable autocert)")
+	}
+
+	return problems.orNil()
+}
+
+// validateTLSPorts rejects a redirect listener on the HTTPS port itself.
+func (c *Config) validateTLSPorts(problems *ValidationError) {
+	tlsCfg := c.Server.TLS
+	if tlsCfg.Enabled && tlsCfg.RedirectHTTP && tlsCfg.GetHTTPPort() == c.Server.Port {
+		problems.addf("server.tls.http_port", "must differ from server.port (%s)", c.Server.Port)
+	}
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Validate checks settings that only make sense together, across fields and
+// blocks, after the per-field and per-block validation of Load. Every
+// failure is reported (as a *ValidationError), not just the first.
+func (c *Config) Validate() error {
+	problems := &ValidationError{}
+
+	// database/sql-style pools keep at most MaxOpenConns connections, so a
+	// larger idle pool is silently capped; usually the two were swapped.
+	if c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		problems.addf("database.max_idle_conns", "must not exceed database.max_open_conns (%d)", c.Database.MaxOpenConns)
+	}
+	if c.Database.ConnMaxIdleTime > c.Database.ConnMaxLifetime {
+		problems.addf("database.conn_max_idle_time", "must not exceed database.conn_max_lifetime (%ds)", c.Database.ConnMaxLifetime)
+	}
+
+	c.validateCORS(problems)
+	c.validateTLSPorts(problems)
+
+	// A request deadline beyond the write timeout never fires: net/http has
+	// already cut the response off, and the client sees a reset connection
+	// instead of a 504.
+	if c.Timeouts != nil && c.Server.WriteTimeout > 0 {
+		writeTimeout := time.Duration(c.Server.WriteTimeout) * time.Second
+		if c.Timeouts.Default > writeTimeout {
+			problems.addf("timeouts.default", "must not exceed server.write_timeout (%s)", writeTimeout)
+		}
+		for prefix, d := range c.Timeouts.Groups {
+			if d > writeTimeout {
+				problems.addf("timeouts.groups."+prefix, "must not exceed server.write_timeout (%s)", writeTimeout)
+			}
+		}
+	}
+
+	return problems.orNil()
+}
+
+// validateCORS requires bare origins: browsers send Origin as
+// scheme://host[:port] with no path or trailing slash, so
+// "https://app.example.com/" never matches anything. A leading "*." in the
+// host ("https://*.example.com") allows every subdomain.
+func (c *Config) validateCORS(problems *ValidationError) {
+	const key = "server.cors_allowed_origins"
+
+	origins := c.Server.CORSAllowedOrigins
+	for _, origin := range origins {
+		if origin == "*" {
+			if len(origins) > 1 {
+				problems.addf(key, `"*" allows every origin and cannot be combined with others`)
+			}
+			// Browsers refuse credentialed responses for "*", and reflecting
+			// any origin instead would let every site act as the user.
+			if c.Server.CORSAllowCredentials {
+				problems.addf("server.cors_allow_credentials", `cannot be combined with the "*" origin; list the origins instead`)
+			}
+			continue
+		}
+
+		u, err := url.Parse(origin)
+		switch {
+		case err != nil || u.Scheme == "" || u.Host == "":
+			problems.addf(key, "%q is not an origin (want scheme://host[:port])", origin)
+		case u.Path == "/":
+			problems.addf(key, "%q must not end with a slash", origin)
+		case u.Path != "" || u.RawQuery != "" || u.Fragment != "":
+			problems.addf(key, "%q must not contain a path, query or fragment", origin)
+		case strings.Contains(strings.TrimPrefix(u.Host, "*."), "*"):
+			problems.addf(key, "%q: a wildcard is only allowed as the first label (scheme://*.example.com)", origin)
+		}
+	}
+
+	for _, method := range c.Server.CORSAllowedMethods {
+		if method == "" || strings.ToUpper(method) != method || strings.ContainsAny(method, " ,") {
+			problems.addf("server.cors_allowed_methods", "%q is not an HTTP method (use upper case: GET, POST, ...)", method)
+		}
+	}
+
+	if c.Server.CORSMaxAge < 0 {
+		problems.addf("server.cors_max_age", "must be non-negative")
+	}
+}
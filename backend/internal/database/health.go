@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+)
+
+// Health statuses, worst last.
+const (
+	HealthStatusHealthy   = "healthy"
+	HealthStatusDegraded  = "degraded"
+	HealthStatusUnhealthy = "unhealthy"
+)
+
+// HealthReport is the outcome of CheckHealth.
+type HealthReport struct {
+	// Status is healthy, degraded (serving, but past a threshold) or
+	// unhealthy (the probe failed or exceeded unhealthy_latency).
+	Status string `json:"status"`
+
+	// ResponseTime is the round trip of the probe query.
+	ResponseTime string        `json:"response_time"`
+	Latency      time.Duration `json:"-"`
+
+	// PoolUtilization is the share of the primary pool's connections that
+	// were acquired when the check started.
+	PoolUtilization float64 `json:"pool_utilization"`
+
+	// Replicas reports each read replica, when some are configured.
+	Replicas []ReplicaHealth `json:"replicas,omitempty"`
+
+	// Problems explains a status other than healthy.
+	Problems []string `json:"problems,omitempty"`
+
+	// Error is the probe's error, when it failed.
+	Error string `json:"error,omitempty"`
+}
+
+// ReplicaHealth is the state of one replica as of its last background
+// probe (see database.replica_health_interval).
+type ReplicaHealth struct {
+	Host    string `json:"host"`
+	Healthy bool   `json:"healthy"`
+	Lag     string `json:"lag"`
+}
+
+// degrade lowers the status to at least degraded, recording why.
+func (r *HealthReport) degrade(problem string) {
+	if r.Status == HealthStatusHealthy {
+		r.Status = HealthStatusDegraded
+	}
+	r.Problems = append(r.Problems, problem)
+}
+
+// CheckHealth probes the primary with a SELECT through the pool, so the
+// answer includes the wait for a free connection, and grades the result
+// against database.health:
+//
+//   - the probe failing, or taking longer than unhealthy_latency: unhealthy
+//   - the probe taking longer than degraded_latency: degraded
+//   - the pool at or above degraded_pool_utilization: degraded
+//   - a replica out of rotation or lagging past degraded_replica_lag:
+//     degraded (reads still fall back to the primary)
+//
+// Replicas are not queried here; their state comes from the background
+// probes, which keeps the check cheap enough for frequent polling.
+func (db *Database) CheckHealth(ctx context.Context, cfg config.DatabaseConfig) HealthReport {
+	thresholds := cfg.Health
+	report := HealthReport{Status: HealthStatusHealthy}
+
+	// Before the probe, which acquires a connection itself.
+	stat := db.Pool.Stat()
+	if stat.MaxConns() > 0 {
+		report.PoolUtilization = float64(stat.AcquiredConns()) / float64(stat.MaxConns())
+	}
+
+	start := time.Now()
+	var one int
+	err := db.Pool.QueryRow(ctx, "SELECT 1").Scan(&one)
+	report.Latency = time.Since(start)
+	report.ResponseTime = report.Latency.String()
+
+	if err != nil {
+		report.Status = HealthStatusUnhealthy
+		report.Error = err.Error()
+		report.Problems = append(report.Problems, "probe query failed")
+		return report
+	}
+
+	latency := report.Latency.Round(time.Millisecond)
+	switch {
+	case thresholds.UnhealthyLatency > 0 && report.Latency > thresholds.UnhealthyLatency:
+		report.Status = HealthStatusUnhealthy
+		report.Problems = append(report.Problems,
+			fmt.Sprintf("probe took %s, above %s", latency, thresholds.UnhealthyLatency))
+	case report.Latency > thresholds.GetDegradedLatency():
+		report.degrade(fmt.Sprintf("probe took %s, above %s", latency, thresholds.GetDegradedLatency()))
+	}
+
+	if report.PoolUtilization >= thresholds.GetDegradedPoolUtilization() {
+		report.degrade(fmt.Sprintf("pool saturated: %d of %d connections acquired",
+			stat.AcquiredConns(), stat.MaxConns()))
+	}
+
+	if db.replicas != nil {
+		maxLag := thresholds.GetDegradedReplicaLag(cfg.ReplicaMaxLag)
+		for _, r := range db.replicas.replicas {
+			lag := time.Duration(r.lag.Load())
+			healthy := r.healthy.Load()
+			report.Replicas = append(report.Replicas, ReplicaHealth{
+				Host:    r.host,
+				Healthy: healthy,
+				Lag:     lag.Round(time.Millisecond).String(),
+			})
+
+			switch {
+			case !healthy:
+				report.degrade(fmt.Sprintf("replica %s is out of rotation", r.host))
+			case lag > maxLag:
+				report.degrade(fmt.Sprintf("replica %s lags %s behind, above %s", r.host, lag.Round(time.Millisecond), maxLag))
+			}
+		}
+	}
+
+	return report
+}
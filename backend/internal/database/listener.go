@@ -0,0 +1,263 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/lib/ctxutil"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+)
+
+// Notification is one NOTIFY received by a Listener.
+type Notification struct {
+	Channel string
+	Payload string
+	// PID is the backend process that sent it.
+	PID uint32
+}
+
+// NotificationHandler handles the notifications of one channel. Handlers
+// run one at a time on the listener goroutine, so they should be quick and
+// hand longer work to a job. ctx carries the listener's logger and ends on
+// Close.
+type NotificationHandler func(ctx context.Context, n Notification)
+
+// Listener holds a dedicated connection to the primary that LISTENs on the
+// channels of its handlers (plus database.listen.channels) and dispatches
+// what Postgres NOTIFYs on them: cache invalidation, signals between
+// instances, reacting to triggers.
+//
+// The connection is not from the pool: a LISTEN session must stay open and
+// idle. When it drops, the listener reconnects with exponential backoff and
+// subscribes again; notifications sent in between are lost (Postgres doesn't
+// queue them for absent listeners), which OnReconnect hooks can make up for,
+// e.g. by flushing a cache.
+//
+//	listener.Handle("todos_changed", func(ctx context.Context, n database.Notification) {
+//		cache.EvictLocal("todos")
+//	})
+//
+//	-- anywhere in SQL, a trigger included
+//	SELECT pg_notify('todos_changed', 'some payload');
+type Listener struct {
+	cfg    config.ListenConfig
+	dsn    string
+	logger *zerolog.Logger
+
+	mu          sync.Mutex
+	handlers    map[string][]NotificationHandler
+	onReconnect []func(ctx context.Context)
+	started     bool
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewListener creates a listener on the primary of cfg. Register handlers,
+// then Start it.
+func NewListener(cfg *config.Config, logger *zerolog.Logger) *Listener {
+	l := &Listener{
+		cfg:      cfg.Database.Listen,
+		dsn:      cfg.Database.PrimaryDSN(),
+		logger:   logger,
+		handlers: make(map[string][]NotificationHandler),
+	}
+	for _, channel := range cfg.Database.Listen.Channels {
+		l.handlers[channel] = nil
+	}
+	return l
+}
+
+// Handle registers h for channel. Channels are subscribed when the
+// connection opens, so Handle must be called before Start.
+func (l *Listener) Handle(channel string, h NotificationHandler) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.started {
+		return fmt.Errorf("listener already started, cannot subscribe to %q", channel)
+	}
+	l.handlers[channel] = append(l.handlers[channel], h)
+	return nil
+}
+
+// OnReconnect registers fn to run after every reconnect (not the first
+// connect), once the channels are subscribed again.
+func (l *Listener) OnReconnect(fn func(ctx context.Context)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onReconnect = append(l.onReconnect, fn)
+}
+
+// Channels returns the subscribed channels, sorted.
+func (l *Listener) Channels() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	channels := make([]string, 0, len(l.handlers))
+	for channel := range l.handlers {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+// Start connects and listens in a background goroutine. An unreachable
+// database doesn't fail Start: the listener keeps retrying. It is a no-op
+// without channels.
+func (l *Listener) Start() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.started || len(l.handlers) == 0 {
+		return
+	}
+	l.started = true
+
+	logger := l.logger.With().Str("component", "pg_listener").Logger()
+	ctx, cancel := context.WithCancel(ctxutil.WithLogger(context.Background(), &logger))
+	l.cancel = cancel
+	l.done = make(chan struct{})
+
+	go l.run(ctx, &logger)
+}
+
+// Close stops listening and closes the connection.
+func (l *Listener) Close() {
+	l.mu.Lock()
+	cancel, done := l.cancel, l.done
+	l.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// run keeps a listening session open until ctx ends, reconnecting with
+// backoff. The backoff restarts once a session was established.
+func (l *Listener) run(ctx context.Context, logger *zerolog.Logger) {
+	defer close(l.done)
+
+	attempt := 0
+	connected := false
+	for {
+		established, err := l.session(ctx, logger, connected)
+		if ctx.Err() != nil {
+			return
+		}
+		if established {
+			connected = true
+			attempt = 0
+		}
+
+		wait := l.backoff(attempt)
+		attempt++
+		logger.Warn().Err(err).Dur("retry_in", wait).Msg("database listener not connected, retrying")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// session connects, subscribes and dispatches notifications until the
+// connection fails. established reports whether the subscription went
+// through, reconnected whether this is not the first session.
+func (l *Listener) session(ctx context.Context, logger *zerolog.Logger, reconnected bool) (established bool, err error) {
+	conn, err := pgx.Connect(ctx, l.dsn)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = conn.Close(closeCtx)
+	}()
+
+	channels := l.Channels()
+	for _, channel := range channels {
+		if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			return false, fmt.Errorf("listen %q: %w", channel, err)
+		}
+	}
+	logger.Info().Strs("channels", channels).Bool("reconnected", reconnected).Msg("database listener subscribed")
+
+	if reconnected {
+		l.mu.Lock()
+		hooks := append([]func(context.Context){}, l.onReconnect...)
+		l.mu.Unlock()
+		for _, fn := range hooks {
+			fn(ctx)
+		}
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+		l.dispatch(ctx, logger, Notification{Channel: n.Channel, Payload: n.Payload, PID: n.PID})
+	}
+}
+
+// dispatch calls the handlers of n's channel. A panicking handler is logged
+// and doesn't take the listener down.
+func (l *Listener) dispatch(ctx context.Context, logger *zerolog.Logger, n Notification) {
+	l.mu.Lock()
+	handlers := l.handlers[n.Channel]
+	l.mu.Unlock()
+
+	if len(handlers) == 0 {
+		logger.Debug().Str("channel", n.Channel).Str("payload", n.Payload).Msg("notification without handler")
+		return
+	}
+
+	for _, h := range handlers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Error().Str("channel", n.Channel).Err(fmt.Errorf("%v", r)).Msg("notification handler panicked")
+				}
+			}()
+			h(ctx, n)
+		}()
+	}
+}
+
+// backoff returns the wait before reconnect attempt+1: MinBackoff doubled
+// per attempt up to MaxBackoff, with jitter in [wait/2, wait) so instances
+// don't reconnect in lockstep after a database restart.
+func (l *Listener) backoff(attempt int) time.Duration {
+	wait := l.cfg.GetMinBackoff() << min(attempt, 30)
+	if wait <= 0 || wait > l.cfg.GetMaxBackoff() {
+		wait = l.cfg.GetMaxBackoff()
+	}
+
+	half := wait / 2
+	if half <= 0 {
+		return wait
+	}
+	return half + rand.N(half)
+}
+
+// Notify sends payload on channel (pg_notify) from the primary. Inside a
+// transaction, use the transaction instead: Postgres delivers the
+// notification on commit.
+func (db *Database) Notify(ctx context.Context, channel, payload string) error {
+	if channel == "" {
+		return errors.New("notify: empty channel")
+	}
+	_, err := db.Pool.Exec(ctx, "SELECT pg_notify($1, $2)", channel, payload)
+	return err
+}
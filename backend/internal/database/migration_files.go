@@ -0,0 +1,198 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tern "github.com/jackc/tern/v2/migrate"
+)
+
+// Migration files are named <prefix>_<name>.sql, with up statements above
+// the separator line and the statements reverting them below (tern's
+// format). The prefix is either:
+//
+//   - a sequence number (001_setup.sql): only the migrations that predate
+//     timestamped naming, up to lastNumberedMigration
+//   - a UTC timestamp (20261016093000_add_projects.sql), as created by
+//     `migrate new <name>`, so migrations written on parallel branches don't
+//     compete for the same number
+//
+// Files run in prefix order and the version stored in schema_version is the
+// position in that order. A migration merged with a timestamp older than one
+// that is already deployed would change the meaning of applied versions, so
+// the applied names are recorded (see migration_history.go) and migrating
+// fails until it is recreated with `migrate new`.
+const (
+	migrationSeparator    = "---- create above / drop below ----"
+	migrationTimeLayout   = "20060102150405"
+	lastNumberedMigration = 5
+)
+
+var migrationFileName = regexp.MustCompile(`\A(\d+)_([a-z0-9_]+)\.sql\z`)
+
+// ValidateMigrations checks the embedded migration filenames: well formed,
+// unique and ordered (see listMigrations). It needs no database, so services
+// run it at startup to fail on a broken build before touching the schema.
+func ValidateMigrations() error {
+	subtree, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return fmt.Errorf("retrieving database migrations subtree: %w", err)
+	}
+	_, err = listMigrations(subtree)
+	return err
+}
+
+// listMigrations returns the migration filenames in fsys in run order.
+//
+// Every .sql file must match <prefix>_<name>.sql; numbered prefixes must be
+// 001 to lastNumberedMigration without gaps, timestamped ones valid times
+// (numbered files always sort first). Two files with the same prefix are an
+// error, as their order would be arbitrary.
+func listMigrations(fsys fs.FS) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	type file struct {
+		name   string
+		prefix uint64
+	}
+	var (
+		files    []file
+		numbered = map[uint64]string{}
+		seen     = map[uint64]string{}
+		problems []error
+	)
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		name := entry.Name()
+
+		matches := migrationFileName.FindStringSubmatch(name)
+		if matches == nil {
+			problems = append(problems, fmt.Errorf("%s: name must be <number or timestamp>_<lowercase_name>.sql", name))
+			continue
+		}
+
+		prefix, _ := strconv.ParseUint(matches[1], 10, 64)
+		switch len(matches[1]) {
+		case 3:
+			if prefix < 1 || prefix > lastNumberedMigration {
+				problems = append(problems, fmt.Errorf("%s: numbered migrations end at %03d, create new ones with `migrate new`", name, lastNumberedMigration))
+				continue
+			}
+			numbered[prefix] = name
+		case len(migrationTimeLayout):
+			if _, err := time.Parse(migrationTimeLayout, matches[1]); err != nil {
+				problems = append(problems, fmt.Errorf("%s: invalid timestamp %s", name, matches[1]))
+				continue
+			}
+		default:
+			problems = append(problems, fmt.Errorf("%s: prefix must be a 3-digit number or a %d-digit timestamp", name, len(migrationTimeLayout)))
+			continue
+		}
+
+		if other, ok := seen[prefix]; ok {
+			problems = append(problems, fmt.Errorf("%s: same prefix as %s", name, other))
+			continue
+		}
+		seen[prefix] = name
+		files = append(files, file{name: name, prefix: prefix})
+	}
+
+	for n := uint64(1); n <= lastNumberedMigration; n++ {
+		if _, ok := numbered[n]; !ok {
+			problems = append(problems, fmt.Errorf("missing migration %03d", n))
+		}
+	}
+
+	if err := errors.Join(problems...); err != nil {
+		return nil, fmt.Errorf("invalid database migrations: %w", err)
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].prefix < files[j].prefix })
+
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = f.name
+	}
+	return names, nil
+}
+
+// loadMigrations appends the migrations in fsys to m in run order. It
+// replaces tern's LoadMigrations, which only understands sequence numbers
+// (and has no use here for its template support).
+func loadMigrations(m *tern.Migrator, fsys fs.FS) error {
+	names, err := listMigrations(fsys)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		body, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		up, down, _ := strings.Cut(string(body), migrationSeparator)
+		m.AppendMigration(name, strings.TrimSpace(up), strings.TrimSpace(down))
+	}
+	return nil
+}
+
+// migrationTemplate is the body of a new migration; %s are the name and
+// the creation time.
+const migrationTemplate = `-- %s
+--
+-- Created %s.
+--
+-- Write the up statements above the separator and the statements reverting
+-- them below it. If this migration is irreversible, delete the separator
+-- line (migrate rollback then refuses to go past it).
+
+` + migrationSeparator + `
+
+`
+
+// NewMigration creates an empty migration named <timestamp>_<name>.sql in
+// dir (the source tree's internal/database/migrations) and returns its path.
+// name is lowercased and spaces and dashes become underscores.
+func NewMigration(dir, name string, now time.Time) (string, error) {
+	name = strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(strings.TrimSpace(name)))
+	if name == "" {
+		return "", errors.New("migration name is required")
+	}
+
+	now = now.UTC()
+	filename := now.Format(migrationTimeLayout) + "_" + name + ".sql"
+	if !migrationFileName.MatchString(filename) {
+		return "", fmt.Errorf("invalid migration name %q: use letters, digits and underscores", name)
+	}
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("migrations directory %s not found (run from the backend directory or pass --dir)", dir)
+	}
+
+	path := filepath.Join(dir, filename)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintf(f, migrationTemplate, name, now.Format(time.RFC3339)); err != nil {
+		return "", err
+	}
+	return path, f.Close()
+}
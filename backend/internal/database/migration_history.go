@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	tern "github.com/jackc/tern/v2/migrate"
+)
+
+// schema_version (tern's table) only stores how many migrations ran, which
+// is a position in the sorted file list, not an identity. migrationHistoryTable
+// records which file ran at each version, so a migration merged with a
+// timestamp older than an applied one is caught instead of silently skipped
+// (with every later version changing meaning).
+const migrationHistoryTable = "schema_migration_names"
+
+// ensureMigrationHistory creates the history table if needed and returns the
+// recorded name of each applied version. Applied versions without a record
+// (databases migrated before the table existed, or a run that died between
+// a migration and its record) are filled in from the current file order, the
+// only record there is.
+func ensureMigrationHistory(ctx context.Context, conn *pgx.Conn, m *tern.Migrator, current int32) (map[int32]string, error) {
+	_, err := conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS `+migrationHistoryTable+` (
+			version    INTEGER PRIMARY KEY,
+			name       TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`)
+	if err != nil {
+		return nil, fmt.Errorf("creating migration history table: %w", err)
+	}
+
+	history, err := readMigrationHistory(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	if int32(len(history)) < current {
+		if err := recordMigrationHistory(ctx, conn, m, current); err != nil {
+			return nil, err
+		}
+		return readMigrationHistory(ctx, conn)
+	}
+	return history, nil
+}
+
+// readMigrationHistory returns the recorded name of each applied version.
+func readMigrationHistory(ctx context.Context, conn *pgx.Conn) (map[int32]string, error) {
+	rows, err := conn.Query(ctx, "SELECT version, name FROM "+migrationHistoryTable)
+	if err != nil {
+		return nil, fmt.Errorf("reading migration history: %w", err)
+	}
+	defer rows.Close()
+
+	history := map[int32]string{}
+	for rows.Next() {
+		var version int32
+		var name string
+		if err := rows.Scan(&version, &name); err != nil {
+			return nil, fmt.Errorf("reading migration history: %w", err)
+		}
+		history[version] = name
+	}
+	return history, rows.Err()
+}
+
+// verifyMigrationHistory checks that the first current migrations of m are
+// the ones that were applied, in that order. A mismatch means a migration
+// was inserted before (or an applied one renamed or removed from) the
+// applied range; running tern would skip it and shift every later version.
+func verifyMigrationHistory(m *tern.Migrator, history map[int32]string, current int32) error {
+	var problems []error
+	for v := int32(1); v <= current; v++ {
+		applied, ok := history[v]
+		if !ok {
+			problems = append(problems, fmt.Errorf("version %d: applied, but not in the migration history", v))
+			continue
+		}
+		if int(v) > len(m.Migrations) {
+			problems = append(problems, fmt.Errorf("version %d: %s was applied, but is not in this binary", v, applied))
+			continue
+		}
+		if name := m.Migrations[v-1].Name; name != applied {
+			problems = append(problems, fmt.Errorf(
+				"version %d: %s was applied, but %s sorts there now (unapplied migrations must sort after the applied ones: recreate them with `migrate new`)",
+				v, applied, name,
+			))
+		}
+	}
+
+	if err := errors.Join(problems...); err != nil {
+		return fmt.Errorf("database migrations out of order: %w", err)
+	}
+	return nil
+}
+
+// recordMigrationHistory makes the history match the database at version:
+// the names of the first version migrations of m, nothing after them.
+func recordMigrationHistory(ctx context.Context, conn *pgx.Conn, m *tern.Migrator, version int32) error {
+	batch := &pgx.Batch{}
+	batch.Queue("DELETE FROM "+migrationHistoryTable+" WHERE version > $1", version)
+	for v := int32(1); v <= version && int(v) <= len(m.Migrations); v++ {
+		batch.Queue(
+			"INSERT INTO "+migrationHistoryTable+" (version, name) VALUES ($1, $2) ON CONFLICT (version) DO NOTHING",
+			v, m.Migrations[v-1].Name,
+		)
+	}
+
+	if err := conn.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("recording migration history: %w", err)
+	}
+	return nil
+}
+
+// syncMigrationHistory records the history up to the version the database
+// is at now. Migrate and MigrateTo call it also when tern failed halfway, as
+// the migrations before the failing one stay applied.
+func syncMigrationHistory(ctx context.Context, conn *pgx.Conn, m *tern.Migrator) error {
+	current, err := m.GetCurrentVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("retrieving current database migration version: %w", err)
+	}
+	return recordMigrationHistory(ctx, conn, m, current)
+}
+
+// checkMigrationHistory reads the applied version and verifies the history
+// (see verifyMigrationHistory). Callers hold the migration lock.
+func checkMigrationHistory(ctx context.Context, conn *pgx.Conn, m *tern.Migrator) (int32, error) {
+	current, err := m.GetCurrentVersion(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("retrieving current database migration version: %w", err)
+	}
+
+	history, err := ensureMigrationHistory(ctx, conn, m, current)
+	if err != nil {
+		return 0, err
+	}
+	return current, verifyMigrationHistory(m, history, current)
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+)
+
+// migrationLockKey is the advisory lock key tern takes around MigrateTo.
+// Using the same one makes the lock exclusive with `tern migrate` runs too;
+// tern's own pg_advisory_lock on this session then nests in ours.
+const migrationLockKey = int64(9628173550095224)
+
+// migrationLockPoll is how often a waiting instance retries the lock.
+const migrationLockPoll = time.Second
+
+// errMigrationLocked is returned by acquireMigrationLock when another
+// session holds the lock and the caller doesn't wait.
+var errMigrationLocked = errors.New("another instance is migrating the database")
+
+// acquireMigrationLock takes the migration advisory lock on conn. When it is
+// held elsewhere, it logs who holds it and, with wait, retries until timeout
+// (or ctx ends); without wait it returns errMigrationLocked right away.
+//
+// The lock belongs to the session: it is released by releaseMigrationLock
+// or when conn closes, also if the process dies mid-migration.
+func acquireMigrationLock(ctx context.Context, conn *pgx.Conn, logger *zerolog.Logger, timeout time.Duration, wait bool) error {
+	var acquired bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", migrationLockKey).Scan(&acquired); err != nil {
+		return fmt.Errorf("acquiring migration lock: %w", err)
+	}
+	if acquired {
+		return nil
+	}
+
+	event := logger.Warn()
+	if holder := migrationLockHolder(ctx, conn); holder != "" {
+		event = event.Str("holder", holder)
+	}
+	if !wait {
+		event.Msg("another instance is migrating the database, skipping migrations")
+		return errMigrationLocked
+	}
+	event.Dur("timeout", timeout).Msg("another instance is migrating the database, waiting for it")
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(migrationLockPoll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out after %s waiting for the migration lock: %w", timeout, ctx.Err())
+		case <-ticker.C:
+		}
+
+		if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", migrationLockKey).Scan(&acquired); err != nil {
+			if ctx.Err() != nil {
+				continue // reported by the select above
+			}
+			return fmt.Errorf("acquiring migration lock: %w", err)
+		}
+		if acquired {
+			logger.Info().Dur("waited", time.Since(start)).Msg("acquired migration lock")
+			return nil
+		}
+	}
+}
+
+// releaseMigrationLock releases the lock taken by acquireMigrationLock. A
+// failure is only logged: closing the connection releases it anyway.
+func releaseMigrationLock(ctx context.Context, conn *pgx.Conn, logger *zerolog.Logger) {
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", migrationLockKey); err != nil {
+		logger.Warn().Err(err).Msg("failed to release migration lock")
+	}
+}
+
+// migrationLockHolder describes the session holding the lock (pid,
+// application and client address) for the contention log, or returns ""
+// when it can't be read. A bigint advisory key is stored as classid (high
+// 32 bits) and objid (low 32 bits) with objsubid 1.
+func migrationLockHolder(ctx context.Context, conn *pgx.Conn) string {
+	var pid int32
+	var application, client string
+	err := conn.QueryRow(ctx, `
+		SELECT a.pid, COALESCE(a.application_name, ''), COALESCE(host(a.client_addr), '')
+		FROM pg_locks l
+		JOIN pg_stat_activity a ON a.pid = l.pid
+		WHERE l.locktype = 'advisory' AND l.granted
+		  AND l.classid = $1::bigint::oid AND l.objid = $2::bigint::oid AND l.objsubid = 1`,
+		migrationLockKey>>32, migrationLockKey&0xffffffff,
+	).Scan(&pid, &application, &client)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("pid=%d application=%q client=%q", pid, application, client)
+}
@@ -0,0 +1,16 @@
+-- Shared trigger function that keeps updated_at current on every UPDATE.
+-- (Conditional polling uses table_changes instead, see 20261016130000.)
+--
+--   CREATE TRIGGER set_updated_at BEFORE UPDATE ON todos
+--       FOR EACH ROW EXECUTE FUNCTION trigger_set_updated_at();
+CREATE OR REPLACE FUNCTION trigger_set_updated_at()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+---- create above / drop below ----
+
+DROP FUNCTION IF EXISTS trigger_set_updated_at();
@@ -0,0 +1,20 @@
+-- Progress of long-running data backfills (see lib/backfill).
+--
+-- One row per named backfill. last_key is the keyset cursor of the last
+-- processed batch, so a restarted or crashed backfill resumes where it
+-- stopped instead of starting over.
+CREATE TABLE backfill_progress (
+    name           TEXT PRIMARY KEY,
+    last_key       TEXT NOT NULL DEFAULT '',
+    rows_processed BIGINT NOT NULL DEFAULT 0,
+    started_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at   TIMESTAMPTZ
+);
+
+CREATE TRIGGER set_updated_at BEFORE UPDATE ON backfill_progress
+    FOR EACH ROW EXECUTE FUNCTION trigger_set_updated_at();
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS backfill_progress;
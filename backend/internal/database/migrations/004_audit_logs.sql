@@ -0,0 +1,25 @@
+-- Audit trail of mutating API requests (see middleware.AuditMiddleware).
+--
+-- Rows are append-only: written asynchronously by the audit:write job and
+-- never updated, so there is no updated_at trigger.
+CREATE TABLE audit_logs (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id      TEXT NOT NULL DEFAULT '',
+    tenant_id    TEXT NOT NULL DEFAULT '',
+    method       TEXT NOT NULL,
+    route        TEXT NOT NULL,
+    resource_ids JSONB NOT NULL DEFAULT '{}'::jsonb,
+    status       INTEGER NOT NULL,
+    request_id   TEXT NOT NULL DEFAULT '',
+    body_hash    TEXT NOT NULL DEFAULT '',
+    ip           TEXT NOT NULL DEFAULT '',
+    occurred_at  TIMESTAMPTZ NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_logs_user_id_occurred_at ON audit_logs (user_id, occurred_at DESC);
+CREATE INDEX idx_audit_logs_occurred_at ON audit_logs (occurred_at DESC);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS audit_logs;
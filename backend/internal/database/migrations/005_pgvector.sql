@@ -0,0 +1,17 @@
+-- pgvector, for vector embeddings (see lib/embedding).
+--
+-- Optional: the extension is only created when the server ships it
+-- (pgvector/pgvector images, most managed Postgres offerings), so this
+-- migration is harmless where it doesn't. Tables with vector columns must
+-- come in later migrations, which then require the extension.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'vector') THEN
+        CREATE EXTENSION IF NOT EXISTS vector;
+    END IF;
+END
+$$;
+
+---- create above / drop below ----
+
+DROP EXTENSION IF EXISTS vector;
@@ -0,0 +1,23 @@
+-- Application users, mirrored from the identity provider (Clerk) on first
+-- sign-in. Queried through the sqlc layer (see internal/queries).
+--
+-- Emails are unique case-insensitively, hence the index on lower(email)
+-- rather than a UNIQUE constraint.
+CREATE TABLE users (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    external_id TEXT NOT NULL UNIQUE,
+    email       TEXT NOT NULL,
+    name        TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_users_email ON users (lower(email));
+CREATE INDEX idx_users_created_at_id ON users (created_at DESC, id DESC);
+
+CREATE TRIGGER set_updated_at BEFORE UPDATE ON users
+    FOR EACH ROW EXECUTE FUNCTION trigger_set_updated_at();
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,43 @@
+-- Per-table change tracking for conditional (If-Modified-Since) polling
+-- through repository.Base.LastModified.
+--
+-- MAX(updated_at) misses deletes and can't tell two writes within the same
+-- second apart, so each tracked table instead bumps its row here from a
+-- statement-level trigger on every INSERT, UPDATE, DELETE and TRUNCATE.
+-- changed_at is clock_timestamp(), which keeps advancing inside a
+-- transaction, unlike NOW().
+--
+-- tenant_id is app.tenant_id when tenancy.rls sets it on the connection, ''
+-- otherwise (writes then count as changes for every tenant, which is safe).
+-- In schema_per_tenant mode the table lives in each tenant's schema.
+--
+-- Attach it to any table served with handler.WithLastModified:
+--
+--   CREATE TRIGGER track_changes AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON todos
+--       FOR EACH STATEMENT EXECUTE FUNCTION trigger_track_table_changes();
+CREATE TABLE table_changes (
+    table_name TEXT NOT NULL,
+    tenant_id  TEXT NOT NULL DEFAULT '',
+    changed_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (table_name, tenant_id)
+);
+
+CREATE OR REPLACE FUNCTION trigger_track_table_changes()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO table_changes (table_name, tenant_id, changed_at)
+    VALUES (TG_TABLE_NAME, COALESCE(current_setting('app.tenant_id', true), ''), clock_timestamp())
+    ON CONFLICT (table_name, tenant_id) DO UPDATE
+        SET changed_at = GREATEST(table_changes.changed_at, EXCLUDED.changed_at);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER track_changes AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON users
+    FOR EACH STATEMENT EXECUTE FUNCTION trigger_track_table_changes();
+
+---- create above / drop below ----
+
+DROP TRIGGER IF EXISTS track_changes ON users;
+DROP FUNCTION IF EXISTS trigger_track_table_changes();
+DROP TABLE IF EXISTS table_changes;
@@ -3,11 +3,9 @@ package database
 import (
 	"context"
 	"embed"
+	"errors"
 	"fmt"
 	"io/fs"
-	"net"
-	"net/url"
-	"strconv"
 
 	"github.com/deppfellow/go-boilerplate/internal/config"
 	"github.com/jackc/pgx/v5"
@@ -25,61 +23,39 @@ var migrations embed.FS
 // Migrate runs database migrations using jackc/tern.
 //
 // Behavior:
-//   - Build DSN from config
-//   - Connect using pgx (single connection, not a pool)
-//   - Create tern migrator and load embedded migrations
+//   - Connect to the primary and load the embedded migrations (openMigrator)
+//   - Take the migration lock, so concurrently starting replicas apply
+//     migrations once: the others wait for it (database.migration_lock_timeout)
+//     or, with database.migration_skip_if_locked, start right away
+//   - Fail if a migration that was never applied sorts before an applied one
 //   - Run migrations to latest
 //   - Log whether it was already up-to-date or migrated
 func Migrate(ctx context.Context, logger *zerolog.Logger, cfg *config.Config) error {
-	hostPort := net.JoinHostPort(cfg.Database.Host, strconv.Itoa(cfg.Database.Port))
-
-	// URL-encode the password to keep DSN valid.
-	encodedPassword := url.QueryEscape(cfg.Database.Password)
-
-	dsn := fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=%s",
-		cfg.Database.User,
-		encodedPassword,
-		hostPort,
-		cfg.Database.Name,
-		cfg.Database.SSLMode,
-	)
-
-	// Open a direct connection for migrations.
-	// Using a single connection avoids pool complexity for a one-time action.
-	conn, err := pgx.Connect(ctx, dsn)
+	m, conn, err := openMigrator(ctx, logger, cfg)
 	if err != nil {
 		return err
 	}
 	defer conn.Close(ctx)
 
-	// Create a migrator that stores migration version in the schema_version table.
-	m, err := tern.NewMigrator(ctx, conn, "schema_version")
-	if err != nil {
-		return fmt.Errorf("constructing database migrator: %w", err)
+	err = acquireMigrationLock(ctx, conn, logger, cfg.Database.GetMigrationLockTimeout(), !cfg.Database.MigrationSkipIfLocked)
+	if errors.Is(err, errMigrationLocked) {
+		return nil
 	}
-
-	// Get a subtree view starting at "migrations" directory within the embedded FS.
-	// tern expects an fs.FS pointing at the directory containing migration files.
-	subtree, err := fs.Sub(migrations, "migrations")
 	if err != nil {
-		return fmt.Errorf("retrieving database migrations subtree: %w", err)
-	}
-
-	// Load migrations from the embedded filesystem.
-	// tern parses filenames and orders them.
-	if err := m.LoadMigrations(subtree); err != nil {
-		return fmt.Errorf("loading database migrations: %w", err)
+		return err
 	}
+	defer releaseMigrationLock(ctx, conn, logger)
 
-	// Read current version from schema_version.
+	// Read current version from schema_version and check the applied
+	// migrations are still the first ones (see migration_history.go).
 	// `from` is the version number already applied.
-	from, err := m.GetCurrentVersion(ctx)
+	from, err := checkMigrationHistory(ctx, conn, m)
 	if err != nil {
-		return fmt.Errorf("retrieving current database migration version: %w", err)
+		return err
 	}
 
 	// Apply migrations up to latest.
-	if err := m.Migrate(ctx); err != nil {
+	if err := errors.Join(m.Migrate(ctx), syncMigrationHistory(ctx, conn, m)); err != nil {
 		return err
 	}
 
@@ -92,3 +68,163 @@ func Migrate(ctx context.Context, logger *zerolog.Logger, cfg *config.Config) er
 	}
 	return nil
 }
+
+// MigrateTo migrates up or down to version (0 reverts every migration).
+//
+// Going down runs the "drop below" part of each migration, newest first.
+// Every migration on the way must have one: an irreversible migration is
+// reported before anything is reverted, rather than halfway through.
+func MigrateTo(ctx context.Context, logger *zerolog.Logger, cfg *config.Config, version int32) error {
+	m, conn, err := openMigrator(ctx, logger, cfg)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+
+	if err := acquireMigrationLock(ctx, conn, logger, cfg.Database.GetMigrationLockTimeout(), true); err != nil {
+		return err
+	}
+	defer releaseMigrationLock(ctx, conn, logger)
+
+	from, err := checkMigrationHistory(ctx, conn, m)
+	if err != nil {
+		return err
+	}
+	if version < 0 || version > int32(len(m.Migrations)) {
+		return fmt.Errorf("version %d is outside the valid versions 0 to %d", version, len(m.Migrations))
+	}
+	if from > int32(len(m.Migrations)) {
+		return fmt.Errorf("database is at version %d, newer than the %d migrations of this binary", from, len(m.Migrations))
+	}
+
+	// Migrations from..version+1 are reverted; they are 0-indexed in m.Migrations.
+	for v := from; v > version; v-- {
+		if migration := m.Migrations[v-1]; migration.DownSQL == "" && migration.DownFunc == nil {
+			return fmt.Errorf("migration %d (%s) is irreversible, cannot migrate down to %d", v, migration.Name, version)
+		}
+	}
+
+	if err := errors.Join(m.MigrateTo(ctx, version), syncMigrationHistory(ctx, conn, m)); err != nil {
+		return err
+	}
+
+	if from == version {
+		logger.Info().Msgf("database schema already at version %d", version)
+	} else {
+		logger.Info().Msgf("migrated database schema, from %d to %d", from, version)
+	}
+	return nil
+}
+
+// Rollback reverts the last n applied migrations (see MigrateTo).
+func Rollback(ctx context.Context, logger *zerolog.Logger, cfg *config.Config, n int) error {
+	if n < 1 {
+		return fmt.Errorf("rollback count must be at least 1, got %d", n)
+	}
+
+	status, err := Status(ctx, logger, cfg)
+	if err != nil {
+		return err
+	}
+	if int32(n) > status.Current {
+		return fmt.Errorf("cannot roll back %d migrations, only %d applied", n, status.Current)
+	}
+
+	return MigrateTo(ctx, logger, cfg, status.Current-int32(n))
+}
+
+// MigrationStatus is the schema version of the database against the
+// migrations embedded in the binary.
+type MigrationStatus struct {
+	// Current is the applied version (schema_version), Latest the number of
+	// embedded migrations. Current > Latest means the database was migrated
+	// by a newer binary.
+	Current    int32           `json:"current"`
+	Latest     int32           `json:"latest"`
+	Migrations []MigrationInfo `json:"migrations"`
+}
+
+// MigrationInfo describes one embedded migration.
+type MigrationInfo struct {
+	Version    int32  `json:"version"`
+	Name       string `json:"name"`
+	Applied    bool   `json:"applied"`
+	Reversible bool   `json:"reversible"`
+}
+
+// Pending returns the number of migrations not applied yet.
+func (s MigrationStatus) Pending() int {
+	return max(int(s.Latest-s.Current), 0)
+}
+
+// Status reports applied and pending migrations. It changes nothing.
+func Status(ctx context.Context, logger *zerolog.Logger, cfg *config.Config) (MigrationStatus, error) {
+	m, conn, err := openMigrator(ctx, logger, cfg)
+	if err != nil {
+		return MigrationStatus{}, err
+	}
+	defer conn.Close(ctx)
+
+	current, err := m.GetCurrentVersion(ctx)
+	if err != nil {
+		return MigrationStatus{}, fmt.Errorf("retrieving current database migration version: %w", err)
+	}
+
+	status := MigrationStatus{Current: current, Latest: int32(len(m.Migrations))}
+	for _, migration := range m.Migrations {
+		status.Migrations = append(status.Migrations, MigrationInfo{
+			Version:    migration.Sequence,
+			Name:       migration.Name,
+			Applied:    migration.Sequence <= current,
+			Reversible: migration.DownSQL != "" || migration.DownFunc != nil,
+		})
+	}
+	return status, nil
+}
+
+// openMigrator connects to the primary and loads the embedded migrations.
+// The caller closes the connection.
+//
+// Behavior:
+//   - Connect using pgx (single connection, not a pool)
+//   - Create tern migrator and load embedded migrations
+//   - Log every migration step as it starts
+func openMigrator(ctx context.Context, logger *zerolog.Logger, cfg *config.Config) (*tern.Migrator, *pgx.Conn, error) {
+	// Migrations always run on the primary.
+	dsn := cfg.Database.PrimaryDSN()
+
+	// Open a direct connection for migrations.
+	// Using a single connection avoids pool complexity for a one-time action.
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create a migrator that stores migration version in the schema_version table.
+	m, err := tern.NewMigrator(ctx, conn, "schema_version")
+	if err != nil {
+		conn.Close(ctx)
+		return nil, nil, fmt.Errorf("constructing database migrator: %w", err)
+	}
+
+	// Get a subtree view starting at "migrations" directory within the embedded FS.
+	// tern expects an fs.FS pointing at the directory containing migration files.
+	subtree, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		conn.Close(ctx)
+		return nil, nil, fmt.Errorf("retrieving database migrations subtree: %w", err)
+	}
+
+	// Load migrations from the embedded filesystem, ordered by filename
+	// prefix (see migration_files.go).
+	if err := loadMigrations(m, subtree); err != nil {
+		conn.Close(ctx)
+		return nil, nil, fmt.Errorf("loading database migrations: %w", err)
+	}
+
+	m.OnStart = func(sequence int32, name, direction, _ string) {
+		logger.Info().Int32("version", sequence).Str("name", name).Str("direction", direction).Msg("running migration")
+	}
+
+	return m, conn, nil
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// QueryBudget counts the statements issued while serving one request.
+//
+// The HTTP middleware attaches a budget to the request context
+// (WithQueryBudget); the pool's budgetTracer then records every statement run
+// with that context. Because statements are recorded by their SQL text (with
+// placeholders, not arguments), a loop issuing "SELECT ... WHERE id = $1" once
+// per row shows up as one statement with a high count: the classic N+1.
+//
+// Safe for concurrent use (handlers may query from several goroutines).
+type QueryBudget struct {
+	mu         sync.Mutex
+	total      int
+	statements map[string]int
+}
+
+// RepeatedStatement is a statement executed several times in one request.
+type RepeatedStatement struct {
+	SQL   string
+	Count int
+}
+
+// queryBudgetKey is the context key holding the request's *QueryBudget.
+type queryBudgetKey struct{}
+
+// WithQueryBudget returns a copy of ctx carrying a fresh QueryBudget.
+func WithQueryBudget(ctx context.Context) (context.Context, *QueryBudget) {
+	budget := &QueryBudget{statements: make(map[string]int)}
+	return context.WithValue(ctx, queryBudgetKey{}, budget), budget
+}
+
+// QueryBudgetFromContext returns the budget attached to ctx, or nil.
+func QueryBudgetFromContext(ctx context.Context) *QueryBudget {
+	budget, _ := ctx.Value(queryBudgetKey{}).(*QueryBudget)
+	return budget
+}
+
+// record counts one execution of sql.
+func (b *QueryBudget) record(sql string) {
+	sql = strings.Join(strings.Fields(sql), " ")
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.total++
+	b.statements[sql]++
+}
+
+// Total returns the number of statements recorded so far.
+func (b *QueryBudget) Total() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.total
+}
+
+// Repeated returns statements executed at least threshold times, most
+// frequent first.
+func (b *QueryBudget) Repeated(threshold int) []RepeatedStatement {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var repeated []RepeatedStatement
+	for sql, count := range b.statements {
+		if count >= threshold {
+			repeated = append(repeated, RepeatedStatement{SQL: sql, Count: count})
+		}
+	}
+
+	sort.Slice(repeated, func(i, j int) bool {
+		return repeated[i].Count > repeated[j].Count
+	})
+	return repeated
+}
+
+// budgetTracer records each statement into the QueryBudget found in the
+// query context. Queries without a budget (jobs, startup) are ignored.
+type budgetTracer struct{}
+
+// TraceQueryStart implements pgx.QueryTracer.
+func (budgetTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if budget := QueryBudgetFromContext(ctx); budget != nil {
+		budget.record(data.SQL)
+	}
+	return ctx
+}
+
+// TraceQueryEnd implements pgx.QueryTracer.
+func (budgetTracer) TraceQueryEnd(context.Context, *pgx.Conn, pgx.TraceQueryEndData) {}
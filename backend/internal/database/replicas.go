@@ -0,0 +1,252 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/lib/enum"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+// replica is one read replica pool and its last known health.
+type replica struct {
+	pool    *pgxpool.Pool
+	host    string
+	healthy atomic.Bool
+
+	// lag is the replay lag measured by the last check, in nanoseconds.
+	lag atomic.Int64
+}
+
+// replicaSet balances reads over the healthy replicas (round robin) and
+// probes them in the background.
+type replicaSet struct {
+	replicas []*replica
+	next     atomic.Uint64
+	maxLag   time.Duration
+	log      *zerolog.Logger
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newReplicaSet opens a pool per DSN with the primary's tracer, probes each
+// once and starts the health loop. Only a DSN that doesn't parse is an error;
+// an unreachable replica starts out unhealthy.
+func newReplicaSet(ctx context.Context, appCfg *config.Config, tracer pgx.QueryTracer, logger *zerolog.Logger) (*replicaSet, error) {
+	cfg := appCfg.Database
+	rs := &replicaSet{
+		maxLag: cfg.ReplicaMaxLag,
+		log:    logger,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	for i, dsn := range cfg.ReplicaDSNs {
+		poolConfig, err := pgxpool.ParseConfig(dsn)
+		if err != nil {
+			rs.closePools()
+			// The error would echo the DSN, password included.
+			return nil, fmt.Errorf("failed to parse database.replica_dsns[%d]", i)
+		}
+		poolConfig.ConnConfig.Tracer = tracer
+		setStatementTimeout(poolConfig.ConnConfig, cfg)
+		setPoolSize(poolConfig, cfg)
+		poolConfig.AfterConnect = enum.RegisterPgTypes
+		setTenantSession(poolConfig, appCfg.Tenancy)
+
+		pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+		if err != nil {
+			rs.closePools()
+			return nil, fmt.Errorf("failed to create pool for database.replica_dsns[%d]: %w", i, err)
+		}
+
+		r := &replica{pool: pool, host: poolConfig.ConnConfig.Host}
+		rs.replicas = append(rs.replicas, r)
+		rs.check(ctx, r)
+	}
+
+	go rs.run(cfg.GetReplicaHealthInterval())
+	return rs, nil
+}
+
+// pick returns the next healthy replica, or nil when none is.
+func (rs *replicaSet) pick() *replica {
+	n := uint64(len(rs.replicas))
+	start := rs.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		if r := rs.replicas[(start+i)%n]; r.healthy.Load() {
+			return r
+		}
+	}
+	return nil
+}
+
+// run probes every replica each interval until close.
+func (rs *replicaSet) run(interval time.Duration) {
+	defer close(rs.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-rs.stop:
+			return
+		case <-ticker.C:
+		}
+
+		for _, r := range rs.replicas {
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			rs.check(ctx, r)
+			cancel()
+		}
+	}
+}
+
+// check pings r and measures its replay lag (reported by the health check),
+// then records the result, logging state changes only. With ReplicaMaxLag
+// set, a replica lagging further behind is unhealthy.
+func (rs *replicaSet) check(ctx context.Context, r *replica) {
+	err := r.pool.Ping(ctx)
+	if err == nil {
+		// NULL (no replay yet, or not a standby) counts as no lag.
+		var lagSeconds float64
+		err = r.pool.QueryRow(ctx,
+			"SELECT COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)").Scan(&lagSeconds)
+		lag := time.Duration(lagSeconds * float64(time.Second))
+		if err == nil {
+			r.lag.Store(int64(lag))
+		}
+		if err == nil && rs.maxLag > 0 && lag > rs.maxLag {
+			err = fmt.Errorf("replication lag %s exceeds %s", lag.Round(time.Millisecond), rs.maxLag)
+		}
+	}
+
+	rs.setHealth(r, err)
+}
+
+func (rs *replicaSet) setHealth(r *replica, err error) {
+	healthy := err == nil
+	if r.healthy.Swap(healthy) == healthy {
+		return
+	}
+
+	if healthy {
+		rs.log.Info().Str("replica", r.host).Msg("database replica is healthy, routing reads to it")
+	} else {
+		rs.log.Warn().Err(err).Str("replica", r.host).Msg("database replica is unhealthy, reads fall back to other replicas or the primary")
+	}
+}
+
+func (rs *replicaSet) close() {
+	close(rs.stop)
+	<-rs.done
+	rs.closePools()
+}
+
+func (rs *replicaSet) closePools() {
+	for _, r := range rs.replicas {
+		r.pool.Close()
+	}
+}
+
+type primaryKey struct{}
+
+// WithPrimary returns a copy of ctx whose reads go to the primary, for
+// read-your-writes right after a write (replicas may lag behind):
+//
+//	ctx = database.WithPrimary(ctx)
+//	todo, err := r.GetByID(ctx, id)
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// reader returns the replica for sql, or nil when it must run on the
+// primary: no healthy replica, ctx pinned with WithPrimary, or a statement
+// that may write or lock.
+func (db *Database) reader(ctx context.Context, sql string) *replica {
+	if db.replicas == nil || !isReadOnly(sql) {
+		return nil
+	}
+	if pinned, _ := ctx.Value(primaryKey{}).(bool); pinned {
+		return nil
+	}
+	return db.replicas.pick()
+}
+
+// Query runs sql on a healthy replica when it is a plain read, otherwise on
+// the primary. A replica that can't be reached is marked unhealthy and the
+// query is retried on the primary.
+//
+// Transactions never get here: repositories use the pgx.Tx bound to the
+// context (see repository.Base.Querier), which always is on the primary.
+func (db *Database) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if r := db.reader(ctx, sql); r != nil {
+		rows, err := r.pool.Query(ctx, sql, args...)
+		if err == nil || !isConnError(ctx, err) {
+			return rows, err
+		}
+		db.replicas.setHealth(r, err)
+	}
+	return db.Pool.Query(ctx, sql, args...)
+}
+
+// QueryRow is Query for a single row. Its error only surfaces on Scan, so a
+// replica failure is not retried; the health loop takes the replica out.
+func (db *Database) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if r := db.reader(ctx, sql); r != nil {
+		return r.pool.QueryRow(ctx, sql, args...)
+	}
+	return db.Pool.QueryRow(ctx, sql, args...)
+}
+
+// Exec always runs on the primary.
+func (db *Database) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return db.Pool.Exec(ctx, sql, args...)
+}
+
+// Reader returns a healthy replica pool, or the primary when there is none
+// (or ctx is pinned with WithPrimary), for read-only code that needs a
+// *pgxpool.Pool rather than a Querier (search and embedding loaders, reports).
+func (db *Database) Reader(ctx context.Context) *pgxpool.Pool {
+	if r := db.reader(ctx, "SELECT"); r != nil {
+		return r.pool
+	}
+	return db.Pool
+}
+
+// isReadOnly reports whether sql is a plain read: a SELECT (or a WITH
+// query) without data-modifying CTEs or row locks. Anything it isn't sure
+// about goes to the primary, where it is always correct.
+func isReadOnly(sql string) bool {
+	sql = strings.ToUpper(strings.TrimSpace(sql))
+	if !strings.HasPrefix(sql, "SELECT") && !strings.HasPrefix(sql, "WITH") {
+		return false
+	}
+
+	for _, keyword := range []string{"INSERT", "UPDATE", "DELETE", "MERGE", "NEXTVAL", "SETVAL", "PG_ADVISORY", "FOR SHARE", "FOR KEY SHARE"} {
+		if strings.Contains(sql, keyword) {
+			return false
+		}
+	}
+	return true
+}
+
+// isConnError reports whether err means the server couldn't be used at all
+// (rather than the query failing on it) while ctx is still alive.
+func isConnError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	return !errors.As(err, &pgErr)
+}
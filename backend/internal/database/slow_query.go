@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/lib/tracing"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog"
+)
+
+// maxLoggedStatement caps the SQL written per slow query log line.
+const maxLoggedStatement = 1024
+
+// stringLiteral matches SQL string literals, including escaped quotes.
+var stringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+
+// slowQueryTracer logs queries that are slower than a threshold or fail
+// (LoggingConfig.SlowQueryThreshold; 0 only logs failures).
+//
+// Unlike the local tracelog it is safe for production: nothing is logged for
+// normal queries, arguments are never logged (only their count), and string
+// literals inlined in the SQL are masked. Each logged query also becomes a
+// "SlowQuery" event on the request/job span, so it shows up on the trace.
+type slowQueryTracer struct {
+	logger    zerolog.Logger
+	threshold time.Duration
+}
+
+type slowQueryKey struct{}
+
+type slowQueryStart struct {
+	at   time.Time
+	sql  string
+	args int
+}
+
+func (t slowQueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, slowQueryKey{}, slowQueryStart{at: time.Now(), sql: data.SQL, args: len(data.Args)})
+}
+
+func (t slowQueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(slowQueryKey{}).(slowQueryStart)
+	if !ok {
+		return
+	}
+	duration := time.Since(start.at)
+
+	// A canceled context is the caller giving up (client disconnect, request
+	// timeout), which is reported where it happened.
+	failed := data.Err != nil && !errors.Is(data.Err, context.Canceled)
+	slow := t.threshold > 0 && duration >= t.threshold
+	if !failed && !slow {
+		return
+	}
+
+	statement := redactStatement(start.sql)
+
+	zc := withCorrelation(ctx, t.logger.With()).
+		Str("sql", statement).
+		Int("args", start.args).
+		Dur("duration", duration).
+		Bool("slow_query", slow)
+
+	event := map[string]interface{}{
+		"statement":   statement,
+		"duration_ms": duration.Milliseconds(),
+	}
+
+	if failed {
+		zc = zc.AnErr("query_error", data.Err)
+		event["error"] = data.Err.Error()
+
+		var pgErr *pgconn.PgError
+		if errors.As(data.Err, &pgErr) {
+			zc = zc.Str("sqlstate", pgErr.Code)
+			event["sqlstate"] = pgErr.Code
+		}
+	} else {
+		zc = zc.Int64("rows", data.CommandTag.RowsAffected())
+	}
+
+	logger := zc.Logger()
+	if failed {
+		// Warn rather than error: constraint violations are expected and
+		// mapped to 4xx responses (see sqlerr).
+		logger.Warn().Msg("query failed")
+	} else {
+		logger.Warn().Msgf("slow query (over %s)", t.threshold)
+	}
+
+	if span := tracing.FromContext(ctx); span != nil {
+		span.AddEvent("SlowQuery", event)
+	}
+}
+
+// redactStatement masks string literals and trims whitespace and length,
+// leaving the parameterized SQL ($1, $2...) readable.
+func redactStatement(sql string) string {
+	sql = stringLiteral.ReplaceAllString(sql, "'?'")
+	sql = strings.Join(strings.Fields(sql), " ")
+	if len(sql) > maxLoggedStatement {
+		sql = sql[:maxLoggedStatement] + "..."
+	}
+	return sql
+}
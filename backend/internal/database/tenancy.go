@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/lib/ctxutil"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TenantSetting is the session setting carrying the tenant ID in
+// shared_schema mode with tenancy.rls, for row-level security policies:
+//
+//	ALTER TABLE todos ENABLE ROW LEVEL SECURITY;
+//	CREATE POLICY tenant_isolation ON todos
+//	    USING (tenant_id = current_setting('app.tenant_id', true));
+//
+// The application role must not own the tables (or needs FORCE ROW LEVEL
+// SECURITY), as owners bypass policies.
+const TenantSetting = "app.tenant_id"
+
+// setTenantSession makes every connection acquired from the pool run as the
+// tenant of the acquiring context (ctxutil.Tenant), when tenancy is
+// session-scoped (see config.TenancyConfig.SessionScoped):
+//
+//   - rls: app.tenant_id is set to the tenant, or to "" without one, which
+//     policies like the one above match no row with
+//   - schema_per_tenant: search_path is the tenant's schema, then public
+//     (shared extensions and functions); without a tenant it is reset to
+//     the server default
+//
+// The setting is applied on every acquire, so a pooled connection never
+// keeps the previous tenant. A transaction acquires once, at BEGIN, so the
+// tenant of the context passed to TxManager.WithinTx applies to all of it.
+//
+// Schemas are not created here: provision each tenant's schema (and run the
+// migrations into it) before routing its traffic.
+func setTenantSession(poolConfig *pgxpool.Config, cfg *config.TenancyConfig) {
+	if !cfg.SessionScoped() {
+		return
+	}
+
+	poolConfig.PrepareConn = func(ctx context.Context, conn *pgx.Conn) (bool, error) {
+		if err := applyTenant(ctx, conn, cfg, ctxutil.Tenant(ctx)); err != nil {
+			// The connection may be in any state: destroy it.
+			return false, err
+		}
+		return true, nil
+	}
+}
+
+// applyTenant sets the tenant session setting of conn.
+func applyTenant(ctx context.Context, conn *pgx.Conn, cfg *config.TenancyConfig, tenantID string) error {
+	var err error
+	switch {
+	case cfg.GetMode() == config.TenancySchemaPerTenant && tenantID == "":
+		_, err = conn.Exec(ctx, "SELECT set_config('search_path', reset_val, false) FROM pg_settings WHERE name = 'search_path'")
+	case cfg.GetMode() == config.TenancySchemaPerTenant:
+		if err = cfg.ValidateTenantID(tenantID); err != nil {
+			return err
+		}
+		searchPath := pgx.Identifier{cfg.SchemaName(tenantID)}.Sanitize() + ", public"
+		_, err = conn.Exec(ctx, "SELECT set_config('search_path', $1, false)", searchPath)
+	default:
+		_, err = conn.Exec(ctx, "SELECT set_config($1, $2, false)", TenantSetting, tenantID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set tenant session: %w", err)
+	}
+	return nil
+}
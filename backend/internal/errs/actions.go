@@ -0,0 +1,155 @@
+package errs
+
+import (
+	"strconv"
+	"time"
+)
+
+// Typed action payloads.
+//
+// Each action type has one payload struct, serialized under "action.data",
+// so frontends can switch on action.type and decode a known shape instead of
+// parsing action.value:
+//
+//	{
+//	  "code": "QUOTA_EXCEEDED",
+//	  "action": {
+//	    "type": "upgrade_plan",
+//	    "message": "Upgrade to keep going",
+//	    "value": "pro",
+//	    "data": { "current_plan": "free", "required_plan": "pro", "upgrade_url": "/billing" }
+//	  }
+//	}
+
+// RetryAfterData is the payload of a "retry_after" action.
+type RetryAfterData struct {
+	// Seconds to wait before retrying (mirrors the Retry-After header).
+	Seconds int `json:"seconds"`
+
+	// At is the earliest time to retry.
+	At time.Time `json:"at"`
+}
+
+// VerifyEmailData is the payload of a "verify_email" action.
+type VerifyEmailData struct {
+	// Email is the address awaiting verification (may be empty).
+	Email string `json:"email,omitempty"`
+
+	// ResendURL is where the client can request a new verification email.
+	ResendURL string `json:"resend_url,omitempty"`
+}
+
+// UpgradePlanData is the payload of an "upgrade_plan" action.
+type UpgradePlanData struct {
+	CurrentPlan  string `json:"current_plan,omitempty"`
+	RequiredPlan string `json:"required_plan,omitempty"`
+
+	// UpgradeURL is the billing page to send the user to.
+	UpgradeURL string `json:"upgrade_url,omitempty"`
+}
+
+// ContactSupportData is the payload of a "contact_support" action.
+type ContactSupportData struct {
+	// Reference is what the user should quote to support (usually the request ID).
+	Reference string `json:"reference,omitempty"`
+
+	// SupportURL is a help page or mailto: link.
+	SupportURL string `json:"support_url,omitempty"`
+}
+
+// RefreshTokenData is the payload of a "refresh_token" action.
+type RefreshTokenData struct {
+	// Reason tells why the token was rejected (e.g. "expired", "stale_claims").
+	Reason string `json:"reason,omitempty"`
+}
+
+// RefreshAuthData is the payload of a "refresh_auth" action.
+type RefreshAuthData struct {
+	// Reason tells why a new sign-in is needed (e.g. "session_revoked",
+	// "reauthentication_required").
+	Reason string `json:"reason,omitempty"`
+
+	// LoginURL is where to send the user to sign in (may be empty).
+	LoginURL string `json:"login_url,omitempty"`
+}
+
+// NewRetryAfterAction builds a "retry_after" action for a wait of d
+// (rounded up to whole seconds). Value holds the seconds.
+func NewRetryAfterAction(message string, d time.Duration) *Action {
+	seconds := int((d + time.Second - 1) / time.Second)
+
+	return &Action{
+		Type:    ActionTypeRetryAfter,
+		Message: message,
+		Value:   strconv.Itoa(seconds),
+		Data: RetryAfterData{
+			Seconds: seconds,
+			At:      time.Now().Add(time.Duration(seconds) * time.Second).UTC(),
+		},
+	}
+}
+
+// NewVerifyEmailAction builds a "verify_email" action. Value holds the email.
+func NewVerifyEmailAction(message, email, resendURL string) *Action {
+	return &Action{
+		Type:    ActionTypeVerifyEmail,
+		Message: message,
+		Value:   email,
+		Data:    VerifyEmailData{Email: email, ResendURL: resendURL},
+	}
+}
+
+// NewUpgradePlanAction builds an "upgrade_plan" action. Value holds the
+// required plan.
+func NewUpgradePlanAction(message string, data UpgradePlanData) *Action {
+	return &Action{
+		Type:    ActionTypeUpgradePlan,
+		Message: message,
+		Value:   data.RequiredPlan,
+		Data:    data,
+	}
+}
+
+// NewContactSupportAction builds a "contact_support" action. Value holds the
+// reference to quote.
+func NewContactSupportAction(message, reference, supportURL string) *Action {
+	return &Action{
+		Type:    ActionTypeContactSupport,
+		Message: message,
+		Value:   reference,
+		Data:    ContactSupportData{Reference: reference, SupportURL: supportURL},
+	}
+}
+
+// NewRefreshTokenAction builds a "refresh_token" action. Value holds the reason.
+func NewRefreshTokenAction(message, reason string) *Action {
+	return &Action{
+		Type:    ActionTypeRefreshToken,
+		Message: message,
+		Value:   reason,
+		Data:    RefreshTokenData{Reason: reason},
+	}
+}
+
+// NewRefreshAuthAction builds a "refresh_auth" action. Value holds the reason.
+func NewRefreshAuthAction(message, reason, loginURL string) *Action {
+	return &Action{
+		Type:    ActionTypeRefreshAuth,
+		Message: message,
+		Value:   reason,
+		Data:    RefreshAuthData{Reason: reason, LoginURL: loginURL},
+	}
+}
+
+// WithAction returns a copy of e carrying action.
+//
+// It lets the specific constructors stay small while any error gains a next
+// step at the call site:
+//
+//	return errs.NewQuotaExceededError(msg, "daily").
+//		WithAction(errs.NewRetryAfterAction("Quota resets tomorrow", wait))
+func (e *HTTPError) WithAction(action *Action) *HTTPError {
+	clone := e.clone()
+	clone.Action = action
+	return clone
+}
@@ -0,0 +1,169 @@
+package errs
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// CatalogEntry documents one stable error code.
+//
+// The catalog is the single source for error documentation and the contract
+// with frontend teams: the /docs/errors page and `<service> errors list` are
+// rendered from it, and the global error handler only links codes listed
+// here (see DocsConfig.ErrorDocsURL).
+type CatalogEntry struct {
+	Code        string `json:"code"`
+	Status      int    `json:"status"`
+	Description string `json:"description"`
+
+	// Message is the default client-facing message of the code, if it has
+	// one (codes with per-occurrence messages leave it empty).
+	Message string `json:"message,omitempty"`
+}
+
+// Registry holds the declared error codes.
+//
+// Every package minting codes declares them from init, so collisions (the
+// same code declared with another status or description by another package)
+// would otherwise only show up in production responses. The registry keeps
+// them, and Validate reports them at startup.
+//
+// Declaring a code again with the same status and description, or leaving
+// either empty, completes the first declaration instead: sqlerr declares
+// USER_ALREADY_EXISTS for a table and again for a registered constraint.
+type Registry struct {
+	mu         sync.RWMutex
+	entries    map[string]CatalogEntry
+	collisions []string
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{entries: map[string]CatalogEntry{}}
+}
+
+// DefaultRegistry is the registry of the application, behind RegisterCode,
+// LookupCode and Catalog.
+var DefaultRegistry = NewRegistry()
+
+// Register declares a code.
+func (r *Registry) Register(entry CatalogEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.entries[entry.Code]
+	if !ok {
+		r.entries[entry.Code] = entry
+		return
+	}
+
+	if existing.Status != entry.Status || !compatible(existing.Description, entry.Description) {
+		r.collisions = append(r.collisions, fmt.Sprintf(
+			"%s declared as %d %q and as %d %q",
+			entry.Code, existing.Status, existing.Description, entry.Status, entry.Description,
+		))
+		return
+	}
+
+	if existing.Description == "" {
+		existing.Description = entry.Description
+	}
+	if existing.Message == "" {
+		existing.Message = entry.Message
+	}
+	r.entries[entry.Code] = existing
+}
+
+// compatible reports whether two declarations' texts agree.
+func compatible(a, b string) bool {
+	return a == "" || b == "" || a == b
+}
+
+// Lookup returns the entry of code.
+func (r *Registry) Lookup(code string) (CatalogEntry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, ok := r.entries[code]
+	return entry, ok
+}
+
+// Entries returns every declared code, sorted by code.
+func (r *Registry) Entries() []CatalogEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]CatalogEntry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Code < entries[j].Code })
+	return entries
+}
+
+// Validate reports the colliding declarations, one per line.
+func (r *Registry) Validate() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.collisions) == 0 {
+		return nil
+	}
+	return fmt.Errorf("conflicting error code declarations:\n  %s", strings.Join(r.collisions, "\n  "))
+}
+
+// RegisterCode declares a code in the DefaultRegistry. Packages that mint
+// their own codes register them from init so the docs stay complete; a code
+// returned by a single package (or a single handler file) is registered
+// there, next to where it is returned, rather than in this package.
+func RegisterCode(entry CatalogEntry) {
+	DefaultRegistry.Register(entry)
+}
+
+// LookupCode returns the catalog entry for code.
+func LookupCode(code string) (CatalogEntry, bool) {
+	return DefaultRegistry.Lookup(code)
+}
+
+// Catalog returns every registered code, sorted by code.
+func Catalog() []CatalogEntry {
+	return DefaultRegistry.Entries()
+}
+
+// ValidateCatalog reports colliding declarations in the DefaultRegistry; the
+// services refuse to start with any.
+func ValidateCatalog() error {
+	return DefaultRegistry.Validate()
+}
+
+// The codes produced by the constructors in this package.
+func init() {
+	for _, entry := range []CatalogEntry{
+		{Code: "BAD_REQUEST", Status: http.StatusBadRequest, Description: "The request is malformed or failed validation; see errors for the offending fields."},
+		{Code: "UNAUTHORIZED", Status: http.StatusUnauthorized, Description: "The request has no valid session or API key."},
+		{Code: "FORBIDDEN", Status: http.StatusForbidden, Description: "The caller is authenticated but not allowed to perform this operation."},
+		{Code: "PERMISSION_DENIED", Status: http.StatusForbidden, Description: "The caller lacks the permission named in errors."},
+		{Code: "ROLE_REQUIRED", Status: http.StatusForbidden, Description: "The caller holds none of the roles listed in errors."},
+		{Code: "PAYMENT_REQUIRED", Status: http.StatusPaymentRequired, Description: "The caller's plan doesn't include this feature or its subscription is unpaid."},
+		{Code: "NOT_FOUND", Status: http.StatusNotFound, Description: "The resource or route does not exist."},
+		{Code: "METHOD_NOT_ALLOWED", Status: http.StatusMethodNotAllowed, Description: "The route exists but not for this HTTP method; see the Allow header."},
+		{Code: "REQUEST_TIMEOUT", Status: http.StatusRequestTimeout, Description: "The client gave up before the server answered."},
+		{Code: "CONFLICT", Status: http.StatusConflict, Description: "The request conflicts with the current state of the resource."},
+		{Code: "PAYLOAD_TOO_LARGE", Status: http.StatusRequestEntityTooLarge, Description: "The request body exceeds the route's size limit."},
+		{Code: "UNSUPPORTED_MEDIA_TYPE", Status: http.StatusUnsupportedMediaType, Description: "The request body's Content-Type can't be read by this route; send JSON."},
+		{Code: "UNPROCESSABLE_ENTITY", Status: http.StatusUnprocessableEntity, Description: "The request is well-formed but breaks a business rule; see message and errors."},
+		{Code: "TOO_MANY_REQUESTS", Status: http.StatusTooManyRequests, Description: "The operation is throttled; retry after the hint."},
+		{Code: "RATE_LIMIT_EXCEEDED", Status: http.StatusTooManyRequests, Description: "Too many requests in a short time; back off for Retry-After seconds."},
+		{Code: "QUOTA_EXCEEDED", Status: http.StatusTooManyRequests, Description: "The plan's daily or monthly quota is used up; retry after the reset or upgrade."},
+		{Code: "INTERNAL_SERVER_ERROR", Status: http.StatusInternalServerError, Description: "An unexpected server error; quote the X-Request-ID when contacting support.", Message: "Internal Server Error"},
+		{Code: "MAINTENANCE_MODE", Status: http.StatusServiceUnavailable, Description: "The API is in maintenance; retry after Retry-After seconds."},
+		{Code: "DEADLINE_EXCEEDED", Status: http.StatusServiceUnavailable, Description: "The server could not complete the request in time; retrying later may work."},
+		{Code: "SERVICE_DEGRADED", Status: http.StatusServiceUnavailable, Description: "A dependency this feature needs is down; the rest of the API works, retry after the hint.", Message: "This feature is temporarily unavailable, please retry later"},
+		{Code: "SERVICE_UNAVAILABLE", Status: http.StatusServiceUnavailable, Description: "A transient failure; retry after the hint."},
+		{Code: "SERVER_OVERLOADED", Status: http.StatusServiceUnavailable, Description: "The server is at capacity and shed the request; retry shortly."},
+	} {
+		RegisterCode(entry)
+	}
+}
@@ -23,6 +23,12 @@ type FieldError struct {
 
 	// Error is the human-readable error message.
 	Error string `json:"error"`
+
+	// Key and Params let the global error handler translate Error into the
+	// request locale (see lib/i18n), e.g. "validation.min.string" with
+	// {"param": 8}. Error stays the English fallback.
+	Key    string         `json:"-"`
+	Params map[string]any `json:"-"`
 }
 
 // ActionType is a string-based enum describing what the client should do.
@@ -32,6 +38,34 @@ const (
 	// ActionTypeRedirect tells the client it should redirect somewhere.
 	// Usually "Value" holds the URL or route.
 	ActionTypeRedirect ActionType = "redirect"
+
+	// ActionTypeRestore tells the client the conflicting resource exists but
+	// was soft-deleted, and can be restored instead of re-created.
+	// "Value" holds the ID of the deleted resource.
+	ActionTypeRestore ActionType = "restore"
+
+	// The action types below carry a typed payload in Action.Data
+	// (see actions.go for the payloads and constructors).
+
+	// ActionTypeRetryAfter tells the client to retry the same request later.
+	ActionTypeRetryAfter ActionType = "retry_after"
+
+	// ActionTypeVerifyEmail tells the client the user must verify their email first.
+	ActionTypeVerifyEmail ActionType = "verify_email"
+
+	// ActionTypeUpgradePlan tells the client the feature or quota needs a higher plan.
+	ActionTypeUpgradePlan ActionType = "upgrade_plan"
+
+	// ActionTypeContactSupport tells the client only support can resolve the problem.
+	ActionTypeContactSupport ActionType = "contact_support"
+
+	// ActionTypeRefreshToken tells the client to refresh its session token and retry.
+	ActionTypeRefreshToken ActionType = "refresh_token"
+
+	// ActionTypeRefreshAuth tells the client the user must sign in again
+	// (session revoked, or a fresh sign-in required for a sensitive operation);
+	// unlike refresh_token, a silent refresh won't help.
+	ActionTypeRefreshAuth ActionType = "refresh_auth"
 )
 
 // Action describes an optional “what the client should do next” instruction.
@@ -46,7 +80,13 @@ type Action struct {
 	Message string `json:"message"`
 
 	// Value is the payload for the action (e.g. redirect URL).
+	// Typed actions also fill it with their main value, so clients that only
+	// read Value keep working.
 	Value string `json:"value"`
+
+	// Data is the typed payload of the newer action types
+	// (e.g. RetryAfterData for "retry_after"). Omitted when empty.
+	Data any `json:"data,omitempty"`
 }
 
 // HTTPError is the main custom error type for API responses.
@@ -60,6 +100,7 @@ type Action struct {
 //   - Override: flag to let middleware decide whether to override the message.
 //   - Errors: list of per-field errors (validation).
 //   - Action: client instruction, action to be taken (optional).
+//   - Details: facts about the error beyond the fields (optional).
 type HTTPError struct {
 	Code     string `json:"code"`
 	Message  string `json:"message"`
@@ -71,6 +112,29 @@ type HTTPError struct {
 
 	/// Action is an optional client instruction (redirect, etc.).
 	Action *Action `json:"action"`
+
+	// Details holds machine-readable facts about the error that are not
+	// about a request field (the missing permission, the exhausted quota
+	// window, ...), so clients don't have to parse Message. Omitted when
+	// empty.
+	Details map[string]any `json:"details,omitempty"`
+
+	// DocsURL links the documentation of Code (filled by the global error
+	// handler from the error catalog, see DocsConfig.ErrorDocsURL).
+	DocsURL string `json:"docs_url,omitempty"`
+
+	// Cause is the internal error behind this one (see WithCause). Never
+	// sent to clients.
+	Cause error `json:"-"`
+
+	// Metadata holds internal context for logs and traces (see WithField).
+	// Never sent to clients.
+	Metadata map[string]any `json:"-"`
+
+	// MessageKey and MessageParams translate Message into the request locale
+	// (see WithMessageKey and lib/i18n).
+	MessageKey    string         `json:"-"`
+	MessageParams map[string]any `json:"-"`
 }
 
 // Error makes *HTTPError satisfy the built-in `error` interface.
@@ -102,16 +166,63 @@ func (e *HTTPError) Is(target error) bool {
 //
 // Useful if you have a base error template and want to customize message
 // without mutating the original.
+//
+// The message key (see WithMessageKey) is dropped: it described the old message.
 func (e *HTTPError) WithMessage(message string) *HTTPError {
-	return &HTTPError{
-		// Copy everything, replace only Message.
-		Code:     e.Code,
-		Message:  message,
-		Status:   e.Status,
-		Override: e.Override,
-		Errors:   e.Errors,
-		Action:   e.Action,
+	// Copy everything, replace only Message.
+	clone := e.clone()
+	clone.Message = message
+	clone.MessageKey, clone.MessageParams = "", nil
+	return clone
+}
+
+// WithMessageKey returns a copy of e whose Message is translated into the
+// request locale by the global error handler, using key and params (see
+// lib/i18n). Message stays the English fallback:
+//
+//	return errs.NewBadRequestError("The invoice is already paid", true, &code, nil, nil).
+//		WithMessageKey("invoice.already_paid", nil)
+func (e *HTTPError) WithMessageKey(key string, params map[string]any) *HTTPError {
+	clone := e.clone()
+	clone.MessageKey, clone.MessageParams = key, params
+	return clone
+}
+
+// clone returns a shallow copy of e.
+func (e *HTTPError) clone() *HTTPError {
+	clone := *e
+	return &clone
+}
+
+// WithCause returns a copy of e wrapping cause, the internal error that led
+// to it. The client still only sees e; the global error handler logs the
+// cause, so nothing is lost when a repository turns a database error into a
+// 404:
+//
+//	return errs.NewNotFoundError("User not found", true, nil).WithCause(err)
+func (e *HTTPError) WithCause(cause error) *HTTPError {
+	clone := e.clone()
+	clone.Cause = cause
+	return clone
+}
+
+// WithField returns a copy of e with key set in its Metadata, logged (and
+// added to the request's trace) by the global error handler:
+//
+//	return errs.NewForbiddenError("Not allowed", true).WithField("document_id", id)
+func (e *HTTPError) WithField(key string, value any) *HTTPError {
+	clone := e.clone()
+	clone.Metadata = make(map[string]any, len(e.Metadata)+1)
+	for k, v := range e.Metadata {
+		clone.Metadata[k] = v
 	}
+	clone.Metadata[key] = value
+	return clone
+}
+
+// Unwrap returns the Cause, so errors.Is and errors.As see through e.
+func (e *HTTPError) Unwrap() error {
+	return e.Cause
 }
 
 // MakeUpperCaseWithUnderscores converts a string into an UPPER_CASE_WITH_UNDERSCORES format.
@@ -0,0 +1,58 @@
+package errs
+
+import "net/http"
+
+// ProblemContentType is the media type of RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+// Problem is an HTTPError rendered as RFC 7807 problem details:
+//
+//	{
+//	  "type": "https://api.example.com/docs/errors#USER_ALREADY_EXISTS",
+//	  "title": "Conflict",
+//	  "status": 409,
+//	  "detail": "A user with this email already exists",
+//	  "instance": "/api/v1/users",
+//	  "code": "USER_ALREADY_EXISTS",
+//	  "errors": [{ "field": "email", "error": "already exists" }]
+//	}
+//
+// code, errors, action, details and request_id are extension members
+// carrying what the JSON envelope has on top of the standard ones.
+type Problem struct {
+	// Type is a URI identifying the kind of problem ("about:blank" when the
+	// code has no page).
+	Type string `json:"type"`
+
+	// Title is the status text; it doesn't vary between occurrences.
+	Title string `json:"title"`
+
+	Status int `json:"status"`
+
+	// Detail is the message of this occurrence.
+	Detail string `json:"detail,omitempty"`
+
+	// Instance identifies this occurrence: the request path.
+	Instance string `json:"instance,omitempty"`
+
+	Code      string         `json:"code"`
+	Errors    []FieldError   `json:"errors,omitempty"`
+	Action    *Action        `json:"action,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+}
+
+// Problem renders e as problem details of the given type URI and instance.
+func (e *HTTPError) Problem(typeURI, instance string) Problem {
+	return Problem{
+		Type:     typeURI,
+		Title:    http.StatusText(e.Status),
+		Status:   e.Status,
+		Detail:   e.Message,
+		Instance: instance,
+		Code:     e.Code,
+		Errors:   e.Errors,
+		Action:   e.Action,
+		Details:  e.Details,
+	}
+}
@@ -2,6 +2,8 @@ package errs
 
 import (
 	"net/http"
+	"strings"
+	"time"
 )
 
 // NewUnauthorizedError creates a 401 Unauthorized HTTPError.
@@ -32,6 +34,34 @@ func NewForbiddenError(message string, override bool) *HTTPError {
 	}
 }
 
+// NewMissingPermissionError creates a 403 Forbidden HTTPError with code
+// "PERMISSION_DENIED" for a caller lacking permission.
+//
+// The permission name is also reported in Details ("permission") so
+// clients can show which grant is missing without parsing the message.
+func NewMissingPermissionError(permission string) *HTTPError {
+	return &HTTPError{
+		Code:     "PERMISSION_DENIED",
+		Message:  "Missing required permission: " + permission,
+		Status:   http.StatusForbidden,
+		Override: false,
+		Details:  map[string]any{"permission": permission},
+	}
+}
+
+// NewMissingRoleError creates a 403 Forbidden HTTPError with code
+// "ROLE_REQUIRED" for a caller holding none of the accepted roles
+// (reported in Details as "roles").
+func NewMissingRoleError(roles []string) *HTTPError {
+	return &HTTPError{
+		Code:     "ROLE_REQUIRED",
+		Message:  "Required role: " + strings.Join(roles, " or "),
+		Status:   http.StatusForbidden,
+		Override: false,
+		Details:  map[string]any{"roles": roles},
+	}
+}
+
 // NewBadRequestError creates a 400 Bad Request HTTPError.
 //
 // This supports extra payload:
@@ -41,18 +71,9 @@ func NewForbiddenError(message string, override bool) *HTTPError {
 //
 // This is designed for form validation and “you sent garbage” cases.
 func NewBadRequestError(message string, override bool, code *string, errors []FieldError, action *Action) *HTTPError {
-	// Default code comes from HTTP status text:
-	// http.StatusText(400) => "Bad Request" => "BAD_REQUEST"
-	formattedCode := MakeUpperCaseWithUnderscores(http.StatusText(http.StatusBadRequest))
-
-	// If caller supplies custom code pointer, use it.
-	// Note: this assumes the caller already formatted it the way they want.
-	if code != nil {
-		formattedCode = *code
-	}
-
 	return &HTTPError{
-		Code:     formattedCode,
+		// Defaults to "BAD_REQUEST" (see codeFor).
+		Code:     codeFor(http.StatusBadRequest, code),
 		Message:  message,
 		Status:   http.StatusBadRequest,
 		Override: override,
@@ -65,22 +86,272 @@ func NewBadRequestError(message string, override bool, code *string, errors []Fi
 //
 // Supports optional custom code override similar to NewBadRequestError.
 func NewNotFoundError(message string, override bool, code *string) *HTTPError {
-	// Default code: "NOT_FOUND"
-	formattedCode := MakeUpperCaseWithUnderscores(http.StatusText(http.StatusNotFound))
+	return &HTTPError{
+		// Defaults to "NOT_FOUND".
+		Code:     codeFor(http.StatusNotFound, code),
+		Message:  message,
+		Status:   http.StatusNotFound,
+		Override: override,
+	}
+}
+
+// NewConflictError creates a 409 Conflict HTTPError.
+//
+// Supports optional custom code override similar to NewNotFoundError.
+func NewConflictError(message string, override bool, code *string) *HTTPError {
+	return &HTTPError{
+		// Defaults to "CONFLICT".
+		Code:     codeFor(http.StatusConflict, code),
+		Message:  message,
+		Status:   http.StatusConflict,
+		Override: override,
+	}
+}
+
+// NewUnprocessableEntityError creates a 422 Unprocessable Entity HTTPError.
+//
+// Use it when the request is well-formed but breaks a business rule
+// ("the end date is before the start date", "the invoice is already paid"),
+// keeping 400 for malformed input. errors optionally names the fields.
+func NewUnprocessableEntityError(message string, override bool, code *string, errors []FieldError) *HTTPError {
+	return &HTTPError{
+		// Defaults to "UNPROCESSABLE_ENTITY".
+		Code:     codeFor(http.StatusUnprocessableEntity, code),
+		Message:  message,
+		Status:   http.StatusUnprocessableEntity,
+		Override: override,
+		Errors:   errors,
+	}
+}
+
+// NewPaymentRequiredError creates a 402 Payment Required HTTPError, for a
+// feature the caller's plan doesn't include or an unpaid subscription.
+//
+// action is optional; usually an upgrade_plan action (NewUpgradePlanAction).
+func NewPaymentRequiredError(message string, code *string, action *Action) *HTTPError {
+	return &HTTPError{
+		// Defaults to "PAYMENT_REQUIRED".
+		Code:     codeFor(http.StatusPaymentRequired, code),
+		Message:  message,
+		Status:   http.StatusPaymentRequired,
+		Override: true,
+		Action:   action,
+	}
+}
 
-	// Optional custom error code.
+// NewTooManyRequestsError creates a 429 Too Many Requests HTTPError with a
+// retry_after action for a wait of retryAfter (none when zero), which the
+// global error handler mirrors in the Retry-After header.
+//
+// For the rate limiter and quotas, see NewRateLimitExceededError and
+// NewQuotaExceededError; this is for other throttling, e.g. "one password
+// reset email per minute".
+func NewTooManyRequestsError(message string, code *string, retryAfter time.Duration) *HTTPError {
+	return &HTTPError{
+		// Defaults to "TOO_MANY_REQUESTS".
+		Code:     codeFor(http.StatusTooManyRequests, code),
+		Message:  message,
+		Status:   http.StatusTooManyRequests,
+		Override: true,
+		Action:   retryAfterAction(retryAfter),
+	}
+}
+
+// NewServiceUnavailableError creates a 503 Service Unavailable HTTPError for
+// a transient failure, with a retry_after action like NewTooManyRequestsError.
+func NewServiceUnavailableError(message string, code *string, retryAfter time.Duration) *HTTPError {
+	return &HTTPError{
+		// Defaults to "SERVICE_UNAVAILABLE".
+		Code:     codeFor(http.StatusServiceUnavailable, code),
+		Message:  message,
+		Status:   http.StatusServiceUnavailable,
+		Override: true,
+		Action:   retryAfterAction(retryAfter),
+	}
+}
+
+// codeFor returns code if set, else the status text as a code:
+// http.StatusText(400) => "Bad Request" => "BAD_REQUEST".
+//
+// A custom code is used as is: the caller formats it the way they want.
+func codeFor(status int, code *string) string {
 	if code != nil {
-		formattedCode = *code
+		return *code
+	}
+	return MakeUpperCaseWithUnderscores(http.StatusText(status))
+}
+
+// retryAfterAction is the retry_after action of a wait of d, nil for none.
+func retryAfterAction(d time.Duration) *Action {
+	if d <= 0 {
+		return nil
+	}
+	return NewRetryAfterAction("Retry the request later", d)
+}
+
+// NewRedirectAction builds a redirect Action pointing at url.
+//
+// It is used both in error bodies (e.g. "session expired, go to /login") and
+// in successful redirect responses written by handler.HandleRedirect, so
+// frontends handle both through the same action-processing code path.
+func NewRedirectAction(message, url string) *Action {
+	return &Action{
+		Type:    ActionTypeRedirect,
+		Message: message,
+		Value:   url,
+	}
+}
+
+// NewRestoreAction builds a restore Action for the soft-deleted resource id.
+func NewRestoreAction(message, id string) *Action {
+	return &Action{
+		Type:    ActionTypeRestore,
+		Message: message,
+		Value:   id,
+	}
+}
+
+// NewMethodNotAllowedError creates a 405 Method Not Allowed HTTPError.
+//
+// allowed lists the methods the route does accept; it is echoed in the
+// message so clients that ignore the Allow header still see what to use.
+// The Allow header itself is set by the global error handler.
+func NewMethodNotAllowedError(allowed []string) *HTTPError {
+	message := "Method not allowed"
+	if len(allowed) > 0 {
+		message += ", allowed: " + strings.Join(allowed, ", ")
 	}
 
 	return &HTTPError{
-		Code:     formattedCode,
+		Code:     MakeUpperCaseWithUnderscores(http.StatusText(http.StatusMethodNotAllowed)),
 		Message:  message,
-		Status:   http.StatusNotFound,
+		Status:   http.StatusMethodNotAllowed,
+		Override: true,
+	}
+}
+
+// NewPayloadTooLargeError creates a 413 Request Entity Too Large HTTPError.
+//
+// Used when the request body exceeds the configured body limit.
+// Code is "PAYLOAD_TOO_LARGE" rather than the longer status text so
+// clients get a short, stable machine code.
+func NewPayloadTooLargeError(message string, override bool) *HTTPError {
+	return &HTTPError{
+		Code:     "PAYLOAD_TOO_LARGE",
+		Message:  message,
+		Status:   http.StatusRequestEntityTooLarge,
 		Override: override,
 	}
 }
 
+// NewUnsupportedMediaTypeError creates a 415 Unsupported Media Type
+// HTTPError, for a request body in a Content-Type the route can't bind.
+func NewUnsupportedMediaTypeError(message string) *HTTPError {
+	return &HTTPError{
+		Code:     codeFor(http.StatusUnsupportedMediaType, nil),
+		Message:  message,
+		Status:   http.StatusUnsupportedMediaType,
+		Override: true,
+	}
+}
+
+// NewRateLimitExceededError creates a 429 Too Many Requests HTTPError.
+//
+// Code is "RATE_LIMIT_EXCEEDED" so clients can tell throttling apart from
+// other 429 causes and back off using the Retry-After header.
+func NewRateLimitExceededError(message string) *HTTPError {
+	return &HTTPError{
+		Code:     "RATE_LIMIT_EXCEEDED",
+		Message:  message,
+		Status:   http.StatusTooManyRequests,
+		Override: true,
+	}
+}
+
+// NewQuotaExceededError creates a 429 Too Many Requests HTTPError with code
+// "QUOTA_EXCEEDED".
+//
+// Unlike RATE_LIMIT_EXCEEDED (slow down for a second), this means the plan's
+// daily or monthly budget is used up: clients should stop until Retry-After
+// or upgrade. window ("daily"/"monthly") is reported in Details.
+func NewQuotaExceededError(message, window string) *HTTPError {
+	return &HTTPError{
+		Code:     "QUOTA_EXCEEDED",
+		Message:  message,
+		Status:   http.StatusTooManyRequests,
+		Override: true,
+		Details:  map[string]any{"window": window},
+	}
+}
+
+// NewMaintenanceError creates a 503 Service Unavailable HTTPError with code
+// "MAINTENANCE_MODE".
+//
+// action is optional; when set it usually redirects browsers to a status page.
+func NewMaintenanceError(message string, action *Action) *HTTPError {
+	return &HTTPError{
+		Code:     "MAINTENANCE_MODE",
+		Message:  message,
+		Status:   http.StatusServiceUnavailable,
+		Override: true,
+		Action:   action,
+	}
+}
+
+// NewRequestTimeoutError creates a 408 Request Timeout HTTPError.
+//
+// Used when the client gave up (canceled the request) before we answered.
+func NewRequestTimeoutError(message string) *HTTPError {
+	return &HTTPError{
+		Code:     MakeUpperCaseWithUnderscores(http.StatusText(http.StatusRequestTimeout)),
+		Message:  message,
+		Status:   http.StatusRequestTimeout,
+		Override: true,
+	}
+}
+
+// NewDeadlineExceededError creates a 503 Service Unavailable HTTPError with
+// code "DEADLINE_EXCEEDED".
+//
+// Used when the server-side request deadline expired: the server (or one of
+// its dependencies) was too slow, so it is a 5xx, and retrying later may work.
+func NewDeadlineExceededError(message string) *HTTPError {
+	return &HTTPError{
+		Code:     "DEADLINE_EXCEEDED",
+		Message:  message,
+		Status:   http.StatusServiceUnavailable,
+		Override: true,
+	}
+}
+
+// NewOverloadedError creates a 503 Service Unavailable HTTPError with code
+// "SERVER_OVERLOADED", used when load shedding rejects a request.
+func NewOverloadedError(message string) *HTTPError {
+	return &HTTPError{
+		Code:     "SERVER_OVERLOADED",
+		Message:  message,
+		Status:   http.StatusServiceUnavailable,
+		Override: true,
+	}
+}
+
+// NewServiceDegradedError creates a 503 Service Unavailable HTTPError with
+// code "SERVICE_DEGRADED" for a request needing a dependency that is down.
+//
+// The rest of the API keeps working, so this is reported per feature rather
+// than as an outage: the dependency name is in Details and a retry_after
+// action tells clients when it is worth trying again.
+func NewServiceDegradedError(dependency string, retryAfter time.Duration) *HTTPError {
+	return &HTTPError{
+		Code:     "SERVICE_DEGRADED",
+		Message:  "This feature is temporarily unavailable, please retry later",
+		Status:   http.StatusServiceUnavailable,
+		Override: true,
+		Details:  map[string]any{"dependency": dependency},
+		Action:   NewRetryAfterAction("Retry once the dependency has recovered", retryAfter),
+	}
+}
+
 // NewInternalServerError creates a 500 Internal Server Error HTTPError.
 //
 // Note:
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"github.com/deppfellow/go-boilerplate/internal/lib/backfill"
+	"github.com/deppfellow/go-boilerplate/internal/lib/job"
+	"github.com/deppfellow/go-boilerplate/internal/middleware"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/deppfellow/go-boilerplate/internal/validation"
+	"github.com/labstack/echo/v4"
+)
+
+// BackfillHandler exposes admin endpoints to run, monitor and verify data
+// backfills (see lib/backfill).
+type BackfillHandler struct {
+	Handler
+	runner *backfill.Runner
+}
+
+// NewBackfillHandler constructs a BackfillHandler.
+func NewBackfillHandler(s *server.Server) *BackfillHandler {
+	return &BackfillHandler{
+		Handler: NewHandler(s),
+		runner:  backfill.NewRunner(s.DB.Pool, s.Logger),
+	}
+}
+
+// BackfillRequest identifies a registered backfill by path parameter.
+type BackfillRequest struct {
+	Name string `param:"name" validate:"required"`
+}
+
+func (r *BackfillRequest) Validate() error {
+	if _, ok := backfill.Lookup(r.Name); !ok {
+		return validation.CustomValidationErrors{{Field: "name", Message: "is not a registered backfill"}}
+	}
+	return nil
+}
+
+// BackfillRunResponse acknowledges an enqueued backfill.
+type BackfillRunResponse struct {
+	Name   string `json:"name"`
+	TaskID string `json:"task_id"`
+}
+
+// Run enqueues the backfill on the low-priority job queue. A backfill that
+// is already queued or running is a 409.
+func (h *BackfillHandler) Run(c echo.Context, req *BackfillRequest) (BackfillRunResponse, error) {
+	task, err := job.NewBackfillTask(c.Request().Context(), req.Name)
+	if err != nil {
+		return BackfillRunResponse{}, err
+	}
+
+	info, err := enqueueUnique(c.Request().Context(), h.server.Job.Client, task, "backfill")
+	if err != nil {
+		return BackfillRunResponse{}, err
+	}
+
+	middleware.GetLogger(c).Info().
+		Str("backfill", req.Name).
+		Str("task_id", info.ID).
+		Msg("backfill enqueued")
+
+	return BackfillRunResponse{Name: req.Name, TaskID: info.ID}, nil
+}
+
+// Status returns the persisted progress of the backfill.
+func (h *BackfillHandler) Status(c echo.Context, req *BackfillRequest) (backfill.Progress, error) {
+	return h.runner.Progress(c.Request().Context(), req.Name)
+}
+
+// Verify compares the old and new columns for every row.
+func (h *BackfillHandler) Verify(c echo.Context, req *BackfillRequest) (backfill.VerifyResult, error) {
+	return h.runner.Verify(c.Request().Context(), req.Name)
+}
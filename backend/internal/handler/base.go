@@ -1,14 +1,17 @@
 package handler
 
 import (
+	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/lib/tracing"
 	"github.com/deppfellow/go-boilerplate/internal/middleware"
 	"github.com/deppfellow/go-boilerplate/internal/server"
 	"github.com/deppfellow/go-boilerplate/internal/validation"
 	"github.com/labstack/echo/v4"
-	"github.com/newrelic/go-agent/v3/integrations/nrpkgerrors"
-	"github.com/newrelic/go-agent/v3/newrelic"
 )
 
 // Handler is the base handler type that holds shared application dependencies.
@@ -55,9 +58,9 @@ type ResponseHandler interface {
 	// This helps distinguish handler types (json/no_content/file) in logs.
 	GetOperation() string
 
-	// AddAttributes attaches New Relic attributes based on response type and/or result.
+	// AddAttributes attaches span attributes based on response type and/or result.
 	// This allows customization beyond the generic tracing middleware.
-	AddAttributes(txn *newrelic.Transaction, result interface{})
+	AddAttributes(span tracing.Span, result interface{})
 }
 
 // JSONResponseHandler writes JSON responses with a given status code.
@@ -73,7 +76,7 @@ func (h JSONResponseHandler) GetOperation() string {
 	return "handler"
 }
 
-func (h JSONResponseHandler) AddAttributes(txn *newrelic.Transaction, result interface{}) {
+func (h JSONResponseHandler) AddAttributes(span tracing.Span, result interface{}) {
 	// http.status_code is already set by tracing middleware (EnhanceTracing).
 }
 
@@ -91,7 +94,7 @@ func (h NoContentResponseHandler) GetOperation() string {
 	return "handler_no_content"
 }
 
-func (h NoContentResponseHandler) AddAttributes(txn *newrelic.Transaction, result interface{}) {
+func (h NoContentResponseHandler) AddAttributes(span tracing.Span, result interface{}) {
 	// http.status_code is already set by tracing middleware
 }
 
@@ -120,13 +123,13 @@ func (h FileResponseHandler) GetOperation() string {
 	return "handler_file"
 }
 
-func (h FileResponseHandler) AddAttributes(txn *newrelic.Transaction, result interface{}) {
-	if txn != nil {
+func (h FileResponseHandler) AddAttributes(span tracing.Span, result interface{}) {
+	if span != nil {
 		// http.status_code is already set by tracing middleware (EnhanceTracing).
-		txn.AddAttribute("file.name", h.filename)
-		txn.AddAttribute("file.content_type", h.contentType)
+		span.SetAttribute("file.name", h.filename)
+		span.SetAttribute("file.content_type", h.contentType)
 		if data, ok := result.([]byte); ok {
-			txn.AddAttribute("file.size_bytes", len(data))
+			span.SetAttribute("file.size_bytes", len(data))
 		}
 	}
 }
@@ -138,8 +141,10 @@ func (h FileResponseHandler) AddAttributes(txn *newrelic.Transaction, result int
 //
 // - request binding + validation
 // - structured logging (with request context)
-// - New Relic tracing attributes and error reporting
+// - tracing attributes and error reporting (see lib/tracing)
 // - timing metrics (validation duration, handler duration, total duration)
+// - request body size limits (structured 413 before binding)
+// - per-route hooks (BeforeValidate / AfterValidate / BeforeRespond)
 // - response writing (json / no-content / file)
 //
 // Req must satisfy validation.Validatable (usually pointer-to-struct).
@@ -148,21 +153,25 @@ func handleRequest[Req validation.Validatable](
 	req Req,
 	handler func(c echo.Context, req Req) (interface{}, error),
 	responseHandler ResponseHandler,
+	opts handleOptions,
 ) error {
 	start := time.Now()
 	method := c.Request().Method
 	path := c.Path()
-	route := path
-
-	// New Relic transaction is set by the New Relic Echo middleware (nrecho).
-	txn := newrelic.FromContext(c.Request().Context())
-	if txn != nil {
-		// Attach handler name/route for easier filtering in New Relic.
-		txn.AddAttribute("handler.name", route)
+	route := middleware.RouteName(c)
+
+	// The request span is set by the tracing middleware (TraceRequests).
+	span := tracing.FromContext(c.Request().Context())
+	if span != nil {
+		// Attach handler name/route for easier filtering in the APM.
+		span.SetAttribute("handler.name", route)
+		if opts.operationID != "" {
+			span.SetAttribute("handler.operation_id", opts.operationID)
+		}
 
-		// http.method and http.route are typically already set by nrecho middleware.
+		// http.method and http.route are typically already set by the provider middleware.
 		// Allow response handlers to attach static attributes early (if any).
-		responseHandler.AddAttributes(txn, nil)
+		responseHandler.AddAttributes(span, nil)
 	}
 
 	// Get context-enhanced logger
@@ -175,6 +184,10 @@ func handleRequest[Req validation.Validatable](
 		Str("path", path).
 		Str("route", route)
 
+	if opts.operationID != "" {
+		loggerBuilder = loggerBuilder.Str("operation_id", opts.operationID)
+	}
+
 	// Add file-specific fields to logger if it's a file handler
 	//
 	// If response is a file download, include file metadata in logs.
@@ -191,16 +204,63 @@ func handleRequest[Req validation.Validatable](
 
 	logger.Info().Msg("handling request")
 
+	// ---------------- Conditional request phase ------------------------------
+	// For polling clients: answer 304 before doing any binding or handler work.
+	if opts.lastModified != nil && (method == http.MethodGet || method == http.MethodHead) {
+		notModified, err := checkNotModified(c, opts.lastModified)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to resolve last modified time")
+			return err
+		}
+
+		if notModified {
+			logger.Debug().
+				Dur("total_duration", time.Since(start)).
+				Msg("resource not modified")
+
+			if span != nil {
+				span.SetAttribute("conditional.status", "not_modified")
+			}
+
+			return c.NoContent(http.StatusNotModified)
+		}
+	}
+
+	// ---------------- Body limit phase ---------------------------------------
+	// Reject oversized payloads before binding so clients get a consistent
+	// 413 errs.HTTPError instead of Echo's default error shape.
+	if opts.bodyLimit > 0 {
+		if c.Request().ContentLength > opts.bodyLimit {
+			err := errs.NewPayloadTooLargeError(
+				fmt.Sprintf("Request body must not exceed %d bytes", opts.bodyLimit), true)
+
+			logger.Warn().
+				Int64("content_length", c.Request().ContentLength).
+				Int64("body_limit", opts.bodyLimit).
+				Msg("request body too large")
+
+			if span != nil {
+				span.SetAttribute("validation.status", "body_too_large")
+			}
+
+			return err
+		}
+
+		// Content-Length can be absent (chunked) or lie, so also cap the reader.
+		// Overflow surfaces as *http.MaxBytesError during binding.
+		c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, opts.bodyLimit)
+	}
+
 	// ---------------- Validation phase ---------------------------------------
 	// Validation with observability
 	validationStart := time.Now()
 
-	// BindAndValidate does:
-	// - c.Bind(payload) to populate req
-	// - payload.Validate() which uses validator tags or custom validations
+	// Binding and validation are split so BeforeValidate hooks run in between:
+	// - validation.Bind: path, query and body into req, then normalize tags
+	// - validation.ValidatePayload: payload.Validate() (validator tags or custom validations)
 	//
 	// IMPORTANT: req should be a pointer type so c.Bind can mutate it.
-	if err := validation.BindAndValidate(c, req); err != nil {
+	if err := bindAndValidate(c, req, opts); err != nil {
 		validationDuration := time.Since(validationStart)
 
 		logger.Error().
@@ -209,10 +269,10 @@ func handleRequest[Req validation.Validatable](
 			Msg("request validation failed")
 
 		// Report validation errors to New Relic as noticed errors.
-		if txn != nil {
-			txn.NoticeError(nrpkgerrors.Wrap(err))
-			txn.AddAttribute("validation.status", "failed")
-			txn.AddAttribute("validation.duration_ms", validationDuration.Milliseconds())
+		if span != nil {
+			span.RecordError(err)
+			span.SetAttribute("validation.status", "failed")
+			span.SetAttribute("validation.duration_ms", validationDuration.Milliseconds())
 		}
 
 		// Return error to let global error handler format the response.
@@ -220,15 +280,20 @@ func handleRequest[Req validation.Validatable](
 	}
 
 	validationDuration := time.Since(validationStart)
-	if txn != nil {
-		txn.AddAttribute("validation.status", "success")
-		txn.AddAttribute("validation.duration_ms", validationDuration.Milliseconds())
+	if span != nil {
+		span.SetAttribute("validation.status", "success")
+		span.SetAttribute("validation.duration_ms", validationDuration.Milliseconds())
 	}
 
 	logger.Debug().
 		Dur("validation_duration", validationDuration).
 		Msg("request validation successful")
 
+	if err := runRequestHooks(c, req, opts.afterValidate); err != nil {
+		logger.Error().Err(err).Msg("after validate hook failed")
+		return err
+	}
+
 	// ---------------- Handler execution phase --------------------------------
 	// Execute handler with observability
 	handlerStart := time.Now()
@@ -244,11 +309,11 @@ func handleRequest[Req validation.Validatable](
 			Dur("total_duration", totalDuration).
 			Msg("handler execution failed")
 
-		if txn != nil {
-			txn.NoticeError(nrpkgerrors.Wrap(err))
-			txn.AddAttribute("handler.status", "error")
-			txn.AddAttribute("handler.duration_ms", handlerDuration.Milliseconds())
-			txn.AddAttribute("total.duration_ms", totalDuration.Milliseconds())
+		if span != nil {
+			span.RecordError(err)
+			span.SetAttribute("handler.status", "error")
+			span.SetAttribute("handler.duration_ms", handlerDuration.Milliseconds())
+			span.SetAttribute("total.duration_ms", totalDuration.Milliseconds())
 		}
 		return err
 	}
@@ -256,13 +321,18 @@ func handleRequest[Req validation.Validatable](
 	totalDuration := time.Since(start)
 
 	// Record success attributes for tracing/metrics.
-	if txn != nil {
-		txn.AddAttribute("handler.status", "success")
-		txn.AddAttribute("handler.duration_ms", handlerDuration.Milliseconds())
-		txn.AddAttribute("total.duration_ms", totalDuration.Milliseconds())
+	if span != nil {
+		span.SetAttribute("handler.status", "success")
+		span.SetAttribute("handler.duration_ms", handlerDuration.Milliseconds())
+		span.SetAttribute("total.duration_ms", totalDuration.Milliseconds())
 
 		// Let response handler attach attributes that depend on the response payload.
-		responseHandler.AddAttributes(txn, result)
+		responseHandler.AddAttributes(span, result)
+	}
+
+	if result, err = runResponseHooks(c, result, opts.beforeRespond); err != nil {
+		logger.Error().Err(err).Msg("before respond hook failed")
+		return err
 	}
 
 	logger.Info().
@@ -271,10 +341,194 @@ func handleRequest[Req validation.Validatable](
 		Dur("total_duration", totalDuration).
 		Msg("request completed successfully")
 
+	// Development only: loudly report drift from the documented contract.
+	if jsonHandler, ok := responseHandler.(JSONResponseHandler); ok && opts.validateResponses {
+		if problems := checkResponseContract(c, jsonHandler.status, result); len(problems) > 0 {
+			logger.Error().
+				Strs("violations", problems).
+				Int("status", jsonHandler.status).
+				Msg("RESPONSE CONTRACT VIOLATION: response does not match declared schema")
+		}
+	}
+
 	// Write the response using the configured response handler.
 	return responseHandler.Handle(c, result)
 }
 
+// bindAndValidate binds req, runs BeforeValidate hooks, then validates.
+func bindAndValidate(c echo.Context, req validation.Validatable, opts handleOptions) error {
+	if err := validation.Bind(c, req); err != nil {
+		return err
+	}
+	if err := runRequestHooks(c, req, opts.beforeValidate); err != nil {
+		return err
+	}
+	return validation.ValidatePayload(req)
+}
+
+// checkNotModified sets the validators of the resource and reports whether
+// the request's conditional headers make a full response unnecessary.
+//
+// The ETag carries the exact modification time, so If-None-Match (which
+// takes precedence over If-Modified-Since, RFC 9110) tells writes within
+// the same second apart. HTTP dates only have one-second precision, so
+// Last-Modified is sent once that second is over: a client holding it has
+// seen every write of the second, and comparing at that precision is safe.
+func checkNotModified(c echo.Context, lastModifiedFn LastModifiedFunc) (bool, error) {
+	lastModified, err := lastModifiedFn(c)
+	if err != nil {
+		return false, err
+	}
+	if lastModified.IsZero() {
+		return false, nil
+	}
+
+	lastModified = lastModified.UTC()
+	etag := fmt.Sprintf(`W/"%x"`, lastModified.UnixMicro())
+	header := c.Response().Header()
+	header.Set("ETag", etag)
+
+	seconds := lastModified.Truncate(time.Second)
+	if seconds.Before(time.Now().Truncate(time.Second)) {
+		header.Set(echo.HeaderLastModified, seconds.Format(http.TimeFormat))
+	}
+
+	if ifNoneMatch := c.Request().Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return etagMatches(ifNoneMatch, etag), nil
+	}
+
+	ifModifiedSince := c.Request().Header.Get(echo.HeaderIfModifiedSince)
+	if ifModifiedSince == "" {
+		return false, nil
+	}
+
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		// Malformed header: ignore it and serve the full response (RFC 9110).
+		return false, nil
+	}
+
+	return !seconds.After(since), nil
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, with the
+// weak comparison RFC 9110 prescribes for it.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleWith wraps a typed JSON handler with validation, error handling,
+// logging, metrics, and tracing, configured entirely through options:
+//
+//	router.GET("/todos/:id", handler.HandleWith(h, h.GetTodo,
+//		handler.WithCache(30*time.Second),
+//		handler.WithOperationID("getTodo"),
+//	))
+//
+// The status defaults to 200 and the request prototype to a new Req; every
+// request binds into its own payload (see requestSource).
+func HandleWith[Req validation.Validatable, Res any](
+	h Handler,
+	handler HandlerFunc[Req, Res],
+	opts ...HandleOption,
+) echo.HandlerFunc {
+	options := newHandleOptions(h, opts)
+	requests := newRequestSource[Req](options)
+	status := statusOr(options, http.StatusOK)
+
+	return wrapRoute(h, options, func(c echo.Context) error {
+		req := requests.get()
+		defer requests.put(req)
+
+		// Adapt typed handler (Res) into the generic interface{} pipeline.
+		return handleRequest(c, req, func(c echo.Context, req Req) (interface{}, error) {
+			return handler(c, req)
+		}, JSONResponseHandler{status: status}, options)
+	})
+}
+
+// HandleFileWith wraps a handler returning file bytes ([]byte) into the
+// unified pipeline. The filename and content type come from WithFile.
+func HandleFileWith[Req validation.Validatable](
+	h Handler,
+	handler HandlerFunc[Req, []byte],
+	opts ...HandleOption,
+) echo.HandlerFunc {
+	options := newHandleOptions(h, opts)
+	requests := newRequestSource[Req](options)
+	responseHandler := FileResponseHandler{
+		status:      statusOr(options, http.StatusOK),
+		filename:    options.filename,
+		contentType: options.contentType,
+	}
+
+	return wrapRoute(h, options, func(c echo.Context) error {
+		req := requests.get()
+		defer requests.put(req)
+
+		return handleRequest(c, req, func(c echo.Context, req Req) (interface{}, error) {
+			return handler(c, req)
+		}, responseHandler, options)
+	})
+}
+
+// HandleNoContentWith wraps a handler for endpoints without a response body.
+// The status defaults to 204.
+func HandleNoContentWith[Req validation.Validatable](
+	h Handler,
+	handler HandlerFuncNoContent[Req],
+	opts ...HandleOption,
+) echo.HandlerFunc {
+	options := newHandleOptions(h, opts)
+	requests := newRequestSource[Req](options)
+	status := statusOr(options, http.StatusNoContent)
+
+	return wrapRoute(h, options, func(c echo.Context) error {
+		req := requests.get()
+		defer requests.put(req)
+
+		return handleRequest(c, req, func(c echo.Context, req Req) (interface{}, error) {
+			err := handler(c, req)
+			return nil, err
+		}, NoContentResponseHandler{status: status}, options)
+	})
+}
+
+// statusOr returns the WithStatus code or def.
+func statusOr(o handleOptions, def int) int {
+	if o.status != 0 {
+		return o.status
+	}
+	return def
+}
+
+// wrapRoute applies the route-level options that are middleware rather than
+// pipeline steps. Outermost first: auth, then timeout, then cache headers.
+func wrapRoute(h Handler, o handleOptions, next echo.HandlerFunc) echo.HandlerFunc {
+	if o.cacheTTL > 0 {
+		next = cacheControl(o.cacheTTL)(next)
+	}
+
+	if o.timeout > 0 {
+		next = middleware.NewTimeoutMiddleware(h.server).For(o.timeout)(next)
+	}
+
+	// RequireAuth skips requests an outer RequireAuth (e.g. the builder's
+	// Auth()) already authenticated, so the token is verified once.
+	if len(o.authScopes) > 0 {
+		auth := middleware.NewAuthMiddleware(h.server)
+		next = auth.RequireAuth(auth.RequirePermission(o.authScopes...)(next))
+	}
+
+	return next
+}
+
 // Handle wraps a handler with validation, error handling, logging, metrics, and tracing
 //
 // It returns an echo.HandlerFunc so it can be registered directly on routes.
@@ -282,23 +536,23 @@ func handleRequest[Req validation.Validatable](
 // Usage pattern (typical):
 //
 //	router.POST("/x", handler.Handle(h, myHandlerFn, http.StatusCreated, &MyReq{}))
+//
+// It is a thin wrapper around HandleWith, kept for its compact positional
+// form; further per-route behavior is passed via opts.
 func Handle[Req validation.Validatable, Res any](
 	h Handler,
 	handler HandlerFunc[Req, Res],
 	status int,
 	req Req,
+	opts ...HandleOption,
 ) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		// Adapt typed handler (Res) into the generic interface{} pipeline.
-		return handleRequest(c, req, func(c echo.Context, req Req) (interface{}, error) {
-			return handler(c, req)
-		}, JSONResponseHandler{status: status})
-	}
+	return HandleWith(h, handler, append([]HandleOption{WithStatus(status), WithRequest(req)}, opts...)...)
 }
 
 // HandleFile wraps a handler that returns file bytes ([]byte) into the unified pipeline.
 //
 // It sets response headers (Content-Disposition) and writes Blob response.
+// It is a thin wrapper around HandleFileWith.
 func HandleFile[Req validation.Validatable](
 	h Handler,
 	handler HandlerFunc[Req, []byte],
@@ -306,31 +560,23 @@ func HandleFile[Req validation.Validatable](
 	req Req,
 	filename string,
 	contentType string,
+	opts ...HandleOption,
 ) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		return handleRequest(c, req, func(c echo.Context, req Req) (interface{}, error) {
-			return handler(c, req)
-		}, FileResponseHandler{
-			status:      status,
-			filename:    filename,
-			contentType: contentType,
-		})
-	}
+	return HandleFileWith(h, handler, append([]HandleOption{
+		WithStatus(status), WithRequest(req), WithFile(filename, contentType),
+	}, opts...)...)
 }
 
 // HandleNoContent wraps a handler with validation, error handling, logging, metrics, and tracing for endpoints that don't return content
 //
 // Intended for endpoints that return no body (e.g., DELETE success with 204).
+// It is a thin wrapper around HandleNoContentWith.
 func HandleNoContent[Req validation.Validatable](
 	h Handler,
 	handler HandlerFuncNoContent[Req],
 	status int,
 	req Req,
+	opts ...HandleOption,
 ) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		return handleRequest(c, req, func(c echo.Context, req Req) (interface{}, error) {
-			err := handler(c, req)
-			return nil, err
-		}, NoContentResponseHandler{status: status})
-	}
+	return HandleNoContentWith(h, handler, append([]HandleOption{WithStatus(status), WithRequest(req)}, opts...)...)
 }
@@ -0,0 +1,194 @@
+package handler_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/handler"
+	"github.com/deppfellow/go-boilerplate/internal/handlertest"
+	"github.com/deppfellow/go-boilerplate/internal/validation"
+	"github.com/labstack/echo/v4"
+)
+
+type createTodoRequest struct {
+	ID    string `param:"id"`
+	Title string `json:"title" validate:"required,max=20"`
+	Owner string `json:"owner"`
+}
+
+func (r *createTodoRequest) Validate() error { return validation.NewValidator().Struct(r) }
+
+type todoResponse struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Owner string `json:"owner"`
+}
+
+func createTodo(_ echo.Context, req *createTodoRequest) (todoResponse, error) {
+	return todoResponse{ID: req.ID, Title: req.Title, Owner: req.Owner}, nil
+}
+
+func TestHandleWritesTheHandlerResult(t *testing.T) {
+	h := handlertest.New(t)
+	h.Echo.POST("/todos/:id", handler.Handle(h.Handler(), createTodo, http.StatusCreated, &createTodoRequest{}))
+
+	res, rec := handlertest.DoJSON[todoResponse](h, http.MethodPost, "/todos/42", map[string]any{"title": "milk"})
+	h.RequireStatus(rec, http.StatusCreated)
+	if res.ID != "42" || res.Title != "milk" {
+		t.Fatalf("got %+v, want id 42 and title milk", res)
+	}
+}
+
+func TestHandleRejectsBindAndValidationErrors(t *testing.T) {
+	h := handlertest.New(t)
+	h.Echo.POST("/todos/:id", handler.Handle(h.Handler(), createTodo, http.StatusCreated, &createTodoRequest{}))
+
+	tests := []struct {
+		name  string
+		body  any
+		field string
+	}{
+		{"wrong type", []byte(`{"title": 42}`), "title"},
+		{"malformed JSON", []byte(`{"title": "x",}`), "body"},
+		{"truncated JSON", []byte(`{"title": "x"`), "body"},
+		{"missing required field", map[string]any{"owner": "ann"}, "title"},
+		{"too long", map[string]any{"title": strings.Repeat("x", 21)}, "title"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, rec := handlertest.DoJSON[errs.HTTPError](h, http.MethodPost, "/todos/1", tt.body)
+			h.RequireStatus(rec, http.StatusBadRequest)
+			if len(res.Errors) == 0 || res.Errors[0].Field != tt.field {
+				t.Fatalf("got errors %+v, want one on %q", res.Errors, tt.field)
+			}
+		})
+	}
+}
+
+func TestHandleBodyLimit(t *testing.T) {
+	h := handlertest.New(t)
+	h.Echo.POST("/todos/:id", handler.Handle(h.Handler(), createTodo, http.StatusCreated, &createTodoRequest{},
+		handler.WithBodyLimit(32)))
+
+	body := map[string]any{"title": "x", "owner": strings.Repeat("a", 64)}
+
+	res, rec := handlertest.DoJSON[errs.HTTPError](h, http.MethodPost, "/todos/1", body)
+	h.RequireStatus(rec, http.StatusRequestEntityTooLarge)
+	if res.Code != "PAYLOAD_TOO_LARGE" {
+		t.Fatalf("got code %q, want PAYLOAD_TOO_LARGE", res.Code)
+	}
+
+	// Without Content-Length the limit is enforced while binding.
+	req := strings.NewReader(`{"title": "x", "owner": "` + strings.Repeat("a", 64) + `"}`)
+	rec = h.Do(http.MethodPost, "/todos/1", struct{ *strings.Reader }{req},
+		echo.HeaderContentType, echo.MIMEApplicationJSON)
+	h.RequireStatus(rec, http.StatusRequestEntityTooLarge)
+}
+
+func TestHandleHooks(t *testing.T) {
+	var order []string
+	h := handlertest.New(t)
+	h.Echo.POST("/todos/:id", handler.Handle(h.Handler(), createTodo, http.StatusCreated, &createTodoRequest{},
+		handler.WithBeforeValidate(handler.TypedRequestHook(func(_ echo.Context, req *createTodoRequest) error {
+			order = append(order, "before_validate")
+			req.Owner = "from-auth"
+			return nil
+		})),
+		handler.WithAfterValidate(func(echo.Context, any) error {
+			order = append(order, "after_validate")
+			return nil
+		}),
+		handler.WithBeforeRespond(handler.TypedResponseHook(func(_ echo.Context, res todoResponse) (todoResponse, error) {
+			order = append(order, "before_respond")
+			res.Title = strings.ToUpper(res.Title)
+			return res, nil
+		})),
+	))
+
+	res, rec := handlertest.DoJSON[todoResponse](h, http.MethodPost, "/todos/1", map[string]any{"title": "milk", "owner": "mallory"})
+	h.RequireStatus(rec, http.StatusCreated)
+	if res.Owner != "from-auth" || res.Title != "MILK" {
+		t.Fatalf("got %+v, want the owner set by the hook and the title upper-cased", res)
+	}
+	if got := strings.Join(order, ","); got != "before_validate,after_validate,before_respond" {
+		t.Fatalf("hooks ran as %s", got)
+	}
+}
+
+func TestHandleHookErrorAbortsTheRequest(t *testing.T) {
+	called := false
+	h := handlertest.New(t)
+	h.Echo.POST("/todos/:id", handler.Handle(h.Handler(),
+		func(c echo.Context, req *createTodoRequest) (todoResponse, error) {
+			called = true
+			return createTodo(c, req)
+		},
+		http.StatusCreated, &createTodoRequest{},
+		handler.WithAfterValidate(func(echo.Context, any) error {
+			return errs.NewForbiddenError("Not your todo", true)
+		}),
+	))
+
+	rec := h.Do(http.MethodPost, "/todos/1", map[string]any{"title": "milk"})
+	h.RequireStatus(rec, http.StatusForbidden)
+	if called {
+		t.Fatal("the handler ran after its hook failed")
+	}
+}
+
+type listTodosRequest struct{}
+
+func (*listTodosRequest) Validate() error { return nil }
+
+func TestHandleNotModified(t *testing.T) {
+	changed := time.Now().Add(-time.Hour).Truncate(time.Second)
+	calls := 0
+
+	h := handlertest.New(t)
+	h.Echo.GET("/todos", handler.Handle(h.Handler(),
+		func(echo.Context, *listTodosRequest) ([]todoResponse, error) {
+			calls++
+			return []todoResponse{}, nil
+		},
+		http.StatusOK, &listTodosRequest{},
+		handler.WithLastModified(func(echo.Context) (time.Time, error) { return changed, nil }),
+	))
+
+	rec := h.Do(http.MethodGet, "/todos", nil)
+	h.RequireStatus(rec, http.StatusOK)
+	etag := rec.Header().Get("ETag")
+	lastModified := rec.Header().Get(echo.HeaderLastModified)
+	if etag == "" || lastModified == "" {
+		t.Fatalf("missing validators: ETag %q, Last-Modified %q", etag, lastModified)
+	}
+
+	h.RequireStatus(h.Do(http.MethodGet, "/todos", nil, "If-None-Match", etag), http.StatusNotModified)
+	h.RequireStatus(h.Do(http.MethodGet, "/todos", nil, echo.HeaderIfModifiedSince, lastModified), http.StatusNotModified)
+	if calls != 1 {
+		t.Fatalf("handler ran %d times, want only for the unconditional request", calls)
+	}
+
+	// A change since the client's copy is served in full.
+	changed = changed.Add(time.Minute)
+	h.RequireStatus(h.Do(http.MethodGet, "/todos", nil, "If-None-Match", etag), http.StatusOK)
+}
+
+func TestHandleErrorFromTheHandler(t *testing.T) {
+	h := handlertest.New(t)
+	h.Echo.GET("/todos", handler.Handle(h.Handler(),
+		func(echo.Context, *listTodosRequest) ([]todoResponse, error) {
+			return nil, errors.New("connection reset")
+		},
+		http.StatusOK, &listTodosRequest{},
+	))
+
+	res, rec := handlertest.DoJSON[errs.HTTPError](h, http.MethodGet, "/todos", nil)
+	h.RequireStatus(rec, http.StatusInternalServerError)
+	if strings.Contains(res.Message, "connection reset") {
+		t.Fatalf("internal error leaked to the client: %q", res.Message)
+	}
+}
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/lib/tracing"
+	"github.com/deppfellow/go-boilerplate/internal/middleware"
+	"github.com/deppfellow/go-boilerplate/internal/sqlerr"
+	"github.com/deppfellow/go-boilerplate/internal/validation"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// DefaultBulkMaxItems caps how many items a single bulk request may carry.
+	DefaultBulkMaxItems = 100
+
+	// DefaultBulkConcurrency is how many items are processed in parallel.
+	// Keep it well below the DB pool size so one bulk call can't starve others.
+	DefaultBulkConcurrency = 4
+)
+
+// BulkRequest is the standard bulk payload: {"items": [...]}.
+//
+// Only the envelope is validated up front (item count); each item is
+// validated individually while processing so one bad item doesn't fail
+// the whole batch.
+type BulkRequest[Item validation.Validatable] struct {
+	Items []Item `json:"items"`
+}
+
+func (r *BulkRequest[Item]) Validate() error {
+	switch {
+	case len(r.Items) == 0:
+		return validation.CustomValidationErrors{{Field: "items", Message: "must contain at least 1 item"}}
+	case len(r.Items) > DefaultBulkMaxItems:
+		return validation.CustomValidationErrors{{
+			Field:   "items",
+			Message: fmt.Sprintf("must not exceed %d items", DefaultBulkMaxItems),
+		}}
+	}
+	return nil
+}
+
+// BulkItemResult is the outcome of a single item, reported at the item's
+// position in the request.
+type BulkItemResult[Res any] struct {
+	Index  int             `json:"index"`
+	Status int             `json:"status"`
+	Data   *Res            `json:"data,omitempty"`
+	Error  *errs.HTTPError `json:"error,omitempty"`
+}
+
+// BulkResponse is the standard bulk response envelope.
+type BulkResponse[Res any] struct {
+	Results   []BulkItemResult[Res] `json:"results"`
+	Succeeded int                   `json:"succeeded"`
+	Failed    int                   `json:"failed"`
+}
+
+// BulkItemFunc processes one (already validated) item.
+//
+// It receives the request's context.Context rather than echo.Context because
+// items run on separate goroutines and echo.Context is not safe for
+// concurrent use.
+type BulkItemFunc[Item validation.Validatable, Res any] func(ctx context.Context, item Item) (Res, error)
+
+// RunBulk validates and processes items with at most concurrency workers.
+//
+// Per-item errors are mapped the same way the global error handler maps
+// request errors (errs.HTTPError as-is, database errors via sqlerr), and a
+// panicking item becomes a 500 result instead of crashing the process.
+func RunBulk[Item validation.Validatable, Res any](
+	ctx context.Context,
+	items []Item,
+	concurrency int,
+	successStatus int,
+	fn BulkItemFunc[Item, Res],
+) BulkResponse[Res] {
+	if concurrency <= 0 {
+		concurrency = DefaultBulkConcurrency
+	}
+
+	results := make([]BulkItemResult[Res], len(items))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, item := range items {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, item Item) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			results[i] = runBulkItem(ctx, i, item, successStatus, fn)
+		}(i, item)
+	}
+	wg.Wait()
+
+	response := BulkResponse[Res]{Results: results}
+	for _, r := range results {
+		if r.Error != nil {
+			response.Failed++
+		} else {
+			response.Succeeded++
+		}
+	}
+	return response
+}
+
+func runBulkItem[Item validation.Validatable, Res any](
+	ctx context.Context,
+	index int,
+	item Item,
+	successStatus int,
+	fn BulkItemFunc[Item, Res],
+) (result BulkItemResult[Res]) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = bulkFailure[Res](index, fmt.Errorf("panic: %v", r))
+		}
+	}()
+
+	if err := validation.ValidatePayload(item); err != nil {
+		return bulkFailure[Res](index, err)
+	}
+
+	res, err := fn(ctx, item)
+	if err != nil {
+		return bulkFailure[Res](index, err)
+	}
+
+	return BulkItemResult[Res]{Index: index, Status: successStatus, Data: &res}
+}
+
+// bulkFailure maps err into an item result.
+func bulkFailure[Res any](index int, err error) BulkItemResult[Res] {
+	var httpErr *errs.HTTPError
+	if !errors.As(sqlerr.HandleError(err), &httpErr) {
+		httpErr = errs.NewInternalServerError()
+	}
+
+	return BulkItemResult[Res]{Index: index, Status: httpErr.Status, Error: httpErr}
+}
+
+// BulkResponseHandler writes a BulkResponse: successStatus when every item
+// succeeded, 207 Multi-Status otherwise (the per-item statuses tell the rest).
+type BulkResponseHandler[Res any] struct {
+	status int
+}
+
+func (h BulkResponseHandler[Res]) Handle(c echo.Context, result interface{}) error {
+	status := h.status
+	if result.(BulkResponse[Res]).Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	return c.JSON(status, result)
+}
+
+func (h BulkResponseHandler[Res]) GetOperation() string {
+	return "handler_bulk"
+}
+
+func (h BulkResponseHandler[Res]) AddAttributes(span tracing.Span, result interface{}) {
+	if span == nil {
+		return
+	}
+	if response, ok := result.(BulkResponse[Res]); ok {
+		span.SetAttribute("bulk.succeeded", response.Succeeded)
+		span.SetAttribute("bulk.failed", response.Failed)
+	}
+}
+
+// HandleBulk wraps a per-item function into a bulk endpoint using the
+// standard envelope:
+//
+//	r.POST("/todos/bulk", handler.HandleBulk(h, h.CreateTodo, http.StatusCreated, handler.DefaultBulkConcurrency))
+//
+// where CreateTodo has the signature func(ctx, *CreateTodoRequest) (*Todo, error).
+func HandleBulk[Item validation.Validatable, Res any](
+	h Handler,
+	fn BulkItemFunc[Item, Res],
+	successStatus int,
+	concurrency int,
+	opts ...HandleOption,
+) echo.HandlerFunc {
+	options := newHandleOptions(h, opts)
+	return wrapRoute(h, options, func(c echo.Context) error {
+		// A fresh envelope per request: the pipeline binds into it.
+		req := &BulkRequest[Item]{}
+
+		return handleRequest(c, req, func(c echo.Context, req *BulkRequest[Item]) (interface{}, error) {
+			response := RunBulk(c.Request().Context(), req.Items, concurrency, successStatus, fn)
+
+			middleware.GetLogger(c).Info().
+				Int("bulk_items", len(req.Items)).
+				Int("bulk_succeeded", response.Succeeded).
+				Int("bulk_failed", response.Failed).
+				Msg("bulk request processed")
+
+			return response, nil
+		}, BulkResponseHandler[Res]{status: successStatus}, options)
+	})
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+// ConfigHandler shows the configuration the instance is running with.
+type ConfigHandler struct {
+	Handler
+}
+
+// NewConfigHandler constructs a ConfigHandler.
+func NewConfigHandler(s *server.Server) *ConfigHandler {
+	return &ConfigHandler{Handler: NewHandler(s)}
+}
+
+// GetConfigRequest has no input.
+type GetConfigRequest struct{}
+
+func (r *GetConfigRequest) Validate() error { return nil }
+
+// ConfigResponse is the effective configuration, secrets masked.
+type ConfigResponse struct {
+	Service string         `json:"service"`
+	Config  map[string]any `json:"config"`
+}
+
+// Show returns the effective configuration of this instance, including
+// settings hot-reloaded since startup (see server.CurrentConfig). It is the
+// HTTP counterpart of `<binary> config print`.
+func (h *ConfigHandler) Show(c echo.Context, _ *GetConfigRequest) (ConfigResponse, error) {
+	cfg := h.server.CurrentConfig()
+	return ConfigResponse{
+		Service: cfg.Primary.Service,
+		Config:  config.Redacted(cfg),
+	}, nil
+}
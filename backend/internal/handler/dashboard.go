@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/deppfellow/go-boilerplate/static"
+	"github.com/labstack/echo/v4"
+)
+
+// dashboardTemplate is the embedded admin UI page.
+const dashboardTemplate = "admin.html"
+
+// DashboardHandler serves the embedded admin dashboard: job queues, dead
+// letters, scheduled tasks, recent job errors and health history.
+//
+// The page itself is a static shell with no data. Everything it shows is
+// fetched from the admin endpoints (/api/v1/admin/...) with the admin's
+// bearer token, so the same admin auth as the API protects the data.
+type DashboardHandler struct {
+	Handler
+}
+
+// NewDashboardHandler constructs a DashboardHandler.
+func NewDashboardHandler(s *server.Server) *DashboardHandler {
+	return &DashboardHandler{Handler: NewHandler(s)}
+}
+
+// Serve writes the dashboard page.
+func (h *DashboardHandler) Serve(c echo.Context) error {
+	page, err := fs.ReadFile(static.FS, dashboardTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to read admin dashboard: %w", err)
+	}
+
+	// Its CSP is set by the Secure middleware (see middleware.AdminDashboardPath).
+	c.Response().Header().Set("Cache-Control", "no-cache")
+
+	return c.HTML(http.StatusOK, string(page))
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/lib/embedding"
+	"github.com/deppfellow/go-boilerplate/internal/lib/job"
+	"github.com/deppfellow/go-boilerplate/internal/middleware"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/deppfellow/go-boilerplate/internal/validation"
+	"github.com/labstack/echo/v4"
+)
+
+func init() {
+	errs.RegisterCode(errs.CatalogEntry{
+		Code:        "EMBEDDINGS_DISABLED",
+		Status:      http.StatusNotFound,
+		Description: "Vector embeddings are not enabled on this deployment (embedding.enabled).",
+	})
+}
+
+// EmbeddingHandler exposes admin endpoints over vector embeddings
+// (see lib/embedding).
+type EmbeddingHandler struct {
+	Handler
+}
+
+// NewEmbeddingHandler constructs an EmbeddingHandler.
+func NewEmbeddingHandler(s *server.Server) *EmbeddingHandler {
+	return &EmbeddingHandler{Handler: NewHandler(s)}
+}
+
+// EmbeddingSourceRequest identifies a registered embedding source by path parameter.
+type EmbeddingSourceRequest struct {
+	Source string `param:"source" validate:"required"`
+}
+
+func (r *EmbeddingSourceRequest) Validate() error {
+	if _, ok := embedding.Lookup(r.Source); !ok {
+		return validation.CustomValidationErrors{{Field: "source", Message: "is not a registered embedding source"}}
+	}
+	return nil
+}
+
+// ReembedResponse acknowledges an enqueued re-embed.
+type ReembedResponse struct {
+	Source string `json:"source"`
+	TaskID string `json:"task_id"`
+}
+
+// Reembed recomputes every embedding of the source, one batch per job,
+// e.g. after changing embedding.model. Follow it in the job dashboard.
+func (h *EmbeddingHandler) Reembed(c echo.Context, req *EmbeddingSourceRequest) (ReembedResponse, error) {
+	if h.server.EmbeddingRunner == nil {
+		code := "EMBEDDINGS_DISABLED"
+		return ReembedResponse{}, errs.NewNotFoundError("Embeddings are not enabled", false, &code)
+	}
+
+	task, err := job.NewReembedTask(c.Request().Context(), req.Source, "")
+	if err != nil {
+		return ReembedResponse{}, err
+	}
+
+	info, err := enqueueUnique(c.Request().Context(), h.server.Job.Client, task, "re-embed of this source")
+	if err != nil {
+		return ReembedResponse{}, err
+	}
+
+	middleware.GetLogger(c).Info().
+		Str("source", req.Source).
+		Str("task_id", info.ID).
+		Msg("re-embed enqueued")
+
+	return ReembedResponse{Source: req.Source, TaskID: info.ID}, nil
+}
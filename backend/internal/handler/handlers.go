@@ -22,6 +22,18 @@ import (
 type Handlers struct {
 	Health  *HealthHandler  // Health serves service health endpoints (liveness/readiness).
 	OpenAPI *OpenAPIHandler // OpenAPI serves API documentation (OpenAPI spec / swagger endpoints).
+	Metrics *MetricsHandler // Metrics serves Prometheus metrics.
+
+	Maintenance *MaintenanceHandler // Maintenance toggles maintenance mode (admin only).
+	Config      *ConfigHandler      // Config shows the effective configuration (admin only).
+	Backfill    *BackfillHandler    // Backfill runs/verifies schema backfills (admin only).
+	Jobs        *JobsHandler        // Jobs inspects background job queues (admin only).
+	Search      *SearchHandler      // Search rebuilds search indexes (admin only).
+	Embedding   *EmbeddingHandler   // Embedding recomputes vector embeddings (admin only).
+	SoftDelete  *SoftDeleteHandler  // SoftDelete purges expired soft-deleted rows (admin only).
+	Dashboard   *DashboardHandler   // Dashboard serves the embedded admin UI.
+
+	Usage *UsageHandler // Usage reports the caller's remaining quota.
 }
 
 // NewHandlers constructs the handler container.
@@ -36,5 +48,17 @@ func NewHandlers(s *server.Server, services *service.Services) *Handlers {
 	return &Handlers{
 		Health:  NewHealthHandler(s),
 		OpenAPI: NewOpenAPIHandler(s),
+		Metrics: NewMetricsHandler(s),
+
+		Maintenance: NewMaintenanceHandler(s),
+		Config:      NewConfigHandler(s),
+		Backfill:    NewBackfillHandler(s),
+		Jobs:        NewJobsHandler(s),
+		Search:      NewSearchHandler(s),
+		Embedding:   NewEmbeddingHandler(s),
+		SoftDelete:  NewSoftDeleteHandler(s),
+		Dashboard:   NewDashboardHandler(s),
+
+		Usage: NewUsageHandler(s),
 	}
 }
@@ -12,8 +12,11 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/deppfellow/go-boilerplate/internal/buildinfo"
+	"github.com/deppfellow/go-boilerplate/internal/database"
 	"github.com/deppfellow/go-boilerplate/internal/middleware"
 	"github.com/deppfellow/go-boilerplate/internal/server"
 	"github.com/labstack/echo/v4"
@@ -35,13 +38,16 @@ func NewHealthHandler(s *server.Server) *HealthHandler {
 // CheckHealth returns system health status and dependency checks.
 //
 // Response includes:
-// - overall status (healthy/unhealthy)
+// - overall status (healthy/degraded/unhealthy)
 // - timestamp (UTC)
+// - service name and binary (api, worker, admin; omitted for the single binary)
 // - environment (from config)
-// - checks map (database, redis)
+// - build (version, commit, build time; see buildinfo)
+// - checks map (database, redis, tracked dependencies; database/redis include pool stats)
+// - database thresholds crossed (latency, pool saturation, replica lag), as "problems"
 //
 // It returns:
-// - 200 OK if all checks pass
+// - 200 OK if all checks pass, or only degrade the service (status "degraded")
 // - 503 Service Unavailable if any check fails
 func (h *HealthHandler) CheckHealth(c echo.Context) error {
 	start := time.Now()
@@ -55,9 +61,14 @@ func (h *HealthHandler) CheckHealth(c echo.Context) error {
 	response := map[string]interface{}{
 		"status":      "healthy",
 		"timestamp":   time.Now().UTC(),
+		"service":     h.server.Config.Primary.ServiceName,
 		"environment": h.server.Config.Primary.Env,
+		"build":       buildinfo.Get(),
 		"checks":      make(map[string]interface{}),
 	}
+	if component := h.server.Config.Primary.Service; component != "" {
+		response["component"] = component
+	}
 
 	checks := response["checks"].(map[string]interface{})
 	isHealthy := true
@@ -66,21 +77,35 @@ func (h *HealthHandler) CheckHealth(c echo.Context) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	dbStart := time.Now()
+	// Deep check: a probe query through the pool, graded against the
+	// latency/saturation/lag thresholds of database.health (see
+	// database.CheckHealth). Degraded keeps the endpoint at 200.
+	dbReport := h.server.DB.CheckHealth(ctx, h.server.Config.Database)
 
-	// pgxpool provides Ping to verify connectivity.
-	if err := h.server.DB.Pool.Ping(ctx); err != nil {
-		checks["database"] = map[string]interface{}{
-			"status":        "unhealthy",
-			"response_time": time.Since(dbStart).String(),
-			"error":         err.Error(),
-		}
+	dbCheck := map[string]interface{}{
+		"status":           dbReport.Status,
+		"response_time":    dbReport.ResponseTime,
+		"pool_utilization": dbReport.PoolUtilization,
+	}
+	if len(dbReport.Replicas) > 0 {
+		dbCheck["replicas"] = dbReport.Replicas
+	}
+	if len(dbReport.Problems) > 0 {
+		dbCheck["problems"] = dbReport.Problems
+	}
+	if dbReport.Error != "" {
+		dbCheck["error"] = dbReport.Error
+	}
+	checks["database"] = dbCheck
 
+	switch dbReport.Status {
+	case database.HealthStatusUnhealthy:
 		isHealthy = false
 
 		logger.Error().
-			Err(err).
-			Dur("response_time", time.Since(dbStart)).
+			Str("error", dbReport.Error).
+			Strs("problems", dbReport.Problems).
+			Dur("response_time", dbReport.Latency).
 			Msg("database health check failed")
 
 		// Record a New Relic custom event if enabled.
@@ -91,24 +116,31 @@ func (h *HealthHandler) CheckHealth(c echo.Context) error {
 					"check_type":       "database",
 					"operation":        "health_check",
 					"error_type":       "database_unhealthy",
-					"response_time_ms": time.Since(dbStart).Milliseconds(),
-					"error_message":    err.Error(),
+					"response_time_ms": dbReport.Latency.Milliseconds(),
+					"error_message":    strings.Join(append(dbReport.Problems, dbReport.Error), "; "),
 				},
 			)
 		}
-	} else {
-		checks["database"] = map[string]interface{}{
-			"status":        "healthy",
-			"response_time": time.Since(dbStart).String(),
-		}
+	case database.HealthStatusDegraded:
+		response["status"] = "degraded"
 
+		logger.Warn().
+			Strs("problems", dbReport.Problems).
+			Dur("response_time", dbReport.Latency).
+			Msg("database health check degraded")
+	default:
 		logger.Info().
-			Dur("response_time", time.Since(dbStart)).
+			Dur("response_time", dbReport.Latency).
 			Msg("database health check passed")
 	}
 
 	// Note: DB connection metrics/traces are automatically captured by New Relic nrpgx5 integration.
 
+	// Pool usage next to each connectivity check: a reachable database with
+	// every connection acquired is an outage about to happen.
+	pools := h.server.PoolStats()
+	checks["database"].(map[string]interface{})["pool"] = pools.Database
+
 	// ---------------- Redis connectivity check -------------------------------
 	// Tutor mentions checking "Redis connectivity" as part of health status.
 	if h.server.Redis != nil {
@@ -122,6 +154,7 @@ func (h *HealthHandler) CheckHealth(c echo.Context) error {
 				"status":        "unhealthy",
 				"response_time": time.Since(redisStart).String(),
 				"error":         err.Error(),
+				"pool":          pools.Redis,
 			}
 
 			// NOTE: In your current code, you do NOT set isHealthy=false here.
@@ -150,6 +183,7 @@ func (h *HealthHandler) CheckHealth(c echo.Context) error {
 			checks["redis"] = map[string]interface{}{
 				"status":        "healthy",
 				"response_time": time.Since(redisStart).String(),
+				"pool":          pools.Redis,
 			}
 
 			logger.Info().
@@ -158,6 +192,26 @@ func (h *HealthHandler) CheckHealth(c echo.Context) error {
 		}
 	}
 
+	// ---------------- Tracked dependencies -----------------------------------
+	// Background-probed and passively reported states (server.Dependencies).
+	// A required dependency being down is an outage; an optional one only
+	// degrades the service, which still answers 200.
+	if h.server.Dependencies != nil {
+		statuses := h.server.Dependencies.Statuses()
+		checks["dependencies"] = statuses
+
+		for _, status := range statuses {
+			if status.Healthy {
+				continue
+			}
+			if status.Required {
+				isHealthy = false
+			} else {
+				response["status"] = "degraded"
+			}
+		}
+	}
+
 	// ---------------- Overall status + response ------------------------------
 	if !isHealthy {
 		response["status"] = "unhealthy"
@@ -206,3 +260,11 @@ func (h *HealthHandler) CheckHealth(c echo.Context) error {
 
 	return nil
 }
+
+// Version returns the build information of the running binary (see
+// buildinfo), so operators can tell what is deployed without reading logs.
+//
+// Unlike /status it checks nothing and never fails.
+func (h *HealthHandler) Version(c echo.Context) error {
+	return c.JSON(http.StatusOK, buildinfo.Get())
+}
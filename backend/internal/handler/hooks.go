@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequestHook runs against the bound request payload (a pointer, e.g.
+// *CreateTodoRequest). Returning an error aborts the pipeline; the error is
+// rendered by the global error handler like any handler error.
+type RequestHook func(c echo.Context, req any) error
+
+// ResponseHook runs after the handler succeeded and before the response is
+// written. It may return a replacement result (post-processing) or an error.
+type ResponseHook func(c echo.Context, result any) (any, error)
+
+// Hook points in handleRequest, in execution order:
+//
+//	bind -> BeforeValidate -> validate -> AfterValidate -> handler -> BeforeRespond -> write
+//
+// Hooks let a team extend the pipeline per route (enrich the request from
+// auth context, prime a cache, reshape the response...) without forking
+// handleRequest:
+//
+//	handler.Handle(h, h.CreateTodo, http.StatusCreated, &CreateTodoRequest{},
+//		handler.WithBeforeValidate(handler.TypedRequestHook(func(c echo.Context, req *CreateTodoRequest) error {
+//			req.OwnerID = middleware.GetUserID(c)
+//			return nil
+//		})),
+//	)
+//
+// Hooks of the same kind run in registration order.
+
+// WithBeforeValidate registers hooks that run after binding, before
+// Validate(): the place to fill fields the client must not control.
+func WithBeforeValidate(hooks ...RequestHook) HandleOption {
+	return func(o *handleOptions) {
+		o.beforeValidate = append(o.beforeValidate, hooks...)
+	}
+}
+
+// WithAfterValidate registers hooks that run on the validated payload,
+// before the handler (e.g. authorization checks that need request fields).
+func WithAfterValidate(hooks ...RequestHook) HandleOption {
+	return func(o *handleOptions) {
+		o.afterValidate = append(o.afterValidate, hooks...)
+	}
+}
+
+// WithBeforeRespond registers hooks that run on the handler result before
+// it is written.
+func WithBeforeRespond(hooks ...ResponseHook) HandleOption {
+	return func(o *handleOptions) {
+		o.beforeRespond = append(o.beforeRespond, hooks...)
+	}
+}
+
+// TypedRequestHook adapts a hook written against the concrete request type.
+//
+// A route registered with a different request type fails loudly at request
+// time instead of silently skipping the hook.
+func TypedRequestHook[Req any](fn func(c echo.Context, req Req) error) RequestHook {
+	return func(c echo.Context, req any) error {
+		typed, ok := req.(Req)
+		if !ok {
+			return fmt.Errorf("request hook expects %T, got %T", *new(Req), req)
+		}
+		return fn(c, typed)
+	}
+}
+
+// TypedResponseHook adapts a response hook written against the concrete result type.
+func TypedResponseHook[Res any](fn func(c echo.Context, result Res) (Res, error)) ResponseHook {
+	return func(c echo.Context, result any) (any, error) {
+		typed, ok := result.(Res)
+		if !ok {
+			return nil, fmt.Errorf("response hook expects %T, got %T", *new(Res), result)
+		}
+		return fn(c, typed)
+	}
+}
+
+// runRequestHooks runs hooks in order, stopping at the first error.
+func runRequestHooks(c echo.Context, req any, hooks []RequestHook) error {
+	for _, hook := range hooks {
+		if err := hook(c, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runResponseHooks threads result through hooks in order.
+func runResponseHooks(c echo.Context, result any, hooks []ResponseHook) (any, error) {
+	for _, hook := range hooks {
+		var err error
+		if result, err = hook(c, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/deppfellow/go-boilerplate/internal/validation"
+	"github.com/hibiken/asynq"
+	"github.com/labstack/echo/v4"
+)
+
+func init() {
+	errs.RegisterCode(errs.CatalogEntry{
+		Code:        "QUEUE_NOT_FOUND",
+		Status:      http.StatusNotFound,
+		Description: "The job queue does not exist (queues appear once a task has been enqueued on them).",
+	})
+}
+
+// JobsHandler exposes read-only admin endpoints over the background job
+// queues (asynq), used by the admin dashboard.
+type JobsHandler struct {
+	Handler
+}
+
+// NewJobsHandler constructs a JobsHandler.
+func NewJobsHandler(s *server.Server) *JobsHandler {
+	return &JobsHandler{Handler: NewHandler(s)}
+}
+
+// Task states that can be listed (dead letters are "archived" in asynq).
+const (
+	TaskStatePending   = "pending"
+	TaskStateActive    = "active"
+	TaskStateScheduled = "scheduled"
+	TaskStateRetry     = "retry"
+	TaskStateArchived  = "archived"
+)
+
+// ListQueuesRequest has no parameters.
+type ListQueuesRequest struct{}
+
+func (r *ListQueuesRequest) Validate() error { return nil }
+
+// QueueStats is a snapshot of one queue.
+type QueueStats struct {
+	Queue     string `json:"queue"`
+	Paused    bool   `json:"paused"`
+	Size      int    `json:"size"`
+	Pending   int    `json:"pending"`
+	Active    int    `json:"active"`
+	Scheduled int    `json:"scheduled"`
+	Retry     int    `json:"retry"`
+	Archived  int    `json:"archived"`
+	Completed int    `json:"completed"`
+
+	// Processed and Failed count today's tasks (UTC); the totals are all-time.
+	Processed      int `json:"processed"`
+	Failed         int `json:"failed"`
+	ProcessedTotal int `json:"processed_total"`
+	FailedTotal    int `json:"failed_total"`
+
+	// LatencyMS is how long the oldest pending task has been waiting.
+	LatencyMS int64 `json:"latency_ms"`
+}
+
+// ListQueuesResponse lists every queue known to Redis.
+type ListQueuesResponse struct {
+	Queues []QueueStats `json:"queues"`
+}
+
+// Queues returns the stats of every queue.
+func (h *JobsHandler) Queues(c echo.Context, req *ListQueuesRequest) (ListQueuesResponse, error) {
+	inspector := h.server.Job.Inspector
+
+	names, err := inspector.Queues()
+	if err != nil {
+		return ListQueuesResponse{}, err
+	}
+
+	resp := ListQueuesResponse{Queues: make([]QueueStats, 0, len(names))}
+	for _, name := range names {
+		info, err := inspector.GetQueueInfo(name)
+		if err != nil {
+			return ListQueuesResponse{}, err
+		}
+
+		resp.Queues = append(resp.Queues, QueueStats{
+			Queue:          info.Queue,
+			Paused:         info.Paused,
+			Size:           info.Size,
+			Pending:        info.Pending,
+			Active:         info.Active,
+			Scheduled:      info.Scheduled,
+			Retry:          info.Retry,
+			Archived:       info.Archived,
+			Completed:      info.Completed,
+			Processed:      info.Processed,
+			Failed:         info.Failed,
+			ProcessedTotal: info.ProcessedTotal,
+			FailedTotal:    info.FailedTotal,
+			LatencyMS:      info.Latency.Milliseconds(),
+		})
+	}
+
+	return resp, nil
+}
+
+// ListTasksRequest selects a page of tasks in one state of one queue.
+type ListTasksRequest struct {
+	Queue    string `param:"queue" validate:"required"`
+	State    string `param:"state" validate:"required,oneof=pending active scheduled retry archived"`
+	Page     int    `query:"page" validate:"omitempty,min=1"`
+	PageSize int    `query:"page_size" validate:"omitempty,min=1,max=100"`
+}
+
+func (r *ListTasksRequest) Validate() error {
+	return validation.NewValidator().Struct(r)
+}
+
+// TaskSummary describes one task. Payloads are not included: they may hold
+// personal data (e.g. email addresses) and the dashboard doesn't need them.
+type TaskSummary struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Queue    string `json:"queue"`
+	State    string `json:"state"`
+	MaxRetry int    `json:"max_retry"`
+	Retried  int    `json:"retried"`
+
+	// LastError is the error of the last failed attempt ("" if none).
+	LastError    string     `json:"last_error,omitempty"`
+	LastFailedAt *time.Time `json:"last_failed_at,omitempty"`
+
+	// NextProcessAt is when a scheduled/retry task runs next.
+	NextProcessAt *time.Time `json:"next_process_at,omitempty"`
+}
+
+// ListTasksResponse is one page of tasks.
+type ListTasksResponse struct {
+	Tasks    []TaskSummary `json:"tasks"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"page_size"`
+}
+
+// Tasks lists tasks of a queue in the requested state, most useful for
+// scheduled tasks, retries and dead letters (archived).
+func (h *JobsHandler) Tasks(c echo.Context, req *ListTasksRequest) (ListTasksResponse, error) {
+	page, pageSize := req.Page, req.PageSize
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = 20
+	}
+
+	inspector := h.server.Job.Inspector
+	opts := []asynq.ListOption{asynq.Page(page), asynq.PageSize(pageSize)}
+
+	var (
+		tasks []*asynq.TaskInfo
+		err   error
+	)
+	switch req.State {
+	case TaskStatePending:
+		tasks, err = inspector.ListPendingTasks(req.Queue, opts...)
+	case TaskStateActive:
+		tasks, err = inspector.ListActiveTasks(req.Queue, opts...)
+	case TaskStateScheduled:
+		tasks, err = inspector.ListScheduledTasks(req.Queue, opts...)
+	case TaskStateRetry:
+		tasks, err = inspector.ListRetryTasks(req.Queue, opts...)
+	case TaskStateArchived:
+		tasks, err = inspector.ListArchivedTasks(req.Queue, opts...)
+	}
+	if errors.Is(err, asynq.ErrQueueNotFound) {
+		code := "QUEUE_NOT_FOUND"
+		return ListTasksResponse{}, errs.NewNotFoundError("Queue not found: "+req.Queue, false, &code)
+	}
+	if err != nil {
+		return ListTasksResponse{}, err
+	}
+
+	resp := ListTasksResponse{Tasks: make([]TaskSummary, 0, len(tasks)), Page: page, PageSize: pageSize}
+	for _, t := range tasks {
+		summary := TaskSummary{
+			ID:        t.ID,
+			Type:      t.Type,
+			Queue:     t.Queue,
+			State:     req.State,
+			MaxRetry:  t.MaxRetry,
+			Retried:   t.Retried,
+			LastError: t.LastErr,
+		}
+		if !t.LastFailedAt.IsZero() {
+			summary.LastFailedAt = &t.LastFailedAt
+		}
+		if !t.NextProcessAt.IsZero() {
+			summary.NextProcessAt = &t.NextProcessAt
+		}
+		resp.Tasks = append(resp.Tasks, summary)
+	}
+
+	return resp, nil
+}
+
+// enqueueUnique enqueues a task carrying a unique task ID (one run at a time
+// per backfill, index, source, ...). what names the work in the messages,
+// e.g. "reindex of this index":
+//   - the same work already queued or running is a 409
+//   - any other failure means the job queue (Redis) is down: a server
+//     fault, worth retrying, so a 503
+func enqueueUnique(ctx context.Context, client *asynq.Client, task *asynq.Task, what string) (*asynq.TaskInfo, error) {
+	info, err := client.EnqueueContext(ctx, task)
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		return nil, errs.NewConflictError("The "+what+" is already queued or running", true, nil).WithCause(err)
+	}
+	if err != nil {
+		return nil, errs.NewServiceUnavailableError("The "+what+" could not be enqueued, please retry later", nil, 0).WithCause(err)
+	}
+	return info, nil
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"github.com/deppfellow/go-boilerplate/internal/lib/maintenance"
+	"github.com/deppfellow/go-boilerplate/internal/middleware"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/deppfellow/go-boilerplate/internal/validation"
+	"github.com/labstack/echo/v4"
+)
+
+// MaintenanceHandler exposes the admin endpoints toggling maintenance mode.
+type MaintenanceHandler struct {
+	Handler
+}
+
+// NewMaintenanceHandler constructs a MaintenanceHandler.
+func NewMaintenanceHandler(s *server.Server) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		Handler: NewHandler(s),
+	}
+}
+
+// GetMaintenanceRequest has no input; it exists to use the typed pipeline.
+type GetMaintenanceRequest struct{}
+
+func (r *GetMaintenanceRequest) Validate() error { return nil }
+
+// EnableMaintenanceRequest switches maintenance mode on.
+type EnableMaintenanceRequest struct {
+	Message     string `json:"message" normalize:"strip_control,trim" validate:"max=500"`
+	RetryAfter  int    `json:"retry_after" validate:"min=0"`
+	RedirectURL string `json:"redirect_url" normalize:"trim" validate:"omitempty,urlscheme"`
+}
+
+func (r *EnableMaintenanceRequest) Validate() error {
+	return validation.NewValidator().Struct(r)
+}
+
+// DisableMaintenanceRequest has no input.
+type DisableMaintenanceRequest struct{}
+
+func (r *DisableMaintenanceRequest) Validate() error { return nil }
+
+// Status returns the current maintenance state.
+func (h *MaintenanceHandler) Status(c echo.Context, _ *GetMaintenanceRequest) (maintenance.State, error) {
+	return h.server.Maintenance.Get(c.Request().Context())
+}
+
+// Enable switches maintenance mode on for every instance.
+func (h *MaintenanceHandler) Enable(c echo.Context, req *EnableMaintenanceRequest) (maintenance.State, error) {
+	state := maintenance.State{
+		Message:     req.Message,
+		RetryAfter:  req.RetryAfter,
+		RedirectURL: req.RedirectURL,
+	}
+
+	if err := h.server.Maintenance.Enable(c.Request().Context(), state); err != nil {
+		return maintenance.State{}, err
+	}
+
+	middleware.GetLogger(c).Warn().
+		Str("user_id", middleware.GetUserID(c)).
+		Msg("maintenance mode enabled")
+
+	return h.server.Maintenance.Get(c.Request().Context())
+}
+
+// Disable switches maintenance mode off.
+func (h *MaintenanceHandler) Disable(c echo.Context, _ *DisableMaintenanceRequest) error {
+	if err := h.server.Maintenance.Disable(c.Request().Context()); err != nil {
+		return err
+	}
+
+	middleware.GetLogger(c).Warn().
+		Str("user_id", middleware.GetUserID(c)).
+		Msg("maintenance mode disabled")
+
+	return nil
+}
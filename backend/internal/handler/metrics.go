@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MetricsHandler serves the Prometheus registry (server.Metrics).
+type MetricsHandler struct {
+	Handler
+
+	// exposition writes the registry, negotiating the format (text or
+	// OpenMetrics) and compression with the scraper.
+	exposition http.Handler
+}
+
+// NewMetricsHandler constructs a MetricsHandler.
+func NewMetricsHandler(s *server.Server) *MetricsHandler {
+	h := &MetricsHandler{Handler: NewHandler(s)}
+	if s.Metrics != nil {
+		h.exposition = promhttp.HandlerFor(s.Metrics, promhttp.HandlerOpts{
+			ErrorLog:          promhttpLogger{s},
+			EnableOpenMetrics: true,
+		})
+	}
+	return h
+}
+
+// Enabled reports whether the metrics route should be registered.
+func (h *MetricsHandler) Enabled() bool {
+	return h.server.Metrics != nil
+}
+
+// Path is where the metrics are served (observability.metrics.path).
+func (h *MetricsHandler) Path() string {
+	return h.server.Config.Observability.Metrics.Path
+}
+
+// Serve writes the metrics in the Prometheus exposition format. When
+// observability.metrics.secret is set the scraper must send it as a bearer
+// token (bearer_token in the Prometheus scrape config).
+func (h *MetricsHandler) Serve(c echo.Context) error {
+	if secret := h.server.Config.Observability.Metrics.Secret; secret != "" {
+		token, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			return errs.NewUnauthorizedError("Invalid metrics token", false)
+		}
+	}
+
+	h.exposition.ServeHTTP(c.Response(), c.Request())
+	return nil
+}
+
+// promhttpLogger reports collector failures during a scrape to the app log.
+type promhttpLogger struct{ server *server.Server }
+
+func (l promhttpLogger) Println(v ...any) {
+	l.server.Logger.Error().Msg(fmt.Sprint(v...))
+}
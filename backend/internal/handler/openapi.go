@@ -2,10 +2,16 @@ package handler
 
 import (
 	"fmt"
+	"html/template"
+	"io/fs"
 	"net/http"
-	"os"
+	"strings"
 
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/middleware"
 	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/deppfellow/go-boilerplate/static"
 	"github.com/labstack/echo/v4"
 )
 
@@ -13,10 +19,13 @@ import (
 //
 // Tutor intent (03:11:56–03:13:55):
 // - Provide a simple UI to test APIs.
-// - The UI is a static HTML file (openapi.html) that loads JS from a CDN.
+// - The UI is a static HTML file loading a vendored JS bundle.
 // - It reads an OpenAPI JSON file from the static folder (e.g., openapi.json).
 // - The handler reads the HTML template and serves it as HTML.
 // - Disable caching to ensure updates to docs appear immediately during development.
+//
+// The HTML templates and spec are embedded in the binary (see package static),
+// and the renderer (Scalar, Swagger UI or Redoc) is picked from DocsConfig.
 type OpenAPIHandler struct {
 	Handler
 }
@@ -28,11 +37,61 @@ func NewOpenAPIHandler(s *server.Server) *OpenAPIHandler {
 	}
 }
 
-// ServeOpenAPIUI reads static/openapi.html and serves it as an HTML response.
+// docsTemplates maps DocsConfig.UI to the embedded template file.
+var docsTemplates = map[string]string{
+	config.DocsUIScalar:  "openapi.html",
+	config.DocsUISwagger: "swagger.html",
+	config.DocsUIRedoc:   "redoc.html",
+}
+
+// docsBundles maps DocsConfig.UI to the vendored bundle its page loads. Only
+// Swagger UI ships with the repo (see static/docs/vendor/README.md).
+var docsBundles = map[string]string{
+	config.DocsUIScalar:  "vendor/scalar/standalone.js",
+	config.DocsUISwagger: "vendor/swagger-ui/swagger-ui-bundle.js",
+	config.DocsUIRedoc:   "vendor/redoc/redoc.standalone.js",
+}
+
+// Enabled reports whether the docs routes should be registered.
+func (h *OpenAPIHandler) Enabled() bool {
+	return h.docsConfig().Enabled
+}
+
+// StaticFS returns the assets served at /static: the spec, the docs pages
+// and their vendored bundles. The admin dashboard page is not part of it.
+func (h *OpenAPIHandler) StaticFS() fs.FS {
+	return docsFS()
+}
+
+// docsFS is the docs subtree of the embedded assets.
+func docsFS() fs.FS {
+	docs, err := fs.Sub(static.FS, static.DocsDir)
+	if err != nil {
+		// Only fails on an invalid path, i.e. a broken build.
+		panic(fmt.Sprintf("static: %v", err))
+	}
+	return docs
+}
+
+// ServeOpenAPIUI serves the configured docs UI page from the embedded assets.
+// A UI whose bundle is not vendored falls back to Swagger UI, as its page
+// could not load.
 //
 // Cache-Control is set to "no-cache" so clients do not reuse old docs UI.
 func (h *OpenAPIHandler) ServeOpenAPIUI(c echo.Context) error {
-	templateBytes, err := os.ReadFile("static/openapi.html")
+	ui := h.docsConfig().UI
+	if _, ok := docsTemplates[ui]; !ok {
+		ui = config.DocsUISwagger
+	}
+	if _, err := fs.Stat(docsFS(), docsBundles[ui]); err != nil {
+		middleware.GetLogger(c).Warn().
+			Str("ui", ui).
+			Str("bundle", docsBundles[ui]).
+			Msg("docs UI bundle is not vendored, serving Swagger UI")
+		ui = config.DocsUISwagger
+	}
+
+	templateBytes, err := fs.ReadFile(docsFS(), docsTemplates[ui])
 
 	// Prevent caching of the docs UI page.
 	c.Response().Header().Set("Cache-Control", "no-cache")
@@ -49,3 +108,45 @@ func (h *OpenAPIHandler) ServeOpenAPIUI(c echo.Context) error {
 
 	return nil
 }
+
+// errorCatalogTemplate renders the error catalog; each code is an anchor so
+// the "docs_url" of error responses ("/docs/errors#CODE") lands on it.
+var errorCatalogTemplate = template.Must(template.New("errors").Parse(`<!doctype html>
+<html>
+<head><meta charset="utf-8"><title>API error codes</title></head>
+<body>
+<h1>API error codes</h1>
+<dl>
+{{range .}}<dt id="{{.Code}}"><code>{{.Code}}</code> ({{.Status}})</dt>
+<dd>{{.Description}}{{if .Message}} Default message: "{{.Message}}".{{end}}</dd>
+{{end}}</dl>
+</body>
+</html>
+`))
+
+// ServeErrorCatalog serves the error catalog (see errs.Catalog) as an HTML
+// page, or as JSON when the client asks for application/json.
+func (h *OpenAPIHandler) ServeErrorCatalog(c echo.Context) error {
+	catalog := errs.Catalog()
+
+	c.Response().Header().Set("Cache-Control", "no-cache")
+
+	if strings.Contains(c.Request().Header.Get(echo.HeaderAccept), echo.MIMEApplicationJSON) {
+		return c.JSON(http.StatusOK, catalog)
+	}
+
+	var page strings.Builder
+	if err := errorCatalogTemplate.Execute(&page, catalog); err != nil {
+		return fmt.Errorf("failed to render error catalog: %w", err)
+	}
+
+	return c.HTML(http.StatusOK, page.String())
+}
+
+// docsConfig returns the docs config, falling back to defaults if unset.
+func (h *OpenAPIHandler) docsConfig() *config.DocsConfig {
+	if h.server.Config.Docs == nil {
+		return config.DefaultDocsConfig(h.server.Config.Primary.Env)
+	}
+	return h.server.Config.Docs
+}
@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/validation"
+	"github.com/labstack/echo/v4"
+)
+
+// HandleOption customizes a single route registered through HandleWith,
+// HandleNoContentWith or HandleFileWith (or the positional Handle,
+// HandleFile and HandleNoContent wrappers).
+//
+// Everything about a route is an option, so new cross-cutting features only
+// add a With* function instead of another top-level wrapper:
+//
+//	router.POST("/todos", handler.HandleWith(h, h.CreateTodo,
+//		handler.WithStatus(http.StatusCreated),
+//		handler.WithAuthScope("org:todos:create"),
+//		handler.WithTimeout(5*time.Second),
+//		handler.WithOperationID("createTodo"),
+//	))
+type HandleOption func(*handleOptions)
+
+// handleOptions is the resolved per-route configuration used by handleRequest.
+type handleOptions struct {
+	// bodyLimit is the maximum request body size in bytes.
+	// 0 means "use ServerConfig default".
+	bodyLimit int64
+
+	// lastModified resolves the resource's last modification time for
+	// conditional GETs. nil disables Last-Modified handling.
+	lastModified LastModifiedFunc
+
+	// status is the success status code (0 = the entry point's default).
+	status int
+
+	// request is the request payload prototype (nil = derived from the handler type).
+	// It is copied into a fresh payload for each request, never bound into.
+	request validation.Validatable
+
+	// requestFactory, if set, builds each request payload instead of the prototype.
+	requestFactory func() validation.Validatable
+
+	// requestPool recycles payloads through a sync.Pool.
+	requestPool bool
+
+	// cacheTTL marks successful GET/HEAD responses cacheable (0 = no header).
+	cacheTTL time.Duration
+
+	// timeout is a per-route request deadline (0 = global timeouts only).
+	timeout time.Duration
+
+	// authScopes requires authentication and every listed permission.
+	authScopes []string
+
+	// operationID names the operation in logs, traces and API docs.
+	operationID string
+
+	// filename and contentType describe HandleFileWith downloads.
+	filename    string
+	contentType string
+
+	// validateResponses checks JSON responses against the documented
+	// contract (derived from DocsConfig.ValidateResponses, never in production).
+	validateResponses bool
+
+	// Pipeline hooks (see hooks.go).
+	beforeValidate []RequestHook
+	afterValidate  []RequestHook
+	beforeRespond  []ResponseHook
+}
+
+// LastModifiedFunc returns when the data served by a route last changed,
+// typically via repository.Base.LastModified(ctx, "todos").
+// A zero time disables the conditional check for that request.
+type LastModifiedFunc func(c echo.Context) (time.Time, error)
+
+// WithBodyLimit overrides the global request body limit for this route.
+//
+// Requests exceeding the limit are rejected with a 413 errs.HTTPError
+// before binding happens.
+func WithBodyLimit(bytes int64) HandleOption {
+	return func(o *handleOptions) {
+		o.bodyLimit = bytes
+	}
+}
+
+// WithLastModified enables conditional GET handling (ETag / If-None-Match and
+// Last-Modified / If-Modified-Since) for a GET route (usually a list endpoint
+// polled by clients).
+//
+// The func runs before binding. If the client's validator matches the
+// returned time, the pipeline short-circuits with 304 Not Modified and the
+// handler (and its DB queries) never runs.
+func WithLastModified(fn LastModifiedFunc) HandleOption {
+	return func(o *handleOptions) {
+		o.lastModified = fn
+	}
+}
+
+// WithStatus sets the success status code.
+//
+// Defaults: 200 for HandleWith and HandleFileWith, 204 for HandleNoContentWith.
+func WithStatus(status int) HandleOption {
+	return func(o *handleOptions) {
+		o.status = status
+	}
+}
+
+// WithRequest sets the request payload prototype. It must have the handler's
+// request type; without it one is derived from the handler signature.
+//
+// The prototype itself is never bound into: each request gets its own deep
+// copy, so field values on the prototype act as defaults and maps or slices
+// it holds are never shared between requests.
+func WithRequest(req validation.Validatable) HandleOption {
+	return func(o *handleOptions) {
+		o.request = req
+	}
+}
+
+// WithRequestFactory builds each request payload with fn (which must return
+// the handler's request type), for payloads a copy of a prototype can't
+// build (e.g. computed defaults or state in unexported fields).
+func WithRequestFactory(fn func() validation.Validatable) HandleOption {
+	return func(o *handleOptions) {
+		o.requestFactory = fn
+	}
+}
+
+// WithRequestPool recycles request payloads through a sync.Pool, resetting
+// them to the prototype between uses. Worth it for hot routes with large
+// payload structs.
+//
+// The handler must not keep a reference to the payload (e.g. in a
+// goroutine) after it returns: the payload is reused by a later request.
+// Ignored when WithRequestFactory is set.
+func WithRequestPool() HandleOption {
+	return func(o *handleOptions) {
+		o.requestPool = true
+	}
+}
+
+// WithCache marks successful GET/HEAD responses as cacheable by the client
+// for ttl (same behavior as RouteBuilder.Cache).
+func WithCache(ttl time.Duration) HandleOption {
+	return func(o *handleOptions) {
+		o.cacheTTL = ttl
+	}
+}
+
+// WithTimeout gives the route its own request deadline. It replaces the
+// global timeout (default or group) for the route, longer or shorter.
+func WithTimeout(d time.Duration) HandleOption {
+	return func(o *handleOptions) {
+		o.timeout = d
+	}
+}
+
+// WithAuthScope requires an authenticated caller holding every one of scopes
+// (Clerk organization permissions, see middleware.RequirePermission).
+func WithAuthScope(scopes ...string) HandleOption {
+	return func(o *handleOptions) {
+		o.authScopes = append(o.authScopes, scopes...)
+	}
+}
+
+// WithOperationID names the operation. It is logged as operation_id and
+// recorded on the New Relic transaction as handler.operation_id, and should
+// match the operationId in the OpenAPI spec.
+func WithOperationID(id string) HandleOption {
+	return func(o *handleOptions) {
+		o.operationID = id
+	}
+}
+
+// WithFile sets the download filename and content type for HandleFileWith.
+func WithFile(filename, contentType string) HandleOption {
+	return func(o *handleOptions) {
+		o.filename = filename
+		o.contentType = contentType
+	}
+}
+
+// newHandleOptions applies opts on top of defaults derived from server config.
+func newHandleOptions(h Handler, opts []HandleOption) handleOptions {
+	o := handleOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.bodyLimit <= 0 && h.server != nil {
+		o.bodyLimit = h.server.Config.Server.GetMaxBodyBytes()
+	}
+
+	if h.server != nil && h.server.Config.Docs != nil {
+		o.validateResponses = h.server.Config.Docs.ValidateResponses &&
+			h.server.Config.Primary.Env != "production"
+	}
+
+	return o
+}
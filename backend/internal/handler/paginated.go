@@ -0,0 +1,48 @@
+package handler
+
+import "github.com/deppfellow/go-boilerplate/internal/lib/pagination"
+
+// Paginated is the response envelope of cursor-paginated lists:
+//
+//	{
+//	  "data": [...],
+//	  "pagination": {"next_cursor": "eyJzIjoi...", "has_more": true, "limit": 20}
+//	}
+//
+// Clients pass next_cursor back as ?cursor= until has_more is false.
+type Paginated[T any] struct {
+	Data       []T      `json:"data"`
+	Pagination PageInfo `json:"pagination"`
+}
+
+// PageInfo tells clients how to fetch the next page.
+type PageInfo struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	Limit      int    `json:"limit"`
+}
+
+// NewPaginated wraps a page from repository.ListPage:
+//
+//	func (h *TodoHandler) List(c echo.Context, req *ListTodosRequest) (handler.Paginated[model.Todo], error) {
+//		page, err := h.todos.List(c.Request().Context(), req.Params)
+//		if err != nil {
+//			return handler.Paginated[model.Todo]{}, err
+//		}
+//		return handler.NewPaginated(page), nil
+//	}
+func NewPaginated[T any](page pagination.Page[T]) Paginated[T] {
+	data := page.Items
+	if data == nil {
+		data = []T{}
+	}
+
+	return Paginated[T]{
+		Data: data,
+		Pagination: PageInfo{
+			NextCursor: page.NextCursor,
+			HasMore:    page.HasMore(),
+			Limit:      page.Limit,
+		},
+	}
+}
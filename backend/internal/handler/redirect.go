@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/lib/tracing"
+	"github.com/deppfellow/go-boilerplate/internal/validation"
+	"github.com/labstack/echo/v4"
+)
+
+// RedirectResponse is the result type of redirecting handlers.
+//
+// Returning it from a HandleRedirect handler (instead of calling c.Redirect
+// directly) keeps redirects inside handleRequest, so they are validated,
+// logged and traced like every other response.
+type RedirectResponse struct {
+	// Status is one of 301, 302, 303, 307 or 308. Anything else becomes 302.
+	Status int
+
+	// URL is the Location the client is sent to.
+	URL string
+
+	// Message is optional human-readable context included in the action.
+	Message string
+}
+
+// RedirectFound returns a 302 redirect (the common "go look over there").
+func RedirectFound(url string) RedirectResponse {
+	return RedirectResponse{Status: http.StatusFound, URL: url}
+}
+
+// RedirectPermanent returns a 301 redirect (resource moved for good; cached by clients).
+func RedirectPermanent(url string) RedirectResponse {
+	return RedirectResponse{Status: http.StatusMovedPermanently, URL: url}
+}
+
+// RedirectTemporary returns a 307 redirect (like 302 but the method and body are preserved).
+func RedirectTemporary(url string) RedirectResponse {
+	return RedirectResponse{Status: http.StatusTemporaryRedirect, URL: url}
+}
+
+// Action returns the redirect as an errs.Action.
+func (r RedirectResponse) Action() *errs.Action {
+	return errs.NewRedirectAction(r.Message, r.URL)
+}
+
+// status returns a valid 3xx status for the redirect.
+func (r RedirectResponse) status() int {
+	switch r.Status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return r.Status
+	default:
+		return http.StatusFound
+	}
+}
+
+// redirectBody is the JSON body sent with redirects, for API clients that
+// don't follow Location automatically (or want to decide themselves).
+type redirectBody struct {
+	Action *errs.Action `json:"action"`
+}
+
+// RedirectResponseHandler writes redirect responses.
+//
+// It expects the handler result to be a RedirectResponse.
+type RedirectResponseHandler struct{}
+
+func (h RedirectResponseHandler) Handle(c echo.Context, result interface{}) error {
+	redirect := result.(RedirectResponse)
+
+	c.Response().Header().Set(echo.HeaderLocation, redirect.URL)
+	return c.JSON(redirect.status(), redirectBody{Action: redirect.Action()})
+}
+
+func (h RedirectResponseHandler) GetOperation() string {
+	return "handler_redirect"
+}
+
+func (h RedirectResponseHandler) AddAttributes(span tracing.Span, result interface{}) {
+	if span == nil {
+		return
+	}
+	if redirect, ok := result.(RedirectResponse); ok {
+		span.SetAttribute("redirect.status", redirect.status())
+		span.SetAttribute("redirect.location", redirect.URL)
+	}
+}
+
+// HandleRedirect wraps a handler returning a RedirectResponse into the unified pipeline.
+//
+//	router.GET("/l/:code", handler.HandleRedirect(h, h.ResolveShortLink, &ResolveReq{}))
+func HandleRedirect[Req validation.Validatable](
+	h Handler,
+	handler HandlerFunc[Req, RedirectResponse],
+	req Req,
+	opts ...HandleOption,
+) echo.HandlerFunc {
+	options := newHandleOptions(h, append([]HandleOption{WithRequest(req)}, opts...))
+	requests := newRequestSource[Req](options)
+
+	return wrapRoute(h, options, func(c echo.Context) error {
+		req := requests.get()
+		defer requests.put(req)
+
+		return handleRequest(c, req, func(c echo.Context, req Req) (interface{}, error) {
+			return handler(c, req)
+		}, RedirectResponseHandler{}, options)
+	})
+}
+
+// Redirect finishes a RouteBuilder with a typed redirect handler (see HandleRedirect).
+func Redirect[Req validation.Validatable](
+	rb *RouteBuilder,
+	handler HandlerFunc[Req, RedirectResponse],
+	req Req,
+) echo.HandlerFunc {
+	return rb.wrap(HandleRedirect(rb.h, handler, req, rb.opts...))
+}
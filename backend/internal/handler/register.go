@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Registrar is the route-registration surface shared by *echo.Echo and
+// *echo.Group, so the helpers below work for the root router and groups alike.
+type Registrar interface {
+	Add(method, path string, handler echo.HandlerFunc, middleware ...echo.MiddlewareFunc) *echo.Route
+}
+
+// GET registers a GET route together with an automatic HEAD twin.
+//
+// The HEAD route runs the exact same handler and middleware chain, so status
+// code and headers (Content-Type, Cache-Control, ETag, Last-Modified, rate
+// limit headers...) are identical; only the body is dropped.
+//
+// Routes registered otherwise (g.GET with a RouteBuilder terminal, e.Static,
+// ...) get the same twin from HeadForGET once the router is built.
+func GET(r Registrar, path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) []*echo.Route {
+	return []*echo.Route{
+		r.Add(http.MethodGet, path, h, m...),
+		r.Add(http.MethodHead, path, headHandler(h), m...),
+	}
+}
+
+// HeadForGET registers a HEAD twin (see GET) for every GET route of e that
+// has none, whichever way it was declared: handler.GET, a RouteBuilder
+// terminal on g.GET, plain e.GET, Static. Call it once, after every route is
+// registered.
+//
+// OPTIONS and Allow need nothing more: Echo answers OPTIONS for any
+// registered path with 204, and the global error handler answers a wrong
+// method with 405, both with an Allow header built from the routes in the
+// router, so every GET route advertises "GET, HEAD" consistently.
+func HeadForGET(e *echo.Echo) {
+	routes := e.Routes()
+
+	hasHead := make(map[string]bool, len(routes))
+	for _, r := range routes {
+		if r.Method == http.MethodHead {
+			hasHead[r.Path] = true
+		}
+	}
+
+	// The router stores each GET handler already wrapped in its route and
+	// group middleware: look it up by template rather than registering the
+	// chain again.
+	c := e.NewContext(nil, nil)
+	for _, r := range routes {
+		if r.Method != http.MethodGet || hasHead[r.Path] {
+			continue
+		}
+		e.Router().Find(http.MethodGet, r.Path, c)
+		e.Add(http.MethodHead, r.Path, headHandler(c.Handler()))
+		hasHead[r.Path] = true
+	}
+}
+
+// headHandler runs h with a response writer that discards the body.
+func headHandler(h echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		res := c.Response()
+		res.Writer = headResponseWriter{ResponseWriter: res.Writer}
+		return h(c)
+	}
+}
+
+// headResponseWriter forwards headers and status but swallows body writes.
+//
+// net/http already drops HEAD bodies on the wire; doing it here as well keeps
+// behavior identical behind proxies, in httptest recorders and in middleware
+// that inspects the writer.
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
@@ -0,0 +1,62 @@
+package handler_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/deppfellow/go-boilerplate/internal/handler"
+	"github.com/deppfellow/go-boilerplate/internal/handlertest"
+	"github.com/labstack/echo/v4"
+)
+
+func TestHeadForGET(t *testing.T) {
+	h := handlertest.New(t)
+	setHeader := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Response().Header().Set("X-Route", "todos")
+			return next(c)
+		}
+	}
+
+	g := h.Echo.Group("/api")
+	g.GET("/todos", handler.JSON(handler.Route(h.Handler()).Use(setHeader), func(echo.Context, *listTodosRequest) ([]todoResponse, error) {
+		return []todoResponse{{ID: "1"}}, nil
+	}, http.StatusOK, &listTodosRequest{}))
+	g.POST("/todos", func(c echo.Context) error { return c.NoContent(http.StatusCreated) })
+	handler.GET(h.Echo, "/ping", func(c echo.Context) error { return c.String(http.StatusOK, "pong") })
+
+	handler.HeadForGET(h.Echo)
+
+	get := h.Do(http.MethodGet, "/api/todos", nil)
+	h.RequireStatus(get, http.StatusOK)
+
+	head := h.Do(http.MethodHead, "/api/todos", nil)
+	h.RequireStatus(head, http.StatusOK)
+	if head.Body.Len() != 0 {
+		t.Fatalf("HEAD wrote a body: %q", head.Body.String())
+	}
+	if head.Header().Get("X-Route") != "todos" {
+		t.Fatal("HEAD skipped the route middleware")
+	}
+	for _, header := range []string{echo.HeaderContentType, "ETag", "X-Route"} {
+		if got, want := head.Header().Get(header), get.Header().Get(header); got != want {
+			t.Fatalf("HEAD %s = %q, GET has %q", header, got, want)
+		}
+	}
+
+	// handler.GET already registered its twin.
+	h.RequireStatus(h.Do(http.MethodHead, "/ping", nil), http.StatusOK)
+
+	options := h.Do(http.MethodOptions, "/api/todos", nil)
+	h.RequireStatus(options, http.StatusNoContent)
+	if allow := options.Header().Get(echo.HeaderAllow); !strings.Contains(allow, http.MethodHead) {
+		t.Fatalf("OPTIONS Allow = %q, want HEAD listed", allow)
+	}
+
+	rejected := h.Do(http.MethodDelete, "/api/todos", nil)
+	h.RequireStatus(rejected, http.StatusMethodNotAllowed)
+	if allow := rejected.Header().Get(echo.HeaderAllow); !strings.Contains(allow, http.MethodHead) {
+		t.Fatalf("405 Allow = %q, want HEAD listed", allow)
+	}
+}
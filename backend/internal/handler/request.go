@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/deppfellow/go-boilerplate/internal/validation"
+)
+
+// requestSource hands out a request payload per request.
+//
+// Binding mutates the payload, so it must never be shared between concurrent
+// requests. By default every request gets a fresh allocation initialized from
+// a deep copy of the prototype (so defaults set on the prototype, e.g.
+// &ListRequest{Limit: 20}, still apply, but maps and slices it holds are
+// never shared). WithRequestPool recycles payloads through a sync.Pool instead.
+type requestSource[Req validation.Validatable] struct {
+	newFn func() Req
+	reset func(Req)
+	pool  *sync.Pool
+}
+
+// newRequestSource builds the source for a route from its options.
+func newRequestSource[Req validation.Validatable](o handleOptions) *requestSource[Req] {
+	if o.requestFactory != nil {
+		return &requestSource[Req]{
+			newFn: func() Req { return o.requestFactory().(Req) },
+		}
+	}
+
+	prototype, hasPrototype := o.request.(Req)
+	t := reflect.TypeFor[Req]()
+
+	// Value (non-pointer) payloads are copied on every use, but the maps,
+	// slices and pointers inside them would still be shared.
+	if t.Kind() != reflect.Pointer {
+		return &requestSource[Req]{newFn: func() Req {
+			req := reflect.New(t).Elem()
+			if hasPrototype {
+				deepCopy(req, reflect.ValueOf(prototype))
+			}
+			return req.Interface().(Req)
+		}}
+	}
+
+	// reset overwrites *dst with a deep copy of the prototype (or the zero
+	// value).
+	reset := func(dst Req) {
+		v := reflect.ValueOf(dst).Elem()
+		if hasPrototype && !reflect.ValueOf(prototype).IsNil() {
+			deepCopy(v, reflect.ValueOf(prototype).Elem())
+		} else {
+			v.SetZero()
+		}
+	}
+
+	source := &requestSource[Req]{
+		newFn: func() Req {
+			req := reflect.New(t.Elem()).Interface().(Req)
+			reset(req)
+			return req
+		},
+		reset: reset,
+	}
+
+	if o.requestPool {
+		source.pool = &sync.Pool{New: func() any { return source.newFn() }}
+	}
+
+	return source
+}
+
+// get returns a payload ready for binding.
+func (s *requestSource[Req]) get() Req {
+	if s.pool != nil {
+		return s.pool.Get().(Req)
+	}
+	return s.newFn()
+}
+
+// put returns a pooled payload once the response is written. It is a no-op
+// without WithRequestPool.
+func (s *requestSource[Req]) put(req Req) {
+	if s.pool == nil {
+		return
+	}
+	s.reset(req)
+	s.pool.Put(req)
+}
+
+// deepCopy sets dst to a copy of src that shares no map, slice or pointer
+// with it, so binding into dst never writes into the prototype. Unexported
+// struct fields can't be set through reflection and are copied shallowly;
+// request payloads bind into exported fields only.
+func deepCopy(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			dst.SetZero()
+			return
+		}
+		p := reflect.New(src.Type().Elem())
+		deepCopy(p.Elem(), src.Elem())
+		dst.Set(p)
+	case reflect.Interface:
+		if src.IsNil() {
+			dst.SetZero()
+			return
+		}
+		v := reflect.New(src.Elem().Type()).Elem()
+		deepCopy(v, src.Elem())
+		dst.Set(v)
+	case reflect.Map:
+		if src.IsNil() {
+			dst.SetZero()
+			return
+		}
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			v := reflect.New(src.Type().Elem()).Elem()
+			deepCopy(v, iter.Value())
+			m.SetMapIndex(iter.Key(), v)
+		}
+		dst.Set(m)
+	case reflect.Slice:
+		if src.IsNil() {
+			dst.SetZero()
+			return
+		}
+		s := reflect.MakeSlice(src.Type(), src.Len(), src.Cap())
+		for i := range src.Len() {
+			deepCopy(s.Index(i), src.Index(i))
+		}
+		dst.Set(s)
+	case reflect.Array:
+		for i := range src.Len() {
+			deepCopy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Struct:
+		dst.Set(src)
+		for i := range src.NumField() {
+			if field := dst.Field(i); field.CanSet() {
+				deepCopy(field, src.Field(i))
+			}
+		}
+	default:
+		dst.Set(src)
+	}
+}
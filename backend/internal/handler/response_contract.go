@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/deppfellow/go-boilerplate/internal/validation"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// Response contract checking (development only, see DocsConfig.ValidateResponses).
+//
+// Before a JSON response is written it is checked against:
+//  1. the `validate` tags of the handler's response struct, and
+//  2. the response schema documented in static/docs/openapi.json for the route,
+//     method and status (if the operation is documented).
+//
+// Violations are logged at error level with every mismatch listed; the
+// response is still sent, so a drifting contract is loud but never breaks dev.
+//
+// Only the OpenAPI subset the spec actually uses is understood: $ref to
+// components/schemas, type, properties, required, items, enum and nullable.
+
+// openAPISchema is the subset of an OpenAPI 3.0 schema object we check.
+type openAPISchema struct {
+	Ref        string                    `json:"$ref"`
+	Type       string                    `json:"type"`
+	Properties map[string]*openAPISchema `json:"properties"`
+	Required   []string                  `json:"required"`
+	Items      *openAPISchema            `json:"items"`
+	Enum       []any                     `json:"enum"`
+	Nullable   bool                      `json:"nullable"`
+}
+
+// openAPIDoc is the subset of the spec needed to find response schemas.
+type openAPIDoc struct {
+	Paths map[string]map[string]struct {
+		Responses map[string]struct {
+			Content map[string]struct {
+				Schema *openAPISchema `json:"schema"`
+			} `json:"content"`
+		} `json:"responses"`
+	} `json:"paths"`
+	Components struct {
+		Schemas map[string]*openAPISchema `json:"schemas"`
+	} `json:"components"`
+}
+
+var (
+	contractOnce      sync.Once
+	contractDoc       *openAPIDoc
+	contractErr       error
+	contractValidator *validator.Validate
+)
+
+// loadContract parses the embedded spec once.
+func loadContract() (*openAPIDoc, error) {
+	contractOnce.Do(func() {
+		contractValidator = validation.NewValidator()
+
+		data, err := fs.ReadFile(docsFS(), "openapi.json")
+		if err != nil {
+			contractErr = err
+			return
+		}
+
+		var doc openAPIDoc
+		if err := json.Unmarshal(data, &doc); err != nil {
+			contractErr = fmt.Errorf("invalid openapi.json: %w", err)
+			return
+		}
+		contractDoc = &doc
+	})
+	return contractDoc, contractErr
+}
+
+// checkResponseContract returns every contract violation of result.
+func checkResponseContract(c echo.Context, status int, result any) []string {
+	doc, err := loadContract()
+	if err != nil {
+		return []string{"cannot load OpenAPI spec: " + err.Error()}
+	}
+
+	var problems []string
+
+	// 1. Struct tags on the declared response type.
+	if isStruct(result) {
+		if err := contractValidator.Struct(result); err != nil {
+			var fieldErrs validator.ValidationErrors
+			if errors.As(err, &fieldErrs) {
+				for _, fe := range fieldErrs {
+					problems = append(problems, fmt.Sprintf("%s: failed %q", fe.Namespace(), fe.Tag()))
+				}
+			} else {
+				problems = append(problems, err.Error())
+			}
+		}
+	}
+
+	// 2. Documented OpenAPI schema.
+	schema := doc.responseSchema(c.Path(), c.Request().Method, status)
+	if schema == nil {
+		return problems
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return append(problems, "response is not JSON-encodable: "+err.Error())
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return append(problems, err.Error())
+	}
+
+	return append(problems, doc.check("$", value, schema)...)
+}
+
+// responseSchema finds the application/json schema of an operation's response.
+// Echo's ":id" parameters are matched against OpenAPI's "{id}".
+func (d *openAPIDoc) responseSchema(path, method string, status int) *openAPISchema {
+	operations, ok := d.Paths[echoPathToOpenAPI(path)]
+	if !ok {
+		return nil
+	}
+
+	operation, ok := operations[strings.ToLower(method)]
+	if !ok {
+		return nil
+	}
+
+	response, ok := operation.Responses[strconv.Itoa(status)]
+	if !ok {
+		response, ok = operation.Responses["default"]
+		if !ok {
+			return nil
+		}
+	}
+
+	return response.Content[echo.MIMEApplicationJSON].Schema
+}
+
+// check validates value against schema, returning one line per mismatch.
+func (d *openAPIDoc) check(at string, value any, schema *openAPISchema) []string {
+	if schema.Ref != "" {
+		resolved, ok := d.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+		if !ok {
+			return []string{fmt.Sprintf("%s: unresolved $ref %s", at, schema.Ref)}
+		}
+		schema = resolved
+	}
+
+	if value == nil {
+		if schema.Nullable || schema.Type == "" {
+			return nil
+		}
+		return []string{fmt.Sprintf("%s: null, expected %s", at, schema.Type)}
+	}
+
+	if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, value) {
+		return []string{fmt.Sprintf("%s: %v is not one of %v", at, value, schema.Enum)}
+	}
+
+	var problems []string
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected object", at)}
+		}
+		for _, name := range schema.Required {
+			if _, present := object[name]; !present {
+				problems = append(problems, fmt.Sprintf("%s.%s: required property missing", at, name))
+			}
+		}
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, documented := schema.Properties[name]
+			if !documented {
+				if len(schema.Properties) > 0 {
+					problems = append(problems, fmt.Sprintf("%s.%s: undocumented property", at, name))
+				}
+				continue
+			}
+			problems = append(problems, d.check(at+"."+name, object[name], property)...)
+		}
+
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected array", at)}
+		}
+		if schema.Items != nil {
+			for i, item := range items {
+				problems = append(problems, d.check(fmt.Sprintf("%s[%d]", at, i), item, schema.Items)...)
+			}
+		}
+
+	case "string":
+		if _, ok := value.(string); !ok {
+			problems = append(problems, fmt.Sprintf("%s: expected string", at))
+		}
+
+	case "integer":
+		if n, ok := value.(float64); !ok || n != float64(int64(n)) {
+			problems = append(problems, fmt.Sprintf("%s: expected integer", at))
+		}
+
+	case "number":
+		if _, ok := value.(float64); !ok {
+			problems = append(problems, fmt.Sprintf("%s: expected number", at))
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			problems = append(problems, fmt.Sprintf("%s: expected boolean", at))
+		}
+	}
+
+	return problems
+}
+
+// echoPathToOpenAPI converts "/todos/:id" to "/todos/{id}".
+func echoPathToOpenAPI(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// isStruct reports whether v is a struct or a non-nil pointer to one.
+func isStruct(v any) bool {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return false
+		}
+		rv = rv.Elem()
+	}
+	return rv.Kind() == reflect.Struct
+}
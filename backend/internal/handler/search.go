@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/lib/job"
+	"github.com/deppfellow/go-boilerplate/internal/lib/search"
+	"github.com/deppfellow/go-boilerplate/internal/middleware"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/deppfellow/go-boilerplate/internal/validation"
+	"github.com/labstack/echo/v4"
+)
+
+func init() {
+	errs.RegisterCode(errs.CatalogEntry{
+		Code:        "SEARCH_DISABLED",
+		Status:      http.StatusNotFound,
+		Description: "Search indexing is not enabled on this deployment (search.enabled).",
+	})
+}
+
+// SearchHandler exposes admin endpoints to rebuild and monitor the search
+// indexes (see lib/search).
+type SearchHandler struct {
+	Handler
+}
+
+// NewSearchHandler constructs a SearchHandler.
+func NewSearchHandler(s *server.Server) *SearchHandler {
+	return &SearchHandler{Handler: NewHandler(s)}
+}
+
+// SearchIndexRequest identifies a registered search index by path parameter.
+type SearchIndexRequest struct {
+	Index string `param:"index" validate:"required"`
+}
+
+func (r *SearchIndexRequest) Validate() error {
+	if _, ok := search.Lookup(r.Index); !ok {
+		return validation.CustomValidationErrors{{Field: "index", Message: "is not a registered search index"}}
+	}
+	return nil
+}
+
+// SearchReindexResponse acknowledges an enqueued reindex.
+type SearchReindexResponse struct {
+	Index  string `json:"index"`
+	TaskID string `json:"task_id"`
+}
+
+// Reindex starts a full rebuild of the index on the low-priority job queue.
+// Progress from an earlier run is discarded when the job starts. A reindex
+// of the index that is already queued or running is a 409.
+func (h *SearchHandler) Reindex(c echo.Context, req *SearchIndexRequest) (SearchReindexResponse, error) {
+	if _, err := h.runner(); err != nil {
+		return SearchReindexResponse{}, err
+	}
+
+	ctx := c.Request().Context()
+	task, err := job.NewSearchReindexTask(ctx, req.Index)
+	if err != nil {
+		return SearchReindexResponse{}, err
+	}
+
+	info, err := enqueueUnique(ctx, h.server.Job.Client, task, "reindex of this index")
+	if err != nil {
+		return SearchReindexResponse{}, err
+	}
+
+	middleware.GetLogger(c).Info().
+		Str("index", req.Index).
+		Str("task_id", info.ID).
+		Msg("search reindex enqueued")
+
+	return SearchReindexResponse{Index: req.Index, TaskID: info.ID}, nil
+}
+
+// Status returns the progress of the last full reindex.
+func (h *SearchHandler) Status(c echo.Context, req *SearchIndexRequest) (search.Progress, error) {
+	runner, err := h.runner()
+	if err != nil {
+		return search.Progress{}, err
+	}
+	return runner.Progress(c.Request().Context(), req.Index)
+}
+
+// runner returns the search runner, or SEARCH_DISABLED.
+func (h *SearchHandler) runner() (*search.Runner, error) {
+	if h.server.SearchRunner == nil {
+		code := "SEARCH_DISABLED"
+		return nil, errs.NewNotFoundError("Search is not enabled", false, &code)
+	}
+	return h.server.SearchRunner, nil
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"github.com/deppfellow/go-boilerplate/internal/lib/job"
+	"github.com/deppfellow/go-boilerplate/internal/lib/softdelete"
+	"github.com/deppfellow/go-boilerplate/internal/middleware"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/deppfellow/go-boilerplate/internal/validation"
+	"github.com/labstack/echo/v4"
+)
+
+// SoftDeleteHandler exposes the admin endpoint purging expired soft-deleted
+// rows (see lib/softdelete).
+type SoftDeleteHandler struct {
+	Handler
+}
+
+// NewSoftDeleteHandler constructs a SoftDeleteHandler.
+func NewSoftDeleteHandler(s *server.Server) *SoftDeleteHandler {
+	return &SoftDeleteHandler{Handler: NewHandler(s)}
+}
+
+// PurgeDeletedRequest identifies a table registered for purging.
+type PurgeDeletedRequest struct {
+	Table string `param:"table" validate:"required"`
+}
+
+func (r *PurgeDeletedRequest) Validate() error {
+	if _, ok := softdelete.Lookup(r.Table); !ok {
+		return validation.CustomValidationErrors{{Field: "table", Message: "is not registered for purging"}}
+	}
+	return nil
+}
+
+// PurgeDeletedResponse acknowledges an enqueued purge.
+type PurgeDeletedResponse struct {
+	Table  string `json:"table"`
+	TaskID string `json:"task_id"`
+}
+
+// Purge enqueues the purge of the table on the low-priority job queue.
+func (h *SoftDeleteHandler) Purge(c echo.Context, req *PurgeDeletedRequest) (PurgeDeletedResponse, error) {
+	task, err := job.NewPurgeDeletedTask(c.Request().Context(), req.Table)
+	if err != nil {
+		return PurgeDeletedResponse{}, err
+	}
+
+	info, err := enqueueUnique(c.Request().Context(), h.server.Job.Client, task, "purge of this table")
+	if err != nil {
+		return PurgeDeletedResponse{}, err
+	}
+
+	middleware.GetLogger(c).Info().
+		Str("table", req.Table).
+		Str("task_id", info.ID).
+		Msg("soft delete purge enqueued")
+
+	return PurgeDeletedResponse{Table: req.Table, TaskID: info.ID}, nil
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/lib/quota"
+	"github.com/deppfellow/go-boilerplate/internal/middleware"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+// UsageHandler reports the caller's quota usage.
+type UsageHandler struct {
+	Handler
+}
+
+// NewUsageHandler constructs a UsageHandler.
+func NewUsageHandler(s *server.Server) *UsageHandler {
+	return &UsageHandler{
+		Handler: NewHandler(s),
+	}
+}
+
+// GetUsageRequest has no input; it exists to use the typed pipeline.
+type GetUsageRequest struct{}
+
+func (r *GetUsageRequest) Validate() error { return nil }
+
+// UsageResponse is the caller's plan and per-window usage.
+type UsageResponse struct {
+	Plan     string        `json:"plan"`
+	Enforced bool          `json:"enforced"`
+	Windows  []quota.Usage `json:"windows"`
+}
+
+// Usage returns the caller's remaining daily and monthly quota.
+// Reading usage does not consume any.
+func (h *UsageHandler) Usage(c echo.Context, _ *GetUsageRequest) (UsageResponse, error) {
+	cfg := h.server.CurrentConfig().Quota
+	if cfg == nil {
+		cfg = config.DefaultQuotaConfig()
+	}
+
+	subject, plan, limits, ok := middleware.QuotaSubject(c, cfg)
+	if !ok {
+		return UsageResponse{}, errs.NewUnauthorizedError("Unauthorized", false)
+	}
+
+	usage, err := h.server.Quota.Usage(c.Request().Context(), subject, limits, time.Now())
+	if err != nil {
+		return UsageResponse{}, err
+	}
+
+	return UsageResponse{
+		Plan:     plan,
+		Enforced: cfg.Enabled,
+		Windows:  usage,
+	}, nil
+}
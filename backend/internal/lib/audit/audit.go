@@ -0,0 +1,39 @@
+// Package audit defines the audit trail record shared by the HTTP layer
+// (which captures it), the job queue (which carries it) and the repository
+// (which persists it), without those layers importing each other.
+package audit
+
+import "time"
+
+// Entry records who did what, and when, for one mutating request.
+type Entry struct {
+	// UserID is the authenticated subject, empty for anonymous requests.
+	UserID string `json:"user_id"`
+
+	// TenantID is the resolved organization, if any.
+	TenantID string `json:"tenant_id,omitempty"`
+
+	// Method and Route identify the action (route template, e.g. "/api/v1/todos/:id").
+	Method string `json:"method"`
+	Route  string `json:"route"`
+
+	// ResourceIDs are the route path parameters (e.g. {"id": "42"}).
+	ResourceIDs map[string]string `json:"resource_ids,omitempty"`
+
+	// Status is the HTTP status code returned to the client.
+	Status int `json:"status"`
+
+	// RequestID correlates the entry with request logs.
+	RequestID string `json:"request_id"`
+
+	// BodyHash is the hex SHA-256 of the request body: enough to tell whether
+	// two changes carried the same payload without storing (possibly
+	// sensitive) request data.
+	BodyHash string `json:"body_hash,omitempty"`
+
+	// IP is the client IP as resolved by the proxy-aware IP extractor.
+	IP string `json:"ip"`
+
+	// OccurredAt is when the request was received.
+	OccurredAt time.Time `json:"occurred_at"`
+}
@@ -0,0 +1,207 @@
+// Package backfill supports zero-downtime data model changes.
+//
+// The usual expand/contract sequence for moving data to a new column/table:
+//
+//  1. Expand: add the new column (nullable) in a migration.
+//  2. Dual-write: write both old and new columns (repository.Base.DualWrite).
+//  3. Backfill: copy historical rows in small batches (Runner, as a job).
+//  4. Verify: compare old vs new for every row (Verify).
+//  5. Switch reads to the new column, stop writing the old one.
+//  6. Contract: drop the old column in a later migration.
+package backfill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+// BatchFunc processes one batch of at most limit rows with keys greater than
+// afterKey (keyset pagination, "" means "from the start").
+//
+// It runs inside a transaction shared with the progress update, so a batch
+// and its cursor are committed atomically. It returns the key of the last
+// row processed and how many rows it touched; n == 0 means "done".
+type BatchFunc func(ctx context.Context, tx pgx.Tx, afterKey string, limit int) (lastKey string, n int, err error)
+
+// Backfill describes one named data backfill.
+type Backfill struct {
+	// Name identifies the backfill (and its progress row), e.g. "todos_status_v2".
+	Name string
+
+	// BatchSize is the number of rows per transaction. Defaults to 500.
+	BatchSize int
+
+	// Pause is slept between batches to leave headroom for live traffic.
+	Pause time.Duration
+
+	// Batch does the actual copying.
+	Batch BatchFunc
+
+	// Verify optionally describes how to check the result (see Runner.Verify).
+	Verify *VerifySpec
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Backfill{}
+)
+
+// Register makes a backfill runnable by name (e.g. from the job queue).
+// It panics on duplicates, as registrations happen at init time.
+func Register(b Backfill) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[b.Name]; exists {
+		panic(fmt.Sprintf("backfill: %q registered twice", b.Name))
+	}
+	registry[b.Name] = b
+}
+
+// Lookup returns a registered backfill.
+func Lookup(name string) (Backfill, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	b, ok := registry[name]
+	return b, ok
+}
+
+// Names lists registered backfills.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Progress is the persisted state of a backfill.
+type Progress struct {
+	Name          string     `json:"name"`
+	LastKey       string     `json:"last_key"`
+	RowsProcessed int64      `json:"rows_processed"`
+	StartedAt     time.Time  `json:"started_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	CompletedAt   *time.Time `json:"completed_at"`
+}
+
+// Runner executes backfills and persists their progress in backfill_progress.
+type Runner struct {
+	pool   *pgxpool.Pool
+	logger *zerolog.Logger
+}
+
+// NewRunner constructs a Runner.
+func NewRunner(pool *pgxpool.Pool, logger *zerolog.Logger) *Runner {
+	return &Runner{pool: pool, logger: logger}
+}
+
+// Run executes the named backfill until it completes or ctx is canceled,
+// resuming from the persisted cursor. Running a completed backfill is a no-op.
+func (r *Runner) Run(ctx context.Context, name string) (Progress, error) {
+	b, ok := Lookup(name)
+	if !ok {
+		return Progress{}, fmt.Errorf("backfill: unknown backfill %q", name)
+	}
+
+	batchSize := b.BatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	if _, err := r.pool.Exec(ctx,
+		`INSERT INTO backfill_progress (name) VALUES ($1) ON CONFLICT (name) DO NOTHING`, name); err != nil {
+		return Progress{}, fmt.Errorf("backfill: failed to init progress: %w", err)
+	}
+
+	for {
+		progress, err := r.Progress(ctx, name)
+		if err != nil {
+			return Progress{}, err
+		}
+		if progress.CompletedAt != nil {
+			r.logger.Info().
+				Str("backfill", name).
+				Int64("rows_processed", progress.RowsProcessed).
+				Msg("backfill completed")
+			return progress, nil
+		}
+
+		if err := r.runBatch(ctx, b, progress.LastKey, batchSize); err != nil {
+			return progress, err
+		}
+
+		if b.Pause > 0 {
+			select {
+			case <-ctx.Done():
+				return progress, ctx.Err()
+			case <-time.After(b.Pause):
+			}
+		}
+	}
+}
+
+// runBatch processes one batch and advances the cursor in the same transaction.
+func (r *Runner) runBatch(ctx context.Context, b Backfill, afterKey string, limit int) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		lastKey, n, err := b.Batch(ctx, tx, afterKey, limit)
+		if err != nil {
+			return fmt.Errorf("backfill %s: batch after %q failed: %w", b.Name, afterKey, err)
+		}
+
+		if n == 0 {
+			_, err = tx.Exec(ctx,
+				`UPDATE backfill_progress SET completed_at = NOW() WHERE name = $1`, b.Name)
+			return err
+		}
+
+		_, err = tx.Exec(ctx,
+			`UPDATE backfill_progress SET last_key = $2, rows_processed = rows_processed + $3 WHERE name = $1`,
+			b.Name, lastKey, n)
+		if err != nil {
+			return err
+		}
+
+		r.logger.Debug().
+			Str("backfill", b.Name).
+			Str("last_key", lastKey).
+			Int("batch_rows", n).
+			Msg("backfill batch committed")
+		return nil
+	})
+}
+
+// Progress returns the persisted progress of a backfill.
+func (r *Runner) Progress(ctx context.Context, name string) (Progress, error) {
+	var p Progress
+	err := r.pool.QueryRow(ctx, `
+		SELECT name, last_key, rows_processed, started_at, updated_at, completed_at
+		FROM backfill_progress WHERE name = $1`, name).
+		Scan(&p.Name, &p.LastKey, &p.RowsProcessed, &p.StartedAt, &p.UpdatedAt, &p.CompletedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Progress{Name: name}, nil
+	}
+	if err != nil {
+		return Progress{}, fmt.Errorf("backfill: failed to read progress: %w", err)
+	}
+	return p, nil
+}
+
+// Reset forgets a backfill's progress so the next Run starts over.
+func (r *Runner) Reset(ctx context.Context, name string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM backfill_progress WHERE name = $1`, name)
+	return err
+}
@@ -0,0 +1,96 @@
+package backfill
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// VerifySpec describes an old-vs-new comparison.
+//
+// OldExpr and NewExpr are SQL expressions over the table's columns, written
+// by developers (never user input), e.g. OldExpr: "status",
+// NewExpr: "CASE status_v2 WHEN 1 THEN 'open' WHEN 2 THEN 'done' END".
+type VerifySpec struct {
+	Table   string
+	Key     string
+	OldExpr string
+	NewExpr string
+
+	// SampleSize caps how many mismatching keys are returned (default 20).
+	SampleSize int
+}
+
+// VerifyResult summarizes a comparison.
+type VerifyResult struct {
+	TotalRows      int64    `json:"total_rows"`
+	Mismatches     int64    `json:"mismatches"`
+	SampleMismatch []string `json:"sample_mismatch_keys"`
+}
+
+// OK reports whether old and new agree on every row.
+func (r VerifyResult) OK() bool {
+	return r.Mismatches == 0
+}
+
+// Verify compares OldExpr and NewExpr for every row of Table using
+// IS DISTINCT FROM (so NULL vs value counts as a mismatch).
+func Verify(ctx context.Context, pool *pgxpool.Pool, spec VerifySpec) (VerifyResult, error) {
+	sampleSize := spec.SampleSize
+	if sampleSize <= 0 {
+		sampleSize = 20
+	}
+
+	table := pgx.Identifier{spec.Table}.Sanitize()
+	key := pgx.Identifier{spec.Key}.Sanitize()
+	mismatch := fmt.Sprintf("(%s) IS DISTINCT FROM (%s)", spec.OldExpr, spec.NewExpr)
+
+	var result VerifyResult
+	countQuery := fmt.Sprintf(
+		"SELECT COUNT(*), COUNT(*) FILTER (WHERE %s) FROM %s", mismatch, table)
+	if err := pool.QueryRow(ctx, countQuery).Scan(&result.TotalRows, &result.Mismatches); err != nil {
+		return VerifyResult{}, fmt.Errorf("backfill: verify count failed: %w", err)
+	}
+
+	if result.Mismatches == 0 {
+		return result, nil
+	}
+
+	sampleQuery := fmt.Sprintf(
+		"SELECT %s::text FROM %s WHERE %s ORDER BY %s LIMIT %d", key, table, mismatch, key, sampleSize)
+	rows, err := pool.Query(ctx, sampleQuery)
+	if err != nil {
+		return VerifyResult{}, fmt.Errorf("backfill: verify sample failed: %w", err)
+	}
+
+	result.SampleMismatch, err = pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return VerifyResult{}, fmt.Errorf("backfill: verify sample failed: %w", err)
+	}
+	return result, nil
+}
+
+// Verify runs the VerifySpec registered with the named backfill.
+func (r *Runner) Verify(ctx context.Context, name string) (VerifyResult, error) {
+	b, ok := Lookup(name)
+	if !ok {
+		return VerifyResult{}, fmt.Errorf("backfill: unknown backfill %q", name)
+	}
+	if b.Verify == nil {
+		return VerifyResult{}, fmt.Errorf("backfill: %q has no verify spec", name)
+	}
+
+	result, err := Verify(ctx, r.pool, *b.Verify)
+	if err != nil {
+		return VerifyResult{}, err
+	}
+
+	r.logger.Info().
+		Str("backfill", name).
+		Int64("total_rows", result.TotalRows).
+		Int64("mismatches", result.Mismatches).
+		Msg("backfill verification finished")
+	return result, nil
+}
@@ -0,0 +1,199 @@
+// Package cache is a small in-process read-through cache whose entries are
+// grouped in namespaces (usually one per table/aggregate, e.g. "todos").
+//
+// Writes don't update cached values; they invalidate whole namespaces. The
+// invalidation is published on a Redis channel so every instance evicts its
+// own copy, which keeps a fleet of local caches coherent without storing
+// the values in Redis.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// InvalidationChannel is the Redis pub/sub channel carrying invalidations.
+const InvalidationChannel = "boilerplate:cache:invalidate"
+
+type entry struct {
+	value     any
+	expiresAt time.Time
+}
+
+// invalidation is the pub/sub message payload.
+type invalidation struct {
+	// Origin is the publishing instance; it already evicted locally.
+	Origin     string   `json:"origin"`
+	Namespaces []string `json:"namespaces"`
+}
+
+// Cache is a namespaced in-process TTL cache with cross-instance invalidation.
+//
+// A Cache with a nil Redis client still works, but invalidations only reach
+// the local instance.
+type Cache struct {
+	redis      *redis.Client
+	logger     *zerolog.Logger
+	instanceID string
+
+	mu      sync.RWMutex
+	entries map[string]map[string]entry
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New creates a Cache. Call Start to receive invalidations from other instances.
+func New(client *redis.Client, logger *zerolog.Logger) *Cache {
+	return &Cache{
+		redis:      client,
+		logger:     logger,
+		instanceID: uuid.NewString(),
+		entries:    map[string]map[string]entry{},
+	}
+}
+
+// Get returns a cached value if present and not expired.
+func (c *Cache) Get(namespace, key string) (any, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	e, ok := c.entries[namespace][key]
+	if !ok || time.Now().After(e.expiresAt) {
+		return nil, false
+	}
+	return e.value, true
+}
+
+// Set stores value under namespace/key for ttl.
+func (c *Cache) Set(namespace, key string, value any, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ns, ok := c.entries[namespace]
+	if !ok {
+		ns = map[string]entry{}
+		c.entries[namespace] = ns
+	}
+	ns[key] = entry{value: value, expiresAt: time.Now().Add(ttl)}
+}
+
+// GetOrLoad returns the cached value or calls load and caches its result.
+//
+// Errors are not cached. Concurrent misses may call load more than once;
+// that's acceptable for a read-through cache in front of Postgres.
+func GetOrLoad[T any](
+	ctx context.Context,
+	c *Cache,
+	namespace, key string,
+	ttl time.Duration,
+	load func(ctx context.Context) (T, error),
+) (T, error) {
+	if v, ok := c.Get(namespace, key); ok {
+		if typed, ok := v.(T); ok {
+			return typed, nil
+		}
+	}
+
+	value, err := load(ctx)
+	if err != nil {
+		return value, err
+	}
+
+	c.Set(namespace, key, value, ttl)
+	return value, nil
+}
+
+// EvictLocal drops namespaces from this instance only.
+func (c *Cache) EvictLocal(namespaces ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, ns := range namespaces {
+		delete(c.entries, ns)
+	}
+}
+
+// Invalidate evicts namespaces locally and tells every other instance to do
+// the same.
+//
+// The local eviction always happens; the returned error only reports a
+// failed publish (other instances then serve stale data until TTL expiry).
+func (c *Cache) Invalidate(ctx context.Context, namespaces ...string) error {
+	if len(namespaces) == 0 {
+		return nil
+	}
+
+	c.EvictLocal(namespaces...)
+
+	if c.redis == nil {
+		return nil
+	}
+
+	payload, err := json.Marshal(invalidation{Origin: c.instanceID, Namespaces: namespaces})
+	if err != nil {
+		return err
+	}
+	return c.redis.Publish(ctx, InvalidationChannel, payload).Err()
+}
+
+// Start subscribes to invalidations from other instances in a background
+// goroutine. It is a no-op without Redis.
+func (c *Cache) Start() {
+	if c.redis == nil || c.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+
+	sub := c.redis.Subscribe(ctx, InvalidationChannel)
+
+	go func() {
+		defer close(c.done)
+		defer sub.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-sub.Channel():
+				if !ok {
+					return
+				}
+				c.handleMessage(msg.Payload)
+			}
+		}
+	}()
+}
+
+func (c *Cache) handleMessage(payload string) {
+	var inv invalidation
+	if err := json.Unmarshal([]byte(payload), &inv); err != nil {
+		c.logger.Warn().Err(err).Msg("ignoring malformed cache invalidation")
+		return
+	}
+
+	if inv.Origin == c.instanceID {
+		return
+	}
+
+	c.EvictLocal(inv.Namespaces...)
+	c.logger.Debug().Strs("namespaces", inv.Namespaces).Msg("cache namespaces invalidated by peer")
+}
+
+// Close stops the subscription goroutine.
+func (c *Cache) Close() {
+	if c.cancel == nil {
+		return
+	}
+	c.cancel()
+	<-c.done
+}
@@ -0,0 +1,181 @@
+// Package crypto encrypts sensitive columns (PII) at rest, field by field.
+//
+// Models use EncryptedString or EncryptedJSON for such fields; pgx encrypts
+// them when they are written and decrypts them when they are scanned, so
+// repositories read and write them like plain values:
+//
+//	type Customer struct {
+//		ID      uuid.UUID                     `db:"id"`
+//		Email   crypto.EncryptedString        `db:"email"`
+//		Phone   *crypto.EncryptedString       `db:"phone"` // nullable
+//		Address crypto.EncryptedJSON[Address] `db:"address"`
+//	}
+//
+// Encrypted columns are TEXT. Values are AES-256-GCM with a random nonce, so
+// equal plaintexts encrypt differently: encrypted columns can't be searched,
+// sorted or uniquely indexed. Store a keyed hash alongside when a lookup
+// by value is needed.
+//
+// Every ciphertext names the key that produced it, which is what makes key
+// rotation possible: new values use the primary key while older ones still
+// decrypt with theirs (see config.EncryptionConfig).
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+)
+
+// prefix starts every ciphertext, versioning the format:
+//
+//	enc:v1:<key id>:<base64url(nonce || sealed)>
+const prefix = "enc:v1:"
+
+var (
+	// ErrNoKeyring is returned when an encrypted value is read or written
+	// before SetDefault (encryption.enabled is false).
+	ErrNoKeyring = errors.New("field encryption is not configured")
+
+	// ErrUnknownKey is returned for a ciphertext whose key is not loaded.
+	ErrUnknownKey = errors.New("unknown encryption key")
+
+	// ErrMalformed is returned for values that are not ciphertexts of this
+	// package, or fail authentication (tampered or wrong key).
+	ErrMalformed = errors.New("malformed ciphertext")
+)
+
+// Keyring encrypts with its primary key and decrypts with any of its keys.
+type Keyring struct {
+	primary string
+	aeads   map[string]cipher.AEAD
+}
+
+// NewKeyring builds a keyring from raw 32-byte keys by ID. Keys fetched
+// from a KMS (or decrypted with one at startup) are passed here as well.
+func NewKeyring(primaryID string, keys map[string][]byte) (*Keyring, error) {
+	k := &Keyring{primary: primaryID, aeads: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid key ID %q", id)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		k.aeads[id] = aead
+	}
+
+	if _, ok := k.aeads[primaryID]; !ok {
+		return nil, fmt.Errorf("primary key %q is not among the keys", primaryID)
+	}
+	return k, nil
+}
+
+// New builds the keyring of the encryption config.
+func New(cfg *config.EncryptionConfig) (*Keyring, error) {
+	keys, err := cfg.DecodeKeys()
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyring(cfg.PrimaryKeyID, keys)
+}
+
+// Encrypt seals plaintext with the primary key.
+func (k *Keyring) Encrypt(plaintext []byte) (string, error) {
+	aead := k.aeads[k.primary]
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	// The key ID is authenticated too, so it can't be swapped.
+	sealed := aead.Seal(nonce, nonce, plaintext, []byte(k.primary))
+	return prefix + k.primary + ":" + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a ciphertext produced by Encrypt with any key of k.
+func (k *Keyring) Decrypt(ciphertext string) ([]byte, error) {
+	id, data, err := split(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, ok := k.aeads[id]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, id)
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+
+	nonce, sealed := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, []byte(id))
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	return plaintext, nil
+}
+
+// NeedsRotation reports whether ciphertext was encrypted with another key
+// than the primary one. Re-encrypting such rows is a matter of reading and
+// writing them back (e.g. from a lib/backfill job); once none is left, the
+// old key can be removed from the config.
+func (k *Keyring) NeedsRotation(ciphertext string) bool {
+	id, err := KeyID(ciphertext)
+	return err == nil && id != k.primary
+}
+
+// KeyID returns the ID of the key that encrypted ciphertext.
+func KeyID(ciphertext string) (string, error) {
+	id, _, err := split(ciphertext)
+	return id, err
+}
+
+// split parses "enc:v1:<id>:<data>".
+func split(ciphertext string) (string, []byte, error) {
+	rest, ok := strings.CutPrefix(ciphertext, prefix)
+	if !ok {
+		return "", nil, ErrMalformed
+	}
+	id, encoded, ok := strings.Cut(rest, ":")
+	if !ok || id == "" {
+		return "", nil, ErrMalformed
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", nil, ErrMalformed
+	}
+	return id, data, nil
+}
+
+// defaultKeyring is used by the column types, which pgx encodes and scans
+// without a way to pass dependencies along.
+var defaultKeyring atomic.Pointer[Keyring]
+
+// SetDefault installs the keyring used by EncryptedString and EncryptedJSON.
+// The server sets it at startup when encryption is enabled.
+func SetDefault(k *Keyring) {
+	defaultKeyring.Store(k)
+}
+
+// Default returns the installed keyring, or ErrNoKeyring.
+func Default() (*Keyring, error) {
+	if k := defaultKeyring.Load(); k != nil {
+		return k, nil
+	}
+	return nil, ErrNoKeyring
+}
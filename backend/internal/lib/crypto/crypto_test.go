@@ -0,0 +1,125 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) []byte { return bytes.Repeat([]byte{b}, 32) }
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	k, err := NewKeyring("k1", map[string][]byte{"k1": testKey(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, plaintext := range []string{"", "a@example.com", strings.Repeat("é", 1000)} {
+		ciphertext, err := k.Encrypt([]byte(plaintext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(ciphertext, "enc:v1:k1:") {
+			t.Fatalf("ciphertext %q doesn't name its key", ciphertext)
+		}
+
+		got, err := k.Decrypt(ciphertext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != plaintext {
+			t.Fatalf("round trip returned %q, want %q", got, plaintext)
+		}
+	}
+}
+
+func TestEncryptIsRandomized(t *testing.T) {
+	k, _ := NewKeyring("k1", map[string][]byte{"k1": testKey(1)})
+
+	a, _ := k.Encrypt([]byte("same"))
+	b, _ := k.Encrypt([]byte("same"))
+	if a == b {
+		t.Fatal("equal plaintexts produced equal ciphertexts")
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	old, _ := NewKeyring("k1", map[string][]byte{"k1": testKey(1)})
+	ciphertext, _ := old.Encrypt([]byte("secret"))
+
+	// k2 is the new primary; k1 stays loaded until every row is rewritten.
+	rotated, err := NewKeyring("k2", map[string][]byte{"k1": testKey(1), "k2": testKey(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := rotated.Decrypt(ciphertext)
+	if err != nil || string(got) != "secret" {
+		t.Fatalf("old ciphertext after rotation: %q, %v", got, err)
+	}
+	if !rotated.NeedsRotation(ciphertext) {
+		t.Fatal("ciphertext of the old key doesn't need rotation")
+	}
+
+	rewritten, _ := rotated.Encrypt(got)
+	if id, _ := KeyID(rewritten); id != "k2" {
+		t.Fatalf("rewritten with key %q, want k2", id)
+	}
+	if rotated.NeedsRotation(rewritten) {
+		t.Fatal("ciphertext of the primary key needs rotation")
+	}
+
+	// Once k1 is dropped, its ciphertexts can't be read anymore.
+	dropped, _ := NewKeyring("k2", map[string][]byte{"k2": testKey(2)})
+	if _, err := dropped.Decrypt(ciphertext); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("got %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestDecryptRejectsTampering(t *testing.T) {
+	k, _ := NewKeyring("k1", map[string][]byte{"k1": testKey(1), "k2": testKey(2)})
+	ciphertext, _ := k.Encrypt([]byte("secret"))
+
+	tests := map[string]string{
+		"not a ciphertext": "secret",
+		"bad base64":       "enc:v1:k1:***",
+		"too short":        "enc:v1:k1:AAAA",
+		"flipped byte":     ciphertext[:len(ciphertext)-2] + flip(ciphertext[len(ciphertext)-2]) + ciphertext[len(ciphertext)-1:],
+		// The key ID is authenticated: relabeling fails even if k2 is loaded.
+		"relabeled key": strings.Replace(ciphertext, ":k1:", ":k2:", 1),
+	}
+	for name, tampered := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := k.Decrypt(tampered); !errors.Is(err, ErrMalformed) {
+				t.Fatalf("got %v, want ErrMalformed", err)
+			}
+		})
+	}
+}
+
+func TestNewKeyringValidatesKeys(t *testing.T) {
+	tests := map[string]struct {
+		primary string
+		keys    map[string][]byte
+	}{
+		"missing primary": {"k2", map[string][]byte{"k1": testKey(1)}},
+		"short key":       {"k1", map[string][]byte{"k1": []byte("short")}},
+		"colon in ID":     {"a:b", map[string][]byte{"a:b": testKey(1)}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewKeyring(tt.primary, tt.keys); err == nil {
+				t.Fatal("invalid keyring accepted")
+			}
+		})
+	}
+}
+
+// flip returns another base64url character than c.
+func flip(c byte) string {
+	if c == 'A' {
+		return "B"
+	}
+	return "A"
+}
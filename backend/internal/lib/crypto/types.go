@@ -0,0 +1,104 @@
+package crypto
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// EncryptedString is a string stored encrypted in a TEXT column. It
+// implements pgtype.TextValuer and pgtype.TextScanner, so pgx encrypts it
+// on write and decrypts it on scan with the default keyring. Use a pointer
+// for nullable columns: NULL scans into nil.
+//
+// In Go it is the plaintext: JSON responses and logs show the value, so
+// keep it out of log fields like any other PII.
+type EncryptedString string
+
+// TextValue encrypts s.
+func (s EncryptedString) TextValue() (pgtype.Text, error) {
+	return encryptText([]byte(s))
+}
+
+// ScanText decrypts a stored value.
+func (s *EncryptedString) ScanText(v pgtype.Text) error {
+	plaintext, err := decryptText(v)
+	if err != nil {
+		return err
+	}
+	*s = EncryptedString(plaintext)
+	return nil
+}
+
+// EncryptedJSON is a value of type T stored as encrypted JSON in a TEXT
+// column (it can't be queried with JSON operators, unlike JSONB). NULL
+// scans into the zero value.
+type EncryptedJSON[T any] struct {
+	V T
+}
+
+// TextValue marshals and encrypts j.V.
+func (j EncryptedJSON[T]) TextValue() (pgtype.Text, error) {
+	data, err := json.Marshal(j.V)
+	if err != nil {
+		return pgtype.Text{}, fmt.Errorf("failed to marshal encrypted JSON: %w", err)
+	}
+	return encryptText(data)
+}
+
+// ScanText decrypts and unmarshals a stored value.
+func (j *EncryptedJSON[T]) ScanText(v pgtype.Text) error {
+	plaintext, err := decryptText(v)
+	if err != nil {
+		return err
+	}
+
+	var value T
+	if plaintext != nil {
+		if err := json.Unmarshal(plaintext, &value); err != nil {
+			return fmt.Errorf("failed to unmarshal encrypted JSON: %w", err)
+		}
+	}
+	j.V = value
+	return nil
+}
+
+// MarshalJSON encodes the plaintext value, so the type is transparent in
+// API responses.
+func (j EncryptedJSON[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(j.V)
+}
+
+// UnmarshalJSON decodes the plaintext value of request bodies.
+func (j *EncryptedJSON[T]) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &j.V)
+}
+
+func encryptText(plaintext []byte) (pgtype.Text, error) {
+	k, err := Default()
+	if err != nil {
+		return pgtype.Text{}, err
+	}
+	ciphertext, err := k.Encrypt(plaintext)
+	if err != nil {
+		return pgtype.Text{}, err
+	}
+	return pgtype.Text{String: ciphertext, Valid: true}, nil
+}
+
+// decryptText returns nil for NULL.
+func decryptText(v pgtype.Text) ([]byte, error) {
+	if !v.Valid {
+		return nil, nil
+	}
+	k, err := Default()
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := k.Decrypt(v.String)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt column: %w", err)
+	}
+	return plaintext, nil
+}
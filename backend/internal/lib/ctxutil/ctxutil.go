@@ -0,0 +1,108 @@
+// Package ctxutil carries request-scoped values (logger, user, request ID,
+// tenant, background task ID, locale) on a plain context.Context.
+//
+// Echo middleware stores these values in echo.Context for handlers, and
+// mirrors them into the request's context.Context through this package.
+// Services, repositories and jobs only receive a context.Context, so they use
+// the FromContext-style accessors here to log with correlation fields without
+// importing Echo:
+//
+//	func (r *TodoRepository) Create(ctx context.Context, ...) error {
+//		ctxutil.Logger(ctx).Info().Msg("creating todo") // carries request_id, user_id, ...
+//	}
+//
+// Keys are unexported typed values, so no other package can collide with (or
+// overwrite) them by accident, which plain string keys cannot guarantee.
+package ctxutil
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+)
+
+// key is the private type for context keys defined by this package.
+type key int
+
+const (
+	loggerKey key = iota
+	userIDKey
+	requestIDKey
+	tenantKey
+	taskIDKey
+	localeKey
+)
+
+// WithLogger returns a copy of ctx carrying logger.
+func WithLogger(ctx context.Context, logger *zerolog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// Logger returns the request-scoped logger stored in ctx.
+//
+// Without one (e.g. background code not started from a request) it returns a
+// no-op logger, matching middleware.GetLogger, so callers never nil-check.
+func Logger(ctx context.Context) *zerolog.Logger {
+	if logger, ok := ctx.Value(loggerKey).(*zerolog.Logger); ok && logger != nil {
+		return logger
+	}
+
+	logger := zerolog.Nop()
+	return &logger
+}
+
+// WithUserID returns a copy of ctx carrying the authenticated user ID.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// UserID returns the authenticated user ID, or "" for anonymous requests.
+func UserID(ctx context.Context) string {
+	userID, _ := ctx.Value(userIDKey).(string)
+	return userID
+}
+
+// WithRequestID returns a copy of ctx carrying the request correlation ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the request correlation ID, or "" outside a request.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// WithTenant returns a copy of ctx carrying the tenant (organization) ID.
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey, tenantID)
+}
+
+// Tenant returns the tenant ID, or "" if tenancy was not resolved.
+func Tenant(ctx context.Context) string {
+	tenantID, _ := ctx.Value(tenantKey).(string)
+	return tenantID
+}
+
+// WithTaskID returns a copy of ctx carrying the ID of the background job
+// being processed (set by the job worker for every task).
+func WithTaskID(ctx context.Context, taskID string) context.Context {
+	return context.WithValue(ctx, taskIDKey, taskID)
+}
+
+// TaskID returns the background job ID, or "" outside a job.
+func TaskID(ctx context.Context) string {
+	taskID, _ := ctx.Value(taskIDKey).(string)
+	return taskID
+}
+
+// WithLocale returns a copy of ctx carrying the request locale (BCP 47 tag).
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey, locale)
+}
+
+// Locale returns the request locale, or "" when it was not resolved.
+func Locale(ctx context.Context) string {
+	locale, _ := ctx.Value(localeKey).(string)
+	return locale
+}
@@ -0,0 +1,218 @@
+// Package dependency tracks the health of external dependencies (database,
+// Redis, email provider, ...) so the app can degrade gracefully.
+//
+// Dependencies are either probed actively (a Check run every interval) or
+// reported passively by the code using them (Report after each call), or
+// both. Handlers and services consult the tracker through server.Require and
+// server.Optional instead of letting a dead dependency surface as a timeout
+// or a generic 500.
+package dependency
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// DefaultInterval is how often active checks run.
+const DefaultInterval = 15 * time.Second
+
+// Check probes a dependency; nil means healthy.
+type Check func(ctx context.Context) error
+
+// Status is the last known state of a dependency.
+type Status struct {
+	Name     string `json:"name"`
+	Required bool   `json:"required"`
+	Healthy  bool   `json:"healthy"`
+
+	// Since is when the dependency entered its current state.
+	Since time.Time `json:"since"`
+
+	// LastError is the error that marked it unhealthy ("" when healthy).
+	LastError string `json:"last_error,omitempty"`
+}
+
+type entry struct {
+	status Status
+	check  Check
+
+	// retryAt is when a passively reported dependency that is down lets the
+	// next call through again (see Healthy).
+	retryAt time.Time
+}
+
+// Tracker holds dependency states. The zero value is not usable; use NewTracker.
+type Tracker struct {
+	mu       sync.RWMutex
+	deps     map[string]*entry
+	interval time.Duration
+	logger   *zerolog.Logger
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewTracker creates a Tracker running active checks every interval
+// (DefaultInterval when <= 0) once started.
+func NewTracker(logger *zerolog.Logger, interval time.Duration) *Tracker {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	return &Tracker{
+		deps:     make(map[string]*entry),
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Register adds a dependency, healthy until proven otherwise.
+//
+// required marks dependencies the service cannot work without (they make
+// the health endpoint report unhealthy); the others only make it degraded.
+// check may be nil for passively reported dependencies.
+func (t *Tracker) Register(name string, required bool, check Check) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.deps[name] = &entry{
+		status: Status{Name: name, Required: required, Healthy: true, Since: time.Now()},
+		check:  check,
+	}
+}
+
+// Report records the outcome of a real call to the dependency (nil = it worked).
+// Unknown names are ignored.
+func (t *Tracker) Report(name string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e, ok := t.deps[name]
+	if !ok {
+		return
+	}
+
+	healthy := err == nil
+	if !healthy {
+		e.retryAt = time.Now().Add(t.interval)
+	}
+	if healthy == e.status.Healthy {
+		if err != nil {
+			e.status.LastError = err.Error()
+		}
+		return
+	}
+
+	e.status.Healthy = healthy
+	e.status.Since = time.Now()
+	e.status.LastError = ""
+	e.retryAt = time.Time{}
+
+	if healthy {
+		t.logger.Info().Str("dependency", name).Msg("dependency recovered")
+		return
+	}
+
+	e.status.LastError = err.Error()
+	t.logger.Error().Err(err).Str("dependency", name).Bool("required", e.status.Required).Msg("dependency unavailable")
+}
+
+// Healthy reports whether name is up. Unknown dependencies count as healthy,
+// so guarding on a name that was never registered never blocks requests.
+//
+// A passively reported dependency (no Check) only recovers through a Report,
+// which needs a real call; callers gating on Healthy would never make one.
+// So once per interval after its last failure it is half-open: Healthy
+// returns true for a single caller, whose Report then either recovers it or
+// closes it for another interval.
+func (t *Tracker) Healthy(name string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e, ok := t.deps[name]
+	if !ok || e.status.Healthy {
+		return true
+	}
+
+	if e.check == nil && !time.Now().Before(e.retryAt) {
+		e.retryAt = time.Now().Add(t.interval)
+		return true
+	}
+	return false
+}
+
+// RetryAfter suggests how long clients should wait before retrying a request
+// that failed on a down dependency: one check interval.
+func (t *Tracker) RetryAfter() time.Duration {
+	return t.interval
+}
+
+// Statuses returns every dependency's state, sorted by name.
+func (t *Tracker) Statuses() []Status {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	statuses := make([]Status, 0, len(t.deps))
+	for _, e := range t.deps {
+		statuses = append(statuses, e.status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Start runs the active checks in the background until Close.
+func (t *Tracker) Start() {
+	t.stop = make(chan struct{})
+	t.done = make(chan struct{})
+
+	go func() {
+		defer close(t.done)
+
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+
+		for {
+			t.runChecks()
+
+			select {
+			case <-t.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Close stops the background checks.
+func (t *Tracker) Close() {
+	if t.stop == nil {
+		return
+	}
+	close(t.stop)
+	<-t.done
+	t.stop = nil
+}
+
+// runChecks probes every actively checked dependency once.
+func (t *Tracker) runChecks() {
+	t.mu.RLock()
+	checks := make(map[string]Check, len(t.deps))
+	for name, e := range t.deps {
+		if e.check != nil {
+			checks[name] = e.check
+		}
+	}
+	t.mu.RUnlock()
+
+	for name, check := range checks {
+		ctx, cancel := context.WithTimeout(context.Background(), t.interval/2)
+		err := check(ctx)
+		cancel()
+
+		t.Report(name, err)
+	}
+}
@@ -0,0 +1,184 @@
+// Package embedding turns text into vectors and stores them in Postgres
+// (pgvector), for semantic search and "similar items" features.
+//
+// The moving parts mirror lib/search:
+//
+//  1. A Source describes which rows get an embedding: how to read their
+//     text and which vector column to write (Register, at init time).
+//  2. Repository writes declare what changed with repository.Base.Reembed.
+//     After COMMIT this enqueues an embedding:embed job, whose handler
+//     re-reads the text, calls the Provider and stores the vectors.
+//  3. A full re-embed (embedding:reembed job, admin endpoint) walks the whole
+//     Source one batch per task, e.g. after switching models.
+//  4. Queries use repository.Base.NearestNeighbors with a query vector from
+//     server.Embeddings.
+//
+// Vectors are sent to Postgres in pgvector's text form ("[0.1,0.2]") and cast
+// with ::vector, so no pgvector driver package is needed.
+package embedding
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Vector is one embedding.
+type Vector []float32
+
+// String returns v in pgvector's text form.
+func (v Vector) String() string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, f := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(f), 'f', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// Value implements driver.Valuer; use it with a ::vector cast ($1::vector).
+func (v Vector) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return v.String(), nil
+}
+
+// Scan implements sql.Scanner for vector columns read as text (col::text).
+func (v *Vector) Scan(src any) error {
+	var s string
+	switch x := src.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case string:
+		s = x
+	case []byte:
+		s = string(x)
+	default:
+		return fmt.Errorf("embedding: cannot scan %T into Vector", src)
+	}
+
+	s = strings.TrimSpace(s)
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	if s == "" {
+		*v = Vector{}
+		return nil
+	}
+
+	parts := strings.Split(s, ",")
+	out := make(Vector, len(parts))
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 32)
+		if err != nil {
+			return fmt.Errorf("embedding: invalid vector component %q: %w", p, err)
+		}
+		out[i] = float32(f)
+	}
+	*v = out
+	return nil
+}
+
+// Provider turns texts into vectors of a fixed size.
+type Provider interface {
+	// Embed returns one vector per text, in order.
+	Embed(ctx context.Context, texts []string) ([]Vector, error)
+
+	// Dimensions is the size of the vectors returned by Embed.
+	Dimensions() int
+}
+
+// Item is the text of one row to embed.
+type Item struct {
+	ID   string
+	Text string
+}
+
+// LoadFunc returns the items of the given primary keys. Rows that no longer
+// exist (or have no text) are left out; their vector is left untouched.
+type LoadFunc func(ctx context.Context, db *pgxpool.Pool, ids []string) ([]Item, error)
+
+// BatchFunc returns up to limit items with primary keys greater than afterID
+// ("" = from the start), in key order, and the last key returned. An empty
+// batch means the source is exhausted.
+type BatchFunc func(ctx context.Context, db *pgxpool.Pool, afterID string, limit int) (items []Item, lastID string, err error)
+
+// Source describes the embeddings of one table:
+//
+//	embedding.Register(embedding.Source{
+//		Name:   "todos",
+//		Table:  "todos",
+//		Column: "embedding", // vector(1536), see config.EmbeddingConfig.Dimensions
+//		Load:   loadTodoTexts,
+//		Batch:  batchTodoTexts,
+//	})
+type Source struct {
+	// Name identifies the source in jobs and admin endpoints.
+	Name string
+
+	// Table, IDColumn (default "id") and Column locate the vector column
+	// the embeddings are written to.
+	Table    string
+	IDColumn string
+	Column   string
+
+	// Load (changed rows) and Batch (full re-embed) read the texts.
+	Load  LoadFunc
+	Batch BatchFunc
+}
+
+func (s Source) idColumn() string {
+	if s.IDColumn == "" {
+		return "id"
+	}
+	return s.IDColumn
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Source{}
+)
+
+// Register makes a source embeddable by name. It panics on duplicates, as
+// registrations happen at init time.
+func Register(s Source) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[s.Name]; exists {
+		panic(fmt.Sprintf("embedding: source %q registered twice", s.Name))
+	}
+	registry[s.Name] = s
+}
+
+// Lookup returns a registered source.
+func Lookup(name string) (Source, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	s, ok := registry[name]
+	return s, ok
+}
+
+// Names lists registered sources.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
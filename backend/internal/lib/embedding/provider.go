@@ -0,0 +1,169 @@
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/lib/httpclient"
+)
+
+// New returns the Provider configured by cfg.
+func New(cfg *config.EmbeddingConfig) (Provider, error) {
+	httpCfg := httpclient.DefaultConfig()
+	httpCfg.Timeout = cfg.Timeout
+	client := httpclient.New(httpCfg)
+
+	switch cfg.Provider {
+	case config.EmbeddingProviderOpenAI:
+		return &OpenAI{
+			baseURL:    baseURL(cfg.URL, "https://api.openai.com"),
+			apiKey:     cfg.APIKey,
+			model:      cfg.Model,
+			dimensions: cfg.Dimensions,
+			client:     client,
+		}, nil
+	case config.EmbeddingProviderLocal:
+		return &Ollama{
+			baseURL:    baseURL(cfg.URL, "http://localhost:11434"),
+			model:      cfg.Model,
+			dimensions: cfg.Dimensions,
+			client:     client,
+		}, nil
+	default:
+		return nil, fmt.Errorf("embedding: unknown provider %q", cfg.Provider)
+	}
+}
+
+func baseURL(configured, fallback string) string {
+	if configured == "" {
+		return fallback
+	}
+	return strings.TrimRight(configured, "/")
+}
+
+// OpenAI calls the /v1/embeddings API of OpenAI or a compatible server.
+type OpenAI struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	dimensions int
+	client     *httpclient.Client
+}
+
+func (p *OpenAI) Dimensions() int { return p.dimensions }
+
+func (p *OpenAI) Embed(ctx context.Context, texts []string) ([]Vector, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	var resp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	err := postJSON(ctx, p.client, p.baseURL+"/v1/embeddings", "Bearer "+p.apiKey, map[string]any{
+		"model": p.model,
+		"input": texts,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	vectors := make([]Vector, len(texts))
+	for _, d := range resp.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding: openai returned index %d for %d inputs", d.Index, len(texts))
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return checkVectors(vectors, p.dimensions)
+}
+
+// Ollama calls the /api/embed API of a local Ollama server.
+type Ollama struct {
+	baseURL    string
+	model      string
+	dimensions int
+	client     *httpclient.Client
+}
+
+func (p *Ollama) Dimensions() int { return p.dimensions }
+
+func (p *Ollama) Embed(ctx context.Context, texts []string) ([]Vector, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	var resp struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	err := postJSON(ctx, p.client, p.baseURL+"/api/embed", "", map[string]any{
+		"model": p.model,
+		"input": texts,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("embedding: ollama returned %d vectors for %d inputs", len(resp.Embeddings), len(texts))
+	}
+
+	vectors := make([]Vector, len(texts))
+	for i, e := range resp.Embeddings {
+		vectors[i] = e
+	}
+	return checkVectors(vectors, p.dimensions)
+}
+
+// checkVectors rejects missing vectors and vectors of the wrong size, which
+// Postgres would refuse anyway, with a clearer message (usually the model
+// and embedding.dimensions disagree).
+func checkVectors(vectors []Vector, dimensions int) ([]Vector, error) {
+	for i, v := range vectors {
+		if len(v) != dimensions {
+			return nil, fmt.Errorf("embedding: vector %d has %d dimensions, want %d (check embedding.model and embedding.dimensions)", i, len(v), dimensions)
+		}
+	}
+	return vectors, nil
+}
+
+// postJSON posts body as JSON and decodes the response into out.
+func postJSON(ctx context.Context, client *httpclient.Client, url, authorization string, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("embedding: failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("embedding: failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("embedding: POST %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("embedding: POST %s: status %d: %s", url, resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("embedding: failed to decode response: %w", err)
+	}
+	return nil
+}
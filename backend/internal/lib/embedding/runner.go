@@ -0,0 +1,112 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+// Runner embeds the texts of Sources and writes the vectors to Postgres.
+type Runner struct {
+	provider  Provider
+	pool      *pgxpool.Pool
+	logger    *zerolog.Logger
+	batchSize int
+}
+
+// NewRunner constructs a Runner sending batchSize texts per provider request.
+func NewRunner(provider Provider, pool *pgxpool.Pool, logger *zerolog.Logger, batchSize int) *Runner {
+	return &Runner{
+		provider:  provider,
+		pool:      pool,
+		logger:    logger,
+		batchSize: batchSize,
+	}
+}
+
+// BatchSize is the number of rows a re-embed task handles.
+func (r *Runner) BatchSize() int {
+	return r.batchSize
+}
+
+// Embed (re)computes the embeddings of the given rows. It is what the
+// embedding:embed job runs, and is safe to repeat.
+func (r *Runner) Embed(ctx context.Context, name string, ids []string) error {
+	source, ok := Lookup(name)
+	if !ok {
+		return fmt.Errorf("embedding: unknown source %q", name)
+	}
+
+	items, err := source.Load(ctx, r.pool, ids)
+	if err != nil {
+		return fmt.Errorf("embedding: failed to load %s texts: %w", name, err)
+	}
+
+	for start := 0; start < len(items); start += r.batchSize {
+		end := min(start+r.batchSize, len(items))
+		if err := r.embedItems(ctx, source, items[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EmbedBatch embeds the next batch of a full re-embed, starting after
+// afterID. done is true once the source is exhausted; otherwise the caller
+// continues from lastID.
+func (r *Runner) EmbedBatch(ctx context.Context, name, afterID string) (lastID string, done bool, err error) {
+	source, ok := Lookup(name)
+	if !ok {
+		return "", false, fmt.Errorf("embedding: unknown source %q", name)
+	}
+
+	items, lastID, err := source.Batch(ctx, r.pool, afterID, r.batchSize)
+	if err != nil {
+		return "", false, fmt.Errorf("embedding: failed to read %s batch: %w", name, err)
+	}
+	if len(items) == 0 {
+		return afterID, true, nil
+	}
+
+	if err := r.embedItems(ctx, source, items); err != nil {
+		return "", false, err
+	}
+	return lastID, false, nil
+}
+
+// embedItems calls the provider once and writes the vectors in one batch.
+func (r *Runner) embedItems(ctx context.Context, source Source, items []Item) error {
+	texts := make([]string, len(items))
+	for i, item := range items {
+		texts[i] = item.Text
+	}
+
+	vectors, err := r.provider.Embed(ctx, texts)
+	if err != nil {
+		return err
+	}
+
+	update := fmt.Sprintf(
+		"UPDATE %s SET %s = $2::vector WHERE %s::text = $1",
+		pgx.Identifier{source.Table}.Sanitize(),
+		pgx.Identifier{source.Column}.Sanitize(),
+		pgx.Identifier{source.idColumn()}.Sanitize(),
+	)
+
+	batch := &pgx.Batch{}
+	for i, item := range items {
+		batch.Queue(update, item.ID, vectors[i])
+	}
+	if err := r.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("embedding: failed to store %s vectors: %w", source.Name, err)
+	}
+
+	r.logger.Debug().
+		Str("source", source.Name).
+		Int("rows", len(items)).
+		Msg("embeddings stored")
+	return nil
+}
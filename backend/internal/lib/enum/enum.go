@@ -0,0 +1,223 @@
+// Package enum defines string enums once and derives everything else from
+// that single definition: parsing/JSON validation, the validator "enum" tag,
+// OpenAPI schema fragments and pgx registration of Postgres ENUM types.
+//
+// Typical usage:
+//
+//	type TodoStatus string
+//
+//	const (
+//		TodoStatusOpen TodoStatus = "open"
+//		TodoStatusDone TodoStatus = "done"
+//	)
+//
+//	var TodoStatuses = enum.New("todo_status", TodoStatusOpen, TodoStatusDone).
+//		WithPgType("todo_status") // matches CREATE TYPE todo_status AS ENUM (...)
+//
+//	func (s *TodoStatus) UnmarshalJSON(b []byte) error { return TodoStatuses.DecodeJSON(b, s) }
+//
+// and in request structs:
+//
+//	Status TodoStatus `json:"status" validate:"required,enum=todo_status"`
+package enum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5"
+)
+
+// Definition is the type-erased view of an enum used by the registry,
+// validators, docs generation and the database layer.
+type Definition interface {
+	// Name is the registry name, used in `validate:"enum=<name>"`.
+	Name() string
+
+	// PgType is the Postgres ENUM type name, or "" for TEXT + CHECK columns.
+	PgType() string
+
+	// Strings returns the allowed values in declaration order.
+	Strings() []string
+
+	// OpenAPISchema returns {"type": "string", "enum": [...]}.
+	OpenAPISchema() map[string]any
+}
+
+// Enum is a set of allowed values for the string type T.
+type Enum[T ~string] struct {
+	name   string
+	pgType string
+	values []T
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Definition{}
+)
+
+// New defines and registers an enum. It panics on an empty or duplicate
+// name, because enums are package-level vars and a clash is a programming
+// error that must surface at startup.
+func New[T ~string](name string, values ...T) *Enum[T] {
+	if name == "" {
+		panic("enum: name is required")
+	}
+
+	e := &Enum[T]{name: name, values: slices.Clone(values)}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("enum: %q registered twice", name))
+	}
+	registry[name] = e
+
+	return e
+}
+
+// WithPgType declares that the enum is backed by a Postgres ENUM type, so
+// RegisterPgTypes teaches pgx about it.
+func (e *Enum[T]) WithPgType(typeName string) *Enum[T] {
+	e.pgType = typeName
+	return e
+}
+
+func (e *Enum[T]) Name() string   { return e.name }
+func (e *Enum[T]) PgType() string { return e.pgType }
+
+// Values returns the allowed values in declaration order.
+func (e *Enum[T]) Values() []T {
+	return slices.Clone(e.values)
+}
+
+// Strings returns the allowed values as plain strings.
+func (e *Enum[T]) Strings() []string {
+	out := make([]string, len(e.values))
+	for i, v := range e.values {
+		out[i] = string(v)
+	}
+	return out
+}
+
+// Valid reports whether v is one of the allowed values.
+func (e *Enum[T]) Valid(v T) bool {
+	return slices.Contains(e.values, v)
+}
+
+// Parse converts s into T, rejecting unknown values.
+func (e *Enum[T]) Parse(s string) (T, error) {
+	v := T(s)
+	if !e.Valid(v) {
+		return "", fmt.Errorf("invalid %s %q (must be one of: %s)", e.name, s, strings.Join(e.Strings(), ", "))
+	}
+	return v, nil
+}
+
+// DecodeJSON decodes a JSON string into dst, rejecting unknown values.
+// Call it from the enum type's own UnmarshalJSON method.
+func (e *Enum[T]) DecodeJSON(data []byte, dst *T) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%s must be a string", e.name)
+	}
+
+	v, err := e.Parse(s)
+	if err != nil {
+		return err
+	}
+
+	*dst = v
+	return nil
+}
+
+// OpenAPISchema returns the schema fragment for docs generation.
+func (e *Enum[T]) OpenAPISchema() map[string]any {
+	return map[string]any{
+		"type": "string",
+		"enum": e.Strings(),
+	}
+}
+
+// Lookup returns the enum registered under name.
+func Lookup(name string) (Definition, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	def, ok := registry[name]
+	return def, ok
+}
+
+// All returns every registered enum, sorted by name.
+func All() []Definition {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	defs := make([]Definition, 0, len(registry))
+	for _, def := range registry {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name() < defs[j].Name() })
+	return defs
+}
+
+// OpenAPISchemas returns all enum schemas keyed by name, ready to be merged
+// into components.schemas of the OpenAPI document.
+func OpenAPISchemas() map[string]any {
+	schemas := map[string]any{}
+	for _, def := range All() {
+		schemas[def.Name()] = def.OpenAPISchema()
+	}
+	return schemas
+}
+
+// ValidationTag is the validator tag checking a field against a registered
+// enum: `validate:"enum=todo_status"`.
+const ValidationTag = "enum"
+
+// RegisterValidation installs the "enum" tag on v.
+//
+// Unknown enum names fail validation rather than passing silently, so a typo
+// in a struct tag is caught by the first request instead of never.
+func RegisterValidation(v *validator.Validate) error {
+	return v.RegisterValidation(ValidationTag, func(fl validator.FieldLevel) bool {
+		def, ok := Lookup(fl.Param())
+		if !ok {
+			return false
+		}
+
+		value := fl.Field().String()
+		if value == "" {
+			// Emptiness is `required`'s job.
+			return true
+		}
+		return slices.Contains(def.Strings(), value)
+	})
+}
+
+// RegisterPgTypes loads every enum declared WithPgType (and its array type)
+// into conn's type map. Use it as pgxpool.Config.AfterConnect so enum
+// columns and parameters encode/decode without casts.
+func RegisterPgTypes(ctx context.Context, conn *pgx.Conn) error {
+	for _, def := range All() {
+		if def.PgType() == "" {
+			continue
+		}
+
+		for _, name := range []string{def.PgType(), "_" + def.PgType()} {
+			t, err := conn.LoadType(ctx, name)
+			if err != nil {
+				return fmt.Errorf("enum: failed to load postgres type %q: %w", name, err)
+			}
+			conn.TypeMap().RegisterType(t)
+		}
+	}
+	return nil
+}
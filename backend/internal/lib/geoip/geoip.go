@@ -0,0 +1,91 @@
+// Package geoip provides optional GeoIP lookups backed by a MaxMind database.
+//
+// It wraps oschwald/geoip2-golang so the rest of the application only deals
+// with a tiny Location struct (country + region) and never with MaxMind types.
+//
+// A nil *Client is valid and behaves as "GeoIP disabled": every lookup
+// returns an empty Location. This keeps call sites free of nil checks.
+package geoip
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/oschwald/geoip2-golang"
+)
+
+// Location is the resolved geographic information for an IP address.
+//
+// Fields are empty strings when the lookup fails or the IP is not in the DB
+// (e.g. private ranges like 10.0.0.0/8 or 127.0.0.1).
+type Location struct {
+	// Country is the ISO 3166-1 alpha-2 country code (e.g. "US").
+	Country string `json:"country"`
+
+	// Region is the ISO 3166-2 subdivision code of the most specific
+	// subdivision (e.g. "CA" for California).
+	Region string `json:"region"`
+}
+
+// IsZero reports whether nothing could be resolved.
+func (l Location) IsZero() bool {
+	return l.Country == "" && l.Region == ""
+}
+
+// Client performs GeoIP lookups against an opened MaxMind reader.
+type Client struct {
+	reader *geoip2.Reader
+}
+
+// NewClient opens the MaxMind database configured in cfg.
+//
+// Returns (nil, nil) when GeoIP is disabled, so callers can store the result
+// directly without branching.
+func NewClient(cfg *config.GeoIPConfig) (*Client, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+
+	reader, err := geoip2.Open(cfg.DatabasePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open geoip database %s: %w", cfg.DatabasePath, err)
+	}
+
+	return &Client{reader: reader}, nil
+}
+
+// Lookup resolves the country and region for the given IP string.
+//
+// Invalid IPs and lookup failures yield an empty Location rather than an
+// error: GeoIP is best-effort enrichment and must never fail a request.
+func (c *Client) Lookup(ip string) Location {
+	if c == nil || c.reader == nil {
+		return Location{}
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return Location{}
+	}
+
+	record, err := c.reader.City(parsed)
+	if err != nil {
+		return Location{}
+	}
+
+	location := Location{Country: record.Country.IsoCode}
+	if len(record.Subdivisions) > 0 {
+		location.Region = record.Subdivisions[0].IsoCode
+	}
+
+	return location
+}
+
+// Close releases the underlying memory-mapped database.
+func (c *Client) Close() error {
+	if c == nil || c.reader == nil {
+		return nil
+	}
+	return c.reader.Close()
+}
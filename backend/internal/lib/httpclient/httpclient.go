@@ -0,0 +1,177 @@
+// Package httpclient is the outbound HTTP client shared by code calling
+// other services: per-attempt timeouts, retries with exponential backoff on
+// transient failures, and client spans of the configured APM (which also
+// inject the distributed tracing headers, so the callee joins the caller's
+// trace).
+//
+// Retries resend the request, so only use them for idempotent calls (or set
+// MaxRetries to 0). Bodies must be replayable: requests built with
+// http.NewRequest from a bytes/strings reader are.
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/lib/tracing"
+)
+
+// Config controls timeouts and retries.
+type Config struct {
+	// Timeout bounds a single attempt (0 = no per-attempt timeout; the
+	// caller's context deadline still applies).
+	Timeout time.Duration
+
+	// MaxRetries is the number of extra attempts after the first one.
+	MaxRetries int
+
+	// BaseBackoff is the wait before the first retry; it doubles (with
+	// jitter) on every further retry, capped at MaxBackoff.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// DefaultConfig suits calls between services in the same network.
+func DefaultConfig() Config {
+	return Config{
+		Timeout:     5 * time.Second,
+		MaxRetries:  2,
+		BaseBackoff: 100 * time.Millisecond,
+		MaxBackoff:  2 * time.Second,
+	}
+}
+
+// Client sends requests with Config's timeouts and retries.
+type Client struct {
+	http *http.Client
+	cfg  Config
+}
+
+// New creates a Client. The transport is traced (see tracing.Transport): a
+// child span plus trace headers for requests whose context carries a span.
+func New(cfg Config) *Client {
+	return &Client{
+		http: &http.Client{Transport: tracing.Transport(http.DefaultTransport)},
+		cfg:  cfg,
+	}
+}
+
+// Do sends req, retrying network errors and 429/502/503/504 responses.
+//
+// The last response (or error) is returned; as with http.Client the caller
+// closes the body. Retry-After on 429/503 is honored, capped at MaxBackoff.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := c.attempt(req, attempt)
+
+		if attempt >= c.cfg.MaxRetries || !retryable(req, resp, err) {
+			return resp, err
+		}
+
+		wait := c.backoff(attempt, resp)
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// attempt sends one try of req with the per-attempt timeout.
+func (c *Client) attempt(req *http.Request, n int) (*http.Response, error) {
+	try := req
+	if n > 0 {
+		try = req.Clone(req.Context())
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to replay request body: %w", err)
+			}
+			try.Body = body
+		}
+	}
+
+	if c.cfg.Timeout <= 0 {
+		return c.http.Do(try)
+	}
+
+	ctx, cancel := context.WithTimeout(try.Context(), c.cfg.Timeout)
+	resp, err := c.http.Do(try.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// The timeout covers reading the body too; release it on Close.
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// retryable reports whether a failed attempt is worth repeating.
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	// The caller gave up: retrying can't help.
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	// Without GetBody a consumed body can't be sent again.
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	if err != nil {
+		var netErr net.Error
+		return errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff returns the wait before retry number attempt+1.
+func (c *Client) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, c.cfg.MaxBackoff)
+		}
+	}
+
+	wait := c.cfg.BaseBackoff << attempt
+	if wait <= 0 || wait > c.cfg.MaxBackoff {
+		wait = c.cfg.MaxBackoff
+	}
+
+	// Jitter in [wait/2, wait) keeps callers from retrying in lockstep.
+	half := wait / 2
+	if half <= 0 {
+		return wait
+	}
+	return half + rand.N(half)
+}
+
+// cancelBody releases the attempt's timeout context once the body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
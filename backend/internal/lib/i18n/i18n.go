@@ -0,0 +1,146 @@
+// Package i18n translates client-facing messages (error messages, validation
+// field errors) into the request locale resolved by the locale middleware.
+//
+// Messages live in one JSON file per language under locales/, embedded in
+// the binary: a flat map of keys to templates whose {name} placeholders are
+// filled from the params of the message.
+//
+//	{
+//	  "validation.required": "is required",
+//	  "validation.min.string": "must be at least {param} characters"
+//	}
+//
+// Keys:
+//   - "error.<CODE>" translates the default message of an error code (the
+//     Message of its errs.CatalogEntry)
+//   - "validation.<tag>" translates the field error of a validator tag;
+//     min and max have a ".string" variant for lengths
+//   - anything else is a message key set explicitly (errs.HTTPError.WithMessageKey)
+//
+// A locale without a file falls back to its base language ("pt-BR" =>
+// "pt"), then to English; a key missing everywhere keeps the English text of
+// the caller. To add a language, add locales/<tag>.json and list the tag in
+// locale.supported.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Fallback is the language of the source messages.
+const Fallback = "en"
+
+//go:embed locales/*.json
+var files embed.FS
+
+// Bundle holds the messages of every language.
+type Bundle struct {
+	messages map[string]map[string]string
+}
+
+// Load reads every <tag>.json file of dir in fsys.
+func Load(fsys fs.FS, dir string) (*Bundle, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list message files: %w", err)
+	}
+
+	b := &Bundle{messages: map[string]map[string]string{}}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || path.Ext(name) != ".json" {
+			continue
+		}
+
+		tag, err := language.Parse(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			return nil, fmt.Errorf("message file %s is not named after a locale: %w", name, err)
+		}
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read message file %s: %w", name, err)
+		}
+
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("invalid message file %s: %w", name, err)
+		}
+		b.messages[tag.String()] = messages
+	}
+	return b, nil
+}
+
+// Translate returns the message of key in locale, with params filled in.
+// ok is false when no language in the fallback chain has the key.
+func (b *Bundle) Translate(locale, key string, params map[string]any) (string, bool) {
+	for _, candidate := range fallbackChain(locale) {
+		if template, ok := b.messages[candidate][key]; ok {
+			return fill(template, params), true
+		}
+	}
+	return "", false
+}
+
+// Locales lists the languages with a message file.
+func (b *Bundle) Locales() []string {
+	locales := make([]string, 0, len(b.messages))
+	for locale := range b.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// fallbackChain lists the tags to look a message up in: the locale, its
+// base language, then Fallback.
+func fallbackChain(locale string) []string {
+	chain := make([]string, 0, 3)
+	if tag, err := language.Parse(locale); err == nil {
+		chain = append(chain, tag.String())
+		if base, confidence := tag.Base(); confidence != language.No && base.String() != tag.String() {
+			chain = append(chain, base.String())
+		}
+	}
+	return append(chain, Fallback)
+}
+
+// fill replaces the {name} placeholders of template.
+func fill(template string, params map[string]any) string {
+	if len(params) == 0 {
+		return template
+	}
+
+	pairs := make([]string, 0, 2*len(params))
+	for name, value := range params {
+		pairs = append(pairs, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
+// bundle holds the embedded message files.
+var bundle = func() *Bundle {
+	b, err := Load(files, "locales")
+	if err != nil {
+		panic(err)
+	}
+	return b
+}()
+
+// T translates key with the embedded messages (see Bundle.Translate).
+func T(locale, key string, params map[string]any) (string, bool) {
+	return bundle.Translate(locale, key, params)
+}
+
+// Locales lists the languages of the embedded messages.
+func Locales() []string {
+	return bundle.Locales()
+}
@@ -0,0 +1,194 @@
+// Package inflect converts English nouns between singular and plural, for
+// turning table names into entity names ("companies" => "company") in error
+// codes and messages.
+//
+// It covers what table names use in practice: the regular suffix rules,
+// common irregular nouns and uncountable ones. Snake_case names are
+// inflected on their last word only ("user_addresses" => "user_address").
+// Words it has no rule for get the regular treatment, so add to the
+// irregular list rather than working around it at call sites.
+package inflect
+
+import "strings"
+
+// irregular maps singular to plural for nouns the suffix rules get wrong in
+// either direction.
+var irregular = map[string]string{
+	"person":     "people",
+	"man":        "men",
+	"woman":      "women",
+	"child":      "children",
+	"mouse":      "mice",
+	"goose":      "geese",
+	"tooth":      "teeth",
+	"foot":       "feet",
+	"ox":         "oxen",
+	"die":        "dice",
+	"leaf":       "leaves",
+	"life":       "lives",
+	"knife":      "knives",
+	"wife":       "wives",
+	"half":       "halves",
+	"shelf":      "shelves",
+	"wolf":       "wolves",
+	"hero":       "heroes",
+	"potato":     "potatoes",
+	"tomato":     "tomatoes",
+	"echo":       "echoes",
+	"veto":       "vetoes",
+	"analysis":   "analyses",
+	"basis":      "bases",
+	"crisis":     "crises",
+	"diagnosis":  "diagnoses",
+	"hypothesis": "hypotheses",
+	"thesis":     "theses",
+	"criterion":  "criteria",
+	"phenomenon": "phenomena",
+	"matrix":     "matrices",
+	"vertex":     "vertices",
+	"appendix":   "appendices",
+	"medium":     "media",
+	"curriculum": "curricula",
+	"quiz":       "quizzes",
+	"movie":      "movies",
+	"cookie":     "cookies",
+	"zombie":     "zombies",
+	"calorie":    "calories",
+	"cache":      "caches",
+	"niche":      "niches",
+	"avalanche":  "avalanches",
+	"psyche":     "psyches",
+	"quiche":     "quiches",
+	"microfiche": "microfiches",
+	"status":     "statuses",
+	"bonus":      "bonuses",
+	"campus":     "campuses",
+	"census":     "censuses",
+	"virus":      "viruses",
+	"focus":      "focuses",
+	"radius":     "radiuses",
+	"alias":      "aliases",
+	"canvas":     "canvases",
+	"gas":        "gases",
+	"bus":        "buses",
+}
+
+// uncountable nouns are the same in singular and plural.
+var uncountable = map[string]struct{}{
+	"data": {}, "metadata": {}, "information": {}, "equipment": {}, "news": {},
+	"series": {}, "species": {}, "feedback": {}, "software": {}, "hardware": {},
+	"progress": {}, "sheep": {}, "fish": {}, "deer": {}, "money": {},
+	"staff": {}, "audio": {}, "traffic": {}, "analytics": {},
+}
+
+// singularOf is irregular, inverted.
+var singularOf = func() map[string]string {
+	m := make(map[string]string, len(irregular))
+	for singular, plural := range irregular {
+		m[plural] = singular
+	}
+	// "bases" is both base and basis; tables hold bases.
+	m["bases"] = "base"
+	return m
+}()
+
+// Singular returns the singular of a plural noun ("companies" => "company",
+// "statuses" => "status", "people" => "person"). Singular nouns are
+// returned unchanged.
+func Singular(word string) string {
+	return inflectLast(word, singular)
+}
+
+// Plural returns the plural of a singular noun ("company" => "companies",
+// "person" => "people").
+func Plural(word string) string {
+	return inflectLast(word, plural)
+}
+
+// inflectLast applies fn to the last word of a snake_case name, keeping
+// the input's upper case or capitalization.
+func inflectLast(word string, fn func(string) string) string {
+	if word == "" {
+		return ""
+	}
+
+	prefix, last := "", word
+	if i := strings.LastIndexByte(word, '_'); i >= 0 {
+		prefix, last = word[:i+1], word[i+1:]
+	}
+
+	upper := last == strings.ToUpper(last) && last != strings.ToLower(last)
+	inflected := fn(strings.ToLower(last))
+	if upper {
+		inflected = strings.ToUpper(inflected)
+	} else if last != strings.ToLower(last) && len(inflected) > 0 {
+		// Capitalized input ("Companies" => "Company").
+		inflected = strings.ToUpper(inflected[:1]) + inflected[1:]
+	}
+	return prefix + inflected
+}
+
+func singular(word string) string {
+	if _, ok := uncountable[word]; ok {
+		return word
+	}
+	if s, ok := singularOf[word]; ok {
+		return s
+	}
+	if _, ok := irregular[word]; ok {
+		// Already singular.
+		return word
+	}
+
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "aches") && len(word) > 5 && !isVowel(word[len(word)-6]):
+		// The stem ends in "ache", not in the sibilant "ch": headaches,
+		// moustaches (but beaches, coaches).
+		return strings.TrimSuffix(word, "s")
+	case strings.HasSuffix(word, "sses"),
+		strings.HasSuffix(word, "shes"),
+		strings.HasSuffix(word, "ches"),
+		strings.HasSuffix(word, "xes"),
+		strings.HasSuffix(word, "zzes"):
+		return strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "ss"),
+		strings.HasSuffix(word, "us"),
+		strings.HasSuffix(word, "is"):
+		// Singular already (address, status, analysis).
+		return word
+	case strings.HasSuffix(word, "s") && len(word) > 1:
+		return strings.TrimSuffix(word, "s")
+	}
+	return word
+}
+
+func isVowel(c byte) bool {
+	return strings.IndexByte("aeiou", c) >= 0
+}
+
+func plural(word string) string {
+	if _, ok := uncountable[word]; ok {
+		return word
+	}
+	if p, ok := irregular[word]; ok {
+		return p
+	}
+	if _, ok := singularOf[word]; ok {
+		// Already plural.
+		return word
+	}
+
+	switch {
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !isVowel(word[len(word)-2]):
+		return strings.TrimSuffix(word, "y") + "ies"
+	case strings.HasSuffix(word, "s"),
+		strings.HasSuffix(word, "sh"),
+		strings.HasSuffix(word, "ch"),
+		strings.HasSuffix(word, "x"),
+		strings.HasSuffix(word, "z"):
+		return word + "es"
+	}
+	return word + "s"
+}
@@ -0,0 +1,99 @@
+package inflect
+
+import "testing"
+
+func TestSingular(t *testing.T) {
+	tests := []struct {
+		plural, want string
+	}{
+		// Regular table names.
+		{"users", "user"},
+		{"orders", "order"},
+		{"invoices", "invoice"},
+		{"sizes", "size"},
+		{"shoes", "shoe"},
+		{"companies", "company"},
+		{"categories", "category"},
+		{"keys", "key"},
+
+		// Sibilant stems.
+		{"addresses", "address"},
+		{"wishes", "wish"},
+		{"matches", "match"},
+		{"branches", "branch"},
+		{"churches", "church"},
+		{"beaches", "beach"},
+		{"coaches", "coach"},
+		{"boxes", "box"},
+		{"taxes", "tax"},
+		{"buzzes", "buzz"},
+
+		// Stems ending in "che", not "ch".
+		{"headaches", "headache"},
+		{"moustaches", "moustache"},
+		{"caches", "cache"},
+		{"niches", "niche"},
+		{"avalanches", "avalanche"},
+
+		// Irregular and uncountable.
+		{"people", "person"},
+		{"children", "child"},
+		{"statuses", "status"},
+		{"analyses", "analysis"},
+		{"media", "medium"},
+		{"movies", "movie"},
+		{"quizzes", "quiz"},
+		{"data", "data"},
+		{"news", "news"},
+
+		// Already singular.
+		{"user", "user"},
+		{"address", "address"},
+		{"status", "status"},
+
+		// Snake case and letter case.
+		{"user_addresses", "user_address"},
+		{"order_items", "order_item"},
+		{"audit_logs", "audit_log"},
+		{"USERS", "USER"},
+		{"Companies", "Company"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.plural, func(t *testing.T) {
+			if got := Singular(tt.plural); got != tt.want {
+				t.Errorf("Singular(%q) = %q, want %q", tt.plural, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlural(t *testing.T) {
+	tests := []struct {
+		singular, want string
+	}{
+		{"user", "users"},
+		{"company", "companies"},
+		{"key", "keys"},
+		{"address", "addresses"},
+		{"match", "matches"},
+		{"box", "boxes"},
+		{"headache", "headaches"},
+		{"avalanche", "avalanches"},
+		{"person", "people"},
+		{"status", "statuses"},
+		{"data", "data"},
+		{"people", "people"},
+		{"user_address", "user_addresses"},
+		{"Company", "Companies"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.singular, func(t *testing.T) {
+			if got := Plural(tt.singular); got != tt.want {
+				t.Errorf("Plural(%q) = %q, want %q", tt.singular, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/lib/audit"
+	"github.com/deppfellow/go-boilerplate/internal/lib/ctxutil"
+	"github.com/hibiken/asynq"
+)
+
+const (
+	// TaskAuditLog persists one audit.Entry.
+	TaskAuditLog = "audit:write"
+)
+
+// AuditWriter persists audit entries (implemented by repository.AuditRepository).
+//
+// It is an interface because the job package sits below the repository layer
+// and can't import it.
+type AuditWriter interface {
+	Create(ctx context.Context, entry *audit.Entry) error
+}
+
+// NewAuditLogTask constructs an Asynq task writing entry to the audit log.
+//
+// Options:
+//   - MaxRetry(10): audit rows must not be lost to a transient DB blip
+//   - Queue("default"): not urgent, but shouldn't lag behind backfills either
+//   - Timeout(30s): a single INSERT
+func NewAuditLogTask(ctx context.Context, entry *audit.Entry) (*asynq.Task, error) {
+	payload, err := marshalPayload(ctx, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewTask(
+		TaskAuditLog,
+		payload,
+		asynq.MaxRetry(10),
+		asynq.Queue("default"),
+		asynq.Timeout(30*time.Second),
+	), nil
+}
+
+// InitAuditWriter registers the TaskAuditLog handler backed by writer.
+//
+// Unlike InitHandlers it may be called after Start: repositories are built
+// after the server (and its job worker) is up, and the mux accepts new
+// handlers at any time. Tasks arriving before registration fail and are retried.
+func (j *JobService) InitAuditWriter(writer AuditWriter) {
+	j.mux.HandleFunc(TaskAuditLog, func(ctx context.Context, t *asynq.Task) error {
+		var entry audit.Entry
+		if err := json.Unmarshal(t.Payload(), &entry); err != nil {
+			return fmt.Errorf("failed to unmarshal audit payload: %w", err)
+		}
+
+		if err := writer.Create(ctx, &entry); err != nil {
+			ctxutil.Logger(ctx).Error().
+				Str("type", "audit").
+				Str("request_id", entry.RequestID).
+				Err(err).
+				Msg("Failed to write audit log")
+			return err
+		}
+
+		return nil
+	})
+}
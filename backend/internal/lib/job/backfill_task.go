@@ -0,0 +1,81 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/lib/backfill"
+	"github.com/deppfellow/go-boilerplate/internal/lib/ctxutil"
+	"github.com/hibiken/asynq"
+)
+
+const (
+	// TaskBackfill runs a registered data backfill (see lib/backfill).
+	TaskBackfill = "schema:backfill"
+)
+
+// BackfillPayload is the JSON payload for TaskBackfill.
+type BackfillPayload struct {
+	Name string `json:"name"`
+}
+
+// NewBackfillTask constructs an Asynq task running the named backfill.
+//
+// Options:
+//   - Queue("low"): backfills must not compete with user-facing jobs
+//   - uniqueTaskID: enqueuing the same backfill twice fails while one is queued or running
+//   - Timeout(6h): long, but bounded; progress is persisted so a retry resumes
+func NewBackfillTask(ctx context.Context, name string) (*asynq.Task, error) {
+	p := BackfillPayload{Name: name}
+	payload, err := marshalPayload(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	unique, err := uniqueTaskID(TaskBackfill, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewTask(
+		TaskBackfill,
+		payload,
+		asynq.MaxRetry(5),
+		asynq.Queue("low"),
+		unique,
+		asynq.Timeout(6*time.Hour),
+	), nil
+}
+
+// InitBackfills enables TaskBackfill processing with the given runner.
+// Must be called before Start.
+func (j *JobService) InitBackfills(runner *backfill.Runner) {
+	j.backfills = runner
+}
+
+// handleBackfillTask runs (or resumes) a backfill.
+func (j *JobService) handleBackfillTask(ctx context.Context, t *asynq.Task) error {
+	var p BackfillPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("failed to unmarshal backfill payload: %w", err)
+	}
+
+	ctxutil.Logger(ctx).Info().
+		Str("type", "backfill").
+		Str("backfill", p.Name).
+		Msg("Processing backfill task")
+
+	progress, err := j.backfills.Run(ctx, p.Name)
+	if err != nil {
+		ctxutil.Logger(ctx).Error().
+			Str("type", "backfill").
+			Str("backfill", p.Name).
+			Int64("rows_processed", progress.RowsProcessed).
+			Err(err).
+			Msg("Backfill interrupted, will resume on retry")
+		return err
+	}
+
+	return nil
+}
@@ -1,7 +1,7 @@
 package job
 
 import (
-	"encoding/json"
+	"context"
 	"time"
 
 	"github.com/hibiken/asynq"
@@ -27,9 +27,9 @@ type WelcomeEmailPayload struct {
 //   - MaxRetry(3): retry up to 3 times on failure
 //   - Queue("default"): send into the "default" queue
 //   - Timeout(30s): kill the task if handler runs longer than 30 seconds
-func NewWelcomeEmailTask(to, firstName string) (*asynq.Task, error) {
-	// Serialize payload into JSON bytes.
-	payload, err := json.Marshal(WelcomeEmailPayload{
+func NewWelcomeEmailTask(ctx context.Context, to, firstName string) (*asynq.Task, error) {
+	// Serialize payload into JSON bytes, with the metadata of ctx.
+	payload, err := marshalPayload(ctx, WelcomeEmailPayload{
 		To:        to,
 		FirstName: firstName,
 	})
@@ -0,0 +1,143 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/lib/ctxutil"
+	"github.com/deppfellow/go-boilerplate/internal/lib/embedding"
+	"github.com/hibiken/asynq"
+)
+
+const (
+	// TaskEmbed computes the embeddings of changed rows.
+	TaskEmbed = "embedding:embed"
+
+	// TaskReembed computes the embeddings of one batch of a source and
+	// enqueues itself for the next batch (see lib/embedding).
+	TaskReembed = "embedding:reembed"
+)
+
+// EmbedPayload is the JSON payload for TaskEmbed.
+type EmbedPayload struct {
+	Source string   `json:"source"`
+	IDs    []string `json:"ids"`
+}
+
+// NewEmbedTask constructs a task embedding ids of source. Like search
+// indexing, the texts are re-read when the task runs.
+func NewEmbedTask(ctx context.Context, source string, ids []string) (*asynq.Task, error) {
+	payload, err := marshalPayload(ctx, EmbedPayload{Source: source, IDs: ids})
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewTask(
+		TaskEmbed,
+		payload,
+		asynq.MaxRetry(10),
+		asynq.Queue("default"),
+		asynq.Timeout(2*time.Minute),
+	), nil
+}
+
+// ReembedPayload is the JSON payload for TaskReembed.
+type ReembedPayload struct {
+	Source string `json:"source"`
+
+	// AfterID is the cursor: the last primary key embedded so far.
+	AfterID string `json:"after_id,omitempty"`
+}
+
+// NewReembedTask constructs the re-embed task of the batch after afterID
+// ("" starts over).
+//
+// One task per batch keeps every task short (provider calls are slow and
+// rate limited), and a failed batch is retried on its own, from its cursor.
+func NewReembedTask(ctx context.Context, source, afterID string) (*asynq.Task, error) {
+	p := ReembedPayload{Source: source, AfterID: afterID}
+	payload, err := marshalPayload(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	unique, err := uniqueTaskID(TaskReembed, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewTask(
+		TaskReembed,
+		payload,
+		asynq.MaxRetry(10),
+		asynq.Queue("low"),
+		unique,
+		asynq.Timeout(5*time.Minute),
+	), nil
+}
+
+// InitEmbeddings enables the embedding tasks with the given runner.
+// Must be called before Start.
+func (j *JobService) InitEmbeddings(runner *embedding.Runner) {
+	j.embeddings = runner
+}
+
+// handleEmbedTask embeds the changed rows.
+func (j *JobService) handleEmbedTask(ctx context.Context, t *asynq.Task) error {
+	var p EmbedPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("failed to unmarshal embed payload: %w", err)
+	}
+
+	if err := j.embeddings.Embed(ctx, p.Source, p.IDs); err != nil {
+		ctxutil.Logger(ctx).Error().
+			Str("type", "embed").
+			Str("source", p.Source).
+			Int("rows", len(p.IDs)).
+			Err(err).
+			Msg("Failed to embed rows")
+		return err
+	}
+
+	return nil
+}
+
+// handleReembedTask embeds one batch and chains the next one.
+func (j *JobService) handleReembedTask(ctx context.Context, t *asynq.Task) error {
+	var p ReembedPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("failed to unmarshal reembed payload: %w", err)
+	}
+
+	lastID, done, err := j.embeddings.EmbedBatch(ctx, p.Source, p.AfterID)
+	if err != nil {
+		ctxutil.Logger(ctx).Error().
+			Str("type", "reembed").
+			Str("source", p.Source).
+			Str("after_id", p.AfterID).
+			Err(err).
+			Msg("Failed to embed batch")
+		return err
+	}
+
+	if done {
+		ctxutil.Logger(ctx).Info().
+			Str("type", "reembed").
+			Str("source", p.Source).
+			Msg("Re-embed completed")
+		return nil
+	}
+
+	next, err := NewReembedTask(ctx, p.Source, lastID)
+	if err != nil {
+		return err
+	}
+	// A conflict means a previous attempt of this batch already enqueued it.
+	if _, err := j.Client.EnqueueContext(ctx, next); err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+		return fmt.Errorf("failed to enqueue next reembed batch: %w", err)
+	}
+
+	return nil
+}
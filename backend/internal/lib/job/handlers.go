@@ -6,6 +6,8 @@ import (
 	"fmt"
 
 	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/lib/ctxutil"
+	"github.com/deppfellow/go-boilerplate/internal/lib/dependency"
 	"github.com/deppfellow/go-boilerplate/internal/lib/email"
 	"github.com/hibiken/asynq"
 	"github.com/rs/zerolog"
@@ -28,6 +30,19 @@ func (j *JobService) InitHandlers(config *config.Config, logger *zerolog.Logger)
 	emailClient = email.NewClient(config, logger)
 }
 
+// InitDependencies makes handlers report the health of external providers
+// (the "email" dependency) to tracker. Call it before Start.
+func (j *JobService) InitDependencies(tracker *dependency.Tracker) {
+	j.dependencies = tracker
+}
+
+// reportDependency forwards the outcome of a provider call to the tracker.
+func (j *JobService) reportDependency(name string, err error) {
+	if j.dependencies != nil {
+		j.dependencies.Report(name, err)
+	}
+}
+
 // handleWelcomeEmailTask processes the welcome email task.
 //
 // Steps:
@@ -42,17 +57,22 @@ func (j *JobService) handleWelcomeEmailTask(ctx context.Context, t *asynq.Task)
 		return fmt.Errorf("failed to unmarshal welcome email payload: %w", err)
 	}
 
+	// The job-scoped logger carries job_id, task_type, queue, attempt and the
+	// request_id/user_id of the request that enqueued the task.
+	logger := ctxutil.Logger(ctx)
+
 	// Log that we're processing the task, with some structured fields.
-	j.logger.Info().
+	logger.Info().
 		Str("type", "welcome").
 		Str("to", p.To).
 		Msg("Processing welcome email task")
 
 	// Perform the actual work: send the email.
 	err := emailClient.SendWelcomeEmail(p.To, p.FirstName)
+	j.reportDependency("email", err)
 	if err != nil {
 		// Log error with context.
-		j.logger.Error().
+		logger.Error().
 			Str("type", "welcome").
 			Str("to", p.To).
 			Err(err).
@@ -61,7 +81,7 @@ func (j *JobService) handleWelcomeEmailTask(ctx context.Context, t *asynq.Task)
 	}
 
 	// Success log.
-	j.logger.Info().
+	logger.Info().
 		Str("type", "welcome").
 		Str("to", p.To).
 		Msg("Successfully sent welcome email")
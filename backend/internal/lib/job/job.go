@@ -6,9 +6,20 @@
 package job
 
 import (
+	"context"
+
 	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/lib/backfill"
+	"github.com/deppfellow/go-boilerplate/internal/lib/ctxutil"
+	"github.com/deppfellow/go-boilerplate/internal/lib/dependency"
+	"github.com/deppfellow/go-boilerplate/internal/lib/embedding"
+	"github.com/deppfellow/go-boilerplate/internal/lib/search"
+	"github.com/deppfellow/go-boilerplate/internal/lib/softdelete"
+	"github.com/deppfellow/go-boilerplate/internal/lib/tracing"
 	"github.com/hibiken/asynq"
 	"github.com/rs/zerolog"
+
+	loggerPkg "github.com/deppfellow/go-boilerplate/internal/logger"
 )
 
 // JobService holds the Asynq client (enqueue) and server (worker execution).
@@ -16,11 +27,35 @@ type JobService struct {
 	// Client is used to enqueue tasks into Redis.
 	Client *asynq.Client
 
+	// Inspector reads queue state (sizes, scheduled/retry/archived tasks)
+	// for the admin job endpoints and dashboard.
+	Inspector *asynq.Inspector
+
 	// server runs worker processes that pull tasks from Redis and execute handlers.
 	server *asynq.Server
 
+	// mux routes task types to handlers. It lives on the service (rather than
+	// in Start) so handlers with late dependencies can register afterwards.
+	mux *asynq.ServeMux
+
 	// logger is used for lifecycle logs and handler logs.
 	logger *zerolog.Logger
+
+	// backfills runs schema backfills; nil disables TaskBackfill.
+	backfills *backfill.Runner
+
+	// search syncs the search index; nil disables the search tasks.
+	search *search.Runner
+
+	// embeddings computes vector embeddings; nil disables the embedding tasks.
+	embeddings *embedding.Runner
+
+	// purger purges soft-deleted rows; nil disables TaskPurgeDeleted.
+	purger *softdelete.Purger
+
+	// dependencies receives the outcome of calls to external providers
+	// (e.g. the email API); nil when not tracked.
+	dependencies *dependency.Tracker
 }
 
 // NewJobService creates a JobService configured to use Redis from cfg.
@@ -60,26 +95,102 @@ func NewJobService(logger *zerolog.Logger, cfg *config.Config) *JobService {
 		},
 	)
 
-	return &JobService{
-		Client: client,
-		server: server,
-		logger: logger,
+	j := &JobService{
+		Client:    client,
+		Inspector: asynq.NewInspector(asynq.RedisClientOpt{Addr: redisAddr}),
+		server:    server,
+		mux:       asynq.NewServeMux(),
+		logger:    logger,
 	}
+
+	// Every task handler gets the task ID in its context (ctxutil.TaskID), so
+	// logs and SQL traces from jobs can be tied back to the task, and the
+	// enqueuing request's metadata with a job-scoped logger (see metadata.go).
+	j.mux.Use(withTaskID)
+	j.mux.Use(j.withMetadata)
+
+	return j
+}
+
+// withTaskID is asynq middleware storing the task ID in the handler context.
+func withTaskID(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+		if taskID, ok := asynq.GetTaskID(ctx); ok {
+			ctx = ctxutil.WithTaskID(ctx, taskID)
+		}
+		return next.ProcessTask(ctx, t)
+	})
+}
+
+// InitTracing runs every task in a span of tracer ("job <type>"), with the
+// task ID, queue and retry count as attributes and a failed task recorded
+// as an error. Tasks enqueued from a traced request continue its trace, and
+// the job logger gets the trace IDs. Must be called before Start.
+func (j *JobService) InitTracing(tracer tracing.Tracer) {
+	j.mux.Use(func(next asynq.Handler) asynq.Handler {
+		return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+			ctx, span := tracer.Start(ctx, "job "+t.Type())
+			defer span.End()
+
+			span.SetAttribute("job.type", t.Type())
+			if taskID, ok := asynq.GetTaskID(ctx); ok {
+				span.SetAttribute("job.id", taskID)
+			}
+			if queue, ok := asynq.GetQueueName(ctx); ok {
+				span.SetAttribute("job.queue", queue)
+			}
+			if retry, ok := asynq.GetRetryCount(ctx); ok {
+				span.SetAttribute("job.retry", retry)
+			}
+			if span.TraceID() != "" {
+				logger := loggerPkg.WithTraceContext(*ctxutil.Logger(ctx), span)
+				ctx = ctxutil.WithLogger(ctx, &logger)
+			}
+
+			err := next.ProcessTask(ctx, t)
+			if err != nil {
+				span.RecordError(err)
+			}
+			return err
+		})
+	})
 }
 
 // Start starts the background worker server and registers task handlers.
 //
 // Flow:
-//   - Create a ServeMux (routes task type -> handler function).
+//   - Use the ServeMux (routes task type -> handler function).
 //   - Register handlers (TaskWelcome -> handleWelcomeEmailTask).
 //   - Start the Asynq server (blocks until shutdown or error).
 func (j *JobService) Start() error {
 	// ServeMux is like HTTP routing, but for job types.
-	mux := asynq.NewServeMux()
+	mux := j.mux
 
 	// Register a handler for the "email:welcome" task type.
 	mux.HandleFunc(TaskWelcome, j.handleWelcomeEmailTask)
 
+	// Register the backfill runner if the database side was wired in.
+	if j.backfills != nil {
+		mux.HandleFunc(TaskBackfill, j.handleBackfillTask)
+	}
+
+	// Search indexing, when a search server is configured.
+	if j.search != nil {
+		mux.HandleFunc(TaskSearchIndex, j.handleSearchIndexTask)
+		mux.HandleFunc(TaskSearchReindex, j.handleSearchReindexTask)
+	}
+
+	// Vector embeddings, when an embedding provider is configured.
+	if j.embeddings != nil {
+		mux.HandleFunc(TaskEmbed, j.handleEmbedTask)
+		mux.HandleFunc(TaskReembed, j.handleReembedTask)
+	}
+
+	// Purging of soft-deleted rows, same wiring as backfills.
+	if j.purger != nil {
+		mux.HandleFunc(TaskPurgeDeleted, j.handlePurgeDeletedTask)
+	}
+
 	j.logger.Info().Msg("Starting background job server")
 
 	// Start begins processing tasks. This typically blocks.
@@ -99,4 +210,5 @@ func (j *JobService) Stop() {
 	j.logger.Info().Msg("Stopping background job server")
 	j.server.Shutdown()
 	j.Client.Close()
+	j.Inspector.Close()
 }
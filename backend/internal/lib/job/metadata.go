@@ -0,0 +1,154 @@
+package job
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/deppfellow/go-boilerplate/internal/lib/ctxutil"
+	"github.com/deppfellow/go-boilerplate/internal/lib/tracing"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog"
+)
+
+// Metadata is the context of whoever enqueued a task: the request that
+// triggered it and its trace. It travels with the payload so the task's logs
+// and trace can be joined with the request's.
+type Metadata struct {
+	RequestID string `json:"request_id,omitempty"`
+	UserID    string `json:"user_id,omitempty"`
+	TenantID  string `json:"tenant_id,omitempty"`
+
+	// Trace holds the propagation headers of the enqueuing span
+	// (tracing.Inject).
+	Trace map[string]string `json:"trace,omitempty"`
+}
+
+// MetadataFromContext captures the correlation values of ctx.
+func MetadataFromContext(ctx context.Context) Metadata {
+	return Metadata{
+		RequestID: ctxutil.RequestID(ctx),
+		UserID:    ctxutil.UserID(ctx),
+		TenantID:  ctxutil.Tenant(ctx),
+		Trace:     tracing.Inject(ctx),
+	}
+}
+
+func (m Metadata) empty() bool {
+	return m.RequestID == "" && m.UserID == "" && m.TenantID == "" && len(m.Trace) == 0
+}
+
+// envelope is the stored payload of a task with metadata. The keys are
+// prefixed so a task payload of its own can't be mistaken for one.
+type envelope struct {
+	Meta    *Metadata       `json:"_meta"`
+	Payload json.RawMessage `json:"_payload"`
+}
+
+// marshalPayload encodes v as a task payload carrying the metadata of ctx.
+// Task constructors use it instead of json.Marshal; without correlation
+// values (e.g. scheduled work) the payload is plain JSON.
+func marshalPayload(ctx context.Context, v any) ([]byte, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := MetadataFromContext(ctx)
+	if meta.empty() {
+		return payload, nil
+	}
+	return json.Marshal(envelope{Meta: &meta, Payload: payload})
+}
+
+// uniqueTaskID returns a TaskID option derived from the task type and the
+// business payload v only, never the metadata of marshalPayload.
+//
+// asynq.Unique hashes the stored payload, which carries a different
+// request_id on every enqueue, so it never matched and the same long job
+// could run twice at once. With a fixed ID, enqueuing it again while one is
+// pending, running or retrying fails with asynq.ErrTaskIDConflict; a
+// successful run is deleted from the queue and frees the ID, an archived one
+// keeps it until it is deleted or re-run from the dashboard.
+func uniqueTaskID(typename string, v any) (asynq.Option, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(append([]byte(typename+"\x00"), payload...))
+	return asynq.TaskID(typename + ":" + hex.EncodeToString(sum[:16])), nil
+}
+
+// unwrapPayload splits a payload built by marshalPayload. Plain payloads
+// (older tasks, tasks without metadata) are returned unchanged.
+func unwrapPayload(data []byte) (Metadata, []byte) {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte(`{"_meta"`)) {
+		return Metadata{}, data
+	}
+
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil || env.Meta == nil || env.Payload == nil {
+		return Metadata{}, data
+	}
+	return *env.Meta, env.Payload
+}
+
+// withMetadata is asynq middleware restoring the enqueuer's context: it
+// unwraps the payload (handlers only see their own JSON), puts the request
+// ID, user and tenant back into ctx, marks the trace to continue, and stores
+// a job-scoped logger (ctxutil.Logger) with the task and request fields.
+func (j *JobService) withMetadata(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+		meta, payload := unwrapPayload(t.Payload())
+		if !meta.empty() {
+			// Only type and payload are carried over: handlers don't use
+			// the result writer.
+			t = asynq.NewTask(t.Type(), payload)
+		}
+
+		if meta.RequestID != "" {
+			ctx = ctxutil.WithRequestID(ctx, meta.RequestID)
+		}
+		if meta.UserID != "" {
+			ctx = ctxutil.WithUserID(ctx, meta.UserID)
+		}
+		if meta.TenantID != "" {
+			ctx = ctxutil.WithTenant(ctx, meta.TenantID)
+		}
+		ctx = tracing.WithRemoteParent(ctx, meta.Trace)
+
+		logger := j.jobLogger(ctx, t, meta)
+		ctx = ctxutil.WithLogger(ctx, &logger)
+
+		return next.ProcessTask(ctx, t)
+	})
+}
+
+// jobLogger builds the logger of one task run. attempt starts at 1.
+func (j *JobService) jobLogger(ctx context.Context, t *asynq.Task, meta Metadata) zerolog.Logger {
+	zc := j.logger.With().Str("task_type", t.Type())
+
+	if taskID, ok := asynq.GetTaskID(ctx); ok {
+		zc = zc.Str("job_id", taskID)
+	}
+	if queue, ok := asynq.GetQueueName(ctx); ok {
+		zc = zc.Str("queue", queue)
+	}
+	if retry, ok := asynq.GetRetryCount(ctx); ok {
+		zc = zc.Int("attempt", retry+1)
+	}
+	if meta.RequestID != "" {
+		zc = zc.Str("request_id", meta.RequestID)
+	}
+	if meta.UserID != "" {
+		zc = zc.Str("user_id", meta.UserID)
+	}
+	if meta.TenantID != "" {
+		zc = zc.Str("tenant_id", meta.TenantID)
+	}
+
+	return zc.Logger()
+}
@@ -0,0 +1,139 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/lib/ctxutil"
+	"github.com/deppfellow/go-boilerplate/internal/lib/search"
+	"github.com/hibiken/asynq"
+)
+
+const (
+	// TaskSearchIndex syncs changed documents into the search index.
+	TaskSearchIndex = "search:index"
+
+	// TaskSearchReindex rebuilds a whole search index (see lib/search).
+	TaskSearchReindex = "search:reindex"
+)
+
+// SearchIndexPayload is the JSON payload for TaskSearchIndex.
+type SearchIndexPayload struct {
+	Index string   `json:"index"`
+	IDs   []string `json:"ids"`
+}
+
+// NewSearchIndexTask constructs a task syncing ids of index.
+//
+// The handler reloads the documents from Postgres, so the task carries IDs
+// only and replaying it (retries, duplicates) is harmless.
+func NewSearchIndexTask(ctx context.Context, index string, ids []string) (*asynq.Task, error) {
+	payload, err := marshalPayload(ctx, SearchIndexPayload{Index: index, IDs: ids})
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewTask(
+		TaskSearchIndex,
+		payload,
+		asynq.MaxRetry(10),
+		asynq.Queue("default"),
+		asynq.Timeout(time.Minute),
+	), nil
+}
+
+// SearchReindexPayload is the JSON payload for TaskSearchReindex.
+type SearchReindexPayload struct {
+	Index string `json:"index"`
+}
+
+// NewSearchReindexTask constructs a task rebuilding index.
+//
+// Like backfills: low queue, unique while queued or running (uniqueTaskID),
+// and a long but bounded timeout; progress is saved per batch so a retry
+// resumes.
+func NewSearchReindexTask(ctx context.Context, index string) (*asynq.Task, error) {
+	p := SearchReindexPayload{Index: index}
+	payload, err := marshalPayload(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	unique, err := uniqueTaskID(TaskSearchReindex, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewTask(
+		TaskSearchReindex,
+		payload,
+		asynq.MaxRetry(5),
+		asynq.Queue("low"),
+		unique,
+		asynq.Timeout(6*time.Hour),
+	), nil
+}
+
+// InitSearch enables the search tasks with the given runner.
+// Must be called before Start.
+func (j *JobService) InitSearch(runner *search.Runner) {
+	j.search = runner
+}
+
+// handleSearchIndexTask syncs the changed documents.
+func (j *JobService) handleSearchIndexTask(ctx context.Context, t *asynq.Task) error {
+	var p SearchIndexPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("failed to unmarshal search index payload: %w", err)
+	}
+
+	if err := j.search.Sync(ctx, p.Index, p.IDs); err != nil {
+		ctxutil.Logger(ctx).Error().
+			Str("type", "search_index").
+			Str("index", p.Index).
+			Int("documents", len(p.IDs)).
+			Err(err).
+			Msg("Failed to sync search documents")
+		return err
+	}
+
+	return nil
+}
+
+// handleSearchReindexTask runs a full reindex. The first attempt discards
+// the progress of earlier runs; retries resume from the saved cursor.
+//
+// The reset happens here rather than when enqueuing, so a request that
+// fails to enqueue (or collides with a running reindex) leaves the
+// progress alone.
+func (j *JobService) handleSearchReindexTask(ctx context.Context, t *asynq.Task) error {
+	var p SearchReindexPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("failed to unmarshal search reindex payload: %w", err)
+	}
+
+	if retry, _ := asynq.GetRetryCount(ctx); retry == 0 {
+		if err := j.search.Reset(ctx, p.Index); err != nil {
+			return fmt.Errorf("failed to reset search reindex progress: %w", err)
+		}
+	}
+
+	ctxutil.Logger(ctx).Info().
+		Str("type", "search_reindex").
+		Str("index", p.Index).
+		Msg("Processing search reindex task")
+
+	progress, err := j.search.Reindex(ctx, p.Index)
+	if err != nil {
+		ctxutil.Logger(ctx).Error().
+			Str("type", "search_reindex").
+			Str("index", p.Index).
+			Int64("indexed", progress.Indexed).
+			Err(err).
+			Msg("Search reindex interrupted, will resume on retry")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/lib/ctxutil"
+	"github.com/deppfellow/go-boilerplate/internal/lib/softdelete"
+	"github.com/hibiken/asynq"
+)
+
+const (
+	// TaskPurgeDeleted hard-deletes expired soft-deleted rows of a
+	// registered table (see lib/softdelete).
+	TaskPurgeDeleted = "softdelete:purge"
+)
+
+// PurgeDeletedPayload is the JSON payload for TaskPurgeDeleted.
+type PurgeDeletedPayload struct {
+	Table string `json:"table"`
+}
+
+// NewPurgeDeletedTask constructs a task purging the named table.
+//
+// Options:
+//   - Queue("low"): purges are housekeeping, never urgent
+//   - uniqueTaskID: purging the same table twice at once would only contend
+//   - Timeout(1h): batches commit on their own, so a retry continues
+func NewPurgeDeletedTask(ctx context.Context, table string) (*asynq.Task, error) {
+	p := PurgeDeletedPayload{Table: table}
+	payload, err := marshalPayload(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	unique, err := uniqueTaskID(TaskPurgeDeleted, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewTask(
+		TaskPurgeDeleted,
+		payload,
+		asynq.MaxRetry(3),
+		asynq.Queue("low"),
+		unique,
+		asynq.Timeout(time.Hour),
+	), nil
+}
+
+// InitPurger enables TaskPurgeDeleted processing with the given purger.
+// Must be called before Start.
+func (j *JobService) InitPurger(purger *softdelete.Purger) {
+	j.purger = purger
+}
+
+// handlePurgeDeletedTask purges one table.
+func (j *JobService) handlePurgeDeletedTask(ctx context.Context, t *asynq.Task) error {
+	var p PurgeDeletedPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("failed to unmarshal purge payload: %w", err)
+	}
+
+	result, err := j.purger.Purge(ctx, p.Table)
+	if err != nil {
+		ctxutil.Logger(ctx).Error().
+			Str("type", "purge").
+			Str("table", p.Table).
+			Int64("rows_purged", result.Rows).
+			Err(err).
+			Msg("Failed to purge soft-deleted rows")
+		return err
+	}
+
+	return nil
+}
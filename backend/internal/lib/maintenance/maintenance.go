@@ -0,0 +1,174 @@
+// Package maintenance stores the maintenance-mode flag in Redis so every
+// instance sees the same state and toggling it needs no restart or deploy.
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// Key is the Redis key holding the JSON-encoded State.
+// Absent key means maintenance mode is off.
+const Key = "boilerplate:maintenance"
+
+// failureBackoff is how long a failed Redis read is remembered: while Redis
+// is slow or down, requests get the last known state (and the error) at once
+// instead of each waiting out a network timeout.
+const failureBackoff = 5 * time.Second
+
+// State describes an active maintenance window.
+type State struct {
+	// Enabled reports whether maintenance mode is on.
+	Enabled bool `json:"enabled"`
+
+	// Message is shown to clients in the 503 error body.
+	Message string `json:"message"`
+
+	// RetryAfter is the Retry-After hint in seconds.
+	RetryAfter int `json:"retry_after"`
+
+	// RedirectURL is an optional status page returned as an errs.Action redirect.
+	RedirectURL string `json:"redirect_url,omitempty"`
+
+	// StartedAt is when maintenance mode was switched on.
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Store reads and writes State in Redis.
+//
+// Reads are cached in-process for cacheTTL so the middleware doesn't add a
+// Redis round trip to every request. Toggling therefore takes up to cacheTTL
+// to reach every instance. When the cache expires, one request refreshes it
+// and concurrent ones share its result (singleflight); a failed refresh is
+// not retried for failureBackoff.
+//
+// A nil Store, or one with a nil Redis client, always reports maintenance as off.
+type Store struct {
+	redis    *redis.Client
+	cacheTTL time.Duration
+	group    singleflight.Group
+
+	mu        sync.Mutex
+	cached    State
+	fetchedAt time.Time
+	failedAt  time.Time
+	lastErr   error
+}
+
+// NewStore creates a Store on top of client.
+func NewStore(client *redis.Client, cacheTTL time.Duration) *Store {
+	return &Store{
+		redis:    client,
+		cacheTTL: cacheTTL,
+	}
+}
+
+// Get returns the current state (possibly cached).
+//
+// On Redis errors the last known state is returned together with the error,
+// so callers can fail open without flapping.
+func (s *Store) Get(ctx context.Context) (State, error) {
+	if s == nil || s.redis == nil {
+		return State{}, nil
+	}
+
+	s.mu.Lock()
+	if !s.fetchedAt.IsZero() && time.Since(s.fetchedAt) < s.cacheTTL {
+		defer s.mu.Unlock()
+		return s.cached, nil
+	}
+	if !s.failedAt.IsZero() && time.Since(s.failedAt) < failureBackoff {
+		defer s.mu.Unlock()
+		return s.cached, s.lastErr
+	}
+	s.mu.Unlock()
+
+	// The fetch is shared by every waiting request, so it must not be
+	// cancelled with the request that happened to start it.
+	ctx = context.WithoutCancel(ctx)
+	_, err, _ := s.group.Do(Key, func() (any, error) {
+		state, err := s.load(ctx)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if err != nil {
+			s.failedAt, s.lastErr = time.Now(), err
+			return nil, err
+		}
+		s.cached, s.fetchedAt = state, time.Now()
+		s.failedAt, s.lastErr = time.Time{}, nil
+		return nil, nil
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cached, err
+}
+
+// Enable switches maintenance mode on for every instance.
+func (s *Store) Enable(ctx context.Context, state State) error {
+	if s == nil || s.redis == nil {
+		return errors.New("maintenance: redis is not configured")
+	}
+
+	state.Enabled = true
+	if state.StartedAt.IsZero() {
+		state.StartedAt = time.Now().UTC()
+	}
+
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	if err := s.redis.Set(ctx, Key, raw, 0).Err(); err != nil {
+		return err
+	}
+
+	s.invalidate()
+	return nil
+}
+
+// Disable switches maintenance mode off for every instance.
+func (s *Store) Disable(ctx context.Context) error {
+	if s == nil || s.redis == nil {
+		return errors.New("maintenance: redis is not configured")
+	}
+
+	if err := s.redis.Del(ctx, Key).Err(); err != nil {
+		return err
+	}
+
+	s.invalidate()
+	return nil
+}
+
+func (s *Store) load(ctx context.Context) (State, error) {
+	raw, err := s.redis.Get(ctx, Key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, err
+	}
+
+	var state State
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return State{}, err
+	}
+	return state, nil
+}
+
+// invalidate drops the local cache so this instance sees its own toggle immediately.
+func (s *Store) invalidate() {
+	s.mu.Lock()
+	s.fetchedAt = time.Time{}
+	s.failedAt = time.Time{}
+	s.mu.Unlock()
+}
@@ -0,0 +1,108 @@
+// Package pagination implements keyset (cursor) pagination.
+//
+// OFFSET pagination makes the database read and throw away every skipped
+// row, so page 5000 of a large table is slow, and rows inserted between two
+// requests shift pages (duplicates, gaps). A keyset page instead continues
+// after the last row the client saw:
+//
+//	WHERE (created_at, id) < ($1, $2) ORDER BY created_at DESC, id DESC LIMIT 21
+//
+// which an index on (created_at, id) answers directly at any depth.
+//
+// The position is handed to clients as an opaque cursor: the sort key and
+// id of the last row, JSON in base64url. Clients must treat it as a token;
+// it is not signed, as it only selects rows the query would return anyway.
+//
+// Repositories page with repository.ListPage, handlers bind Params from the
+// query string and answer with handler.Paginated.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// Page size bounds for Params.Limit.
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// ErrInvalidCursor is returned by Decode for a cursor that is malformed or
+// was issued for another sort order.
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// Params are the paging query parameters (?cursor=...&limit=...). Request
+// structs embed it:
+//
+//	type ListTodosRequest struct {
+//		pagination.Params
+//		Status string `query:"status"`
+//	}
+type Params struct {
+	// Cursor is the next_cursor of the previous page; empty for the first.
+	Cursor string `query:"cursor" json:"cursor,omitempty"`
+
+	// Limit is the page size, DefaultLimit when 0.
+	Limit int `query:"limit" json:"limit,omitempty" validate:"omitempty,min=1,max=100"`
+}
+
+// GetLimit returns the effective page size.
+func (p Params) GetLimit() int {
+	switch {
+	case p.Limit <= 0:
+		return DefaultLimit
+	case p.Limit > MaxLimit:
+		return MaxLimit
+	default:
+		return p.Limit
+	}
+}
+
+// Cursor is the position after a row: its sort key and id. Sort names the
+// sort order it belongs to, so a cursor can't be replayed against another.
+type Cursor[K any] struct {
+	Sort string `json:"s,omitempty"`
+	Key  K      `json:"k"`
+	ID   string `json:"i"`
+}
+
+// Encode returns the opaque form of c.
+func Encode[K any](c Cursor[K]) (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// Decode parses a cursor produced by Encode for the sort order sort.
+func Decode[K any](cursor, sort string) (Cursor[K], error) {
+	var c Cursor[K]
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" || c.Sort != sort {
+		return Cursor[K]{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// Page is one page of results.
+type Page[T any] struct {
+	Items []T
+
+	// NextCursor continues after the last item; empty on the last page.
+	NextCursor string
+
+	// Limit is the page size that was applied.
+	Limit int
+}
+
+// HasMore reports whether a next page exists.
+func (p Page[T]) HasMore() bool {
+	return p.NextCursor != ""
+}
@@ -0,0 +1,51 @@
+package pagination
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	key := time.Date(2026, 10, 16, 12, 30, 0, 123456000, time.UTC)
+	in := Cursor[time.Time]{Sort: "created_at", Key: key, ID: "0b7c1f2e"}
+
+	encoded, err := Encode(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := Decode[time.Time](encoded, "created_at")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.Key.Equal(key) || out.ID != in.ID || out.Sort != in.Sort {
+		t.Fatalf("got %+v, want %+v", out, in)
+	}
+}
+
+func TestDecodeRejectsInvalidCursors(t *testing.T) {
+	otherSort, _ := Encode(Cursor[string]{Sort: "title", Key: "b", ID: "1"})
+	noID, _ := Encode(Cursor[string]{Sort: "created_at", Key: "b"})
+
+	for name, cursor := range map[string]string{
+		"not base64":     "%%%",
+		"not JSON":       "bm90IGpzb24",
+		"other sort":     otherSort,
+		"missing row ID": noID,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Decode[string](cursor, "created_at"); !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("got %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestParamsGetLimit(t *testing.T) {
+	for limit, want := range map[int]int{0: DefaultLimit, -1: DefaultLimit, 1: 1, 50: 50, MaxLimit + 1: MaxLimit} {
+		if got := (Params{Limit: limit}).GetLimit(); got != want {
+			t.Errorf("GetLimit() with %d = %d, want %d", limit, got, want)
+		}
+	}
+}
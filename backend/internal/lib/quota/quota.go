@@ -0,0 +1,141 @@
+// Package quota accounts API usage per subject (user or API key) in Redis,
+// against plan limits with a daily and a monthly window.
+//
+// Unlike rate limiting (requests per second, in memory, per instance), quotas
+// are long-lived budgets shared by every instance:
+//
+//   - daily: a sliding 24h window, approximated from two day buckets
+//     (current + previous weighted by how much of it still overlaps the
+//     window). It smooths out the midnight "reset burst" of fixed windows for
+//     the cost of two counters.
+//   - monthly: the calendar month (UTC), i.e. the billing period, so the
+//     count doubles as usage accounting.
+package quota
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix namespaces quota counters: boilerplate:quota:<subject>:<bucket>.
+const keyPrefix = "boilerplate:quota:"
+
+// Window names a quota window.
+type Window string
+
+const (
+	Daily   Window = "daily"
+	Monthly Window = "monthly"
+)
+
+// Limits is a plan's budget per window. Zero means unlimited.
+type Limits struct {
+	Daily   int64 `json:"daily"`
+	Monthly int64 `json:"monthly"`
+}
+
+// Usage reports one window's state for a subject.
+type Usage struct {
+	Window    Window    `json:"window"`
+	Limit     int64     `json:"limit"`
+	Used      int64     `json:"used"`
+	Remaining int64     `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+// Exceeded reports whether the window is over its (non-zero) limit.
+func (u Usage) Exceeded() bool {
+	return u.Limit > 0 && u.Used > u.Limit
+}
+
+// Store keeps quota counters in Redis.
+type Store struct {
+	redis *redis.Client
+}
+
+// NewStore creates a Store on top of client.
+func NewStore(client *redis.Client) *Store {
+	return &Store{redis: client}
+}
+
+// Consume counts one request for subject and returns the resulting usage of
+// every window. If any window is exceeded the request is not counted (it is
+// going to be rejected) and the returned usage reflects that.
+func (s *Store) Consume(ctx context.Context, subject string, limits Limits, now time.Time) ([]Usage, error) {
+	return s.read(ctx, subject, limits, now, 1)
+}
+
+// Usage returns the current usage of every window without counting.
+func (s *Store) Usage(ctx context.Context, subject string, limits Limits, now time.Time) ([]Usage, error) {
+	return s.read(ctx, subject, limits, now, 0)
+}
+
+// read adds delta to the current buckets and evaluates both windows.
+func (s *Store) read(ctx context.Context, subject string, limits Limits, now time.Time, delta int64) ([]Usage, error) {
+	if s == nil || s.redis == nil {
+		return nil, fmt.Errorf("quota: redis not configured")
+	}
+
+	now = now.UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	nextMonth := monthStart.AddDate(0, 1, 0)
+
+	dayKey := bucketKey(subject, "day", dayStart.Format("20060102"))
+	prevDayKey := bucketKey(subject, "day", dayStart.AddDate(0, 0, -1).Format("20060102"))
+	monthKey := bucketKey(subject, "month", monthStart.Format("200601"))
+
+	pipe := s.redis.TxPipeline()
+	day := pipe.IncrBy(ctx, dayKey, delta)
+	pipe.ExpireAt(ctx, dayKey, dayStart.Add(48*time.Hour))
+	prevDay := pipe.Get(ctx, prevDayKey)
+	month := pipe.IncrBy(ctx, monthKey, delta)
+	pipe.ExpireAt(ctx, monthKey, nextMonth.Add(24*time.Hour))
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("quota: failed to update counters: %w", err)
+	}
+
+	prev, _ := prevDay.Int64()
+	overlap := 1 - float64(now.Sub(dayStart))/float64(24*time.Hour)
+	dailyUsed := day.Val() + int64(float64(prev)*overlap)
+
+	usage := []Usage{
+		newUsage(Daily, limits.Daily, dailyUsed, dayStart.Add(24*time.Hour)),
+		newUsage(Monthly, limits.Monthly, month.Val(), nextMonth),
+	}
+
+	// A rejected request must not eat into the budget.
+	if delta > 0 && (usage[0].Exceeded() || usage[1].Exceeded()) {
+		pipe := s.redis.TxPipeline()
+		pipe.DecrBy(ctx, dayKey, delta)
+		pipe.DecrBy(ctx, monthKey, delta)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return usage, fmt.Errorf("quota: failed to roll back counters: %w", err)
+		}
+	}
+
+	return usage, nil
+}
+
+// newUsage builds a Usage, clamping Remaining at zero.
+func newUsage(window Window, limit, used int64, resetAt time.Time) Usage {
+	remaining := int64(-1) // unlimited
+	if limit > 0 {
+		remaining = max(limit-used, 0)
+	}
+
+	return Usage{
+		Window:    window,
+		Limit:     limit,
+		Used:      used,
+		Remaining: remaining,
+		ResetAt:   resetAt,
+	}
+}
+
+func bucketKey(subject, window, bucket string) string {
+	return keyPrefix + subject + ":" + window + ":" + bucket
+}
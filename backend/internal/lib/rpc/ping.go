@@ -0,0 +1,36 @@
+package rpc
+
+import "context"
+
+// callerKey is the context key for the calling service.
+type callerKey struct{}
+
+// WithCaller returns a copy of ctx carrying the calling service name
+// (set by the RPC auth middleware for handlers registered with Handle).
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// Caller returns the service that made the RPC call, or "".
+func Caller(ctx context.Context) string {
+	caller, _ := ctx.Value(callerKey{}).(string)
+	return caller
+}
+
+// PingRequest is the (empty) payload of the built-in ping method.
+type PingRequest struct{}
+
+// PingResponse echoes who answered and who called.
+type PingResponse struct {
+	Service string `json:"service"`
+	Caller  string `json:"caller"`
+}
+
+// Ping is the built-in method every service answers when RPC is enabled.
+// It checks the whole path (URL, shared secret, audience) between two
+// services, e.g. from a deploy smoke test:
+//
+//	resp, err := rpc.Ping(config.ServiceWorker).Call(ctx, s.RPC, &rpc.PingRequest{})
+func Ping(service string) Method[PingRequest, PingResponse] {
+	return NewMethod[PingRequest, PingResponse](service, "ping")
+}
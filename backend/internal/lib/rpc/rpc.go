@@ -0,0 +1,193 @@
+// Package rpc is the internal RPC layer between the services of the
+// platform (cmd/api, cmd/worker, cmd/admin, ...): HTTP+JSON calls with
+// typed clients, signed service tokens, retries/timeouts from
+// lib/httpclient and trace propagation.
+//
+// A call is declared once, next to its request/response types, and that
+// declaration is both the server registration and the typed client, which
+// is what code generation would otherwise produce:
+//
+//	var SendDigest = rpc.NewMethod[SendDigestRequest, SendDigestResponse](config.ServiceWorker, "send_digest")
+//
+//	// callee (worker router):
+//	rpc.Handle(g, SendDigest, func(ctx context.Context, req *SendDigestRequest) (*SendDigestResponse, error) { ... })
+//
+//	// caller (api service):
+//	resp, err := SendDigest.Call(ctx, s.RPC, &SendDigestRequest{UserID: id})
+//
+// Calls go to POST <service base URL>/internal/rpc/<method>, authenticated
+// with a short-lived token (see SignToken) as "Authorization: Bearer". The
+// X-Request-ID of the current request is forwarded and New Relic distributed
+// tracing headers are injected, so logs and traces span both services.
+//
+// Calls are retried on transient failures: RPC handlers must be idempotent.
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/lib/ctxutil"
+	"github.com/deppfellow/go-boilerplate/internal/lib/httpclient"
+	"github.com/labstack/echo/v4"
+)
+
+// PathPrefix is where every service mounts its RPC methods.
+const PathPrefix = "/internal/rpc"
+
+// DefaultTokenTTL is the lifetime of a service token. Tokens are signed per
+// call, so it only needs to cover clock skew and retries.
+const DefaultTokenTTL = time.Minute
+
+// Method describes one RPC method: the service serving it, its name and,
+// through its type parameters, the request and response payloads.
+type Method[Req, Resp any] struct {
+	Service string
+	Name    string
+}
+
+// NewMethod declares a method served by service.
+func NewMethod[Req, Resp any](service, name string) Method[Req, Resp] {
+	return Method[Req, Resp]{Service: service, Name: name}
+}
+
+// Path is the route of the method relative to the service base URL.
+func (m Method[Req, Resp]) Path() string {
+	return PathPrefix + "/" + m.Name
+}
+
+// Call invokes the method on its service through c.
+//
+// Errors returned by the remote handler come back as the *errs.HTTPError it
+// produced (same code and status), so callers can pass them straight through
+// to their own clients or inspect the code.
+func (m Method[Req, Resp]) Call(ctx context.Context, c *Client, req *Req) (*Resp, error) {
+	var resp Resp
+	if err := c.call(ctx, m.Service, m.Path(), req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// HandlerFunc implements a method on the serving side.
+type HandlerFunc[Req, Resp any] func(ctx context.Context, req *Req) (*Resp, error)
+
+// Handle registers fn for m on g, which must be the group mounted at
+// PathPrefix behind the service token check (see middleware.RPCMiddleware).
+//
+// Errors are returned to Echo, so the global error handler renders them the
+// same way as for public routes and Call can decode them on the other side.
+func Handle[Req, Resp any](g *echo.Group, m Method[Req, Resp], fn HandlerFunc[Req, Resp]) {
+	g.POST("/"+m.Name, func(c echo.Context) error {
+		var req Req
+		if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil && err != io.EOF {
+			return errs.NewBadRequestError("Invalid RPC payload: "+err.Error(), false, nil, nil, nil)
+		}
+
+		resp, err := fn(c.Request().Context(), &req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	})
+}
+
+// Client calls methods on other services.
+type Client struct {
+	// caller is the name of this service, put in the tokens it signs.
+	caller string
+
+	secret   []byte
+	tokenTTL time.Duration
+	services map[string]string // service name -> base URL
+	http     *httpclient.Client
+}
+
+// NewClient creates a Client signing as caller and resolving services
+// through the name -> base URL map.
+func NewClient(caller string, secret []byte, services map[string]string, tokenTTL time.Duration, httpCfg httpclient.Config) *Client {
+	if tokenTTL <= 0 {
+		tokenTTL = DefaultTokenTTL
+	}
+
+	return &Client{
+		caller:   caller,
+		secret:   secret,
+		tokenTTL: tokenTTL,
+		services: services,
+		http:     httpclient.New(httpCfg),
+	}
+}
+
+// call POSTs in as JSON to path on service and decodes the reply into out.
+func (c *Client) call(ctx context.Context, service, path string, in, out any) error {
+	baseURL, ok := c.services[service]
+	if !ok {
+		return fmt.Errorf("rpc: no URL configured for service %q", service)
+	}
+
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("rpc: failed to encode request: %w", err)
+	}
+
+	token, err := SignToken(c.secret, Claims{
+		Caller:    c.caller,
+		Audience:  service,
+		ExpiresAt: time.Now().Add(c.tokenTTL).Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("rpc: failed to sign token: %w", err)
+	}
+
+	// NewRequestWithContext sets GetBody for bytes.Reader, so retries can resend it.
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(baseURL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("rpc: failed to build request: %w", err)
+	}
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	if requestID := ctxutil.RequestID(ctx); requestID != "" {
+		req.Header.Set(echo.HeaderXRequestID, requestID)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("rpc: %s%s: %w", service, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return decodeError(resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("rpc: failed to decode %s%s response: %w", service, path, err)
+	}
+
+	return nil
+}
+
+// decodeError turns an error response into the remote *errs.HTTPError, or
+// a generic one when the body isn't in that shape (e.g. a proxy's 502 page).
+func decodeError(resp *http.Response) error {
+	var httpErr errs.HTTPError
+	if err := json.NewDecoder(resp.Body).Decode(&httpErr); err != nil || httpErr.Code == "" {
+		return &errs.HTTPError{
+			Code:    errs.MakeUpperCaseWithUnderscores(http.StatusText(resp.StatusCode)),
+			Message: http.StatusText(resp.StatusCode),
+			Status:  resp.StatusCode,
+		}
+	}
+
+	httpErr.Status = resp.StatusCode
+	return &httpErr
+}
@@ -0,0 +1,163 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Elasticsearch implements Indexer over the Elasticsearch REST API.
+//
+// Documents are indexed with dynamic mappings; filterable string fields
+// should be mapped as keyword (or filtered via "<field>.keyword") for exact
+// matches. Writes are visible after the next refresh (1s by default).
+type Elasticsearch struct {
+	t *transport
+}
+
+// EnsureIndex creates the index unless it exists.
+func (e *Elasticsearch) EnsureIndex(ctx context.Context, s IndexSettings) error {
+	path := "/" + url.PathEscape(e.t.index(s.Name))
+	status, err := e.t.do(ctx, http.MethodHead, path, "", nil, nil, http.StatusNotFound)
+	if err != nil || status != http.StatusNotFound {
+		return err
+	}
+
+	_, err = e.t.do(ctx, http.MethodPut, path, "application/json", map[string]any{}, nil)
+	return err
+}
+
+// Upsert indexes documents through the bulk API.
+func (e *Elasticsearch) Upsert(ctx context.Context, index, primaryKey string, docs []Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, doc := range docs {
+		action := map[string]any{"index": map[string]string{"_index": e.t.index(index), "_id": fmt.Sprint(doc[primaryKey])}}
+		if err := enc.Encode(action); err != nil {
+			return err
+		}
+		if err := enc.Encode(doc); err != nil {
+			return fmt.Errorf("search: failed to encode document: %w", err)
+		}
+	}
+
+	return e.bulk(ctx, body.Bytes())
+}
+
+// Delete removes documents through the bulk API.
+func (e *Elasticsearch) Delete(ctx context.Context, index string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, id := range ids {
+		if err := enc.Encode(map[string]any{"delete": map[string]string{"_index": e.t.index(index), "_id": id}}); err != nil {
+			return err
+		}
+	}
+
+	return e.bulk(ctx, body.Bytes())
+}
+
+// bulk sends an NDJSON bulk request. The bulk API answers 200 even when
+// items fail, so per-item errors are checked too (deleting a missing
+// document is not an error).
+func (e *Elasticsearch) bulk(ctx context.Context, ndjson []byte) error {
+	var resp struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int             `json:"status"`
+			Error  json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if _, err := e.t.do(ctx, http.MethodPost, "/_bulk", "application/x-ndjson", ndjson, &resp); err != nil {
+		return err
+	}
+	if !resp.Errors {
+		return nil
+	}
+
+	for _, item := range resp.Items {
+		for op, result := range item {
+			if len(result.Error) > 0 && !(op == "delete" && result.Status == http.StatusNotFound) {
+				return fmt.Errorf("search: bulk %s failed: %s", op, result.Error)
+			}
+		}
+	}
+	return nil
+}
+
+// Search runs q as a bool query: multi_match on the searchable fields (or
+// match_all), term filters, field sorts.
+func (e *Elasticsearch) Search(ctx context.Context, index string, q Query) (*Result, error) {
+	must := []any{map[string]any{"match_all": map[string]any{}}}
+	if q.Text != "" {
+		match := map[string]any{"query": q.Text}
+		// Without registered searchable fields ES searches all of them.
+		if source, ok := Lookup(index); ok && len(source.Searchable) > 0 {
+			match["fields"] = source.Searchable
+		}
+		must = []any{map[string]any{"multi_match": match}}
+	}
+
+	filters := make([]any, 0, len(q.Filters))
+	for field, value := range q.Filters {
+		filters = append(filters, map[string]any{"term": map[string]any{field: value}})
+	}
+
+	body := map[string]any{
+		"from":             q.Offset,
+		"size":             q.limit(),
+		"track_total_hits": true,
+		"query":            map[string]any{"bool": map[string]any{"must": must, "filter": filters}},
+	}
+
+	if len(q.Sort) > 0 {
+		sorts := make([]any, 0, len(q.Sort))
+		for _, field := range q.Sort {
+			order := "asc"
+			if name, desc := strings.CutPrefix(field, "-"); desc {
+				field, order = name, "desc"
+			}
+			sorts = append(sorts, map[string]any{field: map[string]string{"order": order}})
+		}
+		body["sort"] = sorts
+	}
+
+	var resp struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				Source json.RawMessage `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	path := "/" + url.PathEscape(e.t.index(index)) + "/_search"
+	if _, err := e.t.do(ctx, http.MethodPost, path, "application/json", body, &resp); err != nil {
+		return nil, err
+	}
+
+	result := &Result{Total: resp.Hits.Total.Value, Hits: make([]json.RawMessage, 0, len(resp.Hits.Hits))}
+	for _, hit := range resp.Hits.Hits {
+		result.Hits = append(result.Hits, hit.Source)
+	}
+	return result, nil
+}
+
+// Health checks GET /_cluster/health (red clusters answer 503).
+func (e *Elasticsearch) Health(ctx context.Context) error {
+	_, err := e.t.do(ctx, http.MethodGet, "/_cluster/health", "", nil, nil)
+	return err
+}
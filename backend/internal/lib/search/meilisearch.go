@@ -0,0 +1,137 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Meilisearch implements Indexer over the Meilisearch REST API.
+//
+// Meilisearch applies writes asynchronously (it answers 202 with a task):
+// documents become searchable shortly after Upsert returns.
+type Meilisearch struct {
+	t *transport
+}
+
+// EnsureIndex creates the index (a no-op task when it exists) and applies
+// its searchable/filterable/sortable attributes.
+func (m *Meilisearch) EnsureIndex(ctx context.Context, s IndexSettings) error {
+	uid := m.t.index(s.Name)
+
+	if _, err := m.t.do(ctx, http.MethodPost, "/indexes", "application/json",
+		map[string]string{"uid": uid, "primaryKey": s.PrimaryKey}, nil); err != nil {
+		return err
+	}
+
+	searchable := s.Searchable
+	if len(searchable) == 0 {
+		searchable = []string{"*"}
+	}
+
+	_, err := m.t.do(ctx, http.MethodPatch, "/indexes/"+url.PathEscape(uid)+"/settings", "application/json",
+		map[string][]string{
+			"searchableAttributes": searchable,
+			"filterableAttributes": nonNil(s.Filterable),
+			"sortableAttributes":   nonNil(s.Sortable),
+		}, nil)
+	return err
+}
+
+// Upsert adds or replaces documents.
+func (m *Meilisearch) Upsert(ctx context.Context, index, primaryKey string, docs []Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	path := "/indexes/" + url.PathEscape(m.t.index(index)) + "/documents?primaryKey=" + url.QueryEscape(primaryKey)
+	_, err := m.t.do(ctx, http.MethodPost, path, "application/json", docs, nil)
+	return err
+}
+
+// Delete removes documents by primary key.
+func (m *Meilisearch) Delete(ctx context.Context, index string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	path := "/indexes/" + url.PathEscape(m.t.index(index)) + "/documents/delete-batch"
+	_, err := m.t.do(ctx, http.MethodPost, path, "application/json", ids, nil)
+	return err
+}
+
+// Search runs q with Meilisearch's filter and sort syntax.
+func (m *Meilisearch) Search(ctx context.Context, index string, q Query) (*Result, error) {
+	body := map[string]any{
+		"q":      q.Text,
+		"limit":  q.limit(),
+		"offset": q.Offset,
+	}
+
+	if len(q.Filters) > 0 {
+		body["filter"] = meiliFilters(q.Filters)
+	}
+
+	if len(q.Sort) > 0 {
+		sorts := make([]string, 0, len(q.Sort))
+		for _, field := range q.Sort {
+			if name, desc := strings.CutPrefix(field, "-"); desc {
+				sorts = append(sorts, name+":desc")
+			} else {
+				sorts = append(sorts, field+":asc")
+			}
+		}
+		body["sort"] = sorts
+	}
+
+	var resp struct {
+		Hits               []json.RawMessage `json:"hits"`
+		EstimatedTotalHits int64             `json:"estimatedTotalHits"`
+	}
+	path := "/indexes/" + url.PathEscape(m.t.index(index)) + "/search"
+	if _, err := m.t.do(ctx, http.MethodPost, path, "application/json", body, &resp); err != nil {
+		return nil, err
+	}
+
+	return &Result{Hits: resp.Hits, Total: resp.EstimatedTotalHits}, nil
+}
+
+// Health checks GET /health.
+func (m *Meilisearch) Health(ctx context.Context) error {
+	_, err := m.t.do(ctx, http.MethodGet, "/health", "", nil, nil)
+	return err
+}
+
+// meiliFilters renders exact-match filters as `field = "value"` expressions
+// (an array is ANDed by Meilisearch). Keys are sorted for stable queries.
+func meiliFilters(filters map[string]any) []string {
+	fields := make([]string, 0, len(filters))
+	for field := range filters {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	out := make([]string, 0, len(fields))
+	for _, field := range fields {
+		var value string
+		switch v := filters[field].(type) {
+		case string:
+			value = strconv.Quote(v)
+		default:
+			value = fmt.Sprint(v)
+		}
+		out = append(out, field+" = "+value)
+	}
+	return out
+}
+
+// nonNil turns a nil slice into an empty one, so JSON sends [] (reset) not null.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,108 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/lib/httpclient"
+)
+
+// New returns the Indexer of the configured provider.
+func New(cfg *config.SearchConfig) (Indexer, error) {
+	httpCfg := httpclient.DefaultConfig()
+	httpCfg.Timeout = cfg.Timeout
+
+	t := &transport{
+		baseURL: strings.TrimRight(cfg.URL, "/"),
+		prefix:  cfg.IndexPrefix,
+		client:  httpclient.New(httpCfg),
+	}
+
+	switch cfg.Provider {
+	case config.SearchProviderMeilisearch:
+		if cfg.APIKey != "" {
+			t.authorization = "Bearer " + cfg.APIKey
+		}
+		return &Meilisearch{t: t}, nil
+	case config.SearchProviderElasticsearch:
+		if cfg.APIKey != "" {
+			t.authorization = "ApiKey " + cfg.APIKey
+		}
+		return &Elasticsearch{t: t}, nil
+	default:
+		return nil, fmt.Errorf("search: unknown provider %q", cfg.Provider)
+	}
+}
+
+// transport is the JSON-over-HTTP plumbing shared by the providers.
+type transport struct {
+	baseURL       string
+	prefix        string
+	authorization string
+	client        *httpclient.Client
+}
+
+// index returns the physical index name of a logical one.
+func (t *transport) index(name string) string {
+	return t.prefix + name
+}
+
+// do sends body (JSON-encoded unless it is already []byte) and decodes the
+// response into out (skipped when nil). It returns the status code, and an
+// error for non-2xx responses unless the status is listed in allow.
+func (t *transport) do(ctx context.Context, method, path, contentType string, body, out any, allow ...int) (int, error) {
+	var payload []byte
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		payload = b
+	default:
+		encoded, err := json.Marshal(b)
+		if err != nil {
+			return 0, fmt.Errorf("search: failed to encode request: %w", err)
+		}
+		payload = encoded
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, t.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("search: failed to build request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if t.authorization != "" {
+		req.Header.Set("Authorization", t.authorization)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("search: %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	for _, status := range allow {
+		if resp.StatusCode == status {
+			return resp.StatusCode, nil
+		}
+	}
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp.StatusCode, fmt.Errorf("search: %s %s: status %d: %s", method, path, resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, fmt.Errorf("search: failed to decode %s %s response: %w", method, path, err)
+		}
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,198 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// progressKeyPrefix namespaces reindex progress in Redis:
+// boilerplate:search:reindex:<index>.
+const progressKeyPrefix = "boilerplate:search:reindex:"
+
+// Reindex states.
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// Progress is the state of the last full reindex of an index.
+type Progress struct {
+	Index       string     `json:"index"`
+	Status      string     `json:"status"`
+	LastID      string     `json:"last_id"`
+	Indexed     int64      `json:"indexed"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// Runner moves documents from Postgres (Sources) into the Indexer.
+type Runner struct {
+	indexer   Indexer
+	pool      *pgxpool.Pool
+	redis     *redis.Client
+	logger    *zerolog.Logger
+	batchSize int
+}
+
+// NewRunner constructs a Runner sending batchSize documents per request.
+func NewRunner(indexer Indexer, pool *pgxpool.Pool, redisClient *redis.Client, logger *zerolog.Logger, batchSize int) *Runner {
+	return &Runner{
+		indexer:   indexer,
+		pool:      pool,
+		redis:     redisClient,
+		logger:    logger,
+		batchSize: batchSize,
+	}
+}
+
+// Sync brings the given documents of index up to date: the ones the Source
+// still loads are upserted, the others deleted. It is what the search:index
+// job runs, and is safe to repeat.
+func (r *Runner) Sync(ctx context.Context, index string, ids []string) error {
+	source, ok := Lookup(index)
+	if !ok {
+		return fmt.Errorf("search: unknown index %q", index)
+	}
+
+	docs, err := source.Load(ctx, r.pool, ids)
+	if err != nil {
+		return fmt.Errorf("search: failed to load %s documents: %w", index, err)
+	}
+
+	found := make(map[string]struct{}, len(docs))
+	for _, doc := range docs {
+		found[source.documentID(doc)] = struct{}{}
+	}
+
+	var gone []string
+	for _, id := range ids {
+		if _, ok := found[id]; !ok {
+			gone = append(gone, id)
+		}
+	}
+
+	if err := r.indexer.Upsert(ctx, index, source.primaryKey(), docs); err != nil {
+		return err
+	}
+	return r.indexer.Delete(ctx, index, gone)
+}
+
+// Reset marks index for a fresh full reindex (the next Reindex starts over).
+func (r *Runner) Reset(ctx context.Context, index string) error {
+	return r.save(ctx, Progress{Index: index, Status: StatusPending})
+}
+
+// Reindex walks the whole Source of index in batches, resuming from the
+// saved cursor after a failure. Reindexing a completed index is a no-op
+// until Reset.
+//
+// Documents deleted from Postgres are not removed by a reindex (it only
+// upserts); incremental Sync jobs take care of deletions.
+func (r *Runner) Reindex(ctx context.Context, index string) (Progress, error) {
+	source, ok := Lookup(index)
+	if !ok {
+		return Progress{}, fmt.Errorf("search: unknown index %q", index)
+	}
+
+	progress, err := r.Progress(ctx, index)
+	if err != nil {
+		return Progress{}, err
+	}
+	if progress.Status == StatusCompleted {
+		return progress, nil
+	}
+
+	if err := r.indexer.EnsureIndex(ctx, source.Settings()); err != nil {
+		return progress, r.fail(ctx, progress, err)
+	}
+
+	now := time.Now().UTC()
+	if progress.StartedAt == nil {
+		progress.StartedAt = &now
+	}
+	progress.Status = StatusRunning
+	progress.Error = ""
+
+	for {
+		docs, lastID, err := source.Batch(ctx, r.pool, progress.LastID, r.batchSize)
+		if err != nil {
+			return progress, r.fail(ctx, progress, fmt.Errorf("search: failed to read %s batch: %w", index, err))
+		}
+
+		if len(docs) == 0 {
+			completed := time.Now().UTC()
+			progress.Status = StatusCompleted
+			progress.CompletedAt = &completed
+			progress.UpdatedAt = &completed
+
+			r.logger.Info().
+				Str("index", index).
+				Int64("indexed", progress.Indexed).
+				Msg("search reindex completed")
+
+			return progress, r.save(ctx, progress)
+		}
+
+		if err := r.indexer.Upsert(ctx, index, source.primaryKey(), docs); err != nil {
+			return progress, r.fail(ctx, progress, err)
+		}
+
+		updated := time.Now().UTC()
+		progress.LastID = lastID
+		progress.Indexed += int64(len(docs))
+		progress.UpdatedAt = &updated
+
+		if err := r.save(ctx, progress); err != nil {
+			return progress, err
+		}
+	}
+}
+
+// Progress returns the saved reindex state (StatusPending if none).
+func (r *Runner) Progress(ctx context.Context, index string) (Progress, error) {
+	raw, err := r.redis.Get(ctx, progressKeyPrefix+index).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return Progress{Index: index, Status: StatusPending}, nil
+	}
+	if err != nil {
+		return Progress{}, fmt.Errorf("search: failed to read reindex progress: %w", err)
+	}
+
+	var progress Progress
+	if err := json.Unmarshal(raw, &progress); err != nil {
+		return Progress{}, fmt.Errorf("search: corrupt reindex progress for %s: %w", index, err)
+	}
+	return progress, nil
+}
+
+// fail records err on progress (so the dashboard shows it) and returns it.
+func (r *Runner) fail(ctx context.Context, progress Progress, err error) error {
+	progress.Status = StatusFailed
+	progress.Error = err.Error()
+	if saveErr := r.save(ctx, progress); saveErr != nil {
+		r.logger.Error().Err(saveErr).Str("index", progress.Index).Msg("failed to save reindex progress")
+	}
+	return err
+}
+
+func (r *Runner) save(ctx context.Context, progress Progress) error {
+	raw, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	if err := r.redis.Set(ctx, progressKeyPrefix+progress.Index, raw, 0).Err(); err != nil {
+		return fmt.Errorf("search: failed to save reindex progress: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+// Package search keeps an external search index (Meilisearch or
+// Elasticsearch) in sync with Postgres, for apps that outgrow Postgres
+// full-text search.
+//
+// Postgres stays the source of truth; the index is a projection of it:
+//
+//  1. A Source describes an index: which rows become documents and how
+//     to load them (Register, at init time).
+//  2. Repository writes declare what changed with repository.Base.Reindex.
+//     After COMMIT this enqueues a search:index job (the job queue is the
+//     event bus), whose handler reloads the documents through the Source and
+//     upserts them, or deletes the ones that no longer load.
+//  3. A full reindex (search:reindex job, admin endpoint) walks the whole
+//     Source in batches, with progress tracked in Redis (see Runner).
+//  4. Services query through an Indexer (server.Search), typically with
+//     SearchAs to decode hits into their own types.
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Document is one indexed record. It must contain the Source's primary key.
+type Document map[string]any
+
+// Query is a provider-independent search request.
+type Query struct {
+	// Text is the full-text query ("" matches everything).
+	Text string
+
+	// Filters are exact matches, ANDed: {"status": "open", "org_id": "org_1"}.
+	// Fields must be listed in Source.Filterable.
+	Filters map[string]any
+
+	// Sort lists fields to sort by, "-" prefixed for descending ("-created_at").
+	// Fields must be listed in Source.Sortable.
+	Sort []string
+
+	// Limit defaults to 20; Offset skips that many hits.
+	Limit  int
+	Offset int
+}
+
+// Result is one page of hits.
+type Result struct {
+	// Hits are the matching documents as stored in the index.
+	Hits []json.RawMessage `json:"hits"`
+
+	// Total is the (possibly estimated) number of matches.
+	Total int64 `json:"total"`
+}
+
+// IndexSettings are the parts of a Source the search server needs to know.
+type IndexSettings struct {
+	Name       string
+	PrimaryKey string
+	Searchable []string
+	Filterable []string
+	Sortable   []string
+}
+
+// Indexer is implemented by each search provider.
+//
+// Index names are the logical names of the Sources; implementations add the
+// configured IndexPrefix.
+type Indexer interface {
+	// EnsureIndex creates the index if needed and applies its settings.
+	EnsureIndex(ctx context.Context, settings IndexSettings) error
+
+	// Upsert adds or replaces documents.
+	Upsert(ctx context.Context, index, primaryKey string, docs []Document) error
+
+	// Delete removes documents by primary key.
+	Delete(ctx context.Context, index string, ids []string) error
+
+	// Search runs a query.
+	Search(ctx context.Context, index string, q Query) (*Result, error)
+
+	// Health returns nil when the search server is reachable.
+	Health(ctx context.Context) error
+}
+
+// LoadFunc returns the documents of the given primary keys. Keys without a
+// document (deleted rows, or rows that should no longer be searchable) are
+// simply left out and get removed from the index.
+type LoadFunc func(ctx context.Context, db *pgxpool.Pool, ids []string) ([]Document, error)
+
+// BatchFunc returns up to limit documents with primary keys greater than
+// afterID ("" = from the start), in key order, and the last key returned.
+// An empty batch means the source is exhausted.
+type BatchFunc func(ctx context.Context, db *pgxpool.Pool, afterID string, limit int) (docs []Document, lastID string, err error)
+
+// Source describes one search index and where its documents come from.
+type Source struct {
+	// Index is the logical index name, e.g. "todos".
+	Index string
+
+	// PrimaryKey is the document field holding the ID. Defaults to "id".
+	PrimaryKey string
+
+	// Searchable, Filterable and Sortable configure the index (see Query).
+	Searchable []string
+	Filterable []string
+	Sortable   []string
+
+	// Load (incremental indexing) and Batch (full reindex) read Postgres.
+	Load  LoadFunc
+	Batch BatchFunc
+}
+
+// Settings returns the index settings of s.
+func (s Source) Settings() IndexSettings {
+	return IndexSettings{
+		Name:       s.Index,
+		PrimaryKey: s.primaryKey(),
+		Searchable: s.Searchable,
+		Filterable: s.Filterable,
+		Sortable:   s.Sortable,
+	}
+}
+
+func (s Source) primaryKey() string {
+	if s.PrimaryKey == "" {
+		return "id"
+	}
+	return s.PrimaryKey
+}
+
+// documentID returns the primary key of doc as a string.
+func (s Source) documentID(doc Document) string {
+	return fmt.Sprint(doc[s.primaryKey()])
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Source{}
+)
+
+// Register makes a source indexable by name. It panics on duplicates, as
+// registrations happen at init time.
+func Register(s Source) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[s.Index]; exists {
+		panic(fmt.Sprintf("search: index %q registered twice", s.Index))
+	}
+	registry[s.Index] = s
+}
+
+// Lookup returns a registered source.
+func Lookup(index string) (Source, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	s, ok := registry[index]
+	return s, ok
+}
+
+// Names lists registered indexes.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SearchAs runs q on index and decodes the hits into T:
+//
+//	todos, total, err := search.SearchAs[TodoHit](ctx, s.Search, "todos", search.Query{Text: "milk"})
+func SearchAs[T any](ctx context.Context, indexer Indexer, index string, q Query) ([]T, int64, error) {
+	result, err := indexer.Search(ctx, index, q)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hits := make([]T, 0, len(result.Hits))
+	for _, raw := range result.Hits {
+		var hit T
+		if err := json.Unmarshal(raw, &hit); err != nil {
+			return nil, 0, fmt.Errorf("search: failed to decode %s hit: %w", index, err)
+		}
+		hits = append(hits, hit)
+	}
+
+	return hits, result.Total, nil
+}
+
+// limit returns q.Limit or the default page size.
+func (q Query) limit() int {
+	if q.Limit <= 0 {
+		return 20
+	}
+	return q.Limit
+}
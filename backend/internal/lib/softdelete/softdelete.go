@@ -0,0 +1,169 @@
+// Package softdelete purges soft-deleted rows for good once their retention
+// has passed.
+//
+// Soft deletion itself (SoftDelete, Restore and the deleted_at IS NULL
+// scoping of reads) lives in the repository package; see
+// repository/soft_delete.go for the table conventions. Purging is separate
+// and opt-in per table, as dropping the rows a user could still restore is a
+// product decision:
+//
+//	func init() {
+//		softdelete.Register(softdelete.Table{Name: "todos", Retention: 30 * 24 * time.Hour})
+//	}
+//
+// and then, from an admin endpoint or a scheduled job:
+//
+//	POST /api/v1/admin/soft-deletes/todos/purge
+package softdelete
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+// Column is the soft delete timestamp column: NULL while the row is live.
+const Column = "deleted_at"
+
+// Table describes one table whose soft-deleted rows may be purged.
+type Table struct {
+	// Name is the table name.
+	Name string
+
+	// Retention is how long a row stays restorable after its deletion.
+	// Defaults to 30 days.
+	Retention time.Duration
+
+	// BatchSize is the number of rows deleted per statement, keeping locks
+	// and WAL bursts short. Defaults to 500.
+	BatchSize int
+
+	// Pause is slept between batches to leave headroom for live traffic.
+	Pause time.Duration
+}
+
+// Defaults for Table.
+const (
+	DefaultRetention = 30 * 24 * time.Hour
+	DefaultBatchSize = 500
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Table{}
+)
+
+// Register makes a table purgeable by name. It panics on duplicates, as
+// registrations happen at init time.
+func Register(t Table) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[t.Name]; exists {
+		panic(fmt.Sprintf("softdelete: %q registered twice", t.Name))
+	}
+	if t.Retention <= 0 {
+		t.Retention = DefaultRetention
+	}
+	if t.BatchSize <= 0 {
+		t.BatchSize = DefaultBatchSize
+	}
+	registry[t.Name] = t
+}
+
+// Lookup returns a registered table.
+func Lookup(name string) (Table, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	t, ok := registry[name]
+	return t, ok
+}
+
+// Names lists registered tables.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PurgeResult reports one purge run.
+type PurgeResult struct {
+	Table string `json:"table"`
+	// Before is the cutoff: rows deleted earlier were purged.
+	Before time.Time `json:"before"`
+	Rows   int64     `json:"rows"`
+}
+
+// Purger hard-deletes expired soft-deleted rows of registered tables.
+type Purger struct {
+	pool   *pgxpool.Pool
+	logger *zerolog.Logger
+}
+
+// NewPurger constructs a Purger.
+func NewPurger(pool *pgxpool.Pool, logger *zerolog.Logger) *Purger {
+	return &Purger{pool: pool, logger: logger}
+}
+
+// Purge deletes the rows of the named table that were soft-deleted longer
+// than its retention ago, batch by batch until none is left. Each batch
+// commits on its own, so an interrupted purge keeps what it deleted and a
+// rerun picks up the rest.
+//
+// Rows still referenced by foreign keys without ON DELETE CASCADE make the
+// batch fail; purge (or cascade) the children first.
+func (p *Purger) Purge(ctx context.Context, name string) (PurgeResult, error) {
+	t, ok := Lookup(name)
+	if !ok {
+		return PurgeResult{}, fmt.Errorf("table %q is not registered for purging", name)
+	}
+
+	result := PurgeResult{Table: t.Name, Before: time.Now().Add(-t.Retention).UTC()}
+
+	table := pgx.Identifier{t.Name}.Sanitize()
+	query := fmt.Sprintf(
+		"DELETE FROM %s WHERE ctid = ANY(ARRAY(SELECT ctid FROM %s WHERE %s < $1 LIMIT $2))",
+		table, table, pgx.Identifier{Column}.Sanitize(),
+	)
+
+	for {
+		tag, err := p.pool.Exec(ctx, query, result.Before, t.BatchSize)
+		if err != nil {
+			return result, fmt.Errorf("failed to purge %s: %w", t.Name, err)
+		}
+		result.Rows += tag.RowsAffected()
+
+		if tag.RowsAffected() < int64(t.BatchSize) {
+			break
+		}
+
+		if t.Pause > 0 {
+			select {
+			case <-ctx.Done():
+				return result, ctx.Err()
+			case <-time.After(t.Pause):
+			}
+		}
+	}
+
+	p.logger.Info().
+		Str("table", t.Name).
+		Time("before", result.Before).
+		Int64("rows", result.Rows).
+		Msg("purged soft-deleted rows")
+
+	return result, nil
+}
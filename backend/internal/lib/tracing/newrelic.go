@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/newrelic/go-agent/v3/integrations/nrecho-v4"
+	"github.com/newrelic/go-agent/v3/integrations/nrpgx5"
+	"github.com/newrelic/go-agent/v3/integrations/nrpkgerrors"
+	"github.com/newrelic/go-agent/v3/integrations/nrredis-v9"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/redis/go-redis/v9"
+)
+
+// newRelicTracer maps Tracer onto the New Relic agent. Spans are
+// transactions; the agent's own integrations trace queries and Redis.
+type newRelicTracer struct {
+	app *newrelic.Application
+}
+
+// NewRelic returns a Tracer recording into app.
+func NewRelic(app *newrelic.Application) Tracer {
+	return newRelicTracer{app: app}
+}
+
+func (t newRelicTracer) Name() string { return config.TracingNewRelic }
+
+// Middleware runs nrecho (one transaction per request, stored in the context
+// for the New Relic integrations) and exposes that transaction as the Span.
+func (t newRelicTracer) Middleware() echo.MiddlewareFunc {
+	nr := nrecho.Middleware(t.app)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return nr(func(c echo.Context) error {
+			req := c.Request()
+			if txn := newrelic.FromContext(req.Context()); txn != nil {
+				c.SetRequest(req.WithContext(ContextWithSpan(req.Context(), &nrSpan{txn: txn})))
+			}
+			return next(c)
+		})
+	}
+}
+
+// Start begins a transaction, linked to the trace of WithRemoteParent when
+// ctx carries one.
+func (t newRelicTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	txn := t.app.StartTransaction(name)
+	if h := remoteParent(ctx); h != nil {
+		txn.AcceptDistributedTraceHeaders(newrelic.TransportQueue, h)
+	}
+	span := &nrSpan{txn: txn, owned: true}
+	return ContextWithSpan(newrelic.NewContext(ctx, txn), span), span
+}
+
+func (t newRelicTracer) QueryTracer() pgx.QueryTracer { return nrpgx5.NewTracer() }
+
+func (t newRelicTracer) RedisHook(opts *redis.Options) redis.Hook { return nrredis.NewHook(opts) }
+
+func (t newRelicTracer) Shutdown(context.Context) error {
+	// The application belongs to the logger service, which shuts it down.
+	return nil
+}
+
+// nrSpan is a New Relic transaction. owned is set for transactions started
+// by Start; request transactions are ended by nrecho.
+type nrSpan struct {
+	txn   *newrelic.Transaction
+	owned bool
+}
+
+func (s *nrSpan) SetAttribute(key string, value any) { s.txn.AddAttribute(key, value) }
+
+// RecordError wraps err with nrpkgerrors so New Relic shows its stack trace.
+func (s *nrSpan) RecordError(err error) { s.txn.NoticeError(nrpkgerrors.Wrap(err)) }
+
+func (s *nrSpan) AddEvent(name string, attrs map[string]any) {
+	if app := s.txn.Application(); app != nil {
+		app.RecordCustomEvent(name, attrs)
+	}
+}
+
+func (s *nrSpan) TraceID() string { return s.txn.GetTraceMetadata().TraceID }
+
+func (s *nrSpan) SpanID() string { return s.txn.GetTraceMetadata().SpanID }
+
+func (s *nrSpan) End() {
+	if s.owned {
+		s.txn.End()
+	}
+}
+
+func (s *nrSpan) inject(h http.Header) { s.txn.InsertDistributedTraceHeaders(h) }
+
+// roundTrip records the call as an external segment and adds the
+// distributed tracing headers.
+func (s *nrSpan) roundTrip(base http.RoundTripper, req *http.Request) (*http.Response, error) {
+	return newrelic.NewRoundTripper(base).RoundTrip(req)
+}
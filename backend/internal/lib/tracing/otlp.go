@@ -0,0 +1,342 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/buildinfo"
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceParentHeader is the W3C Trace Context header used to continue traces
+// across services (https://www.w3.org/TR/trace-context/).
+const TraceParentHeader = "traceparent"
+
+// maxStatementLength caps db.statement so huge IN lists don't bloat spans.
+const maxStatementLength = 2048
+
+// instrumentationScope names this code as the producer of the spans.
+const instrumentationScope = "github.com/deppfellow/go-boilerplate/internal/lib/tracing"
+
+// exportErrorLogInterval is how often export failures are logged while the
+// collector stays unreachable (the first one is logged right away).
+const exportErrorLogInterval = time.Minute
+
+// otlpTracer records OpenTelemetry spans with the OTel SDK, which batches
+// them and exports them over OTLP/HTTP. Unsampled spans still carry IDs (for
+// logs and propagation) but are not exported.
+type otlpTracer struct {
+	provider   *sdktrace.TracerProvider
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// NewOTLP returns a Tracer exporting to cfg.Endpoint. The SDK's batch
+// processor runs in the background until Shutdown; export failures are
+// logged to logger, rate limited.
+func NewOTLP(cfg config.OTLPConfig, serviceName, environment string, logger *zerolog.Logger) (Tracer, error) {
+	exporter, err := otlptracehttp.New(context.Background(),
+		otlptracehttp.WithEndpointURL(strings.TrimSuffix(cfg.Endpoint, "/")+"/v1/traces"),
+		otlptracehttp.WithHeaders(cfg.Headers),
+		otlptracehttp.WithTimeout(cfg.Timeout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("otlp exporter: %w", err)
+	}
+
+	// The SDK reports export failures through the global error handler.
+	otel.SetErrorHandler(newExportErrorHandler(logger))
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", serviceName),
+			attribute.String("deployment.environment", environment),
+			attribute.String("service.version", buildinfo.Get().Version),
+		)),
+	)
+
+	return &otlpTracer{
+		provider:   provider,
+		tracer:     provider.Tracer(instrumentationScope),
+		propagator: propagation.TraceContext{},
+	}, nil
+}
+
+func (t *otlpTracer) Name() string { return config.TracingOTLP }
+
+// Middleware starts a server span per request, continuing the caller's trace
+// when a valid traceparent header is present.
+func (t *otlpTracer) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+
+			ctx := t.propagator.Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+			ctx, span := t.start(ctx, req.Method+" "+route, trace.SpanKindServer)
+			defer span.End()
+
+			span.SetAttribute("http.method", req.Method)
+			span.SetAttribute("http.route", route)
+
+			c.SetRequest(req.WithContext(ctx))
+
+			// Errors and the status code are recorded by the tracing
+			// middleware (EnhanceTracing), like for every provider. Only a
+			// 5xx written without an error is left to flag here.
+			err := next(c)
+			if status := c.Response().Status; err == nil && status >= http.StatusInternalServerError {
+				span.setError(http.StatusText(status))
+			}
+			return err
+		}
+	}
+}
+
+// Start begins a new trace, or continues the one of WithRemoteParent.
+func (t *otlpTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	if headers := remoteParent(ctx); headers != nil {
+		ctx = t.propagator.Extract(ctx, propagation.HeaderCarrier(headers))
+	}
+	return t.start(ctx, name, trace.SpanKindInternal)
+}
+
+func (t *otlpTracer) QueryTracer() pgx.QueryTracer { return otlpQueryTracer{} }
+
+func (t *otlpTracer) RedisHook(*redis.Options) redis.Hook { return otlpRedisHook{} }
+
+func (t *otlpTracer) Shutdown(ctx context.Context) error { return t.provider.Shutdown(ctx) }
+
+// start begins a span below the OTel span of ctx (if any) and returns a
+// context carrying it both for the SDK and for FromContext.
+func (t *otlpTracer) start(ctx context.Context, name string, kind trace.SpanKind) (context.Context, *otlpSpan) {
+	ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(kind))
+	s := &otlpSpan{tracer: t, span: span}
+	return ContextWithSpan(ctx, s), s
+}
+
+// otlpSpan adapts an OTel span to Span.
+type otlpSpan struct {
+	tracer *otlpTracer
+	span   trace.Span
+}
+
+func (s *otlpSpan) SetAttribute(key string, value any) {
+	s.span.SetAttributes(keyValue(key, value))
+}
+
+func (s *otlpSpan) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s *otlpSpan) setError(msg string) { s.span.SetStatus(codes.Error, msg) }
+
+func (s *otlpSpan) AddEvent(name string, attrs map[string]any) {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for key, value := range attrs {
+		kvs = append(kvs, keyValue(key, value))
+	}
+	s.span.AddEvent(name, trace.WithAttributes(kvs...))
+}
+
+func (s *otlpSpan) TraceID() string { return s.span.SpanContext().TraceID().String() }
+
+func (s *otlpSpan) SpanID() string { return s.span.SpanContext().SpanID().String() }
+
+func (s *otlpSpan) End() { s.span.End() }
+
+func (s *otlpSpan) inject(h http.Header) {
+	ctx := trace.ContextWithSpan(context.Background(), s.span)
+	s.tracer.propagator.Inject(ctx, propagation.HeaderCarrier(h))
+}
+
+// roundTrip records an outgoing call as a client span and propagates the
+// trace with a traceparent header.
+func (s *otlpSpan) roundTrip(base http.RoundTripper, req *http.Request) (*http.Response, error) {
+	ctx, span := s.tracer.start(req.Context(), "HTTP "+req.Method, trace.SpanKindClient)
+	defer span.End()
+
+	span.SetAttribute("http.method", req.Method)
+	span.SetAttribute("http.url", req.URL.Redacted())
+	span.SetAttribute("server.address", req.URL.Host)
+
+	req = req.Clone(ctx)
+	span.inject(req.Header)
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		return resp, err
+	}
+
+	span.SetAttribute("http.status_code", resp.StatusCode)
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.setError(resp.Status)
+	}
+	return resp, nil
+}
+
+// keyValue converts one attribute; types without an OTel scalar are
+// written with fmt.
+func keyValue(key string, value any) attribute.KeyValue {
+	switch x := value.(type) {
+	case string:
+		return attribute.String(key, x)
+	case bool:
+		return attribute.Bool(key, x)
+	case int:
+		return attribute.Int(key, x)
+	case int32:
+		return attribute.Int64(key, int64(x))
+	case int64:
+		return attribute.Int64(key, x)
+	case float32:
+		return attribute.Float64(key, float64(x))
+	case float64:
+		return attribute.Float64(key, x)
+	default:
+		return attribute.String(key, fmt.Sprint(x))
+	}
+}
+
+// exportErrorHandler logs the SDK's export failures: the first right away,
+// then at most one per exportErrorLogInterval with the number suppressed, so
+// a collector outage is visible without flooding the logs.
+type exportErrorHandler struct {
+	logger *zerolog.Logger
+
+	mu         sync.Mutex
+	lastLogged time.Time
+	suppressed int
+}
+
+func newExportErrorHandler(logger *zerolog.Logger) *exportErrorHandler {
+	return &exportErrorHandler{logger: logger}
+}
+
+func (h *exportErrorHandler) Handle(err error) {
+	h.mu.Lock()
+	now := time.Now()
+	if !h.lastLogged.IsZero() && now.Sub(h.lastLogged) < exportErrorLogInterval {
+		h.suppressed++
+		h.mu.Unlock()
+		return
+	}
+	suppressed := h.suppressed
+	h.lastLogged, h.suppressed = now, 0
+	h.mu.Unlock()
+
+	h.logger.Error().Err(err).
+		Int("suppressed_errors", suppressed).
+		Msg("otlp trace export failed")
+}
+
+// otlpQueryTracer records each query as a child span of the context's span.
+// Queries without a traced context (migrations, startup) are skipped.
+type otlpQueryTracer struct{}
+
+type querySpanKey struct{}
+
+func (otlpQueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	parent, ok := FromContext(ctx).(*otlpSpan)
+	if !ok {
+		return ctx
+	}
+
+	_, span := parent.tracer.start(ctx, "db.query", trace.SpanKindClient)
+	span.SetAttribute("db.system", "postgresql")
+
+	statement := data.SQL
+	if len(statement) > maxStatementLength {
+		statement = statement[:maxStatementLength]
+	}
+	span.SetAttribute("db.statement", statement)
+
+	return context.WithValue(ctx, querySpanKey{}, span)
+}
+
+func (otlpQueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span, ok := ctx.Value(querySpanKey{}).(*otlpSpan)
+	if !ok {
+		return
+	}
+	if data.Err != nil {
+		span.RecordError(data.Err)
+	} else {
+		span.SetAttribute("db.rows_affected", data.CommandTag.RowsAffected())
+	}
+	span.End()
+}
+
+// otlpRedisHook records Redis commands as child spans of the context's span.
+type otlpRedisHook struct{}
+
+func (otlpRedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (otlpRedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		parent, ok := FromContext(ctx).(*otlpSpan)
+		if !ok {
+			return next(ctx, cmd)
+		}
+
+		ctx, span := parent.tracer.start(ctx, "redis "+cmd.Name(), trace.SpanKindClient)
+		defer span.End()
+		span.SetAttribute("db.system", "redis")
+		span.SetAttribute("db.operation", cmd.Name())
+
+		err := next(ctx, cmd)
+		if err != nil && err != redis.Nil {
+			span.RecordError(err)
+		}
+		return err
+	}
+}
+
+func (otlpRedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		parent, ok := FromContext(ctx).(*otlpSpan)
+		if !ok {
+			return next(ctx, cmds)
+		}
+
+		ctx, span := parent.tracer.start(ctx, "redis pipeline", trace.SpanKindClient)
+		defer span.End()
+		span.SetAttribute("db.system", "redis")
+		span.SetAttribute("db.redis.pipeline_length", len(cmds))
+
+		err := next(ctx, cmds)
+		if err != nil && err != redis.Nil {
+			span.RecordError(err)
+		}
+		return err
+	}
+}
@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+)
+
+// injector is implemented by spans that can write their trace context as
+// headers (traceparent for OTLP, the New Relic distributed tracing headers).
+type injector interface {
+	inject(h http.Header)
+}
+
+// Inject returns the trace headers of the current span of ctx, for work
+// that continues in another process without an HTTP call (a queued job).
+// It returns nil when ctx is not traced.
+func Inject(ctx context.Context) map[string]string {
+	span, ok := FromContext(ctx).(injector)
+	if !ok {
+		return nil
+	}
+
+	h := http.Header{}
+	span.inject(h)
+	if len(h) == 0 {
+		return nil
+	}
+
+	headers := make(map[string]string, len(h))
+	for key := range h {
+		headers[key] = h.Get(key)
+	}
+	return headers
+}
+
+type remoteParentKey struct{}
+
+// WithRemoteParent returns a copy of ctx carrying headers produced by
+// Inject. The next Tracer.Start on it continues that trace instead of
+// starting a new one.
+func WithRemoteParent(ctx context.Context, headers map[string]string) context.Context {
+	if len(headers) == 0 {
+		return ctx
+	}
+
+	h := http.Header{}
+	for key, value := range headers {
+		h.Set(key, value)
+	}
+	return context.WithValue(ctx, remoteParentKey{}, h)
+}
+
+// remoteParent returns the headers stored by WithRemoteParent, or nil.
+func remoteParent(ctx context.Context) http.Header {
+	h, _ := ctx.Value(remoteParentKey{}).(http.Header)
+	return h
+}
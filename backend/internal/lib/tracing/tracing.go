@@ -0,0 +1,153 @@
+// Package tracing is the APM abstraction used by handlers, middleware, the
+// database pool, Redis and jobs.
+//
+// Code records onto the Span of its context (FromContext) and never talks to
+// a vendor SDK directly; the Tracer chosen by observability.tracing.provider
+// decides where spans go:
+//   - newrelic: the New Relic agent (transactions, nrpgx5, nrredis)
+//   - otlp:     OpenTelemetry spans recorded with the OTel SDK and exported
+//     over OTLP/HTTP (Jaeger, Tempo, Datadog agent, OpenTelemetry Collector, ...)
+//   - none:     nothing is recorded
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// Span is one unit of traced work: an HTTP request, a job, a query.
+type Span interface {
+	// SetAttribute attaches a key/value (string, bool, int, float) to the span.
+	SetAttribute(key string, value any)
+
+	// RecordError marks the span as failed with err.
+	RecordError(err error)
+
+	// AddEvent records a named point-in-time event with attributes
+	// (a custom event in New Relic, a span event in OTLP).
+	AddEvent(name string, attrs map[string]any)
+
+	// TraceID and SpanID identify the span for log correlation
+	// ("" when the provider has none).
+	TraceID() string
+	SpanID() string
+
+	// End finishes a span returned by Tracer.Start. Spans taken from a
+	// request context are ended by the tracing middleware.
+	End()
+}
+
+// Tracer is an APM provider.
+type Tracer interface {
+	// Name is the provider name (config.TracingNewRelic, ...).
+	Name() string
+
+	// Middleware starts a span per HTTP request and stores it in the
+	// request context, where FromContext finds it.
+	Middleware() echo.MiddlewareFunc
+
+	// Start begins a root span outside of HTTP (a job, a scheduled run).
+	// The returned context carries it; the caller ends it.
+	Start(ctx context.Context, name string) (context.Context, Span)
+
+	// QueryTracer instruments pgx queries; nil when the provider has none.
+	QueryTracer() pgx.QueryTracer
+
+	// RedisHook instruments go-redis commands; nil when the provider has none.
+	RedisHook(opts *redis.Options) redis.Hook
+
+	// Shutdown flushes buffered spans.
+	Shutdown(ctx context.Context) error
+}
+
+// New returns the Tracer selected by cfg.Tracing.Provider. nrApp is the
+// New Relic application of the logger service (nil when not configured);
+// logger receives export failures of the otlp provider.
+func New(cfg *config.ObservabilityConfig, nrApp *newrelic.Application, logger *zerolog.Logger) (Tracer, error) {
+	switch cfg.Tracing.Provider {
+	case config.TracingNewRelic, "":
+		if nrApp == nil {
+			return Noop{}, nil
+		}
+		return NewRelic(nrApp), nil
+	case config.TracingOTLP:
+		return NewOTLP(cfg.Tracing.OTLP, cfg.ServiceName, cfg.Environment, logger)
+	case config.TracingNone:
+		return Noop{}, nil
+	default:
+		return nil, fmt.Errorf("unknown tracing provider %q", cfg.Tracing.Provider)
+	}
+}
+
+type spanKey struct{}
+
+// ContextWithSpan returns a copy of ctx carrying span.
+func ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// FromContext returns the current span of ctx, or nil when nothing is traced.
+func FromContext(ctx context.Context) Span {
+	span, _ := ctx.Value(spanKey{}).(Span)
+	return span
+}
+
+// clientSpan is implemented by spans that can trace an outgoing HTTP call
+// (and propagate the trace to the callee).
+type clientSpan interface {
+	roundTrip(base http.RoundTripper, req *http.Request) (*http.Response, error)
+}
+
+// Transport wraps base so outgoing requests made with a traced context are
+// recorded as child spans and carry the trace headers to the next service.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	return transport{base: base}
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+func (t transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if span, ok := FromContext(req.Context()).(clientSpan); ok {
+		return span.roundTrip(t.base, req)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// Noop is the Tracer of the "none" provider (and of "newrelic" without a
+// license key): requests carry no span and nothing is exported.
+type Noop struct{}
+
+func (Noop) Name() string { return config.TracingNone }
+
+func (Noop) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc { return next }
+}
+
+func (Noop) Start(ctx context.Context, _ string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+func (Noop) QueryTracer() pgx.QueryTracer { return nil }
+
+func (Noop) RedisHook(*redis.Options) redis.Hook { return nil }
+
+func (Noop) Shutdown(context.Context) error { return nil }
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, any)        {}
+func (noopSpan) RecordError(error)               {}
+func (noopSpan) AddEvent(string, map[string]any) {}
+func (noopSpan) TraceID() string                 { return "" }
+func (noopSpan) SpanID() string                  { return "" }
+func (noopSpan) End()                            {}
@@ -13,7 +13,9 @@ import (
 	"os"
 	"time"
 
+	"github.com/deppfellow/go-boilerplate/internal/buildinfo"
 	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/lib/tracing"
 	"github.com/newrelic/go-agent/v3/integrations/logcontext-v2/zerologWriter"
 	"github.com/newrelic/go-agent/v3/newrelic"
 	"github.com/rs/zerolog"
@@ -50,6 +52,12 @@ func NewLoggerService(cfg *config.ObservabilityConfig) *LoggerService {
 		newrelic.ConfigLicense(cfg.NewRelic.LicenseKey),
 		newrelic.ConfigAppLogForwardingEnabled(cfg.NewRelic.AppLogForwardingEnabled),
 		newrelic.ConfigDistributedTracerEnabled(cfg.NewRelic.DistributedTracingEnabled),
+
+		// Labels tag the APM entity, so deploys can be compared by version.
+		func(c *newrelic.Config) {
+			build := buildinfo.Get()
+			c.Labels = map[string]string{"version": build.Version, "commit": build.ShortCommit()}
+		},
 	)
 
 	// Enable debug logging only if explicitly enabled.
@@ -117,49 +125,61 @@ func NewLoggerWithConfig(cfg *config.ObservabilityConfig) zerolog.Logger {
 //   - effective log level (based on cfg.GetLogLevel())
 //   - output format: JSON for production if configured, otherwise console
 //   - New Relic log forwarding wrapper in production when enabled
+//   - optional sampling of debug/info events (LoggingConfig.Sampling)
+//   - optional rotating log file (LoggingConfig.Output / File)
 //
 // It also attaches default fields:
 //   - service
 //   - environment
 func NewLoggerWithService(cfg *config.ObservabilityConfig, loggerService *LoggerService) zerolog.Logger {
 	// Convert string level ("debug"/"info"/...) into zerolog.Level.
-	var logLevel zerolog.Level
-	level := cfg.GetLogLevel()
-
-	switch level {
-	case "debug":
-		logLevel = zerolog.DebugLevel
-	case "info":
-		logLevel = zerolog.InfoLevel
-	case "warn":
-		logLevel = zerolog.WarnLevel
-	case "error":
-		logLevel = zerolog.ErrorLevel
-	default:
-		logLevel = zerolog.InfoLevel
-	}
+	logLevel := ParseLevel(cfg.GetLogLevel())
 
 	// Global zerolog settings.
 	// TimeFieldFormat sets the timestamp format for log entries.
-	// Don't set global level - let each logger have its own level
 	zerolog.TimeFieldFormat = "2006-01-02 15:04:05"
 
+	// The level is enforced globally rather than per logger: every derived
+	// logger (request, job, repository) is a copy with its level frozen in,
+	// so only the global level can be changed at runtime (see SetLevel).
+	zerolog.SetGlobalLevel(logLevel)
+
 	// ErrorStackMarshaler tells zerolog how to encode stack traces.
 	// pkgerrors.MarshalStack supports github.com/pkg/errors stack frames.
 	zerolog.ErrorStackMarshaler = pkgerrors.MarshalStack
 
 	var writer io.Writer
 
+	// Log file (LoggingConfig.Output file/both). A file that can't be opened
+	// doesn't stop the service: it logs to stdout and says so on stderr.
+	var file io.Writer
+	if cfg.Logging.Output == config.LogOutputFile || cfg.Logging.Output == config.LogOutputBoth {
+		rotating, err := NewRotatingFile(cfg.Logging.File)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v, logging to stdout instead\n", err)
+		} else {
+			file = rotating
+		}
+	}
+	fileOnly := file != nil && cfg.Logging.Output == config.LogOutputFile
+
 	// Setup writer:
 	// - Production + json: write structured logs to stdout (good for log ingestion)
 	// - Otherwise: pretty console output for development readability
+	// - The log file, if any, gets JSON lines next to (or instead of) stdout
 	var baseWriter io.Writer
 	if cfg.IsProduction() && cfg.Logging.Format == "json" {
 		// In production, write to stdout
 		baseWriter = os.Stdout
+		switch {
+		case fileOnly:
+			baseWriter = file
+		case file != nil:
+			baseWriter = zerolog.MultiLevelWriter(os.Stdout, file)
+		}
 
-		// Wrap stdout writer with New Relic zerologWriter integration if enabled.
-		// This allows New Relic log forwarding while still writing to stdout.
+		// Wrap the writer with New Relic zerologWriter integration if enabled.
+		// This allows New Relic log forwarding while still writing locally.
 		if loggerService != nil && loggerService.nrApp != nil {
 			nrWriter := zerologWriter.New(baseWriter, loggerService.nrApp)
 			writer = nrWriter
@@ -171,22 +191,36 @@ func NewLoggerWithService(cfg *config.ObservabilityConfig, loggerService *Logger
 		// Development (or non-json format) uses ConsoleWriter for readable logs.
 		consoleWriter := zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: "2006-01-02 15:04:05"}
 		writer = consoleWriter
+		switch {
+		case fileOnly:
+			writer = file
+		case file != nil:
+			writer = zerolog.MultiLevelWriter(consoleWriter, file)
+		}
 	}
 
 	// Note: New Relic log forwarding is now handled automatically by zerologWriter integration
 
 	// Build the logger with:
 	// - output writer
-	// - level filter
-	// - default fields (timestamp + service + environment)
+	// - level filter (global, see above)
+	// - default fields (timestamp + service + environment + build version)
+	build := buildinfo.Get()
 	logger := zerolog.New(writer).
-		Level(logLevel).
 		With().
 		Timestamp().
 		Str("service", cfg.ServiceName).
 		Str("environment", cfg.Environment).
+		Str("version", build.Version).
+		Str("commit", build.ShortCommit()).
 		Logger()
 
+	// Thin out high-volume debug/info logs if configured. Hooks are copied
+	// into every derived logger (request, job, repository).
+	if hook := newSamplingHook(cfg.Logging.Sampling); hook != nil {
+		logger = logger.Hook(hook)
+	}
+
 	// Include stack traces for errors in development
 	// Add stack traces for errors in development for easier debugging.
 	// In production, stack traces often create noise or leak internals.
@@ -197,22 +231,42 @@ func NewLoggerWithService(cfg *config.ObservabilityConfig, loggerService *Logger
 	return logger
 }
 
-// WithTraceContext adds trace/span IDs from a New Relic transaction into the logger.
+// ParseLevel converts a config level ("debug", "info", "warn", "error")
+// into a zerolog.Level, defaulting to info.
+func ParseLevel(level string) zerolog.Level {
+	switch level {
+	case "debug":
+		return zerolog.DebugLevel
+	case "info":
+		return zerolog.InfoLevel
+	case "warn":
+		return zerolog.WarnLevel
+	case "error":
+		return zerolog.ErrorLevel
+	default:
+		return zerolog.InfoLevel
+	}
+}
+
+// SetLevel changes the log level of every logger at runtime (e.g. on a
+// config reload).
+func SetLevel(level string) {
+	zerolog.SetGlobalLevel(ParseLevel(level))
+}
+
+// WithTraceContext adds trace/span IDs from the APM span into the logger.
 //
-// This is used to correlate logs with traces.
-// If txn is nil, it returns the original logger unchanged.
-func WithTraceContext(logger zerolog.Logger, txn *newrelic.Transaction) zerolog.Logger {
-	if txn == nil {
+// This is used to correlate logs with traces, whichever provider records
+// them. If span is nil, it returns the original logger unchanged.
+func WithTraceContext(logger zerolog.Logger, span tracing.Span) zerolog.Logger {
+	if span == nil {
 		return logger
 	}
 
-	// Pull trace metadata from New Relic transaction.
-	metadata := txn.GetTraceMetadata()
-
 	// Attach trace.id and span.id as structured fields.
 	return logger.With().
-		Str("trace.id", metadata.TraceID).
-		Str("span.id", metadata.SpanID).
+		Str("trace.id", span.TraceID()).
+		Str("span.id", span.SpanID()).
 		Logger()
 }
 
@@ -0,0 +1,173 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+)
+
+// backupTimeFormat names rotated files: app-2006-01-02T15-04-05.000.log.
+// It sorts lexically in time order and avoids ':' (invalid on Windows).
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotatingFile is an io.Writer appending to a log file and rotating it when
+// it would grow beyond MaxSizeMB, in the style of lumberjack:
+//
+//   - the current file keeps its name (what log shippers tail)
+//   - rotated files get a timestamp before the extension
+//   - rotated files beyond MaxBackups, or older than MaxAge, are deleted
+//
+// Writes are serialized; each zerolog event is one Write, so lines are
+// never split across files.
+type RotatingFile struct {
+	cfg config.LogFileConfig
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewRotatingFile opens (or creates) cfg.Path, creating missing directories.
+func NewRotatingFile(cfg config.LogFileConfig) (*RotatingFile, error) {
+	r := &RotatingFile{cfg: cfg}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Write appends p, rotating first if p would not fit.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if maxSize := r.maxSize(); maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+func (r *RotatingFile) maxSize() int64 {
+	return int64(r.cfg.MaxSizeMB) * 1024 * 1024
+}
+
+func (r *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.cfg.Path), 0o755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(r.cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// rotate renames the current file to its backup name and starts a new one.
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	r.file = nil
+
+	if err := os.Rename(r.cfg.Path, r.backupName(time.Now())); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+
+	// Cleanup is best effort and off the write path.
+	go r.removeOldBackups()
+	return nil
+}
+
+func (r *RotatingFile) backupName(t time.Time) string {
+	dir, base := filepath.Split(r.cfg.Path)
+	ext := filepath.Ext(base)
+	return filepath.Join(dir, strings.TrimSuffix(base, ext)+"-"+t.Format(backupTimeFormat)+ext)
+}
+
+// removeOldBackups deletes backups beyond MaxBackups or older than MaxAge.
+func (r *RotatingFile) removeOldBackups() {
+	if r.cfg.MaxBackups <= 0 && r.cfg.MaxAge <= 0 {
+		return
+	}
+
+	dir, base := filepath.Split(r.cfg.Path)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+
+	entries, err := os.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		return
+	}
+
+	type backup struct {
+		path string
+		at   time.Time
+	}
+	var backups []backup
+	for _, entry := range entries {
+		name := entry.Name()
+		stamp, ok := strings.CutPrefix(name, prefix)
+		if entry.IsDir() || !ok || !strings.HasSuffix(stamp, ext) {
+			continue
+		}
+		at, err := time.ParseInLocation(backupTimeFormat, strings.TrimSuffix(stamp, ext), time.Local)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{path: filepath.Join(dir, name), at: at})
+	}
+
+	// Newest first.
+	sort.Slice(backups, func(i, j int) bool { return backups[i].at.After(backups[j].at) })
+
+	cutoff := time.Now().Add(-r.cfg.MaxAge)
+	for i, b := range backups {
+		tooMany := r.cfg.MaxBackups > 0 && i >= r.cfg.MaxBackups
+		tooOld := r.cfg.MaxAge > 0 && b.at.Before(cutoff)
+		if tooMany || tooOld {
+			_ = os.Remove(b.path)
+		}
+	}
+}
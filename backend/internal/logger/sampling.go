@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"strings"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/rs/zerolog"
+)
+
+// samplingHook drops debug/info events according to LogSamplingConfig.
+//
+// zerolog's Logger.Sample applies one sampler to every event of a logger;
+// rates per message need the message, which only hooks see. The hook still
+// uses zerolog's BasicSampler (a shared counter keeping every Nth event),
+// one per configured message.
+type samplingHook struct {
+	fallback zerolog.Sampler
+	events   map[string]zerolog.Sampler
+}
+
+// newSamplingHook returns the hook for cfg, or nil when it would keep
+// everything.
+func newSamplingHook(cfg config.LogSamplingConfig) zerolog.Hook {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	hook := &samplingHook{events: make(map[string]zerolog.Sampler, len(cfg.Events))}
+	if cfg.Every > 1 {
+		hook.fallback = &zerolog.BasicSampler{N: cfg.Every}
+	}
+	for msg, n := range cfg.Events {
+		if n > 1 {
+			hook.events[strings.ToLower(msg)] = &zerolog.BasicSampler{N: n}
+		} else {
+			// Listed with 0 or 1: always kept, even if Every would drop it.
+			hook.events[strings.ToLower(msg)] = nil
+		}
+	}
+
+	if hook.fallback == nil && len(hook.events) == 0 {
+		return nil
+	}
+	return hook
+}
+
+func (h *samplingHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if level != zerolog.DebugLevel && level != zerolog.InfoLevel {
+		return
+	}
+
+	sampler, listed := h.events[strings.ToLower(msg)]
+	if !listed {
+		sampler = h.fallback
+	}
+	if sampler != nil && !sampler.Sample(level) {
+		e.Discard()
+	}
+}
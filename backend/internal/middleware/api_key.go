@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/labstack/echo/v4"
+)
+
+// APIKeyVerifier looks up the raw key of an X-API-Key header and returns the
+// ID of the key it belongs to, or ok false for unknown and revoked keys.
+// Implement it over wherever keys are stored (compare hashes, never the raw
+// key); err is for lookup failures, not for invalid keys.
+type APIKeyVerifier func(ctx context.Context, key string) (keyID string, ok bool, err error)
+
+// RequireAPIKey authenticates machine clients by their X-API-Key header.
+//
+// A verified key's ID is stored under APIKeyIDKey, which keys rate limits
+// and quotas on the key rather than the client IP, and the app-wide
+// authenticated budget is charged for it (see ChargeAuthenticated). Missing
+// and unknown keys get a 401.
+func (auth *AuthMiddleware) RequireAPIKey(verify APIKeyVerifier) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(HeaderAPIKey)
+			if key == "" {
+				return errs.NewUnauthorizedError("Missing API key", false)
+			}
+
+			keyID, ok, err := verify(c.Request().Context(), key)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return errs.NewUnauthorizedError("Invalid API key", false)
+			}
+
+			c.Set(APIKeyIDKey, keyID)
+
+			if err := NewRateLimitMiddleware(auth.server).ChargeAuthenticated(c); err != nil {
+				return err
+			}
+			return next(c)
+		}
+	}
+}
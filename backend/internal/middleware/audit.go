@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/lib/audit"
+	"github.com/deppfellow/go-boilerplate/internal/lib/job"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+// auditEnqueueTimeout bounds the background enqueue of one audit entry.
+const auditEnqueueTimeout = 5 * time.Second
+
+// AuditMiddleware records an audit.Entry for every mutating request.
+type AuditMiddleware struct {
+	server *server.Server
+}
+
+// NewAuditMiddleware constructs AuditMiddleware with access to app Server.
+func NewAuditMiddleware(s *server.Server) *AuditMiddleware {
+	return &AuditMiddleware{server: s}
+}
+
+// Record returns middleware auditing POST/PUT/PATCH/DELETE requests.
+//
+// The entry is assembled after the handler ran, so it sees the user set by
+// route-level RequireAuth and the final status. It is then handed to the job
+// queue from a goroutine: the response never waits on Redis, and the INSERT
+// happens in the worker (job.TaskAuditLog).
+//
+// The request body is hashed while the handler reads it (no extra buffering);
+// only the hash is stored, never the payload.
+func (a *AuditMiddleware) Record() echo.MiddlewareFunc {
+	cfg := a.server.Config.Audit
+	if cfg == nil {
+		cfg = config.DefaultAuditConfig()
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !cfg.Enabled {
+			return next
+		}
+
+		return func(c echo.Context) error {
+			req := c.Request()
+			if !isMutatingMethod(req.Method) || slices.Contains(cfg.ExcludePaths, RouteName(c)) {
+				return next(c)
+			}
+
+			occurredAt := time.Now().UTC()
+
+			var body *hashingReader
+			if req.Body != nil && req.Body != http.NoBody {
+				body = &hashingReader{ReadCloser: req.Body, hash: sha256.New()}
+				req.Body = body
+			}
+
+			err := next(c)
+
+			// On error the global error handler hasn't written the response
+			// yet; derive the status it will use.
+			status := c.Response().Status
+			if err != nil {
+				status = errorStatus(err)
+			}
+
+			entry := &audit.Entry{
+				UserID:      GetUserID(c),
+				TenantID:    GetTenantID(c),
+				Method:      req.Method,
+				Route:       RouteName(c),
+				ResourceIDs: pathParams(c),
+				Status:      status,
+				RequestID:   GetRequestID(c),
+				IP:          c.RealIP(),
+				OccurredAt:  occurredAt,
+			}
+			if body != nil && body.n > 0 {
+				entry.BodyHash = hex.EncodeToString(body.hash.Sum(nil))
+			}
+
+			a.enqueue(req.Context(), entry)
+
+			return err
+		}
+	}
+}
+
+// enqueue hands entry to the job queue without blocking the request.
+// reqCtx only provides the task metadata (it is canceled soon after).
+func (a *AuditMiddleware) enqueue(reqCtx context.Context, entry *audit.Entry) {
+	if a.server.Job == nil {
+		return
+	}
+
+	go func() {
+		task, err := job.NewAuditLogTask(reqCtx, entry)
+		if err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), auditEnqueueTimeout)
+			defer cancel()
+			_, err = a.server.Job.Client.EnqueueContext(ctx, task)
+		}
+
+		if err != nil {
+			a.server.Logger.Error().
+				Err(err).
+				Str("request_id", entry.RequestID).
+				Str("method", entry.Method).
+				Str("route", entry.Route).
+				Msg("failed to enqueue audit log")
+		}
+	}()
+}
+
+// isMutatingMethod reports whether method changes server state.
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// errorStatus mirrors the status the global error handler will send for err.
+func errorStatus(err error) int {
+	var httpErr *errs.HTTPError
+	var echoErr *echo.HTTPError
+
+	switch {
+	case errors.As(err, &httpErr):
+		return httpErr.Status
+	case errors.As(err, &echoErr):
+		return echoErr.Code
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// pathParams returns the route parameters, which identify the resources acted on.
+func pathParams(c echo.Context) map[string]string {
+	names := c.ParamNames()
+	if len(names) == 0 {
+		return nil
+	}
+
+	params := make(map[string]string, len(names))
+	for _, name := range names {
+		params[name] = c.Param(name)
+	}
+	return params
+}
+
+// hashingReader hashes a request body as it is consumed.
+type hashingReader struct {
+	io.ReadCloser
+	hash hash.Hash
+	n    int64
+}
+
+func (r *hashingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.hash.Write(p[:n])
+		r.n += int64(n)
+	}
+	return n, err
+}
@@ -1,13 +1,14 @@
 package middleware
 
 import (
-	"encoding/json"
+	"context"
 	"net/http"
 	"time"
 
 	"github.com/clerk/clerk-sdk-go/v2"
 	clerkhttp "github.com/clerk/clerk-sdk-go/v2/http"
 	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/lib/ctxutil"
 	"github.com/deppfellow/go-boilerplate/internal/server"
 	"github.com/labstack/echo/v4"
 )
@@ -29,9 +30,11 @@ func NewAuthMiddleware(s *server.Server) *AuthMiddleware {
 //
 // High-level behavior:
 //  1. It wraps Clerk's middleware that parses the Authorization header.
-//  2. If Clerk fails auth, it uses AuthorizationFailureHandler to emit a JSON 401.
+//  2. If Clerk fails auth, its AuthorizationFailureHandler hands a 401 back
+//     to RequireAuth, which returns it to the global error handler.
 //  3. If Clerk succeeds, it extracts session claims from request context.
-//  4. It stores useful values into Echo context (user_id, role, permissions).
+//  4. It stores useful values into Echo context (user_id, role, permissions,
+//     plan).
 //  5. It calls the next handler.
 func (auth *AuthMiddleware) RequireAuth(next echo.HandlerFunc) echo.HandlerFunc {
 	// echo.WrapMiddleware converts a standard net/http middleware to Echo middleware.
@@ -40,43 +43,27 @@ func (auth *AuthMiddleware) RequireAuth(next echo.HandlerFunc) echo.HandlerFunc
 	// - reads Authorization: Bearer <token>
 	// - validates it
 	// - populates the request context with Clerk session claims
-	return echo.WrapMiddleware(
+	authenticate := echo.WrapMiddleware(
 		clerkhttp.WithHeaderAuthorization(
+			// Decode our custom claims (see sessionClaims) into claims.Custom.
+			clerkhttp.CustomClaimsConstructor(func(context.Context) any {
+				return &sessionClaims{}
+			}),
 			// AuthorizationFailureHandler is called when token is missing/invalid.
+			// It writes nothing: the error is handed back to the Echo chain
+			// (see authFailure) so the global error handler renders it like
+			// any other.
 			clerkhttp.AuthorizationFailureHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				start := time.Now()
-
-				// Return JSON.
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusUnauthorized)
-
-				// Construct a basic response map.
-				//
-				// NOTE: override/status are strings here, not bool/int.
-				// That’s inconsistent with your HTTPError struct.
-				response := map[string]string{
-					"code":     "UNAUTHORIZED",
-					"message":  "Unauthorized",
-					"override": "false",
-					"status":   "401",
+				failure, ok := r.Context().Value(authFailureKey{}).(*authFailure)
+				if !ok {
+					return
 				}
 
-				// Write the JSON response.
-				if err := json.NewEncoder(w).Encode(response); err != nil {
-					// If writing response fails, log it with duration.
-					auth.server.Logger.Error().
-						Err(err).
-						Str("function", "RequireAuth").
-						Dur("duration", time.Since(start)).
-						Msg("failed to write JSON response")
-				} else {
-					// This message is misleading: auth failure handler is called for auth failures,
-					// not necessarily "could not get session claims".
-					auth.server.Logger.Error().
-						Str("function", "RequireAuth").
-						Dur("duration", time.Since(start)).
-						Msg("could not get session claims from context")
-				}
+				// A missing, expired or invalid token is usually fixed by a
+				// silent token refresh, which the refresh_token action tells
+				// the frontend to try.
+				failure.err = errs.NewUnauthorizedError("Unauthorized", false).
+					WithAction(errs.NewRefreshTokenAction("Refresh the session token and retry", "invalid_token"))
 			}))))(
 		// This function runs if Clerk middleware let the request through.
 		func(c echo.Context) error {
@@ -93,26 +80,106 @@ func (auth *AuthMiddleware) RequireAuth(next echo.HandlerFunc) echo.HandlerFunc
 					Dur("duration", time.Since(start)).
 					Msg("could not get session claims from context")
 
-				return errs.NewUnauthorizedError("Unauthorized", false)
+				// A valid token without a session: only signing in again helps.
+				return errs.NewUnauthorizedError("Unauthorized", false).
+					WithAction(errs.NewRefreshAuthAction("Sign in again", "session_missing", ""))
 			}
 
 			// Store auth values into Echo context for handlers to read later.
 			//
-			// These are NOT stored in Go's context.Context.
-			// They're stored in Echo's context (a request-scoped key/value bag).
-			c.Set("user_id", claims.Subject)
-			c.Set("user_role", claims.ActiveOrganizationRole)
-			c.Set("permissions", claims.Claims.ActiveOrganizationPermissions)
+			// They're stored in Echo's context (a request-scoped key/value bag);
+			// the user ID is mirrored into Go's context.Context for ctxutil.UserID.
+			c.Set(UserIDKey, claims.Subject)
+			c.Set(UserRoleKey, claims.ActiveOrganizationRole)
+			c.Set(PermissionsKey, claims.Claims.ActiveOrganizationPermissions)
+			if custom, ok := claims.Custom.(*sessionClaims); ok && custom.Plan != "" {
+				c.Set(PlanKey, custom.Plan)
+			}
+			c.SetRequest(c.Request().WithContext(ctxutil.WithUserID(c.Request().Context(), claims.Subject)))
+
+			// The Clerk active organization is our tenant (see checkClaimsTenant
+			// for how it combines with the header/subdomain sources).
+			// SetTenant enriches logger/trace with a cardinality-safe tenant dimension.
+			if err := checkClaimsTenant(c, auth.server, claims.ActiveOrganizationID); err != nil {
+				return err
+			}
+
+			// The global limiter only saw the client IP: charge the user's
+			// own authenticated budget now that it is known.
+			if err := NewRateLimitMiddleware(auth.server).ChargeAuthenticated(c); err != nil {
+				return err
+			}
 
 			// Success log with request_id for traceability.
+			// The GeoIP location (if resolved) is recorded so sign-ins can be
+			// audited per country/region.
+			location := GetGeoLocation(c)
 			auth.server.Logger.Info().
 				Str("function", "RequireAuth").
 				Str("user_id", claims.Subject).
 				Str("request_id", GetRequestID(c)).
+				Str("geo_country", location.Country).
+				Str("geo_region", location.Region).
 				Dur("duration", time.Since(start)).
 				Msg("user authenticated successfully")
 
 			// Continue to next handler.
 			return next(c)
 		})
+
+	return func(c echo.Context) error {
+		// Already authenticated by an earlier RequireAuth of the chain (e.g. a
+		// group's and a route's, or WithAuthScope on a builder route): don't
+		// verify the token, nor charge the rate limit, a second time.
+		if GetUserID(c) != "" {
+			return next(c)
+		}
+
+		failure := &authFailure{}
+		req := c.Request()
+		c.SetRequest(req.WithContext(context.WithValue(req.Context(), authFailureKey{}, failure)))
+
+		if err := authenticate(c); err != nil {
+			return err
+		}
+		if failure.err != nil {
+			auth.server.Logger.Warn().
+				Str("function", "RequireAuth").
+				Str("request_id", GetRequestID(c)).
+				Msg("missing or invalid session token")
+			return failure.err
+		}
+		return nil
+	}
+}
+
+// authFailure carries the error of Clerk's AuthorizationFailureHandler out of
+// the net/http middleware, which can only write a response, back to
+// RequireAuth, which returns it. The global error handler then renders it
+// (problem+json, localized message, docs_url) like every other error.
+type authFailure struct {
+	err error
+}
+
+type authFailureKey struct{}
+
+// sessionClaims are the custom claims RequireAuth reads from Clerk session
+// tokens. Clerk only includes them once the session token is customized
+// (Dashboard > Sessions > Customize session token):
+//
+//	{"plan": "{{user.public_metadata.plan}}"}
+type sessionClaims struct {
+	// Plan is the caller's subscription plan, from the user's public
+	// metadata "plan". RequireAuth stores it under PlanKey for quotas.
+	Plan string `json:"plan"`
+}
+
+// AdminRole is the Clerk organization role treated as administrator.
+const AdminRole = "org:admin"
+
+// RequireAdmin rejects authenticated users that are not organization admins.
+//
+// It is RequireRole(AdminRole) and must run after RequireAuth.
+func (auth *AuthMiddleware) RequireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return auth.RequireRole(AdminRole)(next)
 }
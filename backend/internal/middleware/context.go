@@ -1,23 +1,27 @@
 package middleware
 
 import (
-	"context"
-
+	"github.com/deppfellow/go-boilerplate/internal/lib/ctxutil"
+	"github.com/deppfellow/go-boilerplate/internal/lib/tracing"
 	"github.com/deppfellow/go-boilerplate/internal/logger"
 	"github.com/deppfellow/go-boilerplate/internal/server"
 	"github.com/labstack/echo/v4"
-	"github.com/newrelic/go-agent/v3/newrelic"
 	"github.com/rs/zerolog"
 )
 
+// Echo context keys.
+//
+// echo.Context.Set only accepts string keys, so these stay strings; always use
+// the constants (never the literal) so readers and writers agree.
+//
+// The values that non-HTTP code needs (logger, user ID, request ID, tenant)
+// are also mirrored into the request's context.Context under the typed keys
+// of lib/ctxutil.
 const (
-	// UserIDKey and UserRoleKey are intended to be the canonical keys used
-	// to store and retrieve user identity from Echo context.
-	//
-	// NOTE: In this file you never actually set these keys, you set "user_id"
-	// elsewhere in auth middleware. That’s slightly inconsistent.
-	UserIDKey   = "user_id"
-	UserRoleKey = "user_role"
+	// UserIDKey, UserRoleKey and PermissionsKey hold the identity set by RequireAuth.
+	UserIDKey      = "user_id"
+	UserRoleKey    = "user_role"
+	PermissionsKey = "permissions"
 
 	// LoggerKey is used as the key for storing the request-scoped logger.
 	LoggerKey = "logger"
@@ -26,10 +30,12 @@ const (
 // ContextEnhancer is a middleware helper that enriches request context.
 //
 // It builds a request-scoped logger with useful fields like:
-//   - request_id
+//   - request_id, server_request_id
 //   - method, path, ip
-//   - trace.id/span.id (if New Relic transaction exists)
+//   - trace.id/span.id (if a request span exists)
 //   - user_id/user_role (if auth middleware set them)
+//   - geo_country/geo_region (if GeoIP middleware resolved them)
+//   - locale (if the locale middleware resolved it)
 //
 // It then stores that logger in:
 //   - Echo context (c.Set)
@@ -48,7 +54,7 @@ func NewContextEnhancer(s *server.Server) *ContextEnhancer {
 // For every request, it:
 //  1. gets the request ID (from request_id middleware)
 //  2. creates a logger with request fields
-//  3. adds trace context if available (New Relic)
+//  3. adds trace context if available (see lib/tracing)
 //  4. adds user context if available (from auth middleware)
 //  5. stores that logger in Echo context + Go context
 func (ce *ContextEnhancer) EnhanceContext() echo.MiddlewareFunc {
@@ -65,17 +71,18 @@ func (ce *ContextEnhancer) EnhanceContext() echo.MiddlewareFunc {
 			// Logger() finalizes a new logger instance.
 			contextLogger := ce.server.Logger.With().
 				Str("request_id", requestID).
+				Str("server_request_id", GetServerRequestID(c)). // never client-controlled
 				Str("method", c.Request().Method).
-				Str("path", c.Path()). // Echo route path template (e.g. "/users/:id"), not raw URL
-				Str("ip", c.RealIP()). // Uses X-Forwarded-For etc when configured
+				Str("path", RouteName(c)). // Echo route path template (e.g. "/users/:id"), not raw URL
+				Str("ip", c.RealIP()).     // Uses X-Forwarded-For etc when configured
 				Logger()
 
-			// Add New Relic trace context if a transaction exists in request context.
+			// Add trace context if a span exists in request context.
 			//
-			// newrelic.FromContext(ctx) returns the txn set by New Relic middleware/integration.
+			// tracing.FromContext(ctx) returns the span set by the tracing middleware.
 			// logger.WithTraceContext adds trace.id + span.id to logger fields.
-			if txn := newrelic.FromContext(c.Request().Context()); txn != nil {
-				contextLogger = logger.WithTraceContext(contextLogger, txn)
+			if span := tracing.FromContext(c.Request().Context()); span != nil {
+				contextLogger = logger.WithTraceContext(contextLogger, span)
 			}
 
 			// Extract user_id from Echo context if auth middleware has already set it.
@@ -91,6 +98,19 @@ func (ce *ContextEnhancer) EnhanceContext() echo.MiddlewareFunc {
 				contextLogger = contextLogger.With().Str("user_role", userRole).Logger()
 			}
 
+			// Attach GeoIP location if the GeoIP middleware resolved one.
+			if location := GetGeoLocation(c); !location.IsZero() {
+				contextLogger = contextLogger.With().
+					Str("geo_country", location.Country).
+					Str("geo_region", location.Region).
+					Logger()
+			}
+
+			// Attach the locale resolved by LocaleMiddleware.Detect.
+			if locale, ok := c.Get(LocaleKey).(string); ok {
+				contextLogger = contextLogger.With().Str("locale", locale).Logger()
+			}
+
 			// Store the enhanced logger in Echo context.
 			//
 			// IMPORTANT: You store *&contextLogger (pointer) so handlers can retrieve it.
@@ -99,15 +119,8 @@ func (ce *ContextEnhancer) EnhanceContext() echo.MiddlewareFunc {
 			// ALSO store the logger pointer into the Go request context.
 			//
 			// This allows non-Echo code (that only sees context.Context)
-			// to fetch the request logger, e.g. in DB/repo layers.
-			//
-			// NOTE: context.WithValue is fine for request-scoped values,
-			// but you should normally use a custom type for the key instead of string
-			// to avoid collisions across packages.
-			ctx := context.WithValue(c.Request().Context(), LoggerKey, &contextLogger)
-
-			// Replace the request with a new request that has the enriched context.
-			c.SetRequest(c.Request().WithContext(ctx))
+			// to fetch the request logger via ctxutil.Logger, e.g. in DB/repo layers.
+			setContextLogger(c, &contextLogger)
 
 			// Continue the middleware chain.
 			return next(c)
@@ -119,10 +132,10 @@ func (ce *ContextEnhancer) EnhanceContext() echo.MiddlewareFunc {
 //
 // It expects auth middleware to have already done:
 //
-//	c.Set("user_id", claims.Subject)
+//	c.Set(UserIDKey, claims.Subject)
 func (ce *ContextEnhancer) extractUserID(c echo.Context) string {
-	// Type assertion: try to get "user_id" as a string.
-	if userID, ok := c.Get("user_id").(string); ok && userID != "" {
+	// Type assertion: try to get the user ID as a string.
+	if userID, ok := c.Get(UserIDKey).(string); ok && userID != "" {
 		return userID
 	}
 	return ""
@@ -132,9 +145,9 @@ func (ce *ContextEnhancer) extractUserID(c echo.Context) string {
 //
 // It expects auth middleware to have already done:
 //
-//	c.Set("user_role", claims.ActiveOrganizationRole)
+//	c.Set(UserRoleKey, claims.ActiveOrganizationRole)
 func (ce *ContextEnhancer) extractUserRole(c echo.Context) string {
-	if userRole, ok := c.Get("user_role").(string); ok && userRole != "" {
+	if userRole, ok := c.Get(UserRoleKey).(string); ok && userRole != "" {
 		return userRole
 	}
 	return ""
@@ -142,10 +155,7 @@ func (ce *ContextEnhancer) extractUserRole(c echo.Context) string {
 
 // GetUserID reads user_id from Echo context using UserIDKey.
 //
-// NOTE: This function is redundant with extractUserID.
-// Also: it depends on c.Set(UserIDKey, ...) being used consistently.
-// In your auth middleware you set "user_id" directly, which matches UserIDKey,
-// so it works.
+// Code that only has a context.Context uses ctxutil.UserID instead.
 func GetUserID(c echo.Context) string {
 	if userID, ok := c.Get(UserIDKey).(string); ok {
 		return userID
@@ -167,3 +177,10 @@ func GetLogger(c echo.Context) *zerolog.Logger {
 	logger := zerolog.Nop()
 	return &logger
 }
+
+// setContextLogger stores logger in both Echo context and the Go request
+// context, so GetLogger and ctxutil.Logger return the same instance.
+func setContextLogger(c echo.Context, logger *zerolog.Logger) {
+	c.Set(LoggerKey, logger)
+	c.SetRequest(c.Request().WithContext(ctxutil.WithLogger(c.Request().Context(), logger)))
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"net/url"
+	"strings"
+)
+
+// originMatcher decides whether a browser Origin is allowed by the
+// configured origins: "*", exact origins, and wildcard-subdomain patterns
+// such as "https://*.example.com".
+//
+// Echo's own pattern support is a glob over the raw string ("*" becomes
+// ".*"). Patterns are matched on the parsed scheme, host and port here
+// instead, so a pattern can't match more than the subdomains it names.
+type originMatcher struct {
+	any      bool
+	exact    map[string]struct{}
+	suffixes []originPattern
+}
+
+// originPattern is a parsed "scheme://*.domain[:port]" origin.
+type originPattern struct {
+	scheme string
+	suffix string // ".example.com"
+	port   string
+}
+
+func newOriginMatcher(origins []string) *originMatcher {
+	m := &originMatcher{exact: make(map[string]struct{}, len(origins))}
+	for _, origin := range origins {
+		origin = strings.ToLower(strings.TrimSpace(origin))
+		switch {
+		case origin == "*":
+			m.any = true
+		case strings.Contains(origin, "://*."):
+			u, err := url.Parse(origin)
+			if err != nil {
+				continue // rejected by config validation
+			}
+			m.suffixes = append(m.suffixes, originPattern{
+				scheme: u.Scheme,
+				suffix: strings.TrimPrefix(u.Hostname(), "*"),
+				port:   u.Port(),
+			})
+		default:
+			m.exact[origin] = struct{}{}
+		}
+	}
+	return m
+}
+
+// allow implements echo's CORSConfig.AllowOriginFunc.
+func (m *originMatcher) allow(origin string) (bool, error) {
+	if m.any {
+		return true, nil
+	}
+
+	origin = strings.ToLower(origin)
+	if _, ok := m.exact[origin]; ok {
+		return true, nil
+	}
+	if len(m.suffixes) == 0 {
+		return false, nil
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Path != "" || u.User != nil {
+		return false, nil
+	}
+	host := u.Hostname()
+	for _, p := range m.suffixes {
+		// At least one label before the suffix: "https://example.com" is not
+		// a subdomain of itself; list it separately if it should be allowed.
+		if u.Scheme == p.scheme && u.Port() == p.port &&
+			strings.HasSuffix(host, p.suffix) && len(host) > len(p.suffix) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/lib/geoip"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+// COUNTRY_NOT_ALLOWED is only produced by CountryRules, so it is cataloged here.
+func init() {
+	errs.RegisterCode(errs.CatalogEntry{
+		Code:        "COUNTRY_NOT_ALLOWED",
+		Status:      http.StatusForbidden,
+		Description: "Requests from the caller's country are blocked by the GeoIP rules.",
+	})
+}
+
+const (
+	// GeoCountryKey and GeoRegionKey are the Echo context keys holding the
+	// resolved GeoIP location of the client.
+	GeoCountryKey = "geo_country"
+	GeoRegionKey  = "geo_region"
+)
+
+// GeoIPMiddleware resolves the client IP into a country/region and optionally
+// enforces country allow/deny rules from config.
+//
+// If GeoIP is disabled (s.GeoIP is nil), both middlewares are pass-through.
+type GeoIPMiddleware struct {
+	server *server.Server
+}
+
+// NewGeoIPMiddleware constructs a GeoIPMiddleware.
+func NewGeoIPMiddleware(s *server.Server) *GeoIPMiddleware {
+	return &GeoIPMiddleware{
+		server: s,
+	}
+}
+
+// Enrich looks up c.RealIP() and stores the location in Echo context.
+//
+// It must run BEFORE EnhanceTracing and EnhanceContext so that traces and the
+// request-scoped logger can pick up geo_country/geo_region.
+func (g *GeoIPMiddleware) Enrich() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if g.server.GeoIP == nil {
+				return next(c)
+			}
+
+			location := g.server.GeoIP.Lookup(c.RealIP())
+			if location.Country != "" {
+				c.Set(GeoCountryKey, location.Country)
+			}
+			if location.Region != "" {
+				c.Set(GeoRegionKey, location.Region)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// CountryRules rejects requests whose country is not permitted by
+// GeoIPConfig.AllowedCountries / DeniedCountries.
+//
+// Rejections are 403 errs.HTTPError with code COUNTRY_NOT_ALLOWED so the
+// global error handler renders the standard error shape.
+func (g *GeoIPMiddleware) CountryRules() echo.MiddlewareFunc {
+	cfg := g.server.Config.GeoIP
+
+	// Nothing to enforce: return a no-op middleware.
+	if g.server.GeoIP == nil || cfg == nil || !cfg.HasCountryRules() {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return next
+		}
+	}
+
+	allowed := toCountrySet(cfg.AllowedCountries)
+	denied := toCountrySet(cfg.DeniedCountries)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			country := GetGeoLocation(c).Country
+
+			blocked := false
+			switch {
+			case country == "":
+				blocked = cfg.BlockUnknown
+			case len(allowed) > 0 && !allowed[country]:
+				blocked = true
+			case denied[country]:
+				blocked = true
+			}
+
+			if blocked {
+				GetLogger(c).Warn().
+					Str("function", "CountryRules").
+					Str("geo_country", country).
+					Msg("request blocked by country rules")
+
+				err := errs.NewForbiddenError("Access from your location is not allowed", true)
+				err.Code = "COUNTRY_NOT_ALLOWED"
+				return err
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// GetGeoLocation returns the GeoIP location stored by Enrich.
+//
+// Returns an empty Location if GeoIP is disabled or the IP did not resolve.
+func GetGeoLocation(c echo.Context) geoip.Location {
+	var location geoip.Location
+	if country, ok := c.Get(GeoCountryKey).(string); ok {
+		location.Country = country
+	}
+	if region, ok := c.Get(GeoRegionKey).(string); ok {
+		location.Region = region
+	}
+	return location
+}
+
+// toCountrySet normalizes country codes to upper case for O(1) lookups.
+func toCountrySet(codes []string) map[string]bool {
+	set := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		set[strings.ToUpper(strings.TrimSpace(code))] = true
+	}
+	return set
+}
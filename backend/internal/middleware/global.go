@@ -1,9 +1,17 @@
 package middleware
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
 
+	"github.com/deppfellow/go-boilerplate/internal/config"
 	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/lib/i18n"
+	"github.com/deppfellow/go-boilerplate/internal/lib/tracing"
 	"github.com/deppfellow/go-boilerplate/internal/server"
 	"github.com/deppfellow/go-boilerplate/internal/sqlerr"
 	"github.com/labstack/echo/v4"
@@ -34,10 +42,49 @@ func NewGlobalMiddlewares(s *server.Server) *GlobalMiddlewares {
 //
 // It allows browser-based clients to call your API from specific origins.
 // If CORSAllowedOrigins is wrong, your frontend will “mysteriously” fail.
+//
+// Origins may use a wildcard for subdomains ("https://*.example.com", see
+// originMatcher); methods, headers, credentials and preflight caching come
+// from the other server.cors_* settings.
+//
+// The correlation headers are always exposed so browser clients can read
+// them and include them in bug reports.
+//
+// The allowed origins are reloadable: on a config change the Echo CORS
+// middleware is rebuilt and swapped in atomically for the next request.
+// The other CORS settings apply on restart.
 func (global *GlobalMiddlewares) CORS() echo.MiddlewareFunc {
-	return middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: global.server.Config.Server.CORSAllowedOrigins,
-	})
+	cfg := global.server.Config.Server
+
+	build := func(origins []string) echo.MiddlewareFunc {
+		return middleware.CORSWithConfig(middleware.CORSConfig{
+			AllowOriginFunc:  newOriginMatcher(origins).allow,
+			AllowMethods:     cfg.CORSAllowedMethods,
+			AllowHeaders:     cfg.CORSAllowedHeaders,
+			AllowCredentials: cfg.CORSAllowCredentials,
+			ExposeHeaders:    append([]string{RequestIDHeader, ServerRequestIDHeader, TraceIDHeader}, cfg.CORSExposedHeaders...),
+			MaxAge:           int(cfg.CORSMaxAge.Seconds()),
+		})
+	}
+
+	var current atomic.Pointer[echo.MiddlewareFunc]
+	cors := build(cfg.CORSAllowedOrigins)
+	current.Store(&cors)
+
+	if global.server.ConfigWatcher != nil {
+		global.server.ConfigWatcher.Subscribe(func(change config.Change) {
+			if change.Has(config.ReloadCORSOrigins) {
+				cors := build(change.New.Server.CORSAllowedOrigins)
+				current.Store(&cors)
+			}
+		})
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			return (*current.Load())(next)(c)
+		}
+	}
 }
 
 // RequestLogger returns Echo’s request logger middleware, but with a custom LogValuesFunc.
@@ -50,7 +97,15 @@ func (global *GlobalMiddlewares) CORS() echo.MiddlewareFunc {
 //
 // Tutor intent (matches the code + the usual Echo behavior):
 // - request logging should produce one “API” log line per request, with severity based on status.
+//
+// Requests slower than LoggingConfig.SlowRequestThreshold are logged at least
+// at warn with slow_request=true (so log sampling never drops them), and a
+// "SlowRequest" event (route, method, status, latency_ms, tenant_dimension)
+// is recorded on the request span; with New Relic it is a custom event to
+// alert on.
 func (global *GlobalMiddlewares) RequestLogger() echo.MiddlewareFunc {
+	slowThreshold := global.server.Config.Observability.Logging.SlowRequestThreshold
+
 	return middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
 		LogURI:     true,
 		LogStatus:  true,
@@ -93,16 +148,34 @@ func (global *GlobalMiddlewares) RequestLogger() echo.MiddlewareFunc {
 			// - 5xx = server fault -> Error
 			// - 4xx = client fault -> Warn
 			// - otherwise -> Info
+			// - slow (over SlowRequestThreshold) -> at least Warn
+			slow := slowThreshold > 0 && v.Latency >= slowThreshold
+
 			var e *zerolog.Event
 			switch {
 			case statusCode >= 500:
 				e = logger.Error().Err(v.Error)
-			case statusCode >= 400:
+			case statusCode >= 400 || slow:
 				e = logger.Warn()
 			default:
 				e = logger.Info()
 			}
 
+			if slow {
+				e = e.Bool("slow_request", true)
+
+				if span := tracing.FromContext(c.Request().Context()); span != nil {
+					span.AddEvent("SlowRequest", map[string]interface{}{
+						"route":            RouteName(c),
+						"method":           v.Method,
+						"status":           statusCode,
+						"latency_ms":       v.Latency.Milliseconds(),
+						"request_id":       GetRequestID(c),
+						"tenant_dimension": GetTenantDimension(c),
+					})
+				}
+			}
+
 			// Correlation: request id (if RequestID middleware ran).
 			if requestID := GetRequestID(c); requestID != "" {
 				e = e.Str("request_id", requestID)
@@ -118,7 +191,8 @@ func (global *GlobalMiddlewares) RequestLogger() echo.MiddlewareFunc {
 				Dur("latency", v.Latency).
 				Int("status", statusCode).
 				Str("method", v.Method).
-				Str("uri", v.URI).
+				Str("route", RouteName(c)). // low-cardinality route template for aggregation
+				Str("uri", safeLogURI(c, v.URI)).
 				Str("host", v.Host).
 				Str("ip", c.RealIP()).
 				Str("user_agent", c.Request().UserAgent()).
@@ -129,20 +203,128 @@ func (global *GlobalMiddlewares) RequestLogger() echo.MiddlewareFunc {
 	})
 }
 
-// Recover returns Echo’s panic recovery middleware.
+// Recover returns the panic recovery middleware.
 //
 // If your handler panics, Recover prevents the whole process from crashing.
-// Panics become 500 responses (and should be logged).
+// Unlike Echo's stock middleware.Recover, which only prints the stack, it:
+//   - returns errs.NewInternalServerError, so the client gets the standard
+//     500 body from the global error handler
+//   - logs the panic value and stack through the request-scoped logger
+//     (request_id, user_id, route... are attached)
+//   - records the error on the request span, with a "PanicRecovered"
+//     event (route, request_id, tenant_dimension) - a custom event in New Relic
+//
+// http.ErrAbortHandler is re-panicked: it is the standard way to abort a
+// response and net/http handles it itself.
 func (global *GlobalMiddlewares) Recover() echo.MiddlewareFunc {
-	return middleware.Recover()
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (returnErr error) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					return
+				}
+				if r == http.ErrAbortHandler {
+					panic(r)
+				}
+
+				panicErr, ok := r.(error)
+				if !ok {
+					panicErr = fmt.Errorf("%v", r)
+				}
+
+				route := RouteName(c)
+				requestID := GetRequestID(c)
+
+				GetLogger(c).Error().
+					Err(panicErr).
+					Str("route", route).
+					Bytes("stack", debug.Stack()).
+					Msg("panic recovered")
+
+				if span := tracing.FromContext(c.Request().Context()); span != nil {
+					span.RecordError(panicErr)
+					span.AddEvent("PanicRecovered", map[string]interface{}{
+						"route":            route,
+						"method":           c.Request().Method,
+						"request_id":       requestID,
+						"error":            panicErr.Error(),
+						"tenant_dimension": GetTenantDimension(c),
+					})
+				}
+
+				returnErr = errs.NewInternalServerError()
+			}()
+
+			return next(c)
+		}
+	}
 }
 
-// Secure returns Echo’s secure headers middleware. (SRT Video Timestamp 02:44:00)
+// Secure sets security response headers from ServerConfig.SecurityHeaders.
+// (SRT Video Timestamp 02:44:00)
+//
+// It replaces Echo's middleware.Secure(), whose fixed defaults have no CSP,
+// no Permissions-Policy and no way to relax the policy for the docs UI.
+//
+// Headers:
+//   - Content-Security-Policy: strict for the API, relaxed for /docs and /static
+//     and (less so) for the admin dashboard page
+//   - Strict-Transport-Security: production by default (see HSTSHeader)
+//   - Referrer-Policy, Permissions-Policy, X-Frame-Options, X-Content-Type-Options
 //
-// Adds standard security-related headers.
 // It’s not a magical shield, but it stops some low-effort nonsense.
 func (global *GlobalMiddlewares) Secure() echo.MiddlewareFunc {
-	return middleware.Secure()
+	cfg := global.server.Config.Server.SecurityHeaders
+
+	cspHeader := echo.HeaderContentSecurityPolicy
+	if cfg.CSPReportOnly {
+		cspHeader = echo.HeaderContentSecurityPolicyReportOnly
+	}
+
+	apiCSP := cfg.GetContentSecurityPolicy()
+	docsCSP := cfg.GetDocsContentSecurityPolicy()
+	dashboardCSP := cfg.GetDashboardContentSecurityPolicy()
+	hsts := cfg.HSTSHeader(global.server.Config.Primary.Env)
+	referrerPolicy := cfg.GetReferrerPolicy()
+	permissionsPolicy := cfg.GetPermissionsPolicy()
+	frameOptions := cfg.GetFrameOptions()
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Response().Header()
+
+			header.Set(echo.HeaderXContentTypeOptions, "nosniff")
+			header.Set(echo.HeaderXFrameOptions, frameOptions)
+			header.Set(echo.HeaderReferrerPolicy, referrerPolicy)
+			header.Set("Permissions-Policy", permissionsPolicy)
+
+			switch {
+			case isDocsRoute(c):
+				header.Set(cspHeader, docsCSP)
+			case c.Path() == AdminDashboardPath:
+				header.Set(cspHeader, dashboardCSP)
+			default:
+				header.Set(cspHeader, apiCSP)
+			}
+
+			// HSTS only means something over TLS (directly or behind a TLS proxy).
+			if hsts != "" && (c.IsTLS() || c.Request().Header.Get(echo.HeaderXForwardedProto) == "https") {
+				header.Set(echo.HeaderStrictTransportSecurity, hsts)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// AdminDashboardPath serves the embedded admin dashboard page.
+const AdminDashboardPath = "/admin"
+
+// isDocsRoute reports whether the request targets the docs UI or its assets.
+func isDocsRoute(c echo.Context) bool {
+	path := c.Path()
+	return path == "/docs" || strings.HasPrefix(path, "/static")
 }
 
 // GlobalErrorHandler is the final error funnel for the entire HTTP server.
@@ -167,8 +349,18 @@ func (global *GlobalMiddlewares) GlobalErrorHandler(err error, c echo.Context) {
 		if errors.As(err, &echoErr) {
 			// If the user hits a route that doesn’t exist:
 			// convert it into your own NotFound shape.
-			if echoErr.Code == http.StatusNotFound {
+			switch echoErr.Code {
+			case http.StatusNotFound:
 				err = errs.NewNotFoundError("Route not found", false, nil)
+
+			case http.StatusMethodNotAllowed:
+				// Path matched but method didn't: answer with our envelope plus
+				// the RFC 7231 Allow header instead of Echo's plain body.
+				allowed := allowedMethods(c)
+				if len(allowed) > 0 {
+					c.Response().Header().Set(echo.HeaderAllow, strings.Join(allowed, ", "))
+				}
+				err = errs.NewMethodNotAllowedError(allowed)
 			}
 
 		} else {
@@ -186,6 +378,7 @@ func (global *GlobalMiddlewares) GlobalErrorHandler(err error, c echo.Context) {
 	var message string
 	var fieldErrors []errs.FieldError
 	var action *errs.Action
+	var details map[string]any
 
 	switch {
 	case errors.As(err, &httpErr):
@@ -195,6 +388,7 @@ func (global *GlobalMiddlewares) GlobalErrorHandler(err error, c echo.Context) {
 		message = httpErr.Message
 		fieldErrors = httpErr.Errors
 		action = httpErr.Action
+		details = httpErr.Details
 
 	case errors.As(err, &echoErr):
 		// Convert Echo’s error into your schema.
@@ -219,21 +413,164 @@ func (global *GlobalMiddlewares) GlobalErrorHandler(err error, c echo.Context) {
 	// Use enhanced logger from context (request_id/user/trace already included by other middleware).
 	logger := *GetLogger(c)
 
-	logger.Error().Stack().
+	event := logger.Error().Stack().
 		Err(originalErr).
 		Int("status", status).
-		Str("error_code", code).
-		Msg(message)
+		Str("error_code", code)
+
+	// Internal context of an HTTPError (WithField, WithCause) goes to logs
+	// and traces only. Cause chains may hold SQL or payload fragments, so
+	// they are logged outside production-like environments only; the trace
+	// gets the cause itself.
+	if httpErr != nil {
+		if len(httpErr.Metadata) > 0 {
+			event = event.Fields(httpErr.Metadata)
+		}
+		if httpErr.Cause != nil {
+			if env := global.server.Config.Primary.Env; env == config.EnvLocal || env == config.EnvDevelopment {
+				event = event.Strs("cause_chain", causeChain(httpErr.Cause))
+			}
+			if span := tracing.FromContext(c.Request().Context()); span != nil {
+				span.RecordError(httpErr.Cause)
+			}
+		}
+	}
+
+	event.Msg(message)
 
 	// Only write response if it hasn’t already been written.
 	if !c.Response().Committed {
-		_ = c.JSON(status, errs.HTTPError{
+		message, fieldErrors = localizeError(GetLocale(c), httpErr, code, message, fieldErrors)
+
+		// A retry_after action (e.g. a transient database error) gets the
+		// matching header, unless the middleware rejecting the request set it.
+		if action != nil && action.Type == errs.ActionTypeRetryAfter && c.Response().Header().Get("Retry-After") == "" {
+			c.Response().Header().Set("Retry-After", action.Value)
+		}
+
+		response := &errs.HTTPError{
 			Code:     code,
 			Message:  message,
 			Status:   status,
 			Override: httpErr != nil && httpErr.Override,
 			Errors:   fieldErrors,
 			Action:   action,
-		})
+			Details:  details,
+			DocsURL:  global.errorDocsURL(code),
+		}
+
+		if global.wantsProblem(c) {
+			problem := response.Problem(
+				global.server.Config.ErrorResponse.ProblemTypeFor(code, response.DocsURL),
+				c.Request().URL.Path,
+			)
+			problem.RequestID = GetRequestID(c)
+			_ = writeProblem(c, problem)
+			return
+		}
+
+		_ = c.JSON(status, response)
+	}
+}
+
+// localizeError translates the message and field errors of an error
+// response into locale (see lib/i18n), keeping the English text where no
+// translation exists.
+//
+// The message is translated by its key (HTTPError.WithMessageKey), or, when
+// it is the default message of its code in the catalog, by the code.
+func localizeError(locale string, httpErr *errs.HTTPError, code, message string, fieldErrors []errs.FieldError) (string, []errs.FieldError) {
+	var (
+		key    string
+		params map[string]any
+	)
+	if httpErr != nil && httpErr.MessageKey != "" {
+		key, params = httpErr.MessageKey, httpErr.MessageParams
+	} else if entry, ok := errs.LookupCode(code); ok && entry.Message != "" && entry.Message == message {
+		key = "error." + code
+	}
+	if key != "" {
+		if translated, ok := i18n.T(locale, key, params); ok {
+			message = translated
+		}
+	}
+
+	if len(fieldErrors) == 0 {
+		return message, fieldErrors
+	}
+
+	// Copied: the field errors may belong to a shared HTTPError value.
+	localized := make([]errs.FieldError, len(fieldErrors))
+	for i, fieldErr := range fieldErrors {
+		if fieldErr.Key != "" {
+			if translated, ok := i18n.T(locale, fieldErr.Key, fieldErr.Params); ok {
+				fieldErr.Error = translated
+			}
+		}
+		localized[i] = fieldErr
+	}
+	return message, localized
+}
+
+// causeChain lists the messages of err and the errors it wraps, outermost
+// first.
+func causeChain(err error) []string {
+	var chain []string
+	for ; err != nil; err = errors.Unwrap(err) {
+		chain = append(chain, err.Error())
+	}
+	return chain
+}
+
+// wantsProblem reports whether the error response should be RFC 7807
+// problem+json (see ErrorResponseConfig.Format).
+func (global *GlobalMiddlewares) wantsProblem(c echo.Context) bool {
+	switch global.server.Config.ErrorResponse.GetFormat() {
+	case config.ErrorFormatProblem:
+		return true
+	case config.ErrorFormatNegotiate:
+		return strings.Contains(c.Request().Header.Get(echo.HeaderAccept), errs.ProblemContentType)
+	}
+	return false
+}
+
+// writeProblem writes problem with the problem+json content type, which
+// c.JSON would replace with application/json.
+func writeProblem(c echo.Context, problem errs.Problem) error {
+	body, err := json.Marshal(problem)
+	if err != nil {
+		return err
+	}
+	return c.Blob(problem.Status, errs.ProblemContentType, body)
+}
+
+// errorDocsURL links code to its documentation (see DocsConfig.DocsURLFor).
+func (global *GlobalMiddlewares) errorDocsURL(code string) string {
+	docs := global.server.Config.Docs
+	if docs == nil {
+		return ""
+	}
+
+	_, cataloged := errs.LookupCode(code)
+	return docs.DocsURLFor(code, cataloged)
+}
+
+// allowedMethods returns the methods registered for the matched path.
+//
+// Echo's router stores them (comma separated) under echo.ContextKeyHeaderAllow
+// when it resolves a request to MethodNotAllowedHandler.
+func allowedMethods(c echo.Context) []string {
+	raw, _ := c.Get(echo.ContextKeyHeaderAllow).(string)
+	if raw == "" {
+		return nil
+	}
+
+	parts := strings.Split(raw, ",")
+	methods := make([]string, 0, len(parts))
+	for _, m := range parts {
+		if m = strings.TrimSpace(m); m != "" {
+			methods = append(methods, m)
+		}
 	}
+	return methods
 }
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/labstack/echo/v4"
+)
+
+// HeaderCFConnectingIP is Cloudflare's single-value client IP header.
+const HeaderCFConnectingIP = "CF-Connecting-IP"
+
+// NewIPExtractor builds the echo.IPExtractor behind c.RealIP() from ProxyConfig.
+//
+// Assign it to echo.Echo.IPExtractor before serving traffic:
+//
+//	router.IPExtractor = middleware.NewIPExtractor(s.Config.Proxy)
+func NewIPExtractor(cfg *config.ProxyConfig) echo.IPExtractor {
+	if cfg == nil {
+		cfg = config.DefaultProxyConfig()
+	}
+
+	// Validate() already rejected bad CIDRs at startup.
+	trusted, _ := cfg.ParsedTrustedProxies()
+
+	options := []echo.TrustOption{echo.TrustLoopback(true)}
+	if len(trusted) > 0 {
+		// Explicit list: stop trusting every private network by default.
+		options = append(options, echo.TrustLinkLocal(false), echo.TrustPrivateNet(false))
+		for _, n := range trusted {
+			options = append(options, echo.TrustIPRange(n))
+		}
+	}
+
+	switch cfg.IPHeader {
+	case config.IPHeaderNone:
+		return echo.ExtractIPDirect()
+	case config.IPHeaderXRealIP:
+		return echo.ExtractIPFromRealIPHeader(options...)
+	case config.IPHeaderCFConnectingIP:
+		return extractIPFromCFHeader(trusted)
+	default:
+		return echo.ExtractIPFromXFFHeader(options...)
+	}
+}
+
+// extractIPFromCFHeader trusts CF-Connecting-IP only when the direct peer is
+// a trusted proxy (Cloudflare's published ranges, configured as TrustedProxies).
+// Without configured ranges loopback and private peers are trusted, matching
+// Echo's defaults for the other headers.
+func extractIPFromCFHeader(trusted []*net.IPNet) echo.IPExtractor {
+	direct := echo.ExtractIPDirect()
+
+	return func(req *http.Request) string {
+		peer := direct(req)
+
+		cfIP := strings.TrimSpace(req.Header.Get(HeaderCFConnectingIP))
+		if cfIP == "" || net.ParseIP(cfIP) == nil {
+			return peer
+		}
+
+		if isTrustedProxyIP(net.ParseIP(peer), trusted) {
+			return cfIP
+		}
+		return peer
+	}
+}
+
+func isTrustedProxyIP(ip net.IP, trusted []*net.IPNet) bool {
+	if ip == nil {
+		return false
+	}
+	if ip.IsLoopback() {
+		return true
+	}
+
+	if len(trusted) == 0 {
+		return ip.IsPrivate()
+	}
+	for _, n := range trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
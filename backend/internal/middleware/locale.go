@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/lib/ctxutil"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/text/language"
+)
+
+// LocaleKey is the Echo context key holding the resolved locale (BCP 47 tag).
+const LocaleKey = "locale"
+
+// HeaderAcceptLanguage is not among Echo's header constants.
+const HeaderAcceptLanguage = "Accept-Language"
+
+// GetLocale returns the locale resolved for the request (e.g. "en", "pt-BR").
+//
+// Without the Detect middleware (e.g. in unit tests) it returns the default
+// locale of DefaultLocaleConfig, so callers never deal with "".
+func GetLocale(c echo.Context) string {
+	if locale, ok := c.Get(LocaleKey).(string); ok && locale != "" {
+		return locale
+	}
+	return config.DefaultLocaleConfig().Default
+}
+
+// LocaleMiddleware resolves the client's preferred locale.
+type LocaleMiddleware struct {
+	server *server.Server
+}
+
+// NewLocaleMiddleware constructs LocaleMiddleware.
+func NewLocaleMiddleware(s *server.Server) *LocaleMiddleware {
+	return &LocaleMiddleware{server: s}
+}
+
+// Detect picks the best supported locale for each request and stores it in
+// Echo context (GetLocale) and the Go context (ctxutil.Locale).
+//
+// Resolution order:
+//  1. the query override (?lang=de by default), if supported
+//  2. Accept-Language, honoring q-values and regional fallbacks
+//     ("de-AT" matches a supported "de")
+//  3. LocaleConfig.Default
+//
+// It must run before ContextEnhancer so the request logger carries "locale".
+func (l *LocaleMiddleware) Detect() echo.MiddlewareFunc {
+	cfg := l.server.Config.Locale
+	if cfg == nil {
+		cfg = config.DefaultLocaleConfig()
+	}
+
+	// The default goes first: the matcher falls back to the first tag.
+	supported := []language.Tag{language.Make(cfg.Default)}
+	for _, tag := range cfg.Supported {
+		if tag != cfg.Default {
+			supported = append(supported, language.Make(tag))
+		}
+	}
+	matcher := language.NewMatcher(supported)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var preferences []string
+			if cfg.QueryParam != "" {
+				if lang := c.QueryParam(cfg.QueryParam); lang != "" {
+					preferences = append(preferences, lang)
+				}
+			}
+			preferences = append(preferences, c.Request().Header.Get(HeaderAcceptLanguage))
+
+			// MatchStrings ignores malformed entries; confidence No means
+			// nothing matched and index 0 (the default) is returned.
+			_, index := language.MatchStrings(matcher, preferences...)
+			locale := supported[index].String()
+
+			c.Set(LocaleKey, locale)
+			req := c.Request()
+			c.SetRequest(req.WithContext(ctxutil.WithLocale(req.Context(), locale)))
+
+			// Responses may differ per Accept-Language, so shared caches
+			// must key on it.
+			c.Response().Header().Add(echo.HeaderVary, HeaderAcceptLanguage)
+
+			return next(c)
+		}
+	}
+}
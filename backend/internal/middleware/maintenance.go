@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"slices"
+	"strconv"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+// MaintenanceTogglePath is the admin endpoint switching maintenance mode.
+// It is always reachable, otherwise maintenance mode could never be turned off.
+const MaintenanceTogglePath = "/api/v1/admin/maintenance"
+
+// MaintenanceMiddleware rejects traffic while maintenance mode is on.
+type MaintenanceMiddleware struct {
+	server *server.Server
+}
+
+// NewMaintenanceMiddleware constructs the maintenance middleware.
+func NewMaintenanceMiddleware(s *server.Server) *MaintenanceMiddleware {
+	return &MaintenanceMiddleware{
+		server: s,
+	}
+}
+
+// Guard returns a middleware answering 503 MAINTENANCE_MODE (with Retry-After
+// and an optional redirect action) for every non-allowlisted route while the
+// Redis flag is set.
+//
+// Redis failures fail open: an unreachable Redis must not take the API down.
+func (m *MaintenanceMiddleware) Guard() echo.MiddlewareFunc {
+	cfg := m.server.Config.Maintenance
+	if cfg == nil {
+		cfg = config.DefaultMaintenanceConfig()
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			route := RouteName(c)
+			if route == MaintenanceTogglePath || slices.Contains(cfg.AllowedPaths, route) {
+				return next(c)
+			}
+
+			state, err := m.server.Maintenance.Get(c.Request().Context())
+			if err != nil {
+				GetLogger(c).Warn().Err(err).Msg("could not read maintenance flag, failing open")
+			}
+
+			if !state.Enabled {
+				return next(c)
+			}
+
+			retryAfter := state.RetryAfter
+			if retryAfter <= 0 {
+				retryAfter = cfg.DefaultRetryAfter
+			}
+			if retryAfter > 0 {
+				c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(retryAfter))
+			}
+
+			message := state.Message
+			if message == "" {
+				message = "The service is undergoing maintenance, please retry later"
+			}
+
+			var action *errs.Action
+			if state.RedirectURL != "" {
+				action = &errs.Action{
+					Type:    errs.ActionTypeRedirect,
+					Message: "See the status page for progress",
+					Value:   state.RedirectURL,
+				}
+			}
+
+			return errs.NewMaintenanceError(message, action)
+		}
+	}
+}
@@ -2,7 +2,6 @@ package middleware
 
 import (
 	"github.com/deppfellow/go-boilerplate/internal/server"
-	"github.com/newrelic/go-agent/v3/newrelic"
 )
 
 // Middlewares is a lightweight container that groups all middleware components
@@ -11,7 +10,7 @@ import (
 // Why this exists:
 //   - Avoid scattering middleware construction throughout routing/setup code.
 //   - Provide a single place where shared dependencies (like *server.Server and
-//     New Relic application instance) are wired into middleware.
+//     its APM tracer) are wired into middleware.
 //
 // This is dependency injection in its simplest form: build once, reuse everywhere.
 type Middlewares struct {
@@ -26,39 +25,86 @@ type Middlewares struct {
 	// (request_id, method, path, ip, optional user & trace metadata).
 	ContextEnhancer *ContextEnhancer
 
-	// Tracing provides New Relic middleware and helpers to attach custom attributes
-	// and notice errors on transactions.
+	// Tracing provides the APM request middleware and helpers to attach custom
+	// attributes and record errors on spans.
 	Tracing *TracingMiddleware
 
+	// Metrics records Prometheus request metrics (count, latency, in-flight).
+	Metrics *MetricsMiddleware
+
 	// RateLimit is telemetry/utility around rate limit events (records New Relic custom events).
 	// Note: the enforcement logic, if any, typically lives elsewhere.
 	RateLimit *RateLimitMiddleware
+
+	// GeoIP resolves client country/region and enforces country allow/deny rules.
+	GeoIP *GeoIPMiddleware
+
+	// CSRF protects cookie-authenticated browser clients (config-gated).
+	CSRF *CSRFMiddleware
+
+	// Path canonicalizes request paths (trailing/duplicate slashes) before routing.
+	Path *PathMiddleware
+
+	// Maintenance rejects traffic with 503 while the Redis maintenance flag is set.
+	Maintenance *MaintenanceMiddleware
+
+	// Timeout applies config-driven request deadlines.
+	Timeout *TimeoutMiddleware
+
+	// LoadShed caps in-flight requests and sheds excess load with 503.
+	LoadShed *LoadShedMiddleware
+
+	// Audit records who changed what (mutating requests) via the job queue.
+	Audit *AuditMiddleware
+
+	// QueryBudget counts DB statements per request and reports N+1 patterns.
+	QueryBudget *QueryBudgetMiddleware
+
+	// Webhook deduplicates provider deliveries by event ID (Redis).
+	Webhook *WebhookMiddleware
+
+	// Quota enforces per-plan daily/monthly usage quotas (Redis).
+	Quota *QuotaMiddleware
+
+	// Locale resolves the request locale from ?lang / Accept-Language.
+	Locale *LocaleMiddleware
+
+	// RPC authenticates internal calls from other services (service tokens).
+	RPC *RPCMiddleware
+
+	// Tenancy resolves the request tenant (header/subdomain/claims) and
+	// guards tenant-scoped routes.
+	Tenancy *TenancyMiddleware
 }
 
 // NewMiddlewares constructs all middleware components using the application container.
 //
-// It also extracts the New Relic application instance (if configured) from the server's
-// LoggerService and injects it into TracingMiddleware.
+// It also injects the server's APM tracer (see lib/tracing) into TracingMiddleware.
 //
-// Behavior when New Relic is not configured:
-// - nrApp will be nil.
-// - tracing middleware should degrade into a no-op (no transactions, no attributes).
+// Behavior when tracing is not configured:
+// - the tracer is the no-op provider.
+// - tracing middleware degrades into a no-op (no spans, no attributes).
 func NewMiddlewares(s *server.Server) *Middlewares {
-	// Get New Relic application instance from server, if available.
-	//
-	// LoggerService is responsible for initializing New Relic.
-	// If New Relic is disabled or misconfigured, GetApplication() returns nil.
-	var nrApp *newrelic.Application
-	if s.LoggerService != nil {
-		nrApp = s.LoggerService.GetApplication()
-	}
-
 	// Construct all middleware "services" once and reuse them during router setup.
 	return &Middlewares{
 		Global:          NewGlobalMiddlewares(s),
 		Auth:            NewAuthMiddleware(s),
 		ContextEnhancer: NewContextEnhancer(s),
-		Tracing:         NewTracingMiddleware(s, nrApp),
+		Tracing:         NewTracingMiddleware(s, s.Tracer),
+		Metrics:         NewMetricsMiddleware(s),
 		RateLimit:       NewRateLimitMiddleware(s),
+		GeoIP:           NewGeoIPMiddleware(s),
+		CSRF:            NewCSRFMiddleware(s),
+		Path:            NewPathMiddleware(s),
+		Maintenance:     NewMaintenanceMiddleware(s),
+		Timeout:         NewTimeoutMiddleware(s),
+		LoadShed:        NewLoadShedMiddleware(s),
+		Audit:           NewAuditMiddleware(s),
+		QueryBudget:     NewQueryBudgetMiddleware(s),
+		Webhook:         NewWebhookMiddleware(s),
+		Quota:           NewQuotaMiddleware(s),
+		Locale:          NewLocaleMiddleware(s),
+		RPC:             NewRPCMiddleware(s),
+		Tenancy:         NewTenancyMiddleware(s),
 	}
 }
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+// PathMiddleware canonicalizes request paths before routing (see config.PathConfig).
+type PathMiddleware struct {
+	server *server.Server
+}
+
+// NewPathMiddleware constructs the path normalization middleware.
+func NewPathMiddleware(s *server.Server) *PathMiddleware {
+	return &PathMiddleware{
+		server: s,
+	}
+}
+
+// Normalize returns a Pre middleware (register with router.Pre, it must run
+// before routing) that canonicalizes host and path.
+//
+// In redirect mode non-canonical URLs get a 308 to the canonical one; in
+// rewrite mode the request is silently routed as if the canonical URL was
+// requested. Host lowercasing is always applied in place.
+func (m *PathMiddleware) Normalize() echo.MiddlewareFunc {
+	cfg := m.server.Config.Paths
+	if cfg == nil {
+		cfg = config.DefaultPathConfig()
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			if cfg.LowercaseHost {
+				req.Host = strings.ToLower(req.Host)
+			}
+
+			path := req.URL.Path
+			canonical := normalizePath(path, cfg)
+			if canonical == path {
+				return next(c)
+			}
+
+			if cfg.Mode == config.PathModeRewrite {
+				req.URL.Path = canonical
+				req.URL.RawPath = ""
+				return next(c)
+			}
+
+			target := canonical
+			if req.URL.RawQuery != "" {
+				target += "?" + req.URL.RawQuery
+			}
+			return c.Redirect(http.StatusPermanentRedirect, target)
+		}
+	}
+}
+
+// normalizePath applies the slash policies. The root "/" is never modified.
+//
+// Leading slashes are always collapsed to one, whatever CollapseSlashes says:
+// redirecting to "//evil.com/x" (or "/\evil.com/x", which browsers read the
+// same way) would send the client to another host.
+func normalizePath(path string, cfg *config.PathConfig) string {
+	if path == "" {
+		return "/"
+	}
+
+	if strings.HasPrefix(path, "/") {
+		path = "/" + strings.TrimLeft(path, "/\\")
+	}
+
+	if cfg.CollapseSlashes {
+		for strings.Contains(path, "//") {
+			path = strings.ReplaceAll(path, "//", "/")
+		}
+	}
+
+	if path == "/" {
+		return path
+	}
+
+	switch cfg.TrailingSlash {
+	case config.TrailingSlashStrip:
+		path = strings.TrimRight(path, "/")
+		if path == "" {
+			path = "/"
+		}
+	case config.TrailingSlashAdd:
+		if !strings.HasSuffix(path, "/") {
+			path += "/"
+		}
+	}
+
+	return path
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+)
+
+func TestNormalizePath(t *testing.T) {
+	strip := config.DefaultPathConfig()
+	add := &config.PathConfig{TrailingSlash: config.TrailingSlashAdd, CollapseSlashes: true}
+	keep := &config.PathConfig{TrailingSlash: config.TrailingSlashKeep}
+
+	tests := []struct {
+		name string
+		path string
+		cfg  *config.PathConfig
+		want string
+	}{
+		{"empty", "", strip, "/"},
+		{"root", "/", strip, "/"},
+		{"canonical", "/api/v1/todos", strip, "/api/v1/todos"},
+		{"strip trailing slash", "/api/v1/todos/", strip, "/api/v1/todos"},
+		{"strip repeated trailing slashes", "/todos///", strip, "/todos"},
+		{"collapse inner slashes", "/api//v1///todos", strip, "/api/v1/todos"},
+		{"add trailing slash", "/todos", add, "/todos/"},
+		{"keep trailing slash", "/todos/", keep, "/todos/"},
+		{"keep inner slashes", "/a//b", keep, "/a//b"},
+
+		// A redirect to "//host/x" or "/\host/x" would leave the site, so
+		// leading slashes collapse whatever the config says.
+		{"protocol-relative", "//evil.com/x", keep, "/evil.com/x"},
+		{"backslash", "/\\evil.com/x", keep, "/evil.com/x"},
+		{"mixed", "/\\/\\evil.com", strip, "/evil.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizePath(tt.path, tt.cfg); got != tt.want {
+				t.Errorf("normalizePath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/database"
+	"github.com/deppfellow/go-boilerplate/internal/lib/tracing"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+// QueryBudgetMiddleware enforces (by reporting, not rejecting) the
+// per-request database statement budget from QueryBudgetConfig.
+type QueryBudgetMiddleware struct {
+	server *server.Server
+}
+
+// NewQueryBudgetMiddleware constructs QueryBudgetMiddleware with access to app Server.
+func NewQueryBudgetMiddleware(s *server.Server) *QueryBudgetMiddleware {
+	return &QueryBudgetMiddleware{server: s}
+}
+
+// Track attaches a database.QueryBudget to the request context and inspects
+// it once the handler returns.
+//
+// Requests over MaxQueries are logged as "query budget exceeded"; each
+// statement repeated RepeatThreshold times or more is logged as a "possible
+// N+1 query". The count and an over-budget flag are also added to the New
+// Relic transaction (db.query_count, db.query_budget_exceeded) so offenders
+// can be found in production without reading logs.
+//
+// It must run after EnhanceContext so the warnings carry request_id etc.
+func (q *QueryBudgetMiddleware) Track() echo.MiddlewareFunc {
+	cfg := q.server.Config.QueryBudget
+	if cfg == nil {
+		cfg = config.DefaultQueryBudgetConfig()
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !cfg.Enabled {
+			return next
+		}
+
+		return func(c echo.Context) error {
+			ctx, budget := database.WithQueryBudget(c.Request().Context())
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			err := next(c)
+
+			total := budget.Total()
+			exceeded := total > cfg.MaxQueries
+
+			if span := tracing.FromContext(c.Request().Context()); span != nil {
+				span.SetAttribute("db.query_count", total)
+				span.SetAttribute("db.query_budget_exceeded", exceeded)
+			}
+
+			logger := GetLogger(c)
+
+			if exceeded {
+				logger.Warn().
+					Int("query_count", total).
+					Int("query_budget", cfg.MaxQueries).
+					Msg("query budget exceeded")
+			}
+
+			for _, repeated := range budget.Repeated(cfg.RepeatThreshold) {
+				logger.Warn().
+					Str("statement", repeated.SQL).
+					Int("executions", repeated.Count).
+					Msg("possible N+1 query")
+			}
+
+			return err
+		}
+	}
+}
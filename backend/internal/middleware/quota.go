@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/lib/quota"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+// PlanKey is the Echo context key holding the caller's plan name. RequireAuth
+// sets it from the "plan" session claim, which carries the Clerk user's
+// public metadata "plan" (see sessionClaims for the token template); without
+// it the configured default plan applies.
+const PlanKey = "plan"
+
+const (
+	// Quota response headers, one set per window.
+	HeaderQuotaDailyRemaining   = "X-Quota-Daily-Remaining"
+	HeaderQuotaMonthlyRemaining = "X-Quota-Monthly-Remaining"
+)
+
+// GetPlan returns the caller's plan from Echo context ("" when unknown).
+func GetPlan(c echo.Context) string {
+	if plan, ok := c.Get(PlanKey).(string); ok {
+		return plan
+	}
+	return ""
+}
+
+// QuotaMiddleware enforces per-plan usage quotas (see lib/quota).
+type QuotaMiddleware struct {
+	server *server.Server
+}
+
+// NewQuotaMiddleware constructs QuotaMiddleware.
+func NewQuotaMiddleware(s *server.Server) *QuotaMiddleware {
+	return &QuotaMiddleware{server: s}
+}
+
+// QuotaSubject returns the identity quotas are counted against (user or
+// verified API key, see DefaultIdentifierExtractor), its plan and limits.
+// ok is false for anonymous callers, which quotas do not apply to.
+func QuotaSubject(c echo.Context, cfg *config.QuotaConfig) (subject, plan string, limits quota.Limits, ok bool) {
+	subject, authenticated := DefaultIdentifierExtractor(c)
+	if !authenticated {
+		return "", "", quota.Limits{}, false
+	}
+
+	plan, limits = cfg.LimitsFor(GetPlan(c))
+	return subject, plan, limits, true
+}
+
+// Enforce counts the request against the caller's daily and monthly quota
+// and rejects it with 429 QUOTA_EXCEEDED once either is used up.
+//
+// It is declared per route (handler.Route(h).Auth().Quota()) so it runs
+// after authentication, and only metered endpoints consume quota.
+//
+// Redis failures fail open: an unavailable counter store must not take the
+// API down with it, so the error is logged and the request goes through.
+func (q *QuotaMiddleware) Enforce() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			cfg := q.server.CurrentConfig().Quota
+			if cfg == nil || !cfg.Enabled {
+				return next(c)
+			}
+
+			subject, plan, limits, ok := QuotaSubject(c, cfg)
+			if !ok {
+				return next(c)
+			}
+
+			usage, err := q.server.Quota.Consume(c.Request().Context(), subject, limits, time.Now())
+			if err != nil {
+				GetLogger(c).Error().Err(err).Str("subject", subject).Msg("quota check failed, allowing request")
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			for _, u := range usage {
+				if u.Limit == 0 {
+					continue
+				}
+				switch u.Window {
+				case quota.Daily:
+					header.Set(HeaderQuotaDailyRemaining, strconv.FormatInt(u.Remaining, 10))
+				case quota.Monthly:
+					header.Set(HeaderQuotaMonthlyRemaining, strconv.FormatInt(u.Remaining, 10))
+				}
+			}
+
+			for _, u := range usage {
+				if !u.Exceeded() {
+					continue
+				}
+
+				wait := time.Until(u.ResetAt)
+				retryAfter := ceilSeconds(wait)
+				header.Set(echo.HeaderRetryAfter, strconv.Itoa(retryAfter))
+
+				GetLogger(c).Warn().
+					Str("subject", subject).
+					Str("plan", plan).
+					Str("window", string(u.Window)).
+					Int64("limit", u.Limit).
+					Int("retry_after_seconds", retryAfter).
+					Msg("quota exceeded")
+
+				return errs.NewQuotaExceededError("Your "+string(u.Window)+" quota for the "+plan+" plan is used up", string(u.Window)).
+					WithAction(errs.NewRetryAfterAction("Quota resets at the end of the "+string(u.Window)+" window", wait))
+			}
+
+			return next(c)
+		}
+	}
+}
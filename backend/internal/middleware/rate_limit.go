@@ -1,15 +1,23 @@
 package middleware
 
 import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/errs"
 	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
 )
 
 // RateLimitMiddleware is a helper around rate-limiting behavior.
 //
-// In this snippet, it does NOT enforce a limit.
-// It only records a "RateLimitHit" event to New Relic when a limit is hit.
-//
-// Actual limiting logic (deny requests, token bucket, etc.) would be elsewhere.
+// It builds rate limiter middleware (Limit) and records a "RateLimitHit"
+// event to New Relic when a limit is hit.
 type RateLimitMiddleware struct {
 	// server holds access to shared dependencies like LoggerService (New Relic).
 	server *server.Server
@@ -26,6 +34,8 @@ func NewRateLimitMiddleware(s *server.Server) *RateLimitMiddleware {
 //
 // Input:
 //   - endpoint: usually the route/path name that was rate-limited
+//   - tenantDimension: GetTenantDimension ("" before tenancy is resolved,
+//     e.g. for the global limiter)
 //
 // Behavior:
 //   - If New Relic is enabled, call RecordCustomEvent.
@@ -33,7 +43,7 @@ func NewRateLimitMiddleware(s *server.Server) *RateLimitMiddleware {
 //
 // Output:
 //   - No return value. This is best-effort telemetry.
-func (r *RateLimitMiddleware) RecordLateLimitHit(endpoint string) {
+func (r *RateLimitMiddleware) RecordLateLimitHit(endpoint, tenantDimension string) {
 	// Check that LoggerService exists and has a real New Relic application instance.
 	if r.server.LoggerService != nil && r.server.LoggerService.GetApplication() != nil {
 		// RecordCustomEvent creates a custom event in New Relic.
@@ -42,8 +52,247 @@ func (r *RateLimitMiddleware) RecordLateLimitHit(endpoint string) {
 		r.server.LoggerService.GetApplication().RecordCustomEvent(
 			"RateLimitHit",
 			map[string]interface{}{
-				"endpoint": endpoint,
+				"endpoint":         endpoint,
+				"tenant_dimension": tenantDimension,
 			},
 		)
 	}
 }
+
+const (
+	// Standard-ish rate limit response headers (draft-ietf-httpapi-ratelimit-headers).
+	HeaderRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	HeaderRateLimitReset     = "X-RateLimit-Reset"
+)
+
+// HeaderAPIKey is the header carrying an API key for machine clients.
+const HeaderAPIKey = "X-API-Key"
+
+// APIKeyIDKey is the Echo context key holding the ID of a verified API key,
+// set by AuthMiddleware.RequireAPIKey. The raw X-API-Key header is never
+// trusted on its own, since any client can send a fresh made-up key per
+// request to dodge its per-IP budget.
+const APIKeyIDKey = "api_key_id"
+
+// GetAPIKeyID returns the verified API key ID from Echo context ("" when the
+// request was not authenticated with an API key).
+func GetAPIKeyID(c echo.Context) string {
+	if keyID, ok := c.Get(APIKeyIDKey).(string); ok {
+		return keyID
+	}
+	return ""
+}
+
+// IdentifierExtractor returns the rate limit key for a request and whether the
+// caller is authenticated (which selects the authenticated budget).
+type IdentifierExtractor func(c echo.Context) (identifier string, authenticated bool)
+
+// DefaultIdentifierExtractor keys limits on, in order of preference:
+//  1. the authenticated user_id (set by RequireAuth; route-level limiters
+//     declared after Auth() see it)
+//  2. the verified API key ID (APIKeyIDKey, set by RequireAPIKey)
+//  3. the client IP (anonymous traffic, including unverified API keys)
+//
+// The global limiter runs before authentication and keys on the IP; the
+// authenticated budget of the user or key is charged by ChargeAuthenticated.
+func DefaultIdentifierExtractor(c echo.Context) (string, bool) {
+	if userID := GetUserID(c); userID != "" {
+		return "user:" + userID, true
+	}
+
+	if keyID := GetAPIKeyID(c); keyID != "" {
+		return "apikey:" + keyID, true
+	}
+
+	return "ip:" + c.RealIP(), false
+}
+
+// RateLimitPolicy configures LimitWithPolicy.
+type RateLimitPolicy struct {
+	// AnonymousRPS is the per-identifier budget for unauthenticated callers.
+	AnonymousRPS float64
+
+	// AuthenticatedRPS is the per-identifier budget for authenticated callers.
+	AuthenticatedRPS float64
+
+	// Extractor picks the identifier. Defaults to DefaultIdentifierExtractor.
+	Extractor IdentifierExtractor
+}
+
+// Limit returns a rate limiter allowing rps requests per second per identity
+// (same budget for anonymous and authenticated callers).
+//
+// It is used for per-route limits declared with handler.Route(h).RateLimit(n).
+func (r *RateLimitMiddleware) Limit(rps float64) echo.MiddlewareFunc {
+	return r.LimitWithPolicy(RateLimitPolicy{
+		AnonymousRPS:     rps,
+		AuthenticatedRPS: rps,
+	})
+}
+
+// Global returns the app-wide limiter using budgets from RateLimitConfig.
+// The budgets follow config reloads.
+//
+// It runs before authentication, when only the client IP is known:
+//   - requests without credentials are charged to their IP at the
+//     anonymous budget
+//   - requests with a bearer token or API key are charged to their IP at
+//     the authenticated budget, so made-up credentials still hit a per-IP
+//     cap; RequireAuth / RequireAPIKey then charge the verified user or key
+//     at the authenticated budget (see ChargeAuthenticated)
+func (r *RateLimitMiddleware) Global() echo.MiddlewareFunc {
+	budgets := globalBudgetsFor(r.server)
+
+	policy := RateLimitPolicy{
+		Extractor: func(c echo.Context) (string, bool) {
+			return "ip:" + c.RealIP(), hasCredentials(c.Request())
+		},
+	}
+	return r.limit(policy, budgets.anonymous, budgets.authenticated)
+}
+
+// ChargeAuthenticated charges the app-wide authenticated budget of the
+// caller identified by DefaultIdentifierExtractor, and returns the 429 to
+// respond with once it is used up. Authentication middleware calls it right
+// after setting the user or API key; it does nothing for anonymous callers.
+func (r *RateLimitMiddleware) ChargeAuthenticated(c echo.Context) error {
+	identifier, authenticated := DefaultIdentifierExtractor(c)
+	if !authenticated {
+		return nil
+	}
+	return r.take(c, identifier, true, globalBudgetsFor(r.server).authenticated)
+}
+
+// globalBudgets are the stores of the app-wide limiter. Global and
+// ChargeAuthenticated share them, and middleware is constructed per route,
+// so they are kept per server rather than per RateLimitMiddleware.
+type globalBudgets struct {
+	anonymous     *RateLimitStore
+	authenticated *RateLimitStore
+}
+
+var (
+	globalBudgetsMu       sync.Mutex
+	globalBudgetsByServer = map[*server.Server]*globalBudgets{}
+)
+
+// globalBudgetsFor returns the app-wide stores of s, creating them (and
+// subscribing them to config reloads) on first use.
+func globalBudgetsFor(s *server.Server) *globalBudgets {
+	globalBudgetsMu.Lock()
+	defer globalBudgetsMu.Unlock()
+
+	if budgets, ok := globalBudgetsByServer[s]; ok {
+		return budgets
+	}
+
+	cfg := s.Config.RateLimit
+	if cfg == nil {
+		cfg = config.DefaultRateLimitConfig()
+	}
+	budgets := &globalBudgets{
+		anonymous:     NewRateLimitStore(cfg.AnonymousRPS),
+		authenticated: NewRateLimitStore(cfg.AuthenticatedRPS),
+	}
+
+	// Budgets are reloadable (config.Watcher); existing clients keep their tokens.
+	if s.ConfigWatcher != nil {
+		s.ConfigWatcher.Subscribe(func(change config.Change) {
+			if change.Has(config.ReloadRateLimit) && change.New.RateLimit != nil {
+				budgets.anonymous.SetRate(change.New.RateLimit.AnonymousRPS)
+				budgets.authenticated.SetRate(change.New.RateLimit.AuthenticatedRPS)
+			}
+		})
+	}
+
+	globalBudgetsByServer[s] = budgets
+	return budgets
+}
+
+// hasCredentials reports whether req carries a bearer token or an API key,
+// verified or not.
+func hasCredentials(req *http.Request) bool {
+	return strings.HasPrefix(req.Header.Get(echo.HeaderAuthorization), "Bearer ") || req.Header.Get(HeaderAPIKey) != ""
+}
+
+// LimitWithPolicy returns a rate limiter with separate budgets for anonymous
+// and authenticated identities.
+//
+// Every response carries X-RateLimit-Limit / -Remaining / -Reset headers.
+// Rejected requests additionally get Retry-After and a 429 errs.HTTPError with
+// code RATE_LIMIT_EXCEEDED, rendered by the global error handler.
+//
+// NOTE: the stores are in-memory, so each call creates independent budgets and
+// each instance in a multi-instance deployment has its own limiter.
+func (r *RateLimitMiddleware) LimitWithPolicy(policy RateLimitPolicy) echo.MiddlewareFunc {
+	return r.limit(policy, NewRateLimitStore(policy.AnonymousRPS), NewRateLimitStore(policy.AuthenticatedRPS))
+}
+
+// limit enforces policy with the given stores.
+func (r *RateLimitMiddleware) limit(policy RateLimitPolicy, anonymousStore, authenticatedStore *RateLimitStore) echo.MiddlewareFunc {
+	extractor := policy.Extractor
+	if extractor == nil {
+		extractor = DefaultIdentifierExtractor
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			identifier, authenticated := extractor(c)
+
+			store := anonymousStore
+			if authenticated {
+				store = authenticatedStore
+			}
+
+			if err := r.take(c, identifier, authenticated, store); err != nil {
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// take charges identifier one request in store, sets the rate limit headers
+// and returns the 429 error once the budget is used up.
+func (r *RateLimitMiddleware) take(c echo.Context, identifier string, authenticated bool, store *RateLimitStore) error {
+	result := store.Take(identifier)
+
+	header := c.Response().Header()
+	header.Set(HeaderRateLimitLimit, strconv.Itoa(result.Limit))
+	header.Set(HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+	header.Set(HeaderRateLimitReset, strconv.Itoa(ceilSeconds(result.Reset)))
+
+	if result.Allowed {
+		return nil
+	}
+
+	retryAfter := ceilSeconds(result.RetryAfter)
+	header.Set(echo.HeaderRetryAfter, strconv.Itoa(retryAfter))
+
+	// Record rate limit hit telemetry (New Relic custom event) if enabled.
+	r.RecordLateLimitHit(RouteName(c), GetTenantDimension(c))
+
+	// Log rate limit rejection with useful correlation fields.
+	r.server.Logger.Warn().
+		Str("request_id", GetRequestID(c)).
+		Str("identifier", identifier). // user:<id>, apikey:<id> or ip:<addr>
+		Bool("authenticated", authenticated).
+		Str("path", RouteName(c)).         // route template path
+		Str("method", c.Request().Method). // HTTP method
+		Str("ip", c.RealIP()).             // client IP (respects proxy headers)
+		Float64("limit_rps", store.Rate()).
+		Int("retry_after_seconds", retryAfter).
+		Msg("rate limit exceeded")
+
+	// The global error handler will format the final JSON response.
+	// The retry_after action mirrors Retry-After in the body for
+	// clients that can't read response headers (e.g. CORS-restricted).
+	return errs.NewRateLimitExceededError("Rate limit exceeded, please retry later").
+		WithAction(errs.NewRetryAfterAction("Retry after the limit resets", result.RetryAfter))
+}
+
+// ceilSeconds rounds a duration up to whole seconds (header values are integers).
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
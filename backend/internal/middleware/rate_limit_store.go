@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// rateLimitStoreExpiry is how long an idle client's limiter is kept in memory.
+const rateLimitStoreExpiry = 3 * time.Minute
+
+// RateLimitResult is the outcome of a single rate limit check.
+//
+// It carries enough information to emit the de-facto standard headers:
+//   - X-RateLimit-Limit:     Limit
+//   - X-RateLimit-Remaining: Remaining
+//   - X-RateLimit-Reset:     seconds until the bucket is full again (Reset)
+//   - Retry-After:           seconds until the next request is allowed (RetryAfter, 429 only)
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// RateLimitStore is an in-memory token bucket store keyed by client identifier.
+//
+// It mirrors Echo's RateLimiterMemoryStore (per-identifier x/time/rate
+// limiters with idle cleanup) but also reports remaining tokens and reset
+// times, which Echo's store interface does not expose.
+type RateLimitStore struct {
+	mu          sync.Mutex
+	visitors    map[string]*rateLimitVisitor
+	rate        rate.Limit
+	burst       int
+	lastCleanup time.Time
+}
+
+type rateLimitVisitor struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewRateLimitStore creates a store allowing rps requests per second with a
+// burst of the same size (minimum 1).
+func NewRateLimitStore(rps float64) *RateLimitStore {
+	burst := int(math.Max(1, math.Floor(rps)))
+	return &RateLimitStore{
+		visitors:    make(map[string]*rateLimitVisitor),
+		rate:        rate.Limit(rps),
+		burst:       burst,
+		lastCleanup: time.Now(),
+	}
+}
+
+// SetRate changes the budget to rps (burst follows, as in NewRateLimitStore)
+// for new and existing clients, keeping their current token counts.
+func (s *RateLimitStore) SetRate(rps float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rate = rate.Limit(rps)
+	s.burst = int(math.Max(1, math.Floor(rps)))
+	for _, v := range s.visitors {
+		v.limiter.SetLimit(s.rate)
+		v.limiter.SetBurst(s.burst)
+	}
+}
+
+// Rate returns the current budget in requests per second.
+func (s *RateLimitStore) Rate() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return float64(s.rate)
+}
+
+// Take consumes one token for identifier and reports the bucket state.
+func (s *RateLimitStore) Take(identifier string) RateLimitResult {
+	now := time.Now()
+
+	s.mu.Lock()
+	v, ok := s.visitors[identifier]
+	if !ok {
+		v = &rateLimitVisitor{limiter: rate.NewLimiter(s.rate, s.burst)}
+		s.visitors[identifier] = v
+	}
+	v.lastSeen = now
+	if now.Sub(s.lastCleanup) > rateLimitStoreExpiry {
+		s.cleanup(now)
+	}
+	limit, burst := s.rate, s.burst
+	s.mu.Unlock()
+
+	allowed := v.limiter.AllowN(now, 1)
+	tokens := v.limiter.TokensAt(now)
+
+	result := RateLimitResult{
+		Allowed:   allowed,
+		Limit:     burst,
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+		Reset:     durationFor(limit, float64(burst)-tokens),
+	}
+
+	if !allowed {
+		result.RetryAfter = durationFor(limit, 1-tokens)
+	}
+
+	return result
+}
+
+// durationFor converts a number of missing tokens into refill time at limit.
+func durationFor(limit rate.Limit, tokens float64) time.Duration {
+	if tokens <= 0 || limit <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / float64(limit) * float64(time.Second))
+}
+
+// cleanup drops limiters that have been idle longer than rateLimitStoreExpiry.
+// Caller must hold s.mu.
+func (s *RateLimitStore) cleanup(now time.Time) {
+	for id, v := range s.visitors {
+		if now.Sub(v.lastSeen) > rateLimitStoreExpiry {
+			delete(s.visitors, id)
+		}
+	}
+	s.lastCleanup = now
+}
@@ -0,0 +1,58 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/handlertest"
+	"github.com/deppfellow/go-boilerplate/internal/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+func TestRateLimitHeadersAndRejection(t *testing.T) {
+	h := handlertest.New(t)
+	h.Echo.GET("/ping", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) },
+		h.Middlewares.RateLimit.Limit(2))
+
+	for remaining := 1; remaining >= 0; remaining-- {
+		rec := h.Do(http.MethodGet, "/ping", nil)
+		h.RequireStatus(rec, http.StatusNoContent)
+		if got := rec.Header().Get(middleware.HeaderRateLimitLimit); got != "2" {
+			t.Fatalf("%s = %q, want 2", middleware.HeaderRateLimitLimit, got)
+		}
+		if got := rec.Header().Get(middleware.HeaderRateLimitRemaining); got != strconv.Itoa(remaining) {
+			t.Fatalf("%s = %q, want %d", middleware.HeaderRateLimitRemaining, got, remaining)
+		}
+	}
+
+	res, rec := handlertest.DoJSON[errs.HTTPError](h, http.MethodGet, "/ping", nil)
+	h.RequireStatus(rec, http.StatusTooManyRequests)
+	if res.Code != "RATE_LIMIT_EXCEEDED" {
+		t.Fatalf("got code %q, want RATE_LIMIT_EXCEEDED", res.Code)
+	}
+	if rec.Header().Get(echo.HeaderRetryAfter) == "" {
+		t.Fatal("429 without Retry-After")
+	}
+	if res.Action == nil || res.Action.Type != errs.ActionTypeRetryAfter {
+		t.Fatalf("got action %+v, want retry_after", res.Action)
+	}
+}
+
+func TestRateLimitBudgetsArePerClient(t *testing.T) {
+	h := handlertest.New(t)
+	h.Echo.GET("/ping", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) },
+		h.Middlewares.RateLimit.Limit(1))
+
+	h.RequireStatus(h.Do(http.MethodGet, "/ping", nil), http.StatusNoContent)
+	h.RequireStatus(h.Do(http.MethodGet, "/ping", nil), http.StatusTooManyRequests)
+
+	// httptest requests come from 192.0.2.1; another client has its own budget.
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.RemoteAddr = "198.51.100.7:1234"
+	rec := httptest.NewRecorder()
+	h.Echo.ServeHTTP(rec, req)
+	h.RequireStatus(rec, http.StatusNoContent)
+}
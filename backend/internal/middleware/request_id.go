@@ -1,6 +1,10 @@
 package middleware
 
 import (
+	"net"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/lib/ctxutil"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
@@ -12,34 +16,70 @@ const (
 
 	// RequestIDKey is the internal key used to store the ID in Echo context.
 	RequestIDKey = "request_id"
+
+	// ServerRequestIDHeader carries the ID generated by this server for every
+	// request, regardless of what the client sent in X-Request-ID.
+	ServerRequestIDHeader = "X-Server-Request-ID"
+
+	// ServerRequestIDKey is the Echo context key for the server-generated ID.
+	ServerRequestIDKey = "server_request_id"
 )
 
-// RequestID returns an Echo middleware that ensures each request has a request ID.
+// RequestID returns the request ID middleware with default settings
+// (header validated, accepted from any peer).
+func RequestID() echo.MiddlewareFunc {
+	return RequestIDWithConfig(config.DefaultRequestIDConfig())
+}
+
+// RequestIDWithConfig returns an Echo middleware that ensures each request has a request ID.
 //
 // Behavior:
-//   - If incoming request already has X-Request-ID header: reuse it.
-//   - If not: generate a new UUID.
-//   - Store it in Echo context (c.Set) for internal access.
-//   - Set it on response header so clients can see it too.
-func RequestID() echo.MiddlewareFunc {
+//   - If incoming request has a valid X-Request-ID header from a trusted peer: reuse it.
+//   - If not (missing, too long, unsafe characters, untrusted peer): generate a new UUID.
+//   - Always generate a separate server request ID, which clients cannot influence,
+//     so logs can be correlated even when the client-supplied ID is reused or forged.
+//   - Store both in Echo context (c.Set) for internal access.
+//   - Set both on response headers so clients can see them too.
+func RequestIDWithConfig(cfg *config.RequestIDConfig) echo.MiddlewareFunc {
+	if cfg == nil {
+		cfg = config.DefaultRequestIDConfig()
+	}
+
+	maxLength := cfg.GetMaxLength()
+
+	// Validate() already rejected bad CIDRs at startup.
+	trusted, _ := cfg.ParsedTrustedProxies()
+
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			// Get request ID from incoming header (if any).
 			requestID := c.Request().Header.Get(RequestIDHeader)
 
-			// If not provided upstream, generate a UUID.
+			// Drop values we don't trust: unsafe content or an untrusted sender.
+			if !isValidRequestID(requestID, maxLength) || !isTrustedPeer(c, trusted) {
+				requestID = ""
+			}
+
+			// If not provided (or rejected), generate a UUID.
 			// UUIDs are cheap, unique enough, and easy for log correlation.
 			if requestID == "" {
 				requestID = uuid.New().String()
 			}
 
+			serverRequestID := uuid.New().String()
+
 			// Store in Echo context so other middleware/handlers can read it.
 			c.Set(RequestIDKey, requestID)
+			c.Set(ServerRequestIDKey, serverRequestID)
+
+			// Mirror the correlation ID for code that only sees context.Context.
+			c.SetRequest(c.Request().WithContext(ctxutil.WithRequestID(c.Request().Context(), requestID)))
 
 			// Echo it back in response header so:
 			// - client can report it in bug reports
 			// - reverse proxies/log systems can correlate
 			c.Response().Header().Set(RequestIDHeader, requestID)
+			c.Response().Header().Set(ServerRequestIDHeader, serverRequestID)
 
 			// Continue request pipeline.
 			return next(c)
@@ -47,6 +87,55 @@ func RequestID() echo.MiddlewareFunc {
 	}
 }
 
+// isValidRequestID accepts non-empty IDs of at most maxLength characters made
+// of [A-Za-z0-9._:-]. That covers UUIDs, ULIDs, W3C trace IDs and most proxy
+// formats while ruling out whitespace, quotes and control characters that
+// could forge log lines.
+func isValidRequestID(id string, maxLength int) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		ch := id[i]
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9':
+		case ch == '-', ch == '_', ch == '.', ch == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isTrustedPeer reports whether the direct peer may set X-Request-ID.
+//
+// It deliberately uses RemoteAddr instead of c.RealIP(): RealIP honors
+// X-Forwarded-For, which is exactly the kind of client-controlled input this
+// check must not rely on.
+func isTrustedPeer(c echo.Context, trusted []*net.IPNet) bool {
+	if len(trusted) == 0 {
+		return true
+	}
+
+	host, _, err := net.SplitHostPort(c.Request().RemoteAddr)
+	if err != nil {
+		host = c.Request().RemoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, n := range trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetRequestID retrieves the request ID from Echo context.
 //
 // Returns empty string if not set.
@@ -56,3 +145,13 @@ func GetRequestID(c echo.Context) string {
 	}
 	return ""
 }
+
+// GetServerRequestID retrieves the server-generated request ID from Echo context.
+//
+// Returns empty string if not set.
+func GetServerRequestID(c echo.Context) string {
+	if id, ok := c.Get(ServerRequestIDKey).(string); ok {
+		return id
+	}
+	return ""
+}
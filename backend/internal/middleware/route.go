@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"reflect"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// RouteUnmatched is the normalized route name for requests that did not
+	// match any registered route (404 scans, typos, bots probing /wp-admin...).
+	RouteUnmatched = "unmatched"
+
+	// RouteMethodNotAllowed is the normalized route name for requests whose
+	// path matched but whose method did not (405).
+	RouteMethodNotAllowed = "method_not_allowed"
+
+	// maxLoggedURILength caps how much of a raw URI is logged for unmatched
+	// routes, so scanners can't flood logs with huge unique strings.
+	maxLoggedURILength = 256
+)
+
+// RouteName returns the low-cardinality name used for metrics, traces and
+// structured log fields.
+//
+// It is always the Echo route template (c.Path(), e.g. "/users/:id"), never
+// the raw URI ("/users/42"). Requests that matched no route collapse into a
+// single RouteUnmatched bucket, so a 404 scan hitting thousands of unique
+// paths produces exactly one metric/trace name instead of thousands.
+func RouteName(c echo.Context) string {
+	handler := c.Handler()
+	if handler != nil {
+		ptr := reflect.ValueOf(handler).Pointer()
+		switch ptr {
+		case reflect.ValueOf(echo.NotFoundHandler).Pointer():
+			return RouteUnmatched
+		case reflect.ValueOf(echo.MethodNotAllowedHandler).Pointer():
+			return RouteMethodNotAllowed
+		}
+	}
+
+	if c.Path() == "" {
+		return RouteUnmatched
+	}
+
+	return c.Path()
+}
+
+// IsUnmatchedRoute reports whether the request did not match any route.
+func IsUnmatchedRoute(c echo.Context) bool {
+	return RouteName(c) == RouteUnmatched
+}
+
+// safeLogURI returns the raw URI for matched routes and a truncated URI for
+// unmatched ones.
+func safeLogURI(c echo.Context, uri string) string {
+	if IsUnmatchedRoute(c) && len(uri) > maxLoggedURILength {
+		return uri[:maxLoggedURILength] + "..."
+	}
+	return uri
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"strings"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/lib/rpc"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+// RPCCallerKey is the Echo context key holding the calling service name.
+const RPCCallerKey = "rpc_caller"
+
+// RPCMiddleware guards the internal RPC routes (see lib/rpc).
+type RPCMiddleware struct {
+	server *server.Server
+}
+
+// NewRPCMiddleware constructs RPCMiddleware with access to app Server.
+func NewRPCMiddleware(s *server.Server) *RPCMiddleware {
+	return &RPCMiddleware{server: s}
+}
+
+// Authenticate accepts only requests carrying a valid service token
+// ("Authorization: Bearer <token>") addressed to this service, and stores
+// the caller in the Echo context (GetRPCCaller) and the request context
+// (rpc.Caller, for rpc.Handle handlers).
+//
+// User tokens (Clerk) are rejected here: internal routes are for services only.
+func (r *RPCMiddleware) Authenticate() echo.MiddlewareFunc {
+	secret := []byte(r.server.Config.RPC.Secret)
+	self := r.server.Config.Primary.Service
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if !ok {
+				return errs.NewUnauthorizedError("Missing service token", false)
+			}
+
+			claims, err := rpc.VerifyToken(secret, token, self, time.Now())
+			if err != nil {
+				GetLogger(c).Warn().Err(err).Str("path", RouteName(c)).Msg("rejected internal RPC call")
+				return errs.NewUnauthorizedError("Invalid service token", false)
+			}
+
+			c.Set(RPCCallerKey, claims.Caller)
+			c.SetRequest(c.Request().WithContext(rpc.WithCaller(c.Request().Context(), claims.Caller)))
+			return next(c)
+		}
+	}
+}
+
+// GetRPCCaller returns the service that made the current RPC call ("" outside RPC routes).
+func GetRPCCaller(c echo.Context) string {
+	caller, _ := c.Get(RPCCallerKey).(string)
+	return caller
+}
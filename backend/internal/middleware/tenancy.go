@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+// TenancyMiddleware resolves the tenant of a request from the sources in
+// TenancyConfig.Sources and rejects requests that need one but have none.
+type TenancyMiddleware struct {
+	server *server.Server
+}
+
+// NewTenancyMiddleware constructs TenancyMiddleware.
+func NewTenancyMiddleware(s *server.Server) *TenancyMiddleware {
+	return &TenancyMiddleware{server: s}
+}
+
+// config returns the tenancy config, never nil.
+func (t *TenancyMiddleware) config() *config.TenancyConfig {
+	if cfg := t.server.Config.Tenancy; cfg != nil {
+		return cfg
+	}
+	return config.DefaultTenancyConfig()
+}
+
+// Resolve sets the tenant from the header and subdomain sources, in the
+// configured order, before authentication. The claims source is applied by
+// RequireAuth once the session is verified; a tenant picked here must then
+// match the user's active organization (see checkClaimsTenant), so the
+// header or host can't be used to reach another tenant's data.
+//
+// A malformed tenant ID is a 400. No-op unless tenancy is enabled. It must
+// run after ContextEnhancer, whose logger SetTenant extends.
+func (t *TenancyMiddleware) Resolve() echo.MiddlewareFunc {
+	cfg := t.config()
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !cfg.Enabled {
+			return next
+		}
+
+		return func(c echo.Context) error {
+			for _, source := range cfg.Sources {
+				var tenantID string
+				switch source {
+				case config.TenantSourceHeader:
+					tenantID = strings.TrimSpace(c.Request().Header.Get(cfg.Header))
+				case config.TenantSourceSubdomain:
+					tenantID = tenantFromHost(c.Request().Host, cfg.BaseDomain)
+				}
+				if tenantID == "" {
+					continue
+				}
+
+				if err := cfg.ValidateTenantID(tenantID); err != nil {
+					return errs.NewBadRequestError("Invalid tenant", true, nil, []errs.FieldError{
+						{Field: source, Error: err.Error()},
+					}, nil)
+				}
+				SetTenant(c, t.server.Config.Observability, tenantID)
+				break
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireTenant rejects requests without a resolved tenant with 403. Use it
+// on tenant-scoped route groups, after RequireAuth so the claims source has
+// been applied:
+//
+//	todos := v1.Group("/todos", middlewares.Auth.RequireAuth, middlewares.Tenancy.RequireTenant())
+func (t *TenancyMiddleware) RequireTenant() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if GetTenantID(c) == "" {
+				return errs.NewForbiddenError("No organization selected", true)
+			}
+			return next(c)
+		}
+	}
+}
+
+// tenantFromHost returns the label right below baseDomain in host
+// ("acme.example.com:8080" => "acme"), or "" for the base domain itself,
+// deeper subdomains and other hosts.
+func tenantFromHost(host, baseDomain string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	suffix := "." + strings.ToLower(strings.Trim(baseDomain, "."))
+
+	label, ok := strings.CutSuffix(host, suffix)
+	if !ok || label == "" || strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
+
+// checkClaimsTenant applies the claims source for an authenticated request:
+// the active organization becomes the tenant, unless tenancy is enabled
+// without that source. A tenant already resolved from the header or host
+// must be that organization, otherwise the request is a 403.
+func checkClaimsTenant(c echo.Context, s *server.Server, organizationID string) error {
+	cfg := s.Config.Tenancy
+	if cfg == nil || !cfg.Enabled {
+		// Tenancy off: the organization is still the tenant for telemetry.
+		SetTenant(c, s.Config.Observability, organizationID)
+		return nil
+	}
+
+	resolved := GetTenantID(c)
+	if resolved != "" && resolved != organizationID {
+		return errs.NewForbiddenError("Not a member of the requested organization", true)
+	}
+	if resolved == "" && slices.Contains(cfg.Sources, config.TenantSourceClaims) {
+		SetTenant(c, s.Config.Observability, organizationID)
+	}
+	return nil
+}
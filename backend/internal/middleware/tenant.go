@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/lib/ctxutil"
+	"github.com/deppfellow/go-boilerplate/internal/lib/tracing"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// TenantIDKey stores the raw tenant/organization ID in Echo context.
+	TenantIDKey = "tenant_id"
+
+	// TenantDimensionKey stores the cardinality-safe tenant label
+	// (raw ID for tracked tenants, hash bucket otherwise).
+	TenantDimensionKey = "tenant_dimension"
+)
+
+// SetTenant records the resolved tenant for the current request and enriches
+// every telemetry channel with it:
+//
+//   - request-scoped logger: tenant_id + tenant_dimension
+//   - request span: tenant.dimension attribute (never the raw ID
+//     unless tracked, to keep cardinality bounded)
+//   - Echo context: TenantIDKey / TenantDimensionKey for handlers, audit
+//     writers and custom metrics
+//   - Go request context: ctxutil.Tenant for services and repositories
+//
+// It is called by whatever resolves tenancy: TenancyMiddleware.Resolve for
+// the header/subdomain sources, RequireAuth for the Clerk active
+// organization. Calling it with an empty ID is a no-op.
+func SetTenant(c echo.Context, cfg *config.ObservabilityConfig, tenantID string) {
+	if tenantID == "" {
+		return
+	}
+
+	dimension := TenantDimension(cfg, tenantID)
+
+	c.Set(TenantIDKey, tenantID)
+	c.Set(TenantDimensionKey, dimension)
+	c.SetRequest(c.Request().WithContext(ctxutil.WithTenant(c.Request().Context(), tenantID)))
+
+	// Rebuild the request logger so everything logged after this point
+	// (handlers, request logger, error handler) carries the tenant.
+	tenantLogger := GetLogger(c).With().
+		Str("tenant_id", tenantID).
+		Str("tenant_dimension", dimension).
+		Logger()
+	setContextLogger(c, &tenantLogger)
+
+	if span := tracing.FromContext(c.Request().Context()); span != nil {
+		span.SetAttribute("tenant.dimension", dimension)
+	}
+}
+
+// GetTenantID returns the raw tenant ID, or "" if tenancy was not resolved.
+func GetTenantID(c echo.Context) string {
+	if tenantID, ok := c.Get(TenantIDKey).(string); ok {
+		return tenantID
+	}
+	return ""
+}
+
+// GetTenantDimension returns the cardinality-safe tenant label for use as a
+// metric/event attribute, or "" if tenancy was not resolved. It labels the
+// http_requests_* metrics and the RateLimitHit, SlowRequest and
+// PanicRecovered events.
+func GetTenantDimension(c echo.Context) string {
+	if dimension, ok := c.Get(TenantDimensionKey).(string); ok {
+		return dimension
+	}
+	return ""
+}
+
+// NoTenantDimension labels metrics of requests without a resolved tenant
+// (tenancy disabled, public routes, requests rejected before resolution).
+const NoTenantDimension = "none"
+
+// tenantMetricLabel returns GetTenantDimension, or NoTenantDimension.
+func tenantMetricLabel(c echo.Context) string {
+	if dimension := GetTenantDimension(c); dimension != "" {
+		return dimension
+	}
+	return NoTenantDimension
+}
+
+// TenantDimension maps a tenant ID to a bounded label.
+//
+// Tracked tenants keep their ID; all others are FNV-hashed into a stable
+// bucket so the same tenant always lands in the same bucket across instances.
+func TenantDimension(cfg *config.ObservabilityConfig, tenantID string) string {
+	if cfg == nil {
+		cfg = config.DefaultObservabilityConfig()
+	}
+
+	for _, tracked := range cfg.Tenants.TrackedTenants {
+		if tracked == tenantID {
+			return tenantID
+		}
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(tenantID))
+	bucket := h.Sum32() % uint32(cfg.Tenants.GetBuckets())
+
+	return fmt.Sprintf("bucket-%02d", bucket)
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+// TimeoutMiddleware applies request deadlines (see config.TimeoutConfig).
+type TimeoutMiddleware struct {
+	server *server.Server
+}
+
+// NewTimeoutMiddleware constructs the timeout middleware.
+func NewTimeoutMiddleware(s *server.Server) *TimeoutMiddleware {
+	return &TimeoutMiddleware{
+		server: s,
+	}
+}
+
+// Timeout returns the global timeout middleware using the config default and
+// per-group overrides.
+func (m *TimeoutMiddleware) Timeout() echo.MiddlewareFunc {
+	cfg := m.server.Config.Timeouts
+	if cfg == nil {
+		cfg = config.DefaultTimeoutConfig()
+	}
+
+	return m.withDeadline(func(c echo.Context) time.Duration {
+		return timeoutFor(RouteName(c), cfg)
+	})
+}
+
+// For returns a middleware with a fixed deadline, for groups declared in
+// code (g.Use(middlewares.Timeout.For(2*time.Minute))) and routes declaring
+// handler.WithTimeout. The deadline replaces the one set earlier in the
+// chain, longer or shorter: the most specific declaration wins.
+func (m *TimeoutMiddleware) For(d time.Duration) echo.MiddlewareFunc {
+	return m.withDeadline(func(echo.Context) time.Duration { return d })
+}
+
+// deadlineFrame is the deadline a withDeadline currently applies to the
+// request, kept in the Echo context so an inner one can replace it.
+type deadlineFrame struct {
+	// client is the request context before any deadline: it is only
+	// canceled when the client goes away.
+	client context.Context
+	// replaced is set once an inner withDeadline took over; expiry is then
+	// reported by the inner one.
+	replaced bool
+}
+
+const deadlineFrameKey = "timeout_deadline"
+
+// withDeadline runs the handler with a context deadline and translates
+// expiry into a consistent errs.HTTPError.
+//
+// Context deadlines only shrink, so an inner deadline nested in the global
+// one could never extend it. Instead, an inner withDeadline detaches from
+// the outer deadline (keeping the context values and the client's
+// cancellation) and marks the outer frame replaced.
+//
+// Unlike Echo's own timeout middleware, the handler is NOT moved to another
+// goroutine: it keeps running on the request goroutine and is expected to
+// return once its context-aware calls fail. This avoids the data races of
+// writing a response from two goroutines.
+func (m *TimeoutMiddleware) withDeadline(deadline func(c echo.Context) time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			d := deadline(c)
+			if d <= 0 {
+				return next(c)
+			}
+
+			base := c.Request().Context()
+			client := base
+			if outer, ok := c.Get(deadlineFrameKey).(*deadlineFrame); ok {
+				outer.replaced = true
+				client = outer.client
+				base = context.WithoutCancel(base)
+			}
+
+			ctx, cancel := context.WithTimeout(base, d)
+			defer cancel()
+			if client != c.Request().Context() {
+				stop := context.AfterFunc(client, cancel)
+				defer stop()
+			}
+
+			frame := &deadlineFrame{client: client}
+			c.Set(deadlineFrameKey, frame)
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			err := next(c)
+			if frame.replaced || c.Response().Committed {
+				return err
+			}
+
+			switch {
+			case client.Err() != nil:
+				// The client went away; nobody will read the response, but the
+				// log line and status should say what happened.
+				GetLogger(c).Warn().Msg("request canceled by client")
+				return errs.NewRequestTimeoutError("Request canceled by client")
+
+			case errors.Is(ctx.Err(), context.DeadlineExceeded):
+				GetLogger(c).Warn().
+					Dur("timeout", d).
+					AnErr("handler_error", err).
+					Msg("request deadline exceeded")
+				return errs.NewDeadlineExceededError("The request took too long to process, please retry later")
+			}
+
+			return err
+		}
+	}
+}
+
+// timeoutFor picks the longest matching group prefix, falling back to Default.
+func timeoutFor(route string, cfg *config.TimeoutConfig) time.Duration {
+	timeout := cfg.Default
+	longest := -1
+
+	for prefix, d := range cfg.Groups {
+		if strings.HasPrefix(route, prefix) && len(prefix) > longest {
+			timeout = d
+			longest = len(prefix)
+		}
+	}
+	return timeout
+}
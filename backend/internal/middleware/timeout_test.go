@@ -0,0 +1,46 @@
+package middleware_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/handlertest"
+	"github.com/labstack/echo/v4"
+)
+
+// waitFor returns a handler taking d, or failing once its context expires.
+func waitFor(d time.Duration) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		select {
+		case <-time.After(d):
+			return c.NoContent(http.StatusNoContent)
+		case <-c.Request().Context().Done():
+			return c.Request().Context().Err()
+		}
+	}
+}
+
+func TestTimeoutRouteDeadlineReplacesTheGlobalOne(t *testing.T) {
+	h := handlertest.New(t, handlertest.WithConfig(func(cfg *config.Config) {
+		cfg.Timeouts = &config.TimeoutConfig{Default: 20 * time.Millisecond}
+	}))
+	timeout := h.Middlewares.Timeout
+	h.Echo.Use(timeout.Timeout())
+
+	h.Echo.GET("/default", waitFor(200*time.Millisecond))
+	h.Echo.GET("/longer", waitFor(50*time.Millisecond), timeout.For(time.Second))
+	h.Echo.GET("/shorter", waitFor(50*time.Millisecond), timeout.For(5*time.Millisecond))
+
+	res, rec := handlertest.DoJSON[errs.HTTPError](h, http.MethodGet, "/default", nil)
+	h.RequireStatus(rec, http.StatusServiceUnavailable)
+	if res.Code != "DEADLINE_EXCEEDED" {
+		t.Fatalf("got code %q, want DEADLINE_EXCEEDED", res.Code)
+	}
+
+	// A route declaring more time than the default gets it, and less is kept.
+	h.RequireStatus(h.Do(http.MethodGet, "/longer", nil), http.StatusNoContent)
+	h.RequireStatus(h.Do(http.MethodGet, "/shorter", nil), http.StatusServiceUnavailable)
+}
@@ -2,123 +2,163 @@ package middleware
 
 import (
 	"github.com/labstack/echo/v4"
-	"github.com/newrelic/go-agent/v3/integrations/nrecho-v4"
-	"github.com/newrelic/go-agent/v3/integrations/nrpkgerrors"
-	"github.com/newrelic/go-agent/v3/newrelic"
 
+	"github.com/deppfellow/go-boilerplate/internal/buildinfo"
+	"github.com/deppfellow/go-boilerplate/internal/lib/tracing"
 	"github.com/deppfellow/go-boilerplate/internal/server"
 )
 
-// TracingMiddleware owns New Relic related Echo middleware.
+// TracingMiddleware owns the APM related Echo middleware.
 //
 // It needs:
 //   - server: for shared deps (logger/config) if needed later
-//   - nrApp: the New Relic application instance (nil if New Relic disabled)
+//   - tracer: the APM provider (New Relic, OTLP or the no-op "none")
 //
-// This middleware has two layers:
-//  1. NewRelicMiddleware()     -> installs New Relic transaction handling into Echo
-//  2. EnhanceTracing()         -> adds custom attributes and notices errors
+// This middleware has three layers:
+//  1. TraceRequests()          -> starts a span (transaction) per request
+//  2. EnhanceTracing()         -> adds custom attributes and records errors
+//  3. TraceHeaders()           -> exposes the trace ID to clients (X-Trace-Id)
 type TracingMiddleware struct {
 	server *server.Server
-	nrApp  *newrelic.Application
+	tracer tracing.Tracer
 }
 
 // NewTracingMiddleware constructs TracingMiddleware.
-func NewTracingMiddleware(s *server.Server, nrApp *newrelic.Application) *TracingMiddleware {
+func NewTracingMiddleware(s *server.Server, tracer tracing.Tracer) *TracingMiddleware {
+	if tracer == nil {
+		tracer = tracing.Noop{}
+	}
 	return &TracingMiddleware{
 		server: s,
-		nrApp:  nrApp,
+		tracer: tracer,
 	}
 }
 
-// NewRelicMiddleware returns the New Relic Echo middleware.
+// TraceRequests returns the request middleware of the tracer.
 //
 // What it does:
-//   - If nrApp is nil, return a no-op middleware (passes request through unchanged).
-//   - If nrApp exists, return nrecho.Middleware(tm.nrApp) which:
-//   - starts a New Relic transaction for each request
-//   - stores that transaction into request context
+//   - With the "none" provider (or New Relic without a license key), nothing:
+//     the request passes through unchanged.
+//   - Otherwise it:
+//   - starts a span for each request (nrecho transaction / OTLP server span)
+//   - stores that span into request context
 //   - records timing, status codes, etc.
 //
-// This middleware is what makes newrelic.FromContext(...) work later.
-func (tm *TracingMiddleware) NewRelicMiddleware() echo.MiddlewareFunc {
-	if tm.nrApp == nil {
-		// No-op middleware: doesn't wrap the handler, just returns it.
-		return func(next echo.HandlerFunc) echo.HandlerFunc {
-			return next
-		}
-	}
-	// Real New Relic middleware for Echo v4.
-	return nrecho.Middleware(tm.nrApp)
+// This middleware is what makes tracing.FromContext(...) work later.
+func (tm *TracingMiddleware) TraceRequests() echo.MiddlewareFunc {
+	return tm.tracer.Middleware()
 }
 
-// EnhanceTracing adds custom attributes to New Relic transactions.
+// EnhanceTracing adds custom attributes to the request span.
 //
-// This middleware assumes NewRelicMiddleware() already ran earlier
-// so that a transaction exists in request context.
+// This middleware assumes TraceRequests() already ran earlier
+// so that a span exists in request context.
 //
 // What it adds:
 //   - client IP and user agent
 //   - request id (if available)
 //   - user id (if auth middleware set it)
+//   - geo country/region (if GeoIP middleware resolved them)
 //   - response status code (after handler)
 //
-// It also records errors using nrpkgerrors.Wrap so stack traces are nicer.
+// It also records errors on the span (New Relic wraps them with nrpkgerrors
+// so stack traces are nicer).
 func (tm *TracingMiddleware) EnhanceTracing() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			// Grab New Relic transaction from request context.
+			// Grab the span from request context.
 			// This will be nil if:
-			//   - New Relic is disabled
-			//   - NewRelicMiddleware wasn't installed
+			//   - tracing is disabled
+			//   - TraceRequests wasn't installed
 			//   - middleware order is wrong
-			txn := newrelic.FromContext(c.Request().Context())
+			span := tracing.FromContext(c.Request().Context())
 
-			// If we don't have a transaction, do nothing and continue.
-			if txn == nil {
+			// If we don't have a span, do nothing and continue.
+			if span == nil {
 				return next(c)
 			}
 
 			// Add some useful HTTP request attributes.
-			// These show up in New Relic transaction traces as custom attributes.
+			// These show up in the APM's traces as custom attributes.
 			//
 			// NOTE: Be careful: user agent can be huge and high-cardinality.
-			txn.AddAttribute("http.real_ip", c.RealIP())
-			txn.AddAttribute("http.user_agent", c.Request().UserAgent())
+			// Route template (or "unmatched") rather than raw URI keeps
+			// attribute cardinality bounded even under 404 scans.
+			span.SetAttribute("http.route_name", RouteName(c))
+			span.SetAttribute("http.real_ip", c.RealIP())
+			span.SetAttribute("http.user_agent", c.Request().UserAgent())
+			span.SetAttribute("service.version", buildinfo.Get().Version)
 
 			// Add request ID if your RequestID middleware has set it.
-			// This helps correlate New Relic traces with logs.
+			// This helps correlate traces with logs.
 			if requestID := GetRequestID(c); requestID != "" {
-				txn.AddAttribute("request.id", requestID)
+				span.SetAttribute("request.id", requestID)
 			}
 
 			// Add user id if auth middleware already put it into Echo context.
 			// c.Get returns interface{}, so we check type.
-			if userID := c.Get("user_id"); userID != nil {
+			if userID := c.Get(UserIDKey); userID != nil {
 				if userIDStr, ok := userID.(string); ok {
-					txn.AddAttribute("user.id", userIDStr)
+					span.SetAttribute("user.id", userIDStr)
 				}
 			}
 
+			// Add GeoIP location if the GeoIP middleware resolved one.
+			if location := GetGeoLocation(c); !location.IsZero() {
+				span.SetAttribute("geo.country", location.Country)
+				span.SetAttribute("geo.region", location.Region)
+			}
+
 			// Run the handler (and rest of middleware chain).
 			err := next(c)
 
-			// If handler returns error:
-			// - record it in New Relic
-			// - wrap it with nrpkgerrors so stack trace info is captured
+			// If handler returns error, record it on the span.
 			//
 			// IMPORTANT:
-			// txn.NoticeError doesn't automatically stop Echo from handling the error.
+			// span.RecordError doesn't automatically stop Echo from handling the error.
 			// You still return err so global error handler can respond properly.
 			if err != nil {
-				txn.NoticeError(nrpkgerrors.Wrap(err))
+				span.RecordError(err)
 			}
 
 			// Add response status code as an attribute.
 			// This is captured after handler runs (since status is known then).
-			txn.AddAttribute("http.status_code", c.Response().Status)
+			span.SetAttribute("http.status_code", c.Response().Status)
 
 			return err
 		}
 	}
 }
+
+// TraceIDHeader carries the trace ID of the request in responses.
+const TraceIDHeader = "X-Trace-Id"
+
+// TraceHeaders writes correlation IDs into the response headers so a client
+// bug report ("it failed, here are the headers") leads straight to the APM
+// trace, without searching logs:
+//   - X-Trace-Id: trace.id of the request span (omitted without one)
+//   - X-Request-ID: request_id (normally already set by the RequestID middleware)
+//
+// Headers are set before the handler runs, so they are present on error
+// responses too. It must run after TraceRequests and RequestID.
+func (tm *TracingMiddleware) TraceHeaders() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Response().Header()
+
+			if span := tracing.FromContext(c.Request().Context()); span != nil {
+				if traceID := span.TraceID(); traceID != "" {
+					header.Set(TraceIDHeader, traceID)
+				}
+			}
+
+			if header.Get(RequestIDHeader) == "" {
+				if requestID := GetRequestID(c); requestID != "" {
+					header.Set(RequestIDHeader, requestID)
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
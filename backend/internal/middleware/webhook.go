@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+// Catalog entry for the in-flight duplicate delivery answer of Dedupe.
+func init() {
+	errs.RegisterCode(errs.CatalogEntry{
+		Code:        "WEBHOOK_IN_PROGRESS",
+		Status:      http.StatusConflict,
+		Description: "The same webhook event is still being processed; the provider should retry later.",
+	})
+}
+
+// DefaultWebhookDedupeTTL is how long a processed event ID is remembered.
+// Providers retry for hours, rarely for more than a day.
+const DefaultWebhookDedupeTTL = 24 * time.Hour
+
+// webhookProcessingTTL bounds the "processing" claim. It only has to outlive
+// the handler; if the process dies mid-delivery the claim expires after it
+// and the provider's next retry is processed instead of getting 409s all day.
+const webhookProcessingTTL = 5 * time.Minute
+
+// maxWebhookEventIDBody is how much of the body EventIDFromJSONField reads
+// looking for the event ID. Larger bodies aren't deduplicated.
+const maxWebhookEventIDBody = 1 << 20
+
+// webhookDedupeKeyPrefix namespaces dedupe keys in Redis:
+// boilerplate:webhook:<provider>:<event id>.
+const webhookDedupeKeyPrefix = "boilerplate:webhook:"
+
+const (
+	webhookStateProcessing = "processing"
+	webhookStateDone       = "done"
+)
+
+// EventIDExtractor returns the provider's unique delivery/event ID.
+// An empty ID means "can't deduplicate this request".
+type EventIDExtractor func(c echo.Context) (string, error)
+
+// EventIDFromHeader reads the event ID from a request header, e.g.
+// "Svix-Id" (Clerk), "X-GitHub-Delivery" (GitHub).
+func EventIDFromHeader(name string) EventIDExtractor {
+	return func(c echo.Context) (string, error) {
+		return c.Request().Header.Get(name), nil
+	}
+}
+
+// EventIDFromJSONField reads a top-level string field of the JSON body
+// (e.g. "id" for Stripe events). The body is restored for the handler.
+// At most maxWebhookEventIDBody bytes are buffered; a larger body is passed
+// on untouched, without an ID.
+func EventIDFromJSONField(field string) EventIDExtractor {
+	return func(c echo.Context) (string, error) {
+		req := c.Request()
+		body, err := io.ReadAll(io.LimitReader(req.Body, maxWebhookEventIDBody+1))
+		if err != nil {
+			return "", err
+		}
+		if len(body) > maxWebhookEventIDBody {
+			req.Body = readCloser{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+			return "", nil
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		var payload map[string]json.RawMessage
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return "", nil
+		}
+
+		var id string
+		_ = json.Unmarshal(payload[field], &id)
+		return id, nil
+	}
+}
+
+// readCloser reads from a replayed prefix plus the rest of the original
+// body, and closes the original.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// WebhookMiddleware makes webhook endpoints idempotent.
+type WebhookMiddleware struct {
+	server *server.Server
+}
+
+// NewWebhookMiddleware constructs WebhookMiddleware with access to app Server.
+func NewWebhookMiddleware(s *server.Server) *WebhookMiddleware {
+	return &WebhookMiddleware{server: s}
+}
+
+// Dedupe drops repeated deliveries of the same provider event.
+//
+// The first delivery claims the event ID in Redis (SET NX, for
+// webhookProcessingTTL) and runs the handler; once it succeeds the ID is
+// marked done for ttl. Later deliveries of the same ID:
+//   - get 200 {"status":"duplicate"} immediately once it was processed, so
+//     the provider stops retrying;
+//   - get 409 while the first delivery is still running, so the provider
+//     retries later instead of recording a success that may yet fail.
+//
+// If the handler fails or panics the claim is released (with a context that
+// survives the request's cancellation), so the provider's retry is
+// processed normally; if the process dies, the claim expires. Requests without an event ID, and any Redis failure,
+// fall through to the handler: a missed dedupe is better than a lost event.
+func (w *WebhookMiddleware) Dedupe(provider string, extractor EventIDExtractor, ttl time.Duration) echo.MiddlewareFunc {
+	if ttl <= 0 {
+		ttl = DefaultWebhookDedupeTTL
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			logger := GetLogger(c)
+
+			eventID, err := extractor(c)
+			if err != nil || eventID == "" || w.server.Redis == nil {
+				logger.Warn().
+					Err(err).
+					Str("provider", provider).
+					Msg("webhook delivery not deduplicated: no event id")
+				return next(c)
+			}
+
+			ctx := c.Request().Context()
+			key := webhookDedupeKeyPrefix + provider + ":" + eventID
+
+			claimed, err := w.server.Redis.SetNX(ctx, key, webhookStateProcessing, webhookProcessingTTL).Result()
+			if err != nil {
+				logger.Error().Err(err).Str("provider", provider).Msg("webhook dedupe unavailable")
+				return next(c)
+			}
+
+			if !claimed {
+				state, _ := w.server.Redis.Get(ctx, key).Result()
+
+				logger.Info().
+					Str("provider", provider).
+					Str("event_id", eventID).
+					Str("state", state).
+					Msg("duplicate webhook delivery")
+
+				if state == webhookStateProcessing {
+					code := "WEBHOOK_IN_PROGRESS"
+					return errs.NewConflictError("Webhook event is already being processed", true, &code)
+				}
+				return c.JSON(http.StatusOK, map[string]string{"status": "duplicate"})
+			}
+
+			// The request context is cancelled on timeout or client
+			// disconnect, exactly when the claim must still be released.
+			redisCtx := context.WithoutCancel(ctx)
+
+			processed := false
+			defer func() {
+				if processed {
+					return
+				}
+				// Release the claim so the provider's retry gets processed.
+				// Also runs while a panic unwinds.
+				if delErr := w.server.Redis.Del(redisCtx, key).Err(); delErr != nil {
+					logger.Error().Err(delErr).Str("event_id", eventID).Msg("failed to release webhook claim")
+				}
+			}()
+
+			if err := next(c); err != nil {
+				return err
+			}
+			processed = true
+
+			if err := w.server.Redis.Set(redisCtx, key, webhookStateDone, ttl).Err(); err != nil {
+				logger.Error().Err(err).Str("event_id", eventID).Msg("failed to mark webhook processed")
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,32 @@
+-- name: GetUser :one
+SELECT * FROM users
+WHERE id = $1;
+
+-- name: GetUserByEmail :one
+SELECT * FROM users
+WHERE lower(email) = lower(@email);
+
+-- name: GetUserByExternalID :one
+SELECT * FROM users
+WHERE external_id = $1;
+
+-- name: ListUsers :many
+-- ListUsers returns the newest users first.
+SELECT * FROM users
+ORDER BY created_at DESC, id DESC
+LIMIT $1;
+
+-- name: CreateUser :one
+INSERT INTO users (external_id, email, name)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: UpdateUser :one
+UPDATE users
+SET email = $2, name = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = $1;
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/deppfellow/go-boilerplate/internal/lib/audit"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+)
+
+// AuditRepository persists audit trail entries to audit_logs.
+//
+// Entries arrive through the audit:write job (see job.InitAuditWriter), never
+// directly from request handlers.
+type AuditRepository struct {
+	Base
+}
+
+// NewAuditRepository constructs an AuditRepository.
+func NewAuditRepository(s *server.Server) *AuditRepository {
+	return &AuditRepository{Base: NewBase(s)}
+}
+
+// Create inserts one audit entry.
+func (r *AuditRepository) Create(ctx context.Context, entry *audit.Entry) error {
+	resourceIDs, err := json.Marshal(entry.ResourceIDs)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit resource ids: %w", err)
+	}
+
+	_, err = r.Querier(ctx).Exec(ctx, `
+		INSERT INTO audit_logs (
+			user_id, tenant_id, method, route, resource_ids,
+			status, request_id, body_hash, ip, occurred_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		entry.UserID, entry.TenantID, entry.Method, entry.Route, resourceIDs,
+		entry.Status, entry.RequestID, entry.BodyHash, entry.IP, entry.OccurredAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit log: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/server"
+)
+
+// Base holds dependencies shared by every concrete repository.
+//
+// Concrete repositories embed it:
+//
+//	type TodoRepository struct {
+//	    Base
+//	}
+//
+//	func NewTodoRepository(s *server.Server) *TodoRepository {
+//	    return &TodoRepository{Base: NewBase(s)}
+//	}
+type Base struct {
+	server *server.Server
+}
+
+// NewBase constructs a Base repository.
+func NewBase(s *server.Server) Base {
+	return Base{server: s}
+}
+
+// LastModified returns when table last changed for the tenant of ctx, as
+// recorded in table_changes by the track_changes trigger (see
+// migrations/20261016130000_table_changes.sql).
+//
+// It feeds conditional GET support (Last-Modified / If-Modified-Since) on list
+// endpoints: polling clients get a quick 304 when nothing changed, and the
+// only DB work is a primary key lookup. Unlike MAX(updated_at), deletes count
+// as changes, and the time has the database's microsecond precision.
+//
+// Writes recorded without a tenant count for every tenant. A table without
+// the trigger (or not written yet) returns the zero time, which disables
+// the conditional check.
+func (b Base) LastModified(ctx context.Context, table string) (time.Time, error) {
+	tenant, args, err := b.TenantScope(ctx, "", []any{table})
+	if err != nil {
+		return time.Time{}, err
+	}
+	query := "SELECT MAX(changed_at) FROM table_changes WHERE table_name = $1 AND (tenant_id = '' OR " + tenant + ")"
+
+	var lastModified *time.Time
+	if err := b.Querier(ctx).QueryRow(ctx, query, args...).Scan(&lastModified); err != nil {
+		return time.Time{}, fmt.Errorf("failed to get last modified for table:%s: %w", table, err)
+	}
+
+	if lastModified == nil {
+		return time.Time{}, nil
+	}
+
+	return lastModified.UTC(), nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/lib/ctxutil"
+	"github.com/deppfellow/go-boilerplate/internal/lib/inflect"
+	"github.com/deppfellow/go-boilerplate/internal/sqlerr"
+	"github.com/jackc/pgx/v5"
+)
+
+// HandleWriteError converts an INSERT/UPDATE error like sqlerr.HandleError,
+// but understands soft deletes (a deleted_at column) on unique violations.
+//
+// Soft-delete tables use one of two index styles, and each conflict deserves
+// a different answer:
+//
+//   - partial index (UNIQUE (email) WHERE deleted_at IS NULL): the conflict is
+//     always with a live row, so the usual "already exists" message is
+//     accurate; sqlerr names the columns from the violation detail.
+//
+//   - full index (UNIQUE (email)): the conflicting row may be soft-deleted. If
+//     it is, the client gets a 409 with code <DOMAIN>_DELETED and a "restore"
+//     errs.Action carrying the row's id, instead of a confusing "already
+//     exists" for something the user deleted.
+//
+// The restore action hands out a row ID, so it is only offered for rows the
+// caller could restore: rows of the caller's tenant (TenantScope) owned by
+// the caller (OwnerColumn). Tables without an owner column, and contexts
+// without a user, get the plain "already exists" answer.
+//
+// Tables without a deleted_at column, or conflicts the lookup can't resolve,
+// fall back to sqlerr.HandleError.
+func (b Base) HandleWriteError(ctx context.Context, err error) error {
+	conflict, ok := sqlerr.ParseUniqueConflict(err)
+	if !ok || conflict.Table == "" || len(conflict.Values) == 0 {
+		return sqlerr.HandleError(err)
+	}
+
+	id, found, lookupErr := b.findSoftDeleted(ctx, conflict)
+	if lookupErr != nil || !found {
+		return sqlerr.HandleError(err)
+	}
+
+	entity := inflect.Singular(conflict.Table)
+	code := strings.ToUpper(entity) + "_DELETED"
+	name := strings.ReplaceAll(entity, "_", " ")
+	message := fmt.Sprintf("A deleted %s with this %s exists", name, strings.Join(conflict.Columns, " and "))
+
+	httpErr := errs.NewConflictError(message, true, &code)
+	httpErr.Action = errs.NewRestoreAction("Restore the deleted "+name+" instead of creating a new one", id)
+	return httpErr
+}
+
+// OwnerColumn is the column holding the user a row belongs to, used to
+// scope the soft-deleted row lookup of HandleWriteError.
+const OwnerColumn = "user_id"
+
+// findSoftDeleted looks up the soft-deleted row holding the conflicting key,
+// among the rows of the caller's tenant and user only.
+//
+// Values come from the violation detail as text, so the key columns are
+// compared as text. A table without deleted_at, id or OwnerColumn, or an
+// expression index key, makes the query fail, which callers treat as
+// "not found"; so does a context without a user or (when required) a tenant.
+func (b Base) findSoftDeleted(ctx context.Context, conflict *sqlerr.UniqueConflict) (string, bool, error) {
+	userID := ctxutil.UserID(ctx)
+	if userID == "" {
+		return "", false, nil
+	}
+
+	conditions := make([]string, len(conflict.Columns), len(conflict.Columns)+2)
+	args := make([]any, len(conflict.Values), len(conflict.Values)+2)
+	for i, column := range conflict.Columns {
+		conditions[i] = fmt.Sprintf("%s::text = $%d", pgx.Identifier{column}.Sanitize(), i+1)
+		args[i] = conflict.Values[i]
+	}
+
+	args = append(args, userID)
+	conditions = append(conditions, fmt.Sprintf("%s::text = $%d", pgx.Identifier{OwnerColumn}.Sanitize(), len(args)))
+
+	tenant, args, err := b.TenantScope(ctx, "", args)
+	if err != nil {
+		return "", false, err
+	}
+	conditions = append(conditions, tenant)
+
+	query := fmt.Sprintf(
+		"SELECT id::text FROM %s WHERE %s AND deleted_at IS NOT NULL LIMIT 1",
+		pgx.Identifier{conflict.Table}.Sanitize(),
+		strings.Join(conditions, " AND "),
+	)
+
+	var id string
+	// Always the pool: a transaction that just hit the violation is aborted
+	// and would reject any further statement.
+	err = b.server.DB.Pool.QueryRow(ctx, query, args...).Scan(&id)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return "", false, nil
+	case err != nil:
+		return "", false, err
+	}
+	return id, true, nil
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+// WriteFunc performs one write using q (the current transaction).
+type WriteFunc func(ctx context.Context, q Querier) error
+
+// DualWrite runs the old-schema and new-schema writes of an expand/contract
+// migration (see lib/backfill) atomically: both succeed or neither does, so
+// rows written during the migration never need a second backfill pass.
+//
+// It joins the transaction bound to ctx, or opens one.
+//
+//	return r.DualWrite(ctx,
+//		func(ctx context.Context, q Querier) error { _, err := q.Exec(ctx, `UPDATE todos SET status = $2 WHERE id = $1`, id, status); return err },
+//		func(ctx context.Context, q Querier) error { _, err := q.Exec(ctx, `UPDATE todos SET status_v2 = $2 WHERE id = $1`, id, code); return err },
+//	)
+//
+// Once reads have switched to the new schema, replace the call with the new write only.
+func (b Base) DualWrite(ctx context.Context, writeOld, writeNew WriteFunc) error {
+	return NewTxManager(b.server).WithinTx(ctx, func(ctx context.Context) error {
+		q := b.Querier(ctx)
+
+		if err := writeOld(ctx, q); err != nil {
+			return fmt.Errorf("dual write (old schema) failed: %w", err)
+		}
+		if err := writeNew(ctx, q); err != nil {
+			return fmt.Errorf("dual write (new schema) failed: %w", err)
+		}
+		return nil
+	})
+}
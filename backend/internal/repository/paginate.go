@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/lib/pagination"
+	"github.com/jackc/pgx/v5"
+)
+
+// ListQuery describes a keyset-paginated list of T sorted by a key of type K
+// (see lib/pagination).
+type ListQuery[T, K any] struct {
+	// Query selects the rows, without ORDER BY or LIMIT. It may filter with
+	// its own placeholders ($1...) and must select SortColumn and IDColumn:
+	//
+	//	"SELECT " + repository.Columns[model.Todo]() + " FROM todos WHERE user_id = $1"
+	Query string
+	Args  []any
+
+	// SortColumn orders the list; IDColumn (default "id") breaks ties, so
+	// the order is total. Index them together for the order used, e.g.
+	// CREATE INDEX ... ON todos (user_id, created_at DESC, id DESC).
+	SortColumn string
+	IDColumn   string
+
+	// Ascending lists oldest/smallest first. Default is descending.
+	Ascending bool
+
+	// Key returns the sort key and id of an item, to build the next cursor.
+	Key func(item T) (K, string)
+}
+
+// sortName identifies the sort order inside cursors.
+func (q ListQuery[T, K]) sortName() string {
+	direction := "desc"
+	if q.Ascending {
+		direction = "asc"
+	}
+	return q.SortColumn + ":" + direction
+}
+
+// ListPage runs q for the page after params.Cursor:
+//
+//	page, err := repository.ListPage(ctx, r.Querier(ctx), repository.ListQuery[model.Todo, time.Time]{
+//		Query:      "SELECT " + repository.Columns[model.Todo]() + " FROM todos WHERE user_id = $1",
+//		Args:       []any{userID},
+//		SortColumn: "created_at",
+//		Key:        func(t model.Todo) (time.Time, string) { return t.CreatedAt, t.ID.String() },
+//	}, req.Params)
+//
+// Query is wrapped in a subquery, so its own WHERE stays intact; Postgres
+// pushes the cursor condition down into it and walks the index. One row
+// more than the limit is fetched to know whether a next page exists.
+//
+// When T maps the deleted_at column (softdelete.Column), soft-deleted rows
+// are filtered out unless ctx is Unscoped.
+//
+// A cursor that doesn't decode, or belongs to another sort order, is a 400.
+func ListPage[T, K any](ctx context.Context, q Querier, lq ListQuery[T, K], params pagination.Params) (pagination.Page[T], error) {
+	limit := params.GetLimit()
+	sort := lq.sortName()
+
+	idColumn := lq.IDColumn
+	if idColumn == "" {
+		idColumn = "id"
+	}
+	sortCol := pgx.Identifier{lq.SortColumn}.Sanitize()
+	idCol := pgx.Identifier{idColumn}.Sanitize()
+
+	operator, direction := "<", "DESC"
+	if lq.Ascending {
+		operator, direction = ">", "ASC"
+	}
+
+	args := append([]any{}, lq.Args...)
+	query := "SELECT * FROM (" + lq.Query + ") AS page"
+
+	var conditions []string
+	if softDeletable[T]() && !IsUnscoped(ctx) {
+		conditions = append(conditions, notDeletedCondition(""))
+	}
+	if params.Cursor != "" {
+		cursor, err := pagination.Decode[K](params.Cursor, sort)
+		if err != nil {
+			return pagination.Page[T]{}, invalidCursorError(err)
+		}
+		args = append(args, cursor.Key, cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(%s, %s) %s ($%d, $%d)", sortCol, idCol, operator, len(args)-1, len(args)))
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query += fmt.Sprintf(" ORDER BY %s %s, %s %s LIMIT %d", sortCol, direction, idCol, direction, limit+1)
+
+	items, err := CollectAll[T](ctx, q, query, args...)
+	if err != nil {
+		return pagination.Page[T]{}, err
+	}
+
+	page := pagination.Page[T]{Items: items, Limit: limit}
+	if len(items) > limit {
+		page.Items = items[:limit]
+
+		key, id := lq.Key(page.Items[limit-1])
+		page.NextCursor, err = pagination.Encode(pagination.Cursor[K]{Sort: sort, Key: key, ID: id})
+		if err != nil {
+			return pagination.Page[T]{}, fmt.Errorf("failed to encode pagination cursor: %w", err)
+		}
+	}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	return page, nil
+}
+
+func invalidCursorError(err error) error {
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		return errs.NewBadRequestError("Invalid pagination cursor", true, nil, []errs.FieldError{
+			{Field: "cursor", Error: "is not a cursor returned by this list"},
+		}, nil)
+	}
+	return err
+}
@@ -23,19 +23,32 @@ import (
 //	    Todos *TodosRepository
 //	}
 //
-// In this boilerplate stage it’s intentionally empty:
+// In this boilerplate stage it holds the transaction manager and the audit log:
 // - to establish the dependency injection shape early
 // - so Services can accept repos even before concrete repositories exist
-type Repositories struct{}
+type Repositories struct {
+	// Tx runs multi-repository units of work in one transaction and
+	// publishes their cache invalidations after commit.
+	Tx *TxManager
+
+	// Audit persists the audit trail written by the audit:write job.
+	Audit *AuditRepository
+
+	// Users is the example repository on top of the sqlc query layer
+	// (see internal/queries and sqlc.yaml).
+	Users *UserRepository
+}
 
 // NewRepositories constructs the repository container.
 //
 // Parameter:
 // - s: application container (DB pool lives on s.DB, logger on s.Logger, etc.)
 //
-// In this minimal boilerplate the returned container is empty, but the constructor exists so
-// future repositories can be initialized here using s.DB.Pool and other shared deps.
+// Future repositories are initialized here using s.DB.Pool and other shared deps.
 func NewRepositories(s *server.Server) *Repositories {
-	_ = s // intentionally unused for now; reserved for future repo initialization
-	return &Repositories{}
+	return &Repositories{
+		Tx:    NewTxManager(s),
+		Audit: NewAuditRepository(s),
+		Users: NewUserRepository(s),
+	}
 }
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/lib/ctxutil"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/deppfellow/go-boilerplate/internal/sqlerr"
+)
+
+// WithRetry runs fn, and runs it again while it fails with a transient
+// error (sqlerr.IsRetryable), up to database.retry_max_attempts times in
+// total with jittered exponential backoff. Use it around single statements
+// that may deadlock or hit a serialization failure:
+//
+//	err := r.WithRetry(ctx, "increment counter", func(ctx context.Context) error {
+//		_, err := r.Querier(ctx).Exec(ctx, incrementCounter, id)
+//		return err
+//	})
+//
+// Inside a transaction fn runs once: after such an error the transaction is
+// aborted, so it is TxManager.WithinTx that retries it as a whole.
+func (b Base) WithRetry(ctx context.Context, op string, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*txState); ok {
+		return fn(ctx)
+	}
+	return retry(ctx, b.server, op, fn)
+}
+
+// retry implements WithRetry and the retries of WithinTx. Every retried
+// failure is logged; the last error is returned as is.
+func retry(ctx context.Context, s *server.Server, op string, fn func(ctx context.Context) error) error {
+	cfg := s.Config.Database
+	attempts := cfg.GetRetryMaxAttempts()
+	base, maxBackoff := cfg.GetRetryBackoff()
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil || attempt >= attempts || !sqlerr.IsRetryable(err) {
+			return err
+		}
+
+		wait := retryBackoff(attempt, base, maxBackoff)
+		s.Logger.Warn().
+			Err(err).
+			Str("request_id", ctxutil.RequestID(ctx)).
+			Str("operation", op).
+			Int("attempt", attempt).
+			Int("max_attempts", attempts).
+			Dur("retry_in", wait).
+			Msg("transient database error, retrying")
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+	}
+}
+
+// retryBackoff returns the wait after failed attempt n (from 1): base
+// doubled per attempt up to maxBackoff, with jitter in [wait/2, wait) so
+// the transactions that just collided don't collide again.
+func retryBackoff(attempt int, base, maxBackoff time.Duration) time.Duration {
+	wait := base << min(attempt-1, 30)
+	if wait <= 0 || wait > maxBackoff {
+		wait = maxBackoff
+	}
+
+	half := wait / 2
+	if half <= 0 {
+		return wait
+	}
+	return half + rand.N(half)
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Querier is satisfied by *pgxpool.Pool, *pgxpool.Conn, pgx.Tx and
+// *database.Database, so the helpers below work both inside and outside
+// transactions.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// CollectAll runs query and scans every row into T by column name.
+//
+// Scanning is strict: every selected column must map to a field of T (by
+// `db` tag, or case-insensitive field name) and every field must be selected.
+// A SELECT that drifts from the struct therefore fails loudly instead of
+// leaving fields silently zeroed. Use `db:"-"` for fields that are never selected.
+//
+//	todos, err := repository.CollectAll[model.Todo](ctx, r.Querier(ctx),
+//		"SELECT "+repository.Columns[model.Todo]()+" FROM todos WHERE user_id = $1", userID)
+func CollectAll[T any](ctx context.Context, q Querier, query string, args ...any) ([]T, error) {
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := pgx.CollectRows(rows, pgx.RowToStructByName[T])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %T rows: %w", *new(T), err)
+	}
+	return items, nil
+}
+
+// CollectOne is CollectAll for queries returning exactly one row.
+//
+// Zero rows surface as pgx.ErrNoRows (wrapped); sqlerr.HandleErrorFor turns
+// it into a 404 naming the entity.
+func CollectOne[T any](ctx context.Context, q Querier, query string, args ...any) (T, error) {
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return *new(T), err
+	}
+
+	item, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[T])
+	if err != nil {
+		return *new(T), fmt.Errorf("failed to scan %T row: %w", item, err)
+	}
+	return item, nil
+}
+
+// CollectScalars scans a single-column result into a slice (e.g. IDs).
+func CollectScalars[T any](ctx context.Context, q Querier, query string, args ...any) ([]T, error) {
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[T])
+}
+
+var columnsCache sync.Map // reflect.Type -> string
+
+// Columns returns the comma-separated column list for T, derived from the
+// same `db` tags the scanners use, so SELECT lists can't drift from structs.
+//
+// Embedded structs are flattened (as pgx does); `db:"-"` fields are skipped.
+func Columns[T any]() string {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if cached, ok := columnsCache.Load(t); ok {
+		return cached.(string)
+	}
+
+	columns := strings.Join(structColumns(t), ", ")
+	columnsCache.Store(t, columns)
+	return columns
+}
+
+// structColumns walks exported fields the same way pgx.RowToStructByName does.
+func structColumns(t reflect.Type) []string {
+	var columns []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag, hasTag := field.Tag.Lookup("db")
+		if tag == "-" {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && !hasTag {
+			columns = append(columns, structColumns(field.Type)...)
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		columns = append(columns, pgx.Identifier{name}.Sanitize())
+	}
+
+	return columns
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/deppfellow/go-boilerplate/internal/lib/ctxutil"
+	"github.com/deppfellow/go-boilerplate/internal/lib/job"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+)
+
+// Reindex declares that a write changed the given documents of a search
+// index (see lib/search), e.g. after updating a todo:
+//
+//	r.Invalidates(ctx, "todos")
+//	r.Reindex(ctx, "todos", todo.ID.String())
+//
+// Deletes are declared the same way: the search:index job drops documents
+// the Source no longer loads. Like Invalidates, inside a transaction the job
+// is enqueued after COMMIT. It is a no-op when search is disabled.
+func (b Base) Reindex(ctx context.Context, index string, ids ...string) {
+	if b.server.SearchRunner == nil || len(ids) == 0 {
+		return
+	}
+
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		state.search.add(index, ids)
+		return
+	}
+
+	enqueueReindex(ctx, b.server, index, ids)
+}
+
+// enqueueReindex enqueues a search:index job. Failures are logged, not
+// returned: the write succeeded, and a full reindex repairs the index.
+func enqueueReindex(ctx context.Context, s *server.Server, index string, ids []string) {
+	if len(ids) == 0 || s.Job == nil {
+		return
+	}
+
+	task, err := job.NewSearchIndexTask(ctx, index, ids)
+	if err == nil {
+		_, err = s.Job.Client.EnqueueContext(ctx, task)
+	}
+	if err != nil {
+		s.Logger.Error().
+			Err(err).
+			Str("request_id", ctxutil.RequestID(ctx)).
+			Str("index", index).
+			Int("documents", len(ids)).
+			Msg("failed to enqueue search indexing")
+	}
+}
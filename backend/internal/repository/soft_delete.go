@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/deppfellow/go-boilerplate/internal/lib/softdelete"
+	"github.com/deppfellow/go-boilerplate/internal/sqlerr"
+	"github.com/jackc/pgx/v5"
+)
+
+// Soft-deletable tables have a nullable deleted_at column (softdelete.Column):
+//
+//	deleted_at TIMESTAMPTZ
+//
+//	-- uniqueness among live rows only (see HandleWriteError for the
+//	-- trade-off against a full unique index)
+//	CREATE UNIQUE INDEX idx_todos_user_id_title ON todos (user_id, title)
+//	    WHERE deleted_at IS NULL;
+//
+// Deleting sets it (SoftDelete), restoring clears it (Restore), and reads
+// skip deleted rows unless the context is Unscoped: Get and ListPage do it
+// by themselves for models that map deleted_at, hand-written queries add
+// NotDeleted to their WHERE clause.
+// Rows are removed for good by the purge job once their retention has passed
+// (see lib/softdelete).
+
+type unscopedKey struct{}
+
+// Unscoped returns a copy of ctx whose reads include soft-deleted rows
+// (Get, ListPage and NotDeleted match everything), for trash listings,
+// restores and admin tools:
+//
+//	todo, err := repository.Get[model.Todo](repository.Unscoped(ctx), r.Base, "todos", id)
+func Unscoped(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unscopedKey{}, true)
+}
+
+// IsUnscoped reports whether ctx was marked with Unscoped.
+func IsUnscoped(ctx context.Context) bool {
+	unscoped, _ := ctx.Value(unscopedKey{}).(bool)
+	return unscoped
+}
+
+// NotDeleted returns the condition hiding soft-deleted rows, for WHERE
+// clauses of hand-written reads. alias qualifies the column in joins ("" for
+// none):
+//
+//	"SELECT ... FROM todos t JOIN ... WHERE t.user_id = $1 AND " + r.NotDeleted(ctx, "t")
+//
+// It is "t.deleted_at IS NULL", or "TRUE" when ctx is Unscoped.
+func (b Base) NotDeleted(ctx context.Context, alias string) string {
+	if IsUnscoped(ctx) {
+		return "TRUE"
+	}
+	return notDeletedCondition(alias)
+}
+
+func notDeletedCondition(alias string) string {
+	if alias == "" {
+		return pgx.Identifier{softdelete.Column}.Sanitize() + " IS NULL"
+	}
+	return pgx.Identifier{alias, softdelete.Column}.Sanitize() + " IS NULL"
+}
+
+// softDeletable reports whether T maps the deleted_at column, i.e. reads of
+// T are scoped to live rows.
+func softDeletable[T any]() bool {
+	return slices.Contains(strings.Split(Columns[T](), ", "), pgx.Identifier{softdelete.Column}.Sanitize())
+}
+
+// Get returns the row of table with primary key id, scanned into T like
+// CollectOne:
+//
+//	todo, err := repository.Get[model.Todo](ctx, r.Base, "todos", id)
+//
+// When T maps deleted_at, a soft-deleted row is a 404 like a missing one,
+// unless ctx is Unscoped. Errors go through sqlerr.HandleErrorFor(table).
+func Get[T any](ctx context.Context, b Base, table string, id any) (T, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1", Columns[T](), pgx.Identifier{table}.Sanitize())
+	if softDeletable[T]() {
+		query += " AND " + b.NotDeleted(ctx, "")
+	}
+
+	item, err := CollectOne[T](ctx, b.Querier(ctx), query, id)
+	if err != nil {
+		return item, sqlerr.HandleErrorFor(err, table)
+	}
+	return item, nil
+}
+
+// SoftDelete marks the live row of table with primary key id as deleted and
+// invalidates the table's cache namespace. A missing or already deleted row
+// is a 404 named after the table (sqlerr.NotFound).
+func (b Base) SoftDelete(ctx context.Context, table string, id any) error {
+	query := fmt.Sprintf(
+		"UPDATE %s SET %s = NOW() WHERE id = $1 AND %s IS NULL",
+		pgx.Identifier{table}.Sanitize(),
+		pgx.Identifier{softdelete.Column}.Sanitize(),
+		pgx.Identifier{softdelete.Column}.Sanitize(),
+	)
+	return b.setDeleted(ctx, table, "soft delete", query, id)
+}
+
+// Restore undoes SoftDelete. A missing or live row is a 404. With a partial unique index, a live row may have taken the
+// restored row's key meanwhile: pass the error to HandleWriteError.
+func (b Base) Restore(ctx context.Context, table string, id any) error {
+	query := fmt.Sprintf(
+		"UPDATE %s SET %s = NULL WHERE id = $1 AND %s IS NOT NULL",
+		pgx.Identifier{table}.Sanitize(),
+		pgx.Identifier{softdelete.Column}.Sanitize(),
+		pgx.Identifier{softdelete.Column}.Sanitize(),
+	)
+	return b.setDeleted(ctx, table, "restore", query, id)
+}
+
+func (b Base) setDeleted(ctx context.Context, table, op, query string, id any) error {
+	tag, err := b.Querier(ctx).Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to %s %s row: %w", op, table, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sqlerr.NotFound(table)
+	}
+
+	b.Invalidates(ctx, table)
+	return nil
+}
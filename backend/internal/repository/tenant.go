@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/lib/ctxutil"
+	"github.com/jackc/pgx/v5"
+)
+
+// Tenant isolation depends on tenancy.mode (see config.TenancyConfig):
+//
+//   - shared_schema: tenant tables have a tenant_id column (TenantColumn)
+//     and every statement filters on it with TenantScope. With tenancy.rls
+//     the connection also carries app.tenant_id, so row-level security
+//     policies back the filter up in the database (see database.TenantSetting).
+//   - schema_per_tenant: connections run with search_path set to the
+//     tenant's schema, so unqualified table names already resolve to the
+//     tenant's tables and TenantScope matches everything.
+//
+// The tenant is ctxutil.Tenant, set per request by the tenancy middleware
+// and carried into jobs by the job metadata.
+
+// TenantColumn is the tenant ID column of shared_schema tables.
+const TenantColumn = "tenant_id"
+
+// ErrNoTenant is returned by TenantScope when tenancy is enabled but ctx
+// carries no tenant. Guard tenant routes with RequireTenant so it's a
+// programming error rather than a client one.
+var ErrNoTenant = errors.New("no tenant in context")
+
+type allTenantsKey struct{}
+
+// AllTenants returns a copy of ctx whose TenantScope matches every tenant,
+// for admin tools and maintenance jobs. It only lifts the tenant_id filter:
+// RLS policies and search_path still apply to the connection, so such work
+// needs a role with BYPASSRLS or schema-qualified names.
+func AllTenants(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allTenantsKey{}, true)
+}
+
+// IsAllTenants reports whether ctx was marked with AllTenants.
+func IsAllTenants(ctx context.Context) bool {
+	all, _ := ctx.Value(allTenantsKey{}).(bool)
+	return all
+}
+
+// TenantScope returns the condition restricting a statement to the tenant
+// of ctx, with the tenant ID appended to args as its placeholder. alias
+// qualifies the column in joins ("" for none):
+//
+//	where, args, err := r.TenantScope(ctx, "t", []any{userID})
+//	if err != nil {
+//		return nil, err
+//	}
+//	query := "SELECT ... FROM todos t WHERE t.user_id = $1 AND " + where
+//
+// It is "t.tenant_id = $2", or "TRUE" (args unchanged) when tenancy is
+// disabled, in schema_per_tenant mode and for AllTenants contexts.
+func (b Base) TenantScope(ctx context.Context, alias string, args []any) (string, []any, error) {
+	cfg := b.server.Config.Tenancy
+	if cfg == nil || !cfg.Enabled || cfg.GetMode() == config.TenancySchemaPerTenant || IsAllTenants(ctx) {
+		return "TRUE", args, nil
+	}
+
+	tenantID := ctxutil.Tenant(ctx)
+	if tenantID == "" {
+		return "", args, ErrNoTenant
+	}
+
+	column := pgx.Identifier{TenantColumn}
+	if alias != "" {
+		column = pgx.Identifier{alias, TenantColumn}
+	}
+
+	args = append(args, tenantID)
+	return fmt.Sprintf("%s = $%d", column.Sanitize(), len(args)), args, nil
+}
+
+// TenantID returns the tenant of ctx for inserts into shared_schema tables,
+// or ErrNoTenant.
+func (b Base) TenantID(ctx context.Context) (string, error) {
+	if tenantID := ctxutil.Tenant(ctx); tenantID != "" {
+		return tenantID, nil
+	}
+	return "", ErrNoTenant
+}
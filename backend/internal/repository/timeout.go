@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type queryTimeoutKey struct{}
+
+// WithQueryTimeout returns a copy of ctx whose statements get d instead of
+// database.query_timeout, for the few known-long queries (reports, exports,
+// maintenance). d <= 0 removes the limit; the context's own deadline still
+// applies.
+func WithQueryTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, queryTimeoutKey{}, d)
+}
+
+// queryTimeout returns the timeout for statements run with ctx.
+func (b Base) queryTimeout(ctx context.Context) time.Duration {
+	if d, ok := ctx.Value(queryTimeoutKey{}).(time.Duration); ok {
+		return d
+	}
+	return b.server.Config.Database.GetQueryTimeout()
+}
+
+// timeoutQuerier runs every statement under its own deadline, unless the
+// caller's context ends sooner. The deadline covers reading the rows too:
+// it is released when Rows are closed or the Row is scanned.
+type timeoutQuerier struct {
+	q       Querier
+	timeout time.Duration
+}
+
+// withTimeout returns ctx bounded by t.timeout, or ctx itself (and a no-op
+// cancel) when its deadline is already closer.
+func (t timeoutQuerier) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= t.timeout {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, t.timeout)
+}
+
+func (t timeoutQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	ctx, cancel := t.withTimeout(ctx)
+	rows, err := t.q.Query(ctx, sql, args...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &cancelRows{Rows: rows, cancel: cancel}, nil
+}
+
+func (t timeoutQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	ctx, cancel := t.withTimeout(ctx)
+	return cancelRow{row: t.q.QueryRow(ctx, sql, args...), cancel: cancel}
+}
+
+func (t timeoutQuerier) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
+	return t.q.Exec(ctx, sql, arguments...)
+}
+
+// cancelRows releases the statement deadline once the rows are closed
+// (CollectRows and friends always close them).
+type cancelRows struct {
+	pgx.Rows
+	cancel context.CancelFunc
+}
+
+func (r *cancelRows) Close() {
+	r.Rows.Close()
+	r.cancel()
+}
+
+// cancelRow releases the statement deadline after Scan, the only call a
+// pgx.Row gets.
+type cancelRow struct {
+	row    pgx.Row
+	cancel context.CancelFunc
+}
+
+func (r cancelRow) Scan(dest ...any) error {
+	defer r.cancel()
+	return r.row.Scan(dest...)
+}
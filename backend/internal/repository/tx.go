@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/deppfellow/go-boilerplate/internal/lib/ctxutil"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/jackc/pgx/v5"
+)
+
+// txKey is the context key holding the active transaction state.
+type txKey struct{}
+
+// txState is the transaction bound to a context plus the cache namespaces,
+// search documents and embedded rows its writes have touched.
+type txState struct {
+	tx         pgx.Tx
+	namespaces map[string]struct{}
+	search     pendingIDs
+	embeddings pendingIDs
+}
+
+// pendingIDs collects row IDs per index/source until COMMIT.
+type pendingIDs map[string]map[string]struct{}
+
+func (p pendingIDs) add(name string, ids []string) {
+	set, ok := p[name]
+	if !ok {
+		set = map[string]struct{}{}
+		p[name] = set
+	}
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+}
+
+// TxManager runs service-level units of work in a single transaction.
+//
+// Repositories pick the transaction up from the context (Base.Querier), so
+// services don't thread pgx.Tx through every call. Cache invalidations and
+// search/embedding updates declared by repository writes are held back
+// until COMMIT succeeds: a rolled-back write must not evict anything, and
+// evicting before commit would let another request re-cache the old row.
+type TxManager struct {
+	server *server.Server
+}
+
+// NewTxManager constructs a TxManager.
+func NewTxManager(s *server.Server) *TxManager {
+	return &TxManager{server: s}
+}
+
+// WithinTx runs fn in a transaction, committing if it returns nil and
+// rolling back otherwise. Nested calls join the outer transaction.
+//
+// A transaction failing with a transient error (deadlock, serialization
+// failure, see sqlerr.IsRetryable) is rolled back and run again from the
+// start, up to database.retry_max_attempts times. fn must therefore be safe
+// to rerun, which holds for database work and the cache/search/embedding
+// updates declared through Base. Errors already converted for the client
+// (errs.HTTPError) are not retried.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*txState); ok {
+		return fn(ctx)
+	}
+
+	return retry(ctx, m.server, "transaction", func(ctx context.Context) error {
+		return m.runTx(ctx, fn)
+	})
+}
+
+// runTx runs one attempt of WithinTx.
+func (m *TxManager) runTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := m.server.DB.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	state := &txState{
+		tx:         tx,
+		namespaces: map[string]struct{}{},
+		search:     pendingIDs{},
+		embeddings: pendingIDs{},
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, state)); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	publishInvalidations(ctx, m.server, slices.Collect(maps.Keys(state.namespaces)))
+	for index, ids := range state.search {
+		enqueueReindex(ctx, m.server, index, slices.Collect(maps.Keys(ids)))
+	}
+	for source, ids := range state.embeddings {
+		enqueueEmbed(ctx, m.server, source, slices.Collect(maps.Keys(ids)))
+	}
+	return nil
+}
+
+// Querier returns the transaction bound to ctx (see TxManager.WithinTx),
+// or the database when there is none, which sends plain SELECTs to a read
+// replica when some are configured (see database.Database.Query).
+//
+// Every statement gets database.query_timeout (or WithQueryTimeout) as its
+// deadline, so a runaway query can't hold a connection indefinitely.
+func (b Base) Querier(ctx context.Context) Querier {
+	var q Querier = b.server.DB
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		q = state.tx
+	}
+
+	if timeout := b.queryTimeout(ctx); timeout > 0 {
+		return timeoutQuerier{q: q, timeout: timeout}
+	}
+	return q
+}
+
+// Invalidates declares that a write affects the given cache namespaces.
+// Call it from repository write methods after the statement succeeded:
+//
+//	func (r *TodoRepository) Create(ctx context.Context, t *model.Todo) error {
+//		if _, err := r.Querier(ctx).Exec(ctx, insertTodo, ...); err != nil {
+//			return err
+//		}
+//		r.Invalidates(ctx, "todos")
+//		return nil
+//	}
+//
+// Inside a transaction the invalidation is published after COMMIT; outside
+// one the write is already durable, so it's published immediately.
+func (b Base) Invalidates(ctx context.Context, namespaces ...string) {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		for _, ns := range namespaces {
+			state.namespaces[ns] = struct{}{}
+		}
+		return
+	}
+
+	publishInvalidations(ctx, b.server, namespaces)
+}
+
+// publishInvalidations evicts namespaces on every instance. Failures are
+// logged, not returned: the write itself succeeded and entries still expire.
+func publishInvalidations(ctx context.Context, s *server.Server, namespaces []string) {
+	if len(namespaces) == 0 || s.Cache == nil {
+		return
+	}
+
+	if err := s.Cache.Invalidate(ctx, namespaces...); err != nil {
+		s.Logger.Error().
+			Err(err).
+			Str("request_id", ctxutil.RequestID(ctx)).
+			Strs("namespaces", namespaces).
+			Msg("failed to publish cache invalidation")
+	}
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/deppfellow/go-boilerplate/internal/queries"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/deppfellow/go-boilerplate/internal/sqlerr"
+	"github.com/google/uuid"
+)
+
+// The email index is on lower(email), which the name heuristics of sqlerr
+// can't turn into a field; the mappings name it for the client.
+func init() {
+	sqlerr.RegisterTable("users")
+	sqlerr.RegisterConstraint(sqlerr.Constraint{
+		Name:    "idx_users_email",
+		Code:    "USER_EMAIL_TAKEN",
+		Fields:  []string{"email"},
+		Message: "A user with this email already exists",
+	})
+	sqlerr.RegisterConstraint(sqlerr.Constraint{
+		Name:    "users_external_id_key",
+		Code:    "USER_ALREADY_EXISTS",
+		Fields:  []string{"external_id"},
+		Message: "This account is already registered",
+	})
+}
+
+// Queries returns the sqlc-generated queries (see internal/queries) bound to
+// Querier(ctx), so they join the context's transaction and get the query
+// timeout and replica routing like hand-written statements:
+//
+//	user, err := r.Queries(ctx).GetUser(ctx, id)
+func (b Base) Queries(ctx context.Context) *queries.Queries {
+	return queries.New(b.Querier(ctx))
+}
+
+// UserRepository reads and writes users through the sqlc query layer.
+//
+// Lookups of a missing user return a 404 USER_NOT_FOUND (sqlerr.HandleErrorFor);
+// other errors are wrapped with %w so Postgres errors reach sqlerr.HandleError
+// through the global error handler.
+type UserRepository struct {
+	Base
+}
+
+// NewUserRepository constructs a UserRepository.
+func NewUserRepository(s *server.Server) *UserRepository {
+	return &UserRepository{Base: NewBase(s)}
+}
+
+// GetByID returns a user by primary key.
+func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*queries.User, error) {
+	user, err := r.Queries(ctx).GetUser(ctx, id)
+	if err != nil {
+		return nil, sqlerr.HandleErrorFor(err, "users")
+	}
+	return &user, nil
+}
+
+// GetByEmail returns a user by email, compared case-insensitively.
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*queries.User, error) {
+	user, err := r.Queries(ctx).GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, sqlerr.HandleErrorFor(err, "users")
+	}
+	return &user, nil
+}
+
+// GetByExternalID returns a user by identity provider ID.
+func (r *UserRepository) GetByExternalID(ctx context.Context, externalID string) (*queries.User, error) {
+	user, err := r.Queries(ctx).GetUserByExternalID(ctx, externalID)
+	if err != nil {
+		return nil, sqlerr.HandleErrorFor(err, "users")
+	}
+	return &user, nil
+}
+
+// List returns up to limit users, newest first.
+func (r *UserRepository) List(ctx context.Context, limit int32) ([]queries.User, error) {
+	users, err := r.Queries(ctx).ListUsers(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	return users, nil
+}
+
+// Create inserts a user. A taken email or external ID is a 409.
+func (r *UserRepository) Create(ctx context.Context, params queries.CreateUserParams) (*queries.User, error) {
+	user, err := r.Queries(ctx).CreateUser(ctx, params)
+	if err != nil {
+		return nil, r.HandleWriteError(ctx, err)
+	}
+	r.Invalidates(ctx, "users")
+	return &user, nil
+}
+
+// Update changes a user's email and name.
+func (r *UserRepository) Update(ctx context.Context, params queries.UpdateUserParams) (*queries.User, error) {
+	user, err := r.Queries(ctx).UpdateUser(ctx, params)
+	if err != nil {
+		return nil, r.HandleWriteError(ctx, err)
+	}
+	r.Invalidates(ctx, "users")
+	return &user, nil
+}
+
+// Delete removes a user. A missing user is a 404 USER_NOT_FOUND.
+func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	rows, err := r.Queries(ctx).DeleteUser(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete user %s: %w", id, err)
+	}
+	if rows == 0 {
+		return sqlerr.NotFound("users")
+	}
+	r.Invalidates(ctx, "users")
+	return nil
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/deppfellow/go-boilerplate/internal/lib/ctxutil"
+	"github.com/deppfellow/go-boilerplate/internal/lib/embedding"
+	"github.com/deppfellow/go-boilerplate/internal/lib/job"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/jackc/pgx/v5"
+)
+
+// Distance metrics of pgvector, by operator.
+const (
+	DistanceCosine       = "<=>"
+	DistanceL2           = "<->"
+	DistanceInnerProduct = "<#>"
+)
+
+// VectorQuery describes a nearest-neighbour search over a vector column.
+type VectorQuery struct {
+	// Table, IDColumn (default "id") and Column locate the vectors.
+	Table    string
+	IDColumn string
+	Column   string
+
+	// Vector is the query embedding, e.g. from server.Embeddings.
+	Vector embedding.Vector
+
+	// Distance is one of the Distance constants (default DistanceCosine).
+	// It must match the operator class of the index, or the index is skipped.
+	Distance string
+
+	// Where is an optional extra condition, with placeholders starting at
+	// $2 ($1 is the vector): "tenant_id = $2 AND deleted_at IS NULL".
+	Where string
+	Args  []any
+
+	// Limit defaults to 10.
+	Limit int
+}
+
+// VectorMatch is one neighbour, closest first.
+type VectorMatch struct {
+	ID       string  `json:"id"`
+	Distance float64 `json:"distance"`
+}
+
+// NearestNeighbors returns the rows whose vectors are closest to q.Vector.
+// Fetch the rows themselves by ID afterwards, keeping the order.
+//
+// Without an index this is an exact scan of the whole table, fine for a few
+// thousand rows. Beyond that add an approximate index in a migration, with
+// the operator class of the distance you query with:
+//
+//	CREATE INDEX idx_todos_embedding ON todos
+//	    USING hnsw (embedding vector_cosine_ops);   -- DistanceCosine
+//	    -- vector_l2_ops for DistanceL2, vector_ip_ops for DistanceInnerProduct
+//
+// HNSW needs no training data and can be created on an empty table; build
+// it with CREATE INDEX CONCURRENTLY on large existing tables.
+func (b Base) NearestNeighbors(ctx context.Context, q VectorQuery) ([]VectorMatch, error) {
+	distance := q.Distance
+	switch distance {
+	case "":
+		distance = DistanceCosine
+	case DistanceCosine, DistanceL2, DistanceInnerProduct:
+	default:
+		return nil, fmt.Errorf("unknown vector distance operator %q", q.Distance)
+	}
+
+	idColumn := q.IDColumn
+	if idColumn == "" {
+		idColumn = "id"
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+
+	column := pgx.Identifier{q.Column}.Sanitize()
+
+	var sql strings.Builder
+	fmt.Fprintf(&sql, "SELECT %s::text, %s %s $1::vector FROM %s WHERE %s IS NOT NULL",
+		pgx.Identifier{idColumn}.Sanitize(), column, distance, pgx.Identifier{q.Table}.Sanitize(), column)
+	if q.Where != "" {
+		fmt.Fprintf(&sql, " AND (%s)", q.Where)
+	}
+	fmt.Fprintf(&sql, " ORDER BY %s %s $1::vector LIMIT %d", column, distance, limit)
+
+	args := append([]any{q.Vector}, q.Args...)
+	rows, err := b.Querier(ctx).Query(ctx, sql.String(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query nearest neighbors of %s: %w", q.Table, err)
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (VectorMatch, error) {
+		var m VectorMatch
+		err := row.Scan(&m.ID, &m.Distance)
+		return m, err
+	})
+}
+
+// Reembed declares that a write changed the text of rows of an embedding
+// source (see lib/embedding), so their vectors are recomputed:
+//
+//	r.Reembed(ctx, "todos", todo.ID.String())
+//
+// Like Invalidates, inside a transaction the job is enqueued after COMMIT.
+// It is a no-op when embeddings are disabled.
+func (b Base) Reembed(ctx context.Context, source string, ids ...string) {
+	if b.server.EmbeddingRunner == nil || len(ids) == 0 {
+		return
+	}
+
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		state.embeddings.add(source, ids)
+		return
+	}
+
+	enqueueEmbed(ctx, b.server, source, ids)
+}
+
+// enqueueEmbed enqueues an embedding:embed job. Failures are logged: rows
+// keep their previous vector until the next change or a full re-embed.
+func enqueueEmbed(ctx context.Context, s *server.Server, source string, ids []string) {
+	if len(ids) == 0 || s.Job == nil {
+		return
+	}
+
+	task, err := job.NewEmbedTask(ctx, source, ids)
+	if err == nil {
+		_, err = s.Job.Client.EnqueueContext(ctx, task)
+	}
+	if err != nil {
+		s.Logger.Error().
+			Err(err).
+			Str("request_id", ctxutil.RequestID(ctx)).
+			Str("source", source).
+			Int("rows", len(ids)).
+			Msg("failed to enqueue embedding")
+	}
+}
@@ -0,0 +1,110 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/deppfellow/go-boilerplate/internal/handler"
+	"github.com/deppfellow/go-boilerplate/internal/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+// registerAdminRoutes registers operational endpoints reserved for
+// organization admins under /api/v1/admin.
+//
+// Maintenance mode (see middleware.MaintenanceTogglePath, always reachable):
+//   - GET    /admin/maintenance  current state
+//   - PUT    /admin/maintenance  switch on (message, retry_after, redirect_url)
+//   - DELETE /admin/maintenance  switch off
+//
+// Configuration (see config.Redacted, also `<binary> config print`):
+//   - GET    /admin/config       effective configuration, secrets masked
+//
+// Schema backfills (see lib/backfill):
+//   - GET  /admin/backfills/:name         persisted progress
+//   - POST /admin/backfills/:name/run     enqueue (or resume) on the job queue
+//   - POST /admin/backfills/:name/verify  compare old vs new columns
+//
+// Background jobs (read-only, see handler.JobsHandler):
+//   - GET  /admin/jobs/queues                 stats of every queue
+//   - GET  /admin/jobs/queues/:queue/:state   tasks (pending, active, scheduled, retry, archived)
+//
+// Search indexes (see lib/search):
+//   - GET  /admin/search/:index/reindex  progress of the last full reindex
+//   - POST /admin/search/:index/reindex  start a full reindex on the job queue
+//
+// Vector embeddings (see lib/embedding):
+//   - POST /admin/embeddings/:source/reembed  recompute every embedding, batch by batch
+//
+// Soft deletes (see lib/softdelete):
+//   - POST /admin/soft-deletes/:table/purge  hard-delete rows past their retention
+func registerAdminRoutes(g *echo.Group, h *handler.Handlers) {
+	m := h.Maintenance
+
+	handler.GET(g, "/admin/maintenance", handler.JSON(
+		handler.Route(m.Handler).Admin(), m.Status, http.StatusOK, &handler.GetMaintenanceRequest{},
+	))
+	g.PUT("/admin/maintenance", handler.JSON(
+		handler.Route(m.Handler).Admin(), m.Enable, http.StatusOK, &handler.EnableMaintenanceRequest{},
+	))
+	g.DELETE("/admin/maintenance", handler.NoContent(
+		handler.Route(m.Handler).Admin(), m.Disable, http.StatusNoContent, &handler.DisableMaintenanceRequest{},
+	))
+
+	handler.GET(g, "/admin/config", handler.JSON(
+		handler.Route(h.Config.Handler).Admin(), h.Config.Show, http.StatusOK, &handler.GetConfigRequest{},
+	))
+
+	b := h.Backfill
+
+	handler.GET(g, "/admin/backfills/:name", handler.JSON(
+		handler.Route(b.Handler).Admin(), b.Status, http.StatusOK, &handler.BackfillRequest{},
+	))
+	g.POST("/admin/backfills/:name/run", handler.JSON(
+		handler.Route(b.Handler).Admin(), b.Run, http.StatusAccepted, &handler.BackfillRequest{},
+	))
+	g.POST("/admin/backfills/:name/verify", handler.JSON(
+		handler.Route(b.Handler).Admin(), b.Verify, http.StatusOK, &handler.BackfillRequest{},
+	))
+
+	j := h.Jobs
+
+	handler.GET(g, "/admin/jobs/queues", handler.JSON(
+		handler.Route(j.Handler).Admin(), j.Queues, http.StatusOK, &handler.ListQueuesRequest{},
+	))
+	handler.GET(g, "/admin/jobs/queues/:queue/:state", handler.JSON(
+		handler.Route(j.Handler).Admin(), j.Tasks, http.StatusOK, &handler.ListTasksRequest{},
+	))
+
+	sh := h.Search
+
+	handler.GET(g, "/admin/search/:index/reindex", handler.JSON(
+		handler.Route(sh.Handler).Admin(), sh.Status, http.StatusOK, &handler.SearchIndexRequest{},
+	))
+	g.POST("/admin/search/:index/reindex", handler.JSON(
+		handler.Route(sh.Handler).Admin(), sh.Reindex, http.StatusAccepted, &handler.SearchIndexRequest{},
+	))
+
+	em := h.Embedding
+
+	g.POST("/admin/embeddings/:source/reembed", handler.JSON(
+		handler.Route(em.Handler).Admin(), em.Reembed, http.StatusAccepted, &handler.EmbeddingSourceRequest{},
+	))
+
+	sd := h.SoftDelete
+
+	g.POST("/admin/soft-deletes/:table/purge", handler.JSON(
+		handler.Route(sd.Handler).Admin(), sd.Purge, http.StatusAccepted, &handler.PurgeDeletedRequest{},
+	))
+}
+
+// registerAdminDashboard serves the embedded admin UI at /admin.
+//
+// The page itself is deliberately not behind admin auth: browsers don't
+// send bearer tokens on navigation, and auth here is header-only (no session
+// cookie), so gating it would make it unreachable. It is a static shell that
+// holds no data: everything it shows comes from the admin routes above,
+// which require the admin's token (entered in the page, kept for the tab).
+// It is served at this path only (not under /static), with the dashboard CSP.
+func registerAdminDashboard(r *echo.Echo, h *handler.Handlers) {
+	handler.GET(r, middleware.AdminDashboardPath, h.Dashboard.Serve)
+}
@@ -12,15 +12,11 @@
 package router
 
 import (
-	"net/http"
-
 	"github.com/deppfellow/go-boilerplate/internal/handler"
 	"github.com/deppfellow/go-boilerplate/internal/middleware"
 	"github.com/deppfellow/go-boilerplate/internal/server"
 	"github.com/deppfellow/go-boilerplate/internal/service"
 	"github.com/labstack/echo/v4"
-	echoMiddleware "github.com/labstack/echo/v4/middleware"
-	"golang.org/x/time/rate"
 )
 
 // Package router initializes the HTTP router (using Echo).
@@ -34,7 +30,29 @@ import (
 //     becomes a consistent JSON response.
 //   - Register global middleware in a single place to enforce consistent behavior
 //     across the entire API (CORS, request_id, tracing, request logging, recovery, etc.).
+//
+// NewRouter serves every route, for running the whole app as one binary.
+// The cmd/<service> binaries use NewAPIRouter, NewAdminRouter or
+// NewWorkerRouter instead (see ForService).
 func NewRouter(s *server.Server, h *handler.Handlers, services *service.Services) *echo.Echo {
+	router := newBaseRouter(s, h)
+
+	// Register versioned routes
+	v1 := router.Group("/api/v1")
+
+	// Admin routes (organization admins only).
+	registerAdminRoutes(v1, h)
+	registerAdminDashboard(router, h)
+
+	// Caller-facing quota usage.
+	registerUsageRoutes(v1, h)
+
+	return router
+}
+
+// newBaseRouter builds the Echo instance every service shares: error
+// handler, global middleware chain and system routes (health, docs).
+func newBaseRouter(s *server.Server, h *handler.Handlers) *echo.Echo {
 	// Construct middleware bundle (DI container).
 	middlewares := middleware.NewMiddlewares(s)
 
@@ -44,6 +62,17 @@ func NewRouter(s *server.Server, h *handler.Handlers, services *service.Services
 	// Create the Echo router instance.
 	router.HTTPErrorHandler = middlewares.Global.GlobalErrorHandler
 
+	// Client IP resolution for c.RealIP(): forwarding headers are only honored
+	// from trusted proxies (see config.ProxyConfig), so clients can't spoof
+	// the IP used by logging, rate limiting, GeoIP and tracing.
+	router.IPExtractor = middleware.NewIPExtractor(s.Config.Proxy)
+
+	// Pre-routing middleware.
+	//
+	// Path normalization must run before the router picks a route, otherwise
+	// "/status/" would already have resolved to 404.
+	router.Pre(middlewares.Path.Normalize())
+
 	// Global middleware registration.
 	//
 	// Middleware order matters at runtime because later middleware can only use
@@ -53,63 +82,90 @@ func NewRouter(s *server.Server, h *handler.Handlers, services *service.Services
 	// - context enhancer can attach trace/user/request fields to logger
 	// - request logger runs after context enrichment so logs include correlation fields
 	router.Use(
-		// Rate limiter middleware (Echo built-in).
+		// Prometheus request metrics (no-op when disabled). Outermost so
+		// shed and rate-limited requests are counted and timed too.
+		middlewares.Metrics.Record(),
+
+		// Load shedding: cap in-flight requests (ServerConfig.MaxConcurrentRequests).
+		// Right after Metrics so shed requests cost as little as possible.
+		middlewares.LoadShed.Limit(),
+
+		// Rate limiter middleware (see RateLimitMiddleware.Global).
 		//
-		// - Uses in-memory store with a limit of 20 requests/second (rate.Limit(20)).
-		// - DenyHandler runs when the request is rejected.
-		// - DenyHandler logs rate limit events and records a New Relic custom event.
+		// - Uses in-memory stores with budgets from RateLimitConfig, keyed by
+		//   client IP here; RequireAuth / RequireAPIKey charge the user or
+		//   API key at the authenticated budget once verified.
+		// - Responses carry X-RateLimit-* headers; 429s add Retry-After.
+		// - Rejections are logged and recorded as a New Relic custom event.
 		//
 		// NOTE: In-memory limiter is per-instance. In multi-instance deployments,
 		// each instance has its own limiter unless you use a distributed store (Redis).
-		echoMiddleware.RateLimiterWithConfig(echoMiddleware.RateLimiterConfig{
-			Store: echoMiddleware.NewRateLimiterMemoryStore(rate.Limit(20)),
-			DenyHandler: func(c echo.Context, identifier string, err error) error {
-				// Record rate limit hit telemetry (New Relic custom event) if enabled.
-				if rateLimitMiddleware := middlewares.RateLimit; rateLimitMiddleware != nil {
-					rateLimitMiddleware.RecordLateLimitHit(c.Path())
-				}
-
-				// Log rate limit rejection with useful correlation fields.
-				s.Logger.Warn().
-					Str("request_id", middleware.GetRequestID(c)).
-					Str("identifier", identifier).     // identifier depends on limiter config (often IP)
-					Str("path", c.Path()).             // route template path
-					Str("method", c.Request().Method). // HTTP method
-					Str("ip", c.RealIP()).             // client IP (respects proxy headers)
-					Msg("rate limit exceeded")
-
-				// Return a 429 error.
-				// The global error handler will format the final JSON response.
-				return echo.NewHTTPError(http.StatusTooManyRequests, "Rate limit exceeded")
-			},
-		}),
+		middlewares.RateLimit.Global(),
 
 		// CORS policy configured via env/config.
 		middlewares.Global.CORS(),
 
-		// Secure headers middleware.
+		// Security headers (CSP, HSTS, Referrer-Policy...) from ServerConfig.SecurityHeaders.
 		middlewares.Global.Secure(),
 
-		// Request ID middleware: reads X-Request-ID or generates UUID, stores it in context.
-		middleware.RequestID(),
+		// Request ID middleware: reads X-Request-ID (validated, optionally only from
+		// trusted proxies) or generates UUID, plus a server-generated ID; stores both in context.
+		middleware.RequestIDWithConfig(s.Config.RequestID),
+
+		// GeoIP enrichment: resolves country/region from the client IP.
+		// Must run before tracing/context enhancer so they can attach geo fields.
+		middlewares.GeoIP.Enrich(),
+
+		// Locale detection (?lang override, then Accept-Language).
+		// Before the context enhancer so request logs carry the locale.
+		middlewares.Locale.Detect(),
 
-		// New Relic transaction middleware.
-		// This must run before EnhanceTracing so a transaction exists in request context.
-		middlewares.Tracing.NewRelicMiddleware(),
+		// APM span per request (New Relic transaction or OTLP server span).
+		// This must run before EnhanceTracing so a span exists in request context.
+		middlewares.Tracing.TraceRequests(),
 
-		// New Relic transaction middleware.
-		// This must run before EnhanceTracing so a transaction exists in request context.
+		// Custom span attributes (route, ip, request/user id, geo, status) and error reporting.
 		middlewares.Tracing.EnhanceTracing(),
 
+		// X-Trace-Id / X-Request-ID response headers for client-side correlation.
+		middlewares.Tracing.TraceHeaders(),
+
 		// Builds a request-scoped logger and stores it in Echo context and Go context.
 		// It uses request_id and optionally trace/user metadata if already available.
 		middlewares.ContextEnhancer.EnhanceContext(),
 
+		// Tenant from the X-Tenant-ID header / subdomain (no-op unless
+		// tenancy is enabled). After the context enhancer, whose logger it
+		// extends; the claims source is applied later by RequireAuth.
+		middlewares.Tenancy.Resolve(),
+
 		// Structured request logging (zerolog), using the enhanced logger from context.
 		middlewares.Global.RequestLogger(),
 
+		// Per-request DB statement budget and N+1 detection (reports, never rejects).
+		middlewares.QueryBudget.Track(),
+
+		// Country allow/deny rules (no-op unless configured).
+		// Runs after the request logger so blocked requests are still logged.
+		middlewares.GeoIP.CountryRules(),
+
+		// CSRF protection for cookie-based browser clients (no-op unless enabled).
+		// Bearer-token and API-key requests are exempt.
+		middlewares.CSRF.Protect(),
+
+		// Maintenance mode: 503 for non-allowlisted routes while the Redis flag is set.
+		middlewares.Maintenance.Guard(),
+
+		// Audit trail for POST/PUT/PATCH/DELETE (written async via the job queue).
+		// Wraps Recover so panicking mutations are still audited as 500s.
+		middlewares.Audit.Record(),
+
 		// Panic recovery middleware.
 		middlewares.Global.Recover(),
+
+		// Request deadline (global default + per-group overrides from config).
+		// Last in the chain so the clock only covers handler work.
+		middlewares.Timeout.Timeout(),
 	)
 
 	// Register system/utility routes such as:
@@ -117,8 +173,10 @@ func NewRouter(s *server.Server, h *handler.Handlers, services *service.Services
 	// - /openapi /swagger
 	registerSystemRoutes(router, h)
 
-	// Register versioned routes
-	router.Group("api/v1")
+	// Internal RPC between the platform's services (see lib/rpc).
+	if s.Config.RPC.Enabled {
+		registerRPCRoutes(router, s, middlewares)
+	}
 
 	return router
 }
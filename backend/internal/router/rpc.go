@@ -0,0 +1,24 @@
+package router
+
+import (
+	"context"
+
+	"github.com/deppfellow/go-boilerplate/internal/lib/rpc"
+	"github.com/deppfellow/go-boilerplate/internal/middleware"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+// registerRPCRoutes mounts the internal RPC methods of this service under
+// /internal/rpc, behind the service token check.
+//
+// Only done when RPCConfig.Enabled. Register new methods here with rpc.Handle;
+// a method only answers on the service named in its declaration.
+func registerRPCRoutes(router *echo.Echo, s *server.Server, m *middleware.Middlewares) {
+	g := router.Group(rpc.PathPrefix, m.RPC.Authenticate())
+
+	self := s.Config.Primary.Service
+	rpc.Handle(g, rpc.Ping(self), func(ctx context.Context, _ *rpc.PingRequest) (*rpc.PingResponse, error) {
+		return &rpc.PingResponse{Service: self, Caller: rpc.Caller(ctx)}, nil
+	})
+}
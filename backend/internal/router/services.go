@@ -0,0 +1,71 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	"github.com/deppfellow/go-boilerplate/internal/handler"
+	"github.com/deppfellow/go-boilerplate/internal/server"
+	"github.com/deppfellow/go-boilerplate/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// RouterFunc builds the router of one service binary.
+type RouterFunc func(s *server.Server, h *handler.Handlers, services *service.Services) *echo.Echo
+
+// ForService returns the router constructor for a service name
+// (config.ServiceAPI, ...). An empty name selects NewRouter (everything).
+func ForService(name string) (RouterFunc, error) {
+	switch name {
+	case "":
+		return withHeadRoutes(NewRouter), nil
+	case config.ServiceAPI:
+		return withHeadRoutes(NewAPIRouter), nil
+	case config.ServiceAdmin:
+		return withHeadRoutes(NewAdminRouter), nil
+	case config.ServiceWorker:
+		return withHeadRoutes(NewWorkerRouter), nil
+	default:
+		return nil, fmt.Errorf("unknown service %q", name)
+	}
+}
+
+// NewAPIRouter serves the public API (cmd/api).
+//
+// Add new feature routes here; anything operators-only goes to NewAdminRouter.
+func NewAPIRouter(s *server.Server, h *handler.Handlers, services *service.Services) *echo.Echo {
+	router := newBaseRouter(s, h)
+
+	v1 := router.Group("/api/v1")
+	registerUsageRoutes(v1, h)
+
+	return router
+}
+
+// NewAdminRouter serves the admin endpoints (cmd/admin), under the same
+// /api/v1/admin paths as the single binary so clients don't change.
+func NewAdminRouter(s *server.Server, h *handler.Handlers, services *service.Services) *echo.Echo {
+	router := newBaseRouter(s, h)
+
+	v1 := router.Group("/api/v1")
+	registerAdminRoutes(v1, h)
+	registerAdminDashboard(router, h)
+
+	return router
+}
+
+// NewWorkerRouter serves only the system routes (cmd/worker): the worker
+// has no API, but orchestrators still need /health to probe it.
+func NewWorkerRouter(s *server.Server, h *handler.Handlers, services *service.Services) *echo.Echo {
+	return newBaseRouter(s, h)
+}
+
+// withHeadRoutes makes every GET route of the built router answer HEAD,
+// however it was registered (see handler.HeadForGET).
+func withHeadRoutes(newRouter RouterFunc) RouterFunc {
+	return func(s *server.Server, h *handler.Handlers, services *service.Services) *echo.Echo {
+		router := newRouter(s, h, services)
+		handler.HeadForGET(router)
+		return router
+	}
+}
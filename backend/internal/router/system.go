@@ -12,15 +12,37 @@ import (
 // - Routes include:
 //  1. Health endpoint
 //  2. Docs endpoint (OpenAPI UI)
-//  3. Static files endpoint (to serve openapi.json and openapi.html assets)
+//  3. Static files endpoint (openapi.json, the docs UI pages and bundles)
+//  4. Error catalog (target of the "docs_url" links in error responses)
+//  5. Prometheus metrics (when observability.metrics.enabled)
+//  6. Version endpoint (build information)
+//
+// Docs and static routes are only registered when DocsConfig.Enabled is true
+// (off by default in production).
 func registerSystemRoutes(r *echo.Echo, h *handler.Handlers) {
 	// Health status endpoint (used by Kubernetes/monitors).
-	r.GET("/status", h.Health.CheckHealth)
+	// Registered with HEAD too, so load balancers probing with HEAD work.
+	handler.GET(r, "/status", h.Health.CheckHealth)
+
+	// Version, commit and build time of the running binary.
+	handler.GET(r, "/version", h.Health.Version)
+
+	// Prometheus scrape endpoint (default /metrics).
+	if h.Metrics.Enabled() {
+		handler.GET(r, h.Metrics.Path(), h.Metrics.Serve)
+	}
+
+	if !h.OpenAPI.Enabled() {
+		return
+	}
+
+	// Serve embedded docs assets at /static/*: openapi.json, the docs UI
+	// pages and their vendored bundles (not the admin dashboard page).
+	r.StaticFS("/static", h.OpenAPI.StaticFS())
 
-	// Serve all files from ./static at /static/*.
-	// Used for openapi.json and openapi.html (and any future docs assets).
-	r.Static("/static", "static")
+	// Docs UI endpoint (serves the configured UI page).
+	handler.GET(r, "/docs", h.OpenAPI.ServeOpenAPIUI)
 
-	// Docs UI endpoint (serves openapi.html).
-	r.GET("/docs", h.OpenAPI.ServeOpenAPIUI)
+	// Error code documentation generated from the errs catalog.
+	handler.GET(r, "/docs/errors", h.OpenAPI.ServeErrorCatalog)
 }
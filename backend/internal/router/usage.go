@@ -0,0 +1,20 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/deppfellow/go-boilerplate/internal/handler"
+	"github.com/labstack/echo/v4"
+)
+
+// registerUsageRoutes registers the caller-facing quota endpoint:
+//   - GET /usage  plan, limits and remaining daily/monthly quota
+//
+// It is not metered itself, so clients can always check where they stand.
+func registerUsageRoutes(g *echo.Group, h *handler.Handlers) {
+	u := h.Usage
+
+	handler.GET(g, "/usage", handler.JSON(
+		handler.Route(u.Handler).Auth(), u.Usage, http.StatusOK, &handler.GetUsageRequest{},
+	))
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"context"
+
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+)
+
+// Names of the dependencies registered by New.
+const (
+	DependencyDatabase = "database"
+	DependencyRedis    = "redis"
+	DependencyEmail    = "email"
+	DependencySearch   = "search"
+)
+
+// registerDependencies sets up the health tracking of the core dependencies.
+//
+// Database and Redis are probed actively; the email provider has no cheap
+// probe, so the job worker reports the outcome of each send instead. Once
+// down, it is retried half-open every check interval (see
+// dependency.Tracker.Healthy), so Optional does not keep it off for good.
+func (s *Server) registerDependencies() {
+	s.Dependencies.Register(DependencyDatabase, true, func(ctx context.Context) error {
+		return s.DB.Pool.Ping(ctx)
+	})
+
+	s.Dependencies.Register(DependencyRedis, false, func(ctx context.Context) error {
+		return s.Redis.Ping(ctx).Err()
+	})
+
+	s.Dependencies.Register(DependencyEmail, false, nil)
+
+	if s.Search != nil {
+		s.Dependencies.Register(DependencySearch, false, s.Search.Health)
+	}
+}
+
+// Require returns nil when dependency name is healthy, and otherwise a 503
+// SERVICE_DEGRADED errs.HTTPError with a retry hint, to return as is:
+//
+//	if err := h.server.Require(server.DependencyRedis); err != nil {
+//		return nil, err
+//	}
+//
+// Use it at the top of features that cannot work without the dependency,
+// so clients get a typed, retryable error instead of a timeout or a 500.
+func (s *Server) Require(name string) error {
+	if s.Dependencies == nil || s.Dependencies.Healthy(name) {
+		return nil
+	}
+	return errs.NewServiceDegradedError(name, s.Dependencies.RetryAfter())
+}
+
+// Optional reports whether dependency name is available, for features that
+// can be skipped or deferred when it is not (e.g. serve uncached, queue the
+// email for later) without failing the request.
+func (s *Server) Optional(name string) bool {
+	return s.Dependencies == nil || s.Dependencies.Healthy(name)
+}
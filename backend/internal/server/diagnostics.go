@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	runtimepprof "runtime/pprof"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/buildinfo"
+)
+
+// setupDiagnostics prepares the diagnostics listener (server.diagnostics)
+// and ties it to the server lifecycle. It is a no-op when disabled.
+//
+// Routes:
+//   - /debug/pprof/...            net/http/pprof (CPU profile, heap, trace, ...)
+//   - /debug/vars                 expvar (memstats, cmdline, build info)
+//   - /debug/dump/goroutines      full stacks of every goroutine, as text
+//   - POST /debug/dump/heap       heap profile taken right after a GC
+//
+// The handlers are registered on a dedicated mux: importing net/http/pprof
+// also adds them to http.DefaultServeMux, which nothing here serves.
+//
+//	go tool pprof http://localhost:6060/debug/pprof/profile?seconds=30
+//	curl -X POST -o heap.pb.gz http://localhost:6060/debug/dump/heap
+func (s *Server) setupDiagnostics() error {
+	cfg := s.Config.Server.Diagnostics
+	if !cfg.Enabled {
+		return nil
+	}
+
+	allowed, err := cfg.ParsedAllowedCIDRs()
+	if err != nil {
+		return fmt.Errorf("invalid diagnostics config: %w", err)
+	}
+
+	if expvar.Get("build") == nil {
+		expvar.Publish("build", expvar.Func(func() any { return buildinfo.Get() }))
+	}
+	if expvar.Get("goroutines") == nil {
+		expvar.Publish("goroutines", expvar.Func(func() any { return runtime.NumGoroutine() }))
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("GET /debug/dump/goroutines", dumpGoroutines)
+	mux.HandleFunc("POST /debug/dump/heap", dumpHeap)
+
+	diagnostics := &http.Server{
+		Addr:              ":" + cfg.GetPort(),
+		Handler:           s.guardDiagnostics(mux, allowed),
+		ReadHeaderTimeout: 10 * time.Second,
+		// No WriteTimeout: CPU profiles and traces stream for ?seconds=N.
+	}
+
+	s.OnStart("diagnostics", func(context.Context, *Server) error {
+		listener, err := net.Listen("tcp", diagnostics.Addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on diagnostics port %s: %w", diagnostics.Addr, err)
+		}
+		s.Logger.Info().Str("addr", diagnostics.Addr).Msg("diagnostics listener started")
+
+		go func() {
+			if err := diagnostics.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				s.Logger.Error().Err(err).Str("addr", diagnostics.Addr).Msg("diagnostics listener stopped")
+			}
+		}()
+		return nil
+	})
+	s.OnShutdown("diagnostics", func(ctx context.Context, _ *Server) error {
+		return diagnostics.Shutdown(ctx)
+	})
+
+	return nil
+}
+
+// guardDiagnostics rejects peers outside allowed (nil allows any) and, when
+// configured, requests without the basic auth credentials. Every request is
+// logged: profiling a production instance should leave a trace.
+func (s *Server) guardDiagnostics(next http.Handler, allowed []*net.IPNet) http.Handler {
+	cfg := s.Config.Server.Diagnostics
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+
+		if allowed != nil && !ipAllowed(net.ParseIP(host), allowed) {
+			s.Logger.Warn().Str("remote_ip", host).Str("path", r.URL.Path).Msg("diagnostics request from disallowed address")
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		if cfg.BasicAuth() {
+			user, pass, ok := r.BasicAuth()
+			if !ok ||
+				subtle.ConstantTimeCompare([]byte(user), []byte(cfg.Username)) != 1 ||
+				subtle.ConstantTimeCompare([]byte(pass), []byte(cfg.Password)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Basic realm="diagnostics"`)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+		}
+
+		s.Logger.Info().Str("remote_ip", host).Str("path", r.URL.Path).Msg("diagnostics request")
+		next.ServeHTTP(w, r)
+	})
+}
+
+func ipAllowed(ip net.IP, allowed []*net.IPNet) bool {
+	if ip == nil {
+		return false
+	}
+	for _, n := range allowed {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// dumpGoroutines writes every goroutine's stack, like a SIGQUIT dump but
+// without killing the process.
+func dumpGoroutines(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_ = runtimepprof.Lookup("goroutine").WriteTo(w, 2)
+}
+
+// dumpHeap runs a GC and writes the heap profile, so it shows live memory
+// only (open it with go tool pprof).
+func dumpHeap(w http.ResponseWriter, _ *http.Request) {
+	runtime.GC()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="heap-%s.pb.gz"`, time.Now().UTC().Format("20060102T150405Z")))
+	if err := runtimepprof.Lookup("heap").WriteTo(w, 0); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
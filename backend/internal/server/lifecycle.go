@@ -0,0 +1,223 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultHookTimeout bounds a lifecycle hook that doesn't set its own timeout.
+const DefaultHookTimeout = 10 * time.Second
+
+// Lifecycle phases.
+const (
+	// PhaseStart hooks run in Start before the listener opens. An error
+	// aborts startup (e.g. a consumer that can't connect).
+	PhaseStart = "start"
+
+	// PhaseReady hooks run once the HTTP listener accepts connections
+	// (e.g. register with service discovery, announce readiness).
+	// Errors are logged only.
+	PhaseReady = "ready"
+
+	// PhaseShutdown hooks run in Shutdown after the HTTP server has drained
+	// and before the database, Redis and job worker are closed, so extensions
+	// can still use them to flush state. Errors are collected, never fatal.
+	PhaseShutdown = "shutdown"
+)
+
+// HookFunc is a lifecycle callback.
+//
+// Start and ready hooks get a context that lives until Shutdown, so they can
+// start background work on it; their timeout only bounds how long the phase
+// waits for them to return. Shutdown hooks get a context that expires with
+// their timeout.
+type HookFunc func(ctx context.Context, s *Server) error
+
+// Hook is a registered lifecycle callback.
+type Hook struct {
+	Name    string
+	Phase   string
+	Order   int
+	Timeout time.Duration
+	Fn      HookFunc
+}
+
+// HookOption customizes a hook registration.
+type HookOption func(*Hook)
+
+// WithHookOrder sets the hook's position within its phase (default 0).
+// Start and ready hooks run in ascending order; shutdown hooks in descending
+// order, so what started first stops last. Equal orders keep registration order.
+func WithHookOrder(order int) HookOption {
+	return func(h *Hook) {
+		h.Order = order
+	}
+}
+
+// WithHookTimeout overrides DefaultHookTimeout for the hook.
+func WithHookTimeout(d time.Duration) HookOption {
+	return func(h *Hook) {
+		h.Timeout = d
+	}
+}
+
+// lifecycle stores hooks per phase, and the context start and ready hooks
+// run with.
+type lifecycle struct {
+	mu    sync.Mutex
+	hooks map[string][]Hook
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// context returns the context of start and ready hooks, cancelled by stop.
+func (l *lifecycle) context() context.Context {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.ctx == nil {
+		l.ctx, l.cancel = context.WithCancel(context.Background())
+	}
+	return l.ctx
+}
+
+// stop cancels the context of start and ready hooks, stopping background
+// work that OnShutdown hooks didn't stop explicitly.
+func (l *lifecycle) stop() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.cancel != nil {
+		l.cancel()
+	}
+}
+
+// OnStart registers fn to run when the server starts (see PhaseStart).
+//
+// Extensions (websocket hubs, schedulers, queue consumers) register
+// themselves instead of being wired into New:
+//
+//	s.OnStart("scheduler", func(ctx context.Context, s *server.Server) error {
+//		return scheduler.Start(ctx)
+//	})
+//	s.OnShutdown("scheduler", func(ctx context.Context, s *server.Server) error {
+//		return scheduler.Stop(ctx)
+//	})
+func (s *Server) OnStart(name string, fn HookFunc, opts ...HookOption) {
+	s.addHook(PhaseStart, name, fn, opts)
+}
+
+// OnReady registers fn to run once the server accepts connections (see PhaseReady).
+func (s *Server) OnReady(name string, fn HookFunc, opts ...HookOption) {
+	s.addHook(PhaseReady, name, fn, opts)
+}
+
+// OnShutdown registers fn to run during graceful shutdown (see PhaseShutdown).
+func (s *Server) OnShutdown(name string, fn HookFunc, opts ...HookOption) {
+	s.addHook(PhaseShutdown, name, fn, opts)
+}
+
+func (s *Server) addHook(phase, name string, fn HookFunc, opts []HookOption) {
+	hook := Hook{Name: name, Phase: phase, Timeout: DefaultHookTimeout, Fn: fn}
+	for _, opt := range opts {
+		opt(&hook)
+	}
+
+	s.lifecycle.mu.Lock()
+	defer s.lifecycle.mu.Unlock()
+
+	if s.lifecycle.hooks == nil {
+		s.lifecycle.hooks = make(map[string][]Hook)
+	}
+	s.lifecycle.hooks[phase] = append(s.lifecycle.hooks[phase], hook)
+}
+
+// runHooks runs the hooks of phase in order with ctx (see HookFunc). With
+// stopOnError the first failure aborts the phase; otherwise every hook runs
+// and errors are joined.
+func (s *Server) runHooks(ctx context.Context, phase string, stopOnError bool) error {
+	s.lifecycle.mu.Lock()
+	hooks := append([]Hook(nil), s.lifecycle.hooks[phase]...)
+	s.lifecycle.mu.Unlock()
+
+	sort.SliceStable(hooks, func(i, j int) bool {
+		if phase == PhaseShutdown {
+			return hooks[i].Order > hooks[j].Order
+		}
+		return hooks[i].Order < hooks[j].Order
+	})
+
+	var errs []error
+	for _, hook := range hooks {
+		start := time.Now()
+		err := runHook(ctx, hook, s)
+
+		event := s.Logger.Info()
+		if err != nil {
+			event = s.Logger.Error().Err(err)
+		}
+		event.Str("phase", phase).
+			Str("hook", hook.Name).
+			Dur("duration", time.Since(start)).
+			Msg("lifecycle hook finished")
+
+		if err != nil {
+			err = fmt.Errorf("%s hook %q: %w", phase, hook.Name, err)
+			if stopOnError {
+				return err
+			}
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// runHook runs a single hook and waits for it up to its timeout. Only
+// shutdown hooks have their context cancelled at the timeout (see HookFunc).
+//
+// A hook that doesn't return in time can't block the phase: it is reported
+// as timed out and left running, and its late return is logged.
+func runHook(ctx context.Context, hook Hook, s *Server) error {
+	if hook.Phase == PhaseShutdown {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, hook.Timeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- hook.Fn(ctx, s)
+	}()
+
+	timer := time.NewTimer(hook.Timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	start := time.Now()
+	go func() {
+		err := <-done
+		s.Logger.Warn().
+			Err(err).
+			Str("phase", hook.Phase).
+			Str("hook", hook.Name).
+			Dur("late_by", time.Since(start)).
+			Msg("lifecycle hook returned after its timeout")
+	}()
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("abandoned: %w", err)
+	}
+	return fmt.Errorf("timed out after %s", hook.Timeout)
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// setupMetrics creates the Prometheus registry (observability.metrics) and
+// registers the collectors of the resources the server owns: the database
+// pool, the job queues, the Go runtime and the process. Request metrics are
+// registered by the metrics middleware.
+//
+// All of them are read at scrape time, so an idle instance costs nothing.
+func (s *Server) setupMetrics() {
+	if !s.Config.Observability.Metrics.Enabled {
+		return
+	}
+	s.Metrics = prometheus.NewRegistry()
+	s.Metrics.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	s.registerPoolMetrics()
+	s.registerQueueMetrics()
+}
+
+// registerPoolMetrics exports pgxpool and go-redis pool statistics. A pool with acquired ==
+// max and a growing empty_acquire count is starved: raise
+// database.max_open_conns or find the slow queries holding connections.
+// The same numbers are in the /status response (see PoolStats).
+func (s *Server) registerPoolMetrics() {
+	pool := s.DB.Pool
+	factory := promauto.With(s.Metrics)
+	gauge := func(name, help string, value func() float64) {
+		factory.NewGaugeFunc(prometheus.GaugeOpts{Name: name, Help: help}, value)
+	}
+	counter := func(name, help string, value func() float64) {
+		factory.NewCounterFunc(prometheus.CounterOpts{Name: name, Help: help}, value)
+	}
+
+	gauge("db_pool_acquired_conns", "Connections currently in use.",
+		func() float64 { return float64(pool.Stat().AcquiredConns()) })
+	gauge("db_pool_idle_conns", "Idle connections in the pool.",
+		func() float64 { return float64(pool.Stat().IdleConns()) })
+	gauge("db_pool_total_conns", "Open connections (acquired, idle and being constructed).",
+		func() float64 { return float64(pool.Stat().TotalConns()) })
+	gauge("db_pool_max_conns", "Maximum size of the pool.",
+		func() float64 { return float64(pool.Stat().MaxConns()) })
+	counter("db_pool_acquires_total", "Successful connection acquires.",
+		func() float64 { return float64(pool.Stat().AcquireCount()) })
+	counter("db_pool_empty_acquires_total", "Acquires that had to wait for a connection.",
+		func() float64 { return float64(pool.Stat().EmptyAcquireCount()) })
+	counter("db_pool_canceled_acquires_total", "Acquires canceled by their context.",
+		func() float64 { return float64(pool.Stat().CanceledAcquireCount()) })
+	counter("db_pool_acquire_wait_seconds_total", "Time spent waiting for a connection.",
+		func() float64 { return pool.Stat().EmptyAcquireWaitTime().Seconds() })
+
+	if s.Redis == nil {
+		return
+	}
+	redisClient := s.Redis
+	gauge("redis_pool_total_conns", "Open Redis connections.",
+		func() float64 { return float64(redisClient.PoolStats().TotalConns) })
+	gauge("redis_pool_idle_conns", "Idle Redis connections.",
+		func() float64 { return float64(redisClient.PoolStats().IdleConns) })
+	counter("redis_pool_hits_total", "Gets that found an idle Redis connection.",
+		func() float64 { return float64(redisClient.PoolStats().Hits) })
+	counter("redis_pool_misses_total", "Gets that had to dial a Redis connection.",
+		func() float64 { return float64(redisClient.PoolStats().Misses) })
+	counter("redis_pool_timeouts_total", "Gets that timed out waiting for a Redis connection.",
+		func() float64 { return float64(redisClient.PoolStats().Timeouts) })
+}
+
+// registerQueueMetrics exports asynq queue sizes per state. The numbers come
+// from Redis and are the same on every instance, so aggregate with max()
+// rather than sum() across instances.
+func (s *Server) registerQueueMetrics() {
+	if s.Job == nil || s.Job.Inspector == nil {
+		return
+	}
+	s.Metrics.MustRegister(&queueCollector{inspector: s.Job.Inspector})
+}
+
+var (
+	queueTasksDesc = prometheus.NewDesc("job_queue_tasks",
+		"Tasks per queue and state.", []string{"queue", "state"}, nil)
+	queueLatencyDesc = prometheus.NewDesc("job_queue_latency_seconds",
+		"Age of the oldest pending task per queue.", []string{"queue"}, nil)
+)
+
+// queueCollector reads the queue sizes from Redis on every scrape. A scrape
+// during a Redis outage exports no queue series rather than failing.
+type queueCollector struct {
+	inspector *asynq.Inspector
+}
+
+func (q *queueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queueTasksDesc
+	ch <- queueLatencyDesc
+}
+
+func (q *queueCollector) Collect(ch chan<- prometheus.Metric) {
+	queues, err := q.inspector.Queues()
+	if err != nil {
+		return
+	}
+	for _, queue := range queues {
+		info, err := q.inspector.GetQueueInfo(queue)
+		if err != nil {
+			continue
+		}
+		for state, size := range map[string]int{
+			"pending":   info.Pending,
+			"active":    info.Active,
+			"scheduled": info.Scheduled,
+			"retry":     info.Retry,
+			"archived":  info.Archived,
+			"completed": info.Completed,
+		} {
+			ch <- prometheus.MustNewConstMetric(queueTasksDesc, prometheus.GaugeValue, float64(size), queue, state)
+		}
+		ch <- prometheus.MustNewConstMetric(queueLatencyDesc, prometheus.GaugeValue, info.Latency.Seconds(), queue)
+	}
+}
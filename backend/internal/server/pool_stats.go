@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"time"
+)
+
+// DatabasePoolStats is a snapshot of pgxpool.Stat().
+type DatabasePoolStats struct {
+	AcquiredConns int32 `json:"acquired_conns"`
+	IdleConns     int32 `json:"idle_conns"`
+	TotalConns    int32 `json:"total_conns"`
+	MaxConns      int32 `json:"max_conns"`
+
+	// AcquireCount counts successful acquires; EmptyAcquireCount those that
+	// had to wait because no connection was idle.
+	AcquireCount         int64 `json:"acquire_count"`
+	EmptyAcquireCount    int64 `json:"empty_acquire_count"`
+	CanceledAcquireCount int64 `json:"canceled_acquire_count"`
+
+	// AcquireWait is the total time spent waiting for a connection.
+	AcquireWait string `json:"acquire_wait"`
+
+	// Utilization is AcquiredConns / MaxConns (0 to 1).
+	Utilization float64 `json:"utilization"`
+}
+
+// RedisPoolStats is a snapshot of redis.Client.PoolStats().
+type RedisPoolStats struct {
+	TotalConns uint32 `json:"total_conns"`
+	IdleConns  uint32 `json:"idle_conns"`
+	StaleConns uint32 `json:"stale_conns"`
+
+	// Hits and Misses count gets that found / didn't find an idle
+	// connection; Timeouts those that gave up waiting for one.
+	Hits     uint32 `json:"hits"`
+	Misses   uint32 `json:"misses"`
+	Timeouts uint32 `json:"timeouts"`
+}
+
+// PoolStats is the connection pool usage reported by the health endpoint.
+type PoolStats struct {
+	Database DatabasePoolStats `json:"database"`
+	Redis    *RedisPoolStats   `json:"redis,omitempty"`
+}
+
+// PoolStats reads the current database and Redis pool statistics. Both are
+// in-memory counters, so it is cheap enough to call on every health check.
+func (s *Server) PoolStats() PoolStats {
+	stat := s.DB.Pool.Stat()
+
+	stats := PoolStats{
+		Database: DatabasePoolStats{
+			AcquiredConns:        stat.AcquiredConns(),
+			IdleConns:            stat.IdleConns(),
+			TotalConns:           stat.TotalConns(),
+			MaxConns:             stat.MaxConns(),
+			AcquireCount:         stat.AcquireCount(),
+			EmptyAcquireCount:    stat.EmptyAcquireCount(),
+			CanceledAcquireCount: stat.CanceledAcquireCount(),
+			AcquireWait:          stat.EmptyAcquireWaitTime().String(),
+		},
+	}
+	if stat.MaxConns() > 0 {
+		stats.Database.Utilization = float64(stat.AcquiredConns()) / float64(stat.MaxConns())
+	}
+
+	if s.Redis != nil {
+		redisStat := s.Redis.PoolStats()
+		stats.Redis = &RedisPoolStats{
+			TotalConns: redisStat.TotalConns,
+			IdleConns:  redisStat.IdleConns,
+			StaleConns: redisStat.StaleConns,
+			Hits:       redisStat.Hits,
+			Misses:     redisStat.Misses,
+			Timeouts:   redisStat.Timeouts,
+		}
+	}
+
+	return stats
+}
+
+// setupPoolStatsRecorder reports the pool statistics to New Relic as custom
+// metrics (Custom/Pool/...) every observability.metrics.pool_stats_interval.
+// New Relic has no scrape endpoint, so unlike Prometheus (see
+// registerPoolMetrics) the values have to be pushed; alert on
+// Custom/Pool/Database/Utilization approaching 1 to catch exhaustion early.
+func (s *Server) setupPoolStatsRecorder() {
+	interval := s.Config.Observability.Metrics.PoolStatsInterval
+	if interval <= 0 || s.LoggerService == nil || s.LoggerService.GetApplication() == nil {
+		return
+	}
+	app := s.LoggerService.GetApplication()
+
+	stop := make(chan struct{})
+
+	s.OnStart("pool-stats", func(context.Context, *Server) error {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-stop:
+					return
+				case <-ticker.C:
+				}
+
+				stats := s.PoolStats()
+				db := stats.Database
+				app.RecordCustomMetric("Custom/Pool/Database/AcquiredConns", float64(db.AcquiredConns))
+				app.RecordCustomMetric("Custom/Pool/Database/IdleConns", float64(db.IdleConns))
+				app.RecordCustomMetric("Custom/Pool/Database/TotalConns", float64(db.TotalConns))
+				app.RecordCustomMetric("Custom/Pool/Database/Utilization", db.Utilization)
+				app.RecordCustomMetric("Custom/Pool/Database/EmptyAcquires", float64(db.EmptyAcquireCount))
+
+				if redisStats := stats.Redis; redisStats != nil {
+					app.RecordCustomMetric("Custom/Pool/Redis/TotalConns", float64(redisStats.TotalConns))
+					app.RecordCustomMetric("Custom/Pool/Redis/IdleConns", float64(redisStats.IdleConns))
+					app.RecordCustomMetric("Custom/Pool/Redis/Timeouts", float64(redisStats.Timeouts))
+				}
+			}
+		}()
+		return nil
+	})
+	s.OnShutdown("pool-stats", func(context.Context, *Server) error {
+		close(stop)
+		return nil
+	})
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"context"
+
+	"github.com/deppfellow/go-boilerplate/internal/config"
+	loggerPkg "github.com/deppfellow/go-boilerplate/internal/logger"
+)
+
+// setupConfigReload creates the config watcher, applies log level changes
+// and ties the watcher to the server lifecycle.
+//
+// Other reloadable settings are applied by their users, which subscribe to
+// ConfigWatcher (e.g. the CORS and rate limit middleware).
+func (s *Server) setupConfigReload() {
+	s.ConfigWatcher = config.NewWatcher(s.Config, s.Logger)
+
+	s.ConfigWatcher.Subscribe(func(change config.Change) {
+		if change.Has(config.ReloadLogLevel) {
+			loggerPkg.SetLevel(change.New.Observability.GetLogLevel())
+		}
+	})
+
+	s.OnStart("config-watcher", func(context.Context, *Server) error {
+		s.ConfigWatcher.Start()
+		return nil
+	})
+	s.OnShutdown("config-watcher", func(context.Context, *Server) error {
+		s.ConfigWatcher.Stop()
+		return nil
+	})
+}
+
+// CurrentConfig returns the config including reloaded settings. Config
+// itself is the startup snapshot and never changes.
+func (s *Server) CurrentConfig() *config.Config {
+	if s.ConfigWatcher == nil {
+		return s.Config
+	}
+	return s.ConfigWatcher.Current()
+}
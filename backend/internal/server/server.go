@@ -6,10 +6,22 @@
 // It owns the lifecycle of:
 //   - configuration
 //   - logger + optional New Relic service wrapper
+//   - APM tracer (New Relic, OTLP or none)
+//   - Prometheus metrics registry
 //   - database pool
 //   - redis client
 //   - background job worker server (asynq)
+//   - optional GeoIP database reader
+//   - maintenance-mode flag store
+//   - usage quota counters
+//   - dependency health tracking (graceful degradation)
+//   - config hot reload
+//   - in-process cache with Redis-propagated invalidation
+//   - optional search index client (Meilisearch / Elasticsearch)
+//   - optional embedding provider (pgvector)
+//   - optional Postgres LISTEN/NOTIFY listener
 //   - http.Server
+//   - optional diagnostics listener (pprof, expvar)
 //
 // It provides constructors and start/shutdown logic to run the application cleanly.
 package server
@@ -18,13 +30,28 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/deppfellow/go-boilerplate/internal/config"
 	"github.com/deppfellow/go-boilerplate/internal/database"
+	"github.com/deppfellow/go-boilerplate/internal/lib/backfill"
+	"github.com/deppfellow/go-boilerplate/internal/lib/cache"
+	"github.com/deppfellow/go-boilerplate/internal/lib/crypto"
+	"github.com/deppfellow/go-boilerplate/internal/lib/dependency"
+	"github.com/deppfellow/go-boilerplate/internal/lib/embedding"
+	"github.com/deppfellow/go-boilerplate/internal/lib/geoip"
+	"github.com/deppfellow/go-boilerplate/internal/lib/httpclient"
 	"github.com/deppfellow/go-boilerplate/internal/lib/job"
-	"github.com/newrelic/go-agent/v3/integrations/nrredis-v9"
+	"github.com/deppfellow/go-boilerplate/internal/lib/maintenance"
+	"github.com/deppfellow/go-boilerplate/internal/lib/quota"
+	"github.com/deppfellow/go-boilerplate/internal/lib/rpc"
+	"github.com/deppfellow/go-boilerplate/internal/lib/search"
+	"github.com/deppfellow/go-boilerplate/internal/lib/softdelete"
+	"github.com/deppfellow/go-boilerplate/internal/lib/tracing"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 
@@ -50,6 +77,14 @@ type Server struct {
 	// If New Relic is disabled, this may exist but contain nil nrApp.
 	LoggerService *loggerPkg.LoggerService
 
+	// Tracer is the APM provider selected by observability.tracing
+	// (see lib/tracing). Never nil: the "none" provider is a no-op.
+	Tracer tracing.Tracer
+
+	// Metrics is the Prometheus registry served on observability.metrics.path
+	// (see metrics.go). It is nil when metrics are disabled.
+	Metrics *prometheus.Registry
+
 	// DB holds the PostgreSQL pool wrapper.
 	DB *database.Database
 
@@ -60,8 +95,57 @@ type Server struct {
 	// It is configured in SetupHTTPServer and started in Start().
 	httpServer *http.Server
 
+	// redirectServer redirects plain HTTP to HTTPS when TLS is enabled with
+	// redirect_http (see tls.go); nil otherwise.
+	redirectServer *http.Server
+
+	// lifecycle holds the OnStart/OnReady/OnShutdown hooks (see lifecycle.go).
+	lifecycle lifecycle
+
 	// Job runs background workers (Asynq server) and provides a client for enqueueing.
 	Job *job.JobService
+
+	// GeoIP resolves client IPs to country/region.
+	// It is nil when GeoIP is disabled; a nil client is safe to call.
+	GeoIP *geoip.Client
+
+	// Maintenance reads/writes the Redis maintenance-mode flag.
+	// Shared by the middleware and the admin endpoint so toggles
+	// invalidate this instance's cache immediately.
+	Maintenance *maintenance.Store
+
+	// Quota holds per-user/API-key usage counters (see lib/quota).
+	Quota *quota.Store
+
+	// Cache is the namespaced read-through cache. Repository writes
+	// invalidate namespaces on every instance via Redis pub/sub.
+	Cache *cache.Cache
+
+	// Dependencies tracks dependency health; consult it via Require/Optional.
+	Dependencies *dependency.Tracker
+
+	// ConfigWatcher reloads non-critical settings on SIGHUP or config file
+	// changes. Read reloadable settings through CurrentConfig.
+	ConfigWatcher *config.Watcher
+
+	// RPC calls other services of the platform (see lib/rpc).
+	// It is nil when RPCConfig.Enabled is false.
+	RPC *rpc.Client
+
+	// Search queries the external search index, and SearchRunner syncs it
+	// (see lib/search). Both are nil when SearchConfig.Enabled is false.
+	Search       search.Indexer
+	SearchRunner *search.Runner
+
+	// Embeddings computes vector embeddings, and EmbeddingRunner stores them
+	// (see lib/embedding). Both are nil when EmbeddingConfig.Enabled is false.
+	Embeddings      embedding.Provider
+	EmbeddingRunner *embedding.Runner
+
+	// Listener dispatches Postgres NOTIFY events to handlers registered
+	// with Listener.Handle before Start. It is nil when
+	// database.listen.enabled is false.
+	Listener *database.Listener
 }
 
 // New constructs a Server and initializes core dependencies.
@@ -69,9 +153,11 @@ type Server struct {
 // It does NOT start the HTTP server directly. That is done in SetupHTTPServer + Start.
 //
 // Initialization performed:
-//   - PostgreSQL pool + optional New Relic tracing
-//   - Redis client + optional New Relic hooks
-//   - JobService (Asynq client/server) + start job worker
+//   - APM tracer (observability.tracing.provider)
+//   - PostgreSQL pool + optional query tracing
+//   - Redis client + optional tracing hook
+//   - JobService (Asynq client/server) + start job worker (worker service only,
+//     or the single binary when no service is set)
 //
 // Notes:
 //   - Redis connection failure does not block startup (it logs and continues).
@@ -79,7 +165,16 @@ type Server struct {
 func New(cfg *config.Config, logger *zerolog.Logger, loggerService *loggerPkg.LoggerService) (*Server, error) {
 	// Initialize PostgreSQL pool.
 	// This also pings the DB to ensure connectivity.
-	db, err := database.New(cfg, logger, loggerService)
+	var nrApp *newrelic.Application
+	if loggerService != nil {
+		nrApp = loggerService.GetApplication()
+	}
+	tracer, err := tracing.New(cfg.Observability, nrApp, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize tracing: %w", err)
+	}
+
+	db, err := database.New(cfg, logger, tracer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize database: %w", err)
 	}
@@ -90,12 +185,12 @@ func New(cfg *config.Config, logger *zerolog.Logger, loggerService *loggerPkg.Lo
 		Addr: cfg.Redis.Address,
 	})
 
-	// Add New Relic Redis hooks if New Relic is enabled.
+	// Add the APM Redis hook if the tracer has one.
 	//
 	// Hooks instrument Redis operations (commands timing, errors, etc.)
 	// so they show up in distributed traces.
-	if loggerService != nil && loggerService.GetApplication() != nil {
-		redisClient.AddHook(nrredis.NewHook(redisClient.Options()))
+	if hook := tracer.RedisHook(redisClient.Options()); hook != nil {
+		redisClient.AddHook(hook)
 	}
 
 	// Test Redis connection with a timeout so it doesn't hang startup.
@@ -118,6 +213,45 @@ func New(cfg *config.Config, logger *zerolog.Logger, loggerService *loggerPkg.Lo
 	// Important: as written, handlers rely on global emailClient in the job package.
 	jobService.InitHandlers(cfg, logger)
 
+	// Every task runs in its own trace (a span per task in the APM).
+	jobService.InitTracing(tracer)
+
+	// Dependency health tracker. Created before the job worker starts so
+	// email tasks can report provider failures to it from the first task.
+	dependencies := dependency.NewTracker(logger, dependency.DefaultInterval)
+	jobService.InitDependencies(dependencies)
+
+	// Backfill jobs need the database pool, which the job package doesn't own.
+	jobService.InitBackfills(backfill.NewRunner(db.Pool, logger))
+	jobService.InitPurger(softdelete.NewPurger(db.Pool, logger))
+
+	// Search indexing jobs, same reason. A bad provider config fails startup.
+	var (
+		searchIndexer search.Indexer
+		searchRunner  *search.Runner
+	)
+	if cfg.Search.Enabled {
+		searchIndexer, err = search.New(cfg.Search)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize search: %w", err)
+		}
+		searchRunner = search.NewRunner(searchIndexer, db.Pool, redisClient, logger, cfg.Search.BatchSize)
+		jobService.InitSearch(searchRunner)
+	}
+
+	var (
+		embeddingProvider embedding.Provider
+		embeddingRunner   *embedding.Runner
+	)
+	if cfg.Embedding.Enabled {
+		embeddingProvider, err = embedding.New(cfg.Embedding)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize embeddings: %w", err)
+		}
+		embeddingRunner = embedding.NewRunner(embeddingProvider, db.Pool, logger, cfg.Embedding.BatchSize)
+		jobService.InitEmbeddings(embeddingRunner)
+	}
+
 	// Start job server.
 	//
 	// Important behavior:
@@ -126,18 +260,89 @@ func New(cfg *config.Config, logger *zerolog.Logger, loggerService *loggerPkg.Lo
 	//
 	// Some people run Start() in a goroutine. If your version of Asynq blocks,
 	// you'll need to wrap it.
-	if err := jobService.Start(); err != nil {
-		return nil, err
+	//
+	// Only binaries that process jobs start the worker (config.Primary.RunsJobs);
+	// the others keep the client and just enqueue.
+	if cfg.Primary.RunsJobs() {
+		if err := jobService.Start(); err != nil {
+			return nil, err
+		}
 	}
 
+	// Field encryption keys for the encrypted column types (see lib/crypto).
+	// A malformed key fails startup rather than the first encrypted write.
+	if cfg.Encryption.Enabled {
+		keyring, err := crypto.New(cfg.Encryption)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize field encryption: %w", err)
+		}
+		crypto.SetDefault(keyring)
+	}
+
+	// Open the GeoIP database if enabled.
+	// A broken path is a configuration error, so fail startup loudly.
+	geoIPClient, err := geoip.NewClient(cfg.GeoIP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize geoip: %w", err)
+	}
+
+	// Local cache; invalidations from other instances arrive via Redis pub/sub.
+	appCache := cache.New(redisClient, logger)
+	appCache.Start()
+
 	// Construct the Server container.
 	server := &Server{
 		Config:        cfg,
 		Logger:        logger,
 		LoggerService: loggerService,
+		Tracer:        tracer,
 		DB:            db,
 		Redis:         redisClient,
 		Job:           jobService,
+		GeoIP:         geoIPClient,
+		Maintenance:   maintenance.NewStore(redisClient, cfg.Maintenance.CacheTTL),
+		Quota:         quota.NewStore(redisClient),
+		Cache:         appCache,
+		Dependencies:  dependencies,
+		Search:        searchIndexer,
+		SearchRunner:  searchRunner,
+
+		Embeddings:      embeddingProvider,
+		EmbeddingRunner: embeddingRunner,
+	}
+
+	if cfg.RPC.Enabled {
+		server.RPC = rpc.NewClient(cfg.Primary.Service, []byte(cfg.RPC.Secret), cfg.RPC.Services, cfg.RPC.TokenTTL, httpclient.Config{
+			Timeout:     cfg.RPC.Timeout,
+			MaxRetries:  cfg.RPC.MaxRetries,
+			BaseBackoff: httpclient.DefaultConfig().BaseBackoff,
+			MaxBackoff:  httpclient.DefaultConfig().MaxBackoff,
+		})
+	}
+
+	// LISTEN connection, opened on start once services registered handlers.
+	if cfg.Database.Listen.Enabled {
+		server.Listener = database.NewListener(cfg, logger)
+		server.OnStart("pg-listener", func(context.Context, *Server) error {
+			server.Listener.Start()
+			return nil
+		})
+		server.OnShutdown("pg-listener", func(context.Context, *Server) error {
+			server.Listener.Close()
+			return nil
+		})
+	}
+
+	server.setupMetrics()
+	server.setupPoolStatsRecorder()
+
+	server.registerDependencies()
+	server.Dependencies.Start()
+
+	server.setupConfigReload()
+
+	if err := server.setupDiagnostics(); err != nil {
+		return nil, err
 	}
 
 	// Runtime metrics comment:
@@ -164,11 +369,15 @@ func (s *Server) SetupHTTPServer(handler http.Handler) {
 		WriteTimeout: time.Duration(s.Config.Server.WriteTimeout) * time.Second,
 		IdleTimeout:  time.Duration(s.Config.Server.IdleTimeout) * time.Second,
 	}
+
+	// HTTPS and the HTTP->HTTPS redirect, when server.tls is enabled.
+	s.setupTLS()
 }
 
 // Start runs the HTTP server.
 //
-// It requires SetupHTTPServer to be called first.
+// It requires SetupHTTPServer to be called first. OnStart hooks run before
+// the listener opens and OnReady hooks right after (see lifecycle.go).
 func (s *Server) Start() error {
 	// Guard clause: without httpServer configured, Start can't run.
 	if s.httpServer == nil {
@@ -180,19 +389,40 @@ func (s *Server) Start() error {
 	s.Logger.Info().
 		Str("port", s.Config.Server.Port).
 		Str("env", s.Config.Primary.Env).
+		Str("service", s.Config.Primary.Service).
+		Bool("tls", s.Config.Server.TLS.Enabled).
 		Msg("starting server")
 
-	// ListenAndServe starts accepting requests.
-	// It blocks until the server stops or errors.
+	// Extensions start first; a failing OnStart hook aborts startup.
+	if err := s.runHooks(s.lifecycle.context(), PhaseStart, true); err != nil {
+		s.lifecycle.stop()
+		return err
+	}
+
+	// Listen and serve are split (instead of ListenAndServe) so OnReady hooks
+	// fire once the port actually accepts connections.
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		s.lifecycle.stop()
+		return fmt.Errorf("failed to listen on %s: %w", s.httpServer.Addr, err)
+	}
+
+	go func() {
+		_ = s.runHooks(s.lifecycle.context(), PhaseReady, false)
+	}()
+
+	// Serve blocks until the server stops or errors.
 	//
 	// If you want graceful shutdown, you call s.Shutdown(ctx) from a signal handler.
-	return s.httpServer.ListenAndServe()
+	return s.serve(listener)
 }
 
 // Shutdown gracefully shuts down the server and its dependencies.
 //
 // It attempts to:
 //   - stop HTTP server (finish inflight requests until ctx deadline)
+//     and the HTTP->HTTPS redirect listener
+//   - run OnShutdown hooks
 //   - close DB pool
 //   - stop job service (asynq) if it exists
 //
@@ -203,6 +433,14 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	if err := s.httpServer.Shutdown(ctx); err != nil {
 		return fmt.Errorf("failed to shutdown HTTP server: %w", err)
 	}
+	if err := s.shutdownRedirect(ctx); err != nil {
+		return err
+	}
+
+	// Extensions stop while the database, Redis and jobs are still up.
+	// Failures are logged by runHooks and don't block the rest of shutdown.
+	_ = s.runHooks(ctx, PhaseShutdown, false)
+	s.lifecycle.stop()
 
 	// Close database connection pool.
 	if err := s.DB.Close(); err != nil {
@@ -214,5 +452,27 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		s.Job.Stop()
 	}
 
+	// Stop dependency probes.
+	if s.Dependencies != nil {
+		s.Dependencies.Close()
+	}
+
+	// Stop listening for cache invalidations.
+	if s.Cache != nil {
+		s.Cache.Close()
+	}
+
+	// Release the GeoIP database (no-op when disabled).
+	if err := s.GeoIP.Close(); err != nil {
+		return fmt.Errorf("failed to close geoip database: %w", err)
+	}
+
+	// Flush buffered spans (OTLP); jobs have stopped, so none are added.
+	if s.Tracer != nil {
+		if err := s.Tracer.Shutdown(ctx); err != nil {
+			return fmt.Errorf("failed to flush traces: %w", err)
+		}
+	}
+
 	return nil
 }
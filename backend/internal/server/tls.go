@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// setupTLS prepares HTTPS on the main server and the optional HTTP redirect
+// listener, following config.TLSConfig. It is a no-op when TLS is disabled.
+func (s *Server) setupTLS() {
+	cfg := s.Config.Server.TLS
+	if !cfg.Enabled {
+		return
+	}
+
+	tlsConfig := &tls.Config{MinVersion: cfg.GetMinVersion()}
+
+	// Without autocert, certificates are loaded from files by ServeTLS.
+	var manager *autocert.Manager
+	if cfg.Autocert.Enabled {
+		manager = &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.Autocert.Hosts...),
+			Cache:      autocert.DirCache(cfg.Autocert.CacheDir),
+			Email:      cfg.Autocert.Email,
+		}
+		tlsConfig.GetCertificate = manager.GetCertificate
+		tlsConfig.NextProtos = []string{"h2", "http/1.1", "acme-tls/1"}
+	}
+	s.httpServer.TLSConfig = tlsConfig
+
+	if !cfg.RedirectHTTP {
+		return
+	}
+
+	redirect := redirectToHTTPS(s.Config.Server.Port)
+	if manager != nil {
+		// http-01 challenges arrive on the redirect listener; everything
+		// else still gets the port-aware redirect (HTTPHandler(nil) would
+		// redirect to the default HTTPS port instead).
+		redirect = manager.HTTPHandler(redirect)
+	}
+
+	s.redirectServer = &http.Server{
+		Addr:              ":" + cfg.GetHTTPPort(),
+		Handler:           redirect,
+		ReadHeaderTimeout: 10 * time.Second,
+		IdleTimeout:       s.httpServer.IdleTimeout,
+	}
+}
+
+// serve runs the main server on listener, with TLS when configured, and the
+// redirect listener next to it.
+func (s *Server) serve(listener net.Listener) error {
+	cfg := s.Config.Server.TLS
+	if !cfg.Enabled {
+		return s.httpServer.Serve(listener)
+	}
+
+	if s.redirectServer != nil {
+		go func() {
+			if err := s.redirectServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				s.Logger.Error().Err(err).Str("addr", s.redirectServer.Addr).Msg("HTTP redirect listener stopped")
+			}
+		}()
+	}
+
+	// Empty file names make ServeTLS use TLSConfig.GetCertificate (autocert).
+	return s.httpServer.ServeTLS(listener, cfg.CertFile, cfg.KeyFile)
+}
+
+// shutdownRedirect stops the redirect listener, if any.
+func (s *Server) shutdownRedirect(ctx context.Context) error {
+	if s.redirectServer == nil {
+		return nil
+	}
+	if err := s.redirectServer.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to shutdown HTTP redirect listener: %w", err)
+	}
+	return nil
+}
+
+// redirectToHTTPS permanently redirects to the same URL over HTTPS, on
+// httpsPort (omitted when it is the default 443).
+func redirectToHTTPS(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+
+		// 308 keeps the method and body, unlike 301, for API clients.
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
@@ -53,6 +53,11 @@ func NewService(s *server.Server, repos *repository.Repositories) (*Services, er
 
 	// Job service is already created and started inside server.New(...),
 	// so we reuse the instance from Server here.
+	//
+	// Audit entries are enqueued by the audit middleware and written here,
+	// the first place where both the job service and repositories exist.
+	s.Job.InitAuditWriter(repos.Audit)
+
 	return &Services{
 		Job:  s.Job,
 		Auth: authService,
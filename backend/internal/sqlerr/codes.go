@@ -0,0 +1,66 @@
+package sqlerr
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/lib/inflect"
+)
+
+// RegisterTable declares the codes HandleError and NotFound generate for
+// table in the error catalog (see errs.Registry), so they are part of the
+// documented contract:
+//
+//	func init() {
+//		sqlerr.RegisterTable("companies") // COMPANY_NOT_FOUND, COMPANY_ALREADY_EXISTS...
+//	}
+//
+// A foreign key violation reuses <DOMAIN>_NOT_FOUND with a 400 (the
+// referenced row is missing); the catalog documents the 404 of NotFound.
+func RegisterTable(table string) {
+	domain := strings.ToUpper(inflect.Singular(table))
+	name := getEntityName(table, "")
+	entity := strings.ToLower(name)
+
+	for _, entry := range []errs.CatalogEntry{
+		{Code: domain + "_NOT_FOUND", Status: http.StatusNotFound, Description: "The " + entity + " does not exist.", Message: name + " not found"},
+		{Code: domain + "_ALREADY_EXISTS", Status: http.StatusBadRequest, Description: "A " + entity + " with the same unique key exists; see errors for the fields."},
+		{Code: domain + "_REQUIRED", Status: http.StatusBadRequest, Description: "A required " + entity + " field is missing; see errors."},
+		{Code: domain + "_INVALID", Status: http.StatusBadRequest, Description: "A " + entity + " value breaks a check constraint."},
+		{Code: domain + "_CONFLICT", Status: http.StatusConflict, Description: "The " + entity + " overlaps an existing one (exclusion constraint)."},
+	} {
+		errs.RegisterCode(entry)
+	}
+}
+
+// registerConstraintCode declares the code of a registered constraint, with
+// the status constraintError answers for its kind of violation.
+func registerConstraintCode(c Constraint) {
+	if c.Code == "" {
+		return
+	}
+	errs.RegisterCode(errs.CatalogEntry{
+		Code:    c.Code,
+		Status:  constraintStatus(c),
+		Message: c.Message,
+	})
+}
+
+// constraintStatus is the status of a violation of c: 409 for exclusion
+// constraints, 400 for the others.
+func constraintStatus(c Constraint) int {
+	kind := c.Kind
+	if kind == "" && strings.HasSuffix(c.Name, "_excl") {
+		kind = ExcludeViolation
+	}
+	if kind == ExcludeViolation {
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
+
+// Codes generated when the table is unknown.
+func init() {
+	RegisterTable("record")
+}
@@ -0,0 +1,149 @@
+package sqlerr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+)
+
+// Constraint maps one named constraint (or unique index) to the error the
+// client gets when a statement violates it.
+//
+// HandleError otherwise guesses the error from the table and constraint
+// names, which goes wrong for irregular plurals ("companies" => "COMPANIE"),
+// expression indexes (lower(email)) and constraints spanning several
+// columns. Register the constraints your UI depends on:
+//
+//	func init() {
+//		sqlerr.RegisterConstraint(sqlerr.Constraint{
+//			Name:    "companies_slug_key",
+//			Code:    "COMPANY_SLUG_TAKEN",
+//			Fields:  []string{"slug"},
+//			Message: "This company URL is already taken",
+//		})
+//	}
+//
+// Empty fields keep what the heuristics produce; for unique violations that
+// includes the fields, one per column of the key.
+type Constraint struct {
+	// Name is the constraint or index name Postgres reports.
+	Name string
+
+	// Code is the machine-readable error code (e.g. "USER_EMAIL_TAKEN").
+	Code string
+
+	// Fields are the request fields the violation is reported on, as
+	// field errors, so forms can highlight them.
+	Fields []string
+
+	// FieldError is the error of each field (e.g. "is already taken").
+	// Defaults to a text matching the kind of violation.
+	FieldError string
+
+	// Message is the client-facing message.
+	Message string
+
+	// Kind is the violation the constraint reports (e.g. ExcludeViolation).
+	// It sets the status Code is cataloged with; when empty it is inferred
+	// from Name (Postgres names exclusion constraints "..._excl").
+	Kind Code
+}
+
+var (
+	constraintsMu sync.RWMutex
+	constraints   = map[string]Constraint{}
+)
+
+// RegisterConstraint adds a constraint mapping and declares its code in the
+// error catalog. It panics on duplicates, as registrations happen at init
+// time.
+func RegisterConstraint(c Constraint) {
+	constraintsMu.Lock()
+	defer constraintsMu.Unlock()
+
+	if c.Name == "" {
+		panic("sqlerr: constraint registered without a name")
+	}
+	if _, exists := constraints[c.Name]; exists {
+		panic(fmt.Sprintf("sqlerr: constraint %q registered twice", c.Name))
+	}
+	constraints[c.Name] = c
+
+	registerConstraintCode(c)
+}
+
+// LookupConstraint returns a registered constraint mapping.
+func LookupConstraint(name string) (Constraint, bool) {
+	constraintsMu.RLock()
+	defer constraintsMu.RUnlock()
+
+	c, ok := constraints[name]
+	return c, ok
+}
+
+// ConstraintNames lists registered constraints.
+func ConstraintNames() []string {
+	constraintsMu.RLock()
+	defer constraintsMu.RUnlock()
+
+	names := make([]string, 0, len(constraints))
+	for name := range constraints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// defaultFieldErrors are the field error texts per kind of violation.
+var defaultFieldErrors = map[Code]string{
+	ForeignKeyViolation: "does not exist",
+	UniqueViolation:     "already exists",
+	NotNullViolation:    "is required",
+	CheckViolation:      "is invalid",
+	ExcludeViolation:    "conflicts with an existing record",
+}
+
+// constraintError builds the error of a violation of a registered
+// constraint; errorCode and userMessage are the heuristic fallbacks.
+func constraintError(sqlErr *Error, c Constraint, errorCode, userMessage string) error {
+	// Without registered fields, a unique violation still names its columns.
+	if len(c.Fields) == 0 && sqlErr.Code == UniqueViolation {
+		c.Fields = extractColumnsForUniqueViolation(sqlErr)
+	}
+
+	if c.Code != "" {
+		errorCode = c.Code
+	}
+
+	message := c.Message
+	if message == "" {
+		message = userMessage
+		if len(c.Fields) > 0 {
+			humanized := make([]string, len(c.Fields))
+			for i, field := range c.Fields {
+				humanized[i] = humanizeText(field)
+			}
+			message = strings.ReplaceAll(message, "identifier", strings.Join(humanized, " and "))
+		}
+	}
+
+	fieldError := c.FieldError
+	if fieldError == "" {
+		fieldError = defaultFieldErrors[sqlErr.Code]
+	}
+
+	var fieldErrors []errs.FieldError
+	for _, field := range c.Fields {
+		fieldErrors = append(fieldErrors, errs.FieldError{Field: field, Error: fieldError})
+	}
+
+	if sqlErr.Code == ExcludeViolation {
+		httpErr := errs.NewConflictError(message, true, &errorCode)
+		httpErr.Errors = fieldErrors
+		return httpErr
+	}
+	return errs.NewBadRequestError(message, true, &errorCode, fieldErrors, nil)
+}
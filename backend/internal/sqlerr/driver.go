@@ -0,0 +1,137 @@
+package sqlerr
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Driver maps the errors of one database driver into the sqlerr taxonomy,
+// so HandleError, ErrCode and IsRetryable work the same whatever database
+// a repository talks to.
+//
+// Postgres (pgx), MySQL (github.com/go-sql-driver/mysql) and SQLite
+// (github.com/mattn/go-sqlite3 and modernc.org/sqlite) are built in. The
+// MySQL and SQLite drivers recognize errors by the package of their type,
+// so sqlerr doesn't import (and link) drivers the application doesn't use.
+type Driver interface {
+	// Name identifies the driver ("postgres", "mysql", "sqlite").
+	Name() string
+
+	// Convert returns err as an *Error, or nil when err (and the errors it
+	// wraps) doesn't come from this driver.
+	Convert(err error) *Error
+}
+
+var (
+	driversMu sync.RWMutex
+	drivers   = []Driver{postgresDriver{}, mysqlDriver{}, sqliteDriver{}}
+)
+
+// RegisterDriver adds a driver, tried after the built-in ones. It panics on
+// duplicate names, as registrations happen at init time.
+func RegisterDriver(d Driver) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+
+	for _, existing := range drivers {
+		if existing.Name() == d.Name() {
+			panic(fmt.Sprintf("sqlerr: driver %q registered twice", d.Name()))
+		}
+	}
+	drivers = append(drivers, d)
+}
+
+// Convert detects the driver err comes from and returns it as an *Error.
+// An *Error already in err's chain is returned as is. It reports false for
+// errors no driver recognizes (pgx.ErrNoRows, context errors...); errors
+// reaching the database are reported as ConnectionFailure.
+func Convert(err error) (*Error, bool) {
+	if err == nil {
+		return nil, false
+	}
+
+	var sqlErr *Error
+	if errors.As(err, &sqlErr) {
+		return sqlErr, true
+	}
+
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+
+	for _, d := range drivers {
+		if converted := d.Convert(err); converted != nil {
+			return converted, true
+		}
+	}
+	return connectionError(err)
+}
+
+// connectionError recognizes failures to reach the database, which the
+// client side reports (pgx, database/sql) rather than the database. Bare
+// network errors are left alone: they may come from any other service.
+func connectionError(err error) (*Error, bool) {
+	var connectErr *pgconn.ConnectError
+	switch {
+	case errors.As(err, &connectErr), errors.Is(err, driver.ErrBadConn):
+		return &Error{
+			Code:      ConnectionFailure,
+			Severity:  SeverityError,
+			Message:   err.Error(),
+			driverErr: err,
+		}, true
+	}
+	return nil, false
+}
+
+// postgresDriver converts pgconn.PgError (pgx).
+type postgresDriver struct{}
+
+func (postgresDriver) Name() string { return "postgres" }
+
+func (postgresDriver) Convert(err error) *Error {
+	var pgerr *pgconn.PgError
+	if errors.As(err, &pgerr) {
+		return ConvertPgError(pgerr)
+	}
+	return nil
+}
+
+// findDriverError returns the first error in err's chain whose type is
+// declared in package pkgPath, dereferenced if it is a pointer.
+func findDriverError(err error, pkgPath string) (error, reflect.Value, bool) {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		v := reflect.ValueOf(e)
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				continue
+			}
+			v = v.Elem()
+		}
+		if v.Type().PkgPath() == pkgPath {
+			return e, v, true
+		}
+	}
+	return nil, reflect.Value{}, false
+}
+
+// intField reads an integer field of a driver error struct by name.
+func intField(v reflect.Value, name string) (int64, bool) {
+	if v.Kind() != reflect.Struct {
+		return 0, false
+	}
+	f := v.FieldByName(name)
+	switch {
+	case !f.IsValid():
+		return 0, false
+	case f.CanInt():
+		return f.Int(), true
+	case f.CanUint():
+		return int64(f.Uint()), true
+	}
+	return 0, false
+}
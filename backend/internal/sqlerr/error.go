@@ -2,7 +2,10 @@
 //
 // It parses cryptic error codes from the database driver and
 // converts them into user-friendly messages (e.g., converting
-// a "foreign key violation" into a "Bad Request" error)
+// a "foreign key violation" into a "Bad Request" error).
+//
+// Postgres, MySQL and SQLite errors map into the same Code taxonomy (see
+// Driver), so the handling doesn't depend on the database.
 package sqlerr
 
 // Code describes a specific type of database error.
@@ -37,13 +40,27 @@ const (
 	// can be detected.
 	DeadlockDetected Code = "deadlock_detected"
 
+	// SerializationFailure is reported when a SERIALIZABLE or REPEATABLE READ
+	// transaction conflicts with a concurrent one; rerunning it succeeds.
+	SerializationFailure Code = "serialization_failure"
+
 	// TooManyConnections is reported when the database rejects a connection request
 	// due to reaching the maximum number of connections.
 	// This is different from blocking waiting on a connection pool.
 	TooManyConnections Code = "too_many_connections"
+
+	// QueryCanceled is reported when a statement was canceled, by
+	// statement_timeout or a canceled client context.
+	QueryCanceled Code = "query_canceled"
+
+	// ConnectionFailure is reported when the connection to the database could
+	// not be established or was lost (SQLSTATE class 08, server shutdown,
+	// refused or broken connections).
+	ConnectionFailure Code = "connection_failure"
 )
 
-// MapCode maps an underlying database error to a Code.
+// MapCode maps a Postgres SQLSTATE to a Code (see Driver for the other
+// databases).
 func MapCode(code string) Code {
 	switch code {
 	case "23502":
@@ -58,10 +75,16 @@ func MapCode(code string) Code {
 		return ExcludeViolation
 	case "25P02":
 		return TransactionFailed
+	case "40001":
+		return SerializationFailure
 	case "40P01":
 		return DeadlockDetected
 	case "53300":
 		return TooManyConnections
+	case "57014":
+		return QueryCanceled
+	case "08000", "08001", "08003", "08004", "08006", "57P01", "57P02", "57P03":
+		return ConnectionFailure
 	default:
 		return Other
 	}
@@ -133,6 +156,10 @@ type Error struct {
 	// the name of the column.
 	ColumnName string
 
+	// Columns: the columns of a multi-column violation, when the driver
+	// names them outside Detail (SQLite).
+	Columns []string
+
 	// DataTypeName: if the error was associated with a specific data type,
 	// the name of the data type.
 	DataTypeName string
@@ -141,6 +168,10 @@ type Error struct {
 	// the name of the constraint.
 	ConstraintName string
 
+	// Detail: secondary message carrying details about the problem, e.g.
+	// "Key (email)=(a@example.com) already exists." for unique violations.
+	Detail string
+
 	// driverErr is the underlying error from the driver.
 	driverErr error
 }
@@ -1,6 +1,7 @@
 package sqlerr
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/lib/inflect"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -18,16 +20,16 @@ import (
 // ErrCode reports the mapped sqlerr.Code for a given error.
 //
 // Behavior:
-//   - If err can be unwrapped into *sqlerr.Error, return its Code.
+//   - If err can be unwrapped into *sqlerr.Error, or a driver error, return its Code.
 //   - Otherwise return sqlerr.Other.
 //
 // This is useful if you already converted/normalized errors into sqlerr.Error
 // and want to quickly check their category.
 func ErrCode(err error) Code {
-	var pgerr *Error
-	// errors.As walks the error chain (using Unwrap()) and tries to find *Error.
-	if errors.As(err, &pgerr) {
-		return pgerr.Code
+	// Convert walks the error chain (using Unwrap()) for an *Error or a
+	// driver error it can map (see Driver).
+	if sqlErr, ok := Convert(err); ok {
+		return sqlErr.Code
 	}
 	return Other
 }
@@ -51,6 +53,7 @@ func ConvertPgError(src *pgconn.PgError) *Error {
 		ColumnName:     src.ColumnName,
 		DataTypeName:   src.DataTypeName,
 		ConstraintName: src.ConstraintName,
+		Detail:         src.Detail,
 		driverErr:      src, // store original for Unwrap() and debugging
 	}
 }
@@ -66,7 +69,8 @@ func ConvertPgError(src *pgconn.PgError) *Error {
 //	users + UniqueViolation => USER_ALREADY_EXISTS
 //
 // Rules:
-//   - DOMAIN comes from tableName (uppercased, singularized crudely by removing trailing 'S')
+//   - DOMAIN comes from tableName, singularized (see lib/inflect) and uppercased:
+//     companies => COMPANY, people => PERSON
 //   - ACTION depends on violation type
 //
 // These codes are meant for machines (frontend logic, analytics), not humans.
@@ -76,14 +80,7 @@ func generateErrorCode(tableName string, errType Code) string {
 		tableName = "RECORD"
 	}
 
-	domain := strings.ToUpper(tableName)
-
-	// Very naive singularization:
-	// "USERS" -> "USER"
-	// It won't handle "companies" etc, but good enough for many schemas.
-	if strings.HasSuffix(domain, "S") && len(domain) > 1 {
-		domain = domain[:len(domain)-1]
-	}
+	domain := strings.ToUpper(inflect.Singular(tableName))
 
 	// Decide what kind of "action" code to generate.
 	action := "ERROR"
@@ -96,6 +93,8 @@ func generateErrorCode(tableName string, errType Code) string {
 		action = "REQUIRED"
 	case CheckViolation:
 		action = "INVALID"
+	case ExcludeViolation:
+		action = "CONFLICT"
 	}
 
 	return fmt.Sprintf("%s_%s", domain, action)
@@ -137,6 +136,12 @@ func formatUserFriendlyMessage(sqlErr *Error) string {
 		}
 		return "One or more values do not meet required conditions"
 
+	case ExcludeViolation:
+		// Exclusion constraints reject overlaps, e.g. two bookings of a room
+		// for intersecting time ranges.
+		// Example: "The Booking conflicts with an existing one"
+		return fmt.Sprintf("The %s conflicts with an existing one", entityName)
+
 	default:
 		// Fallback for unknown DB errors.
 		return "An error occurred while processing your request"
@@ -148,7 +153,7 @@ func formatUserFriendlyMessage(sqlErr *Error) string {
 // Priority rules:
 //  1. If column ends with "_id", use that base name. (Best for FK relations)
 //     e.g. "user_id" -> "User"
-//  2. Otherwise use table name, singularized if it ends with "s".
+//  2. Otherwise use table name, singularized (see lib/inflect).
 //  3. Otherwise fallback to "record".
 func getEntityName(tableName, columnName string) string {
 	// Most reliable for foreign keys: column like "user_id".
@@ -159,11 +164,7 @@ func getEntityName(tableName, columnName string) string {
 
 	// Fallback: table name.
 	if tableName != "" {
-		entity := tableName
-		if strings.HasSuffix(entity, "s") && len(entity) > 1 {
-			entity = entity[:len(entity)-1]
-		}
-		return humanizeText(entity)
+		return humanizeText(inflect.Singular(tableName))
 	}
 
 	return "record"
@@ -183,7 +184,45 @@ func humanizeText(text string) string {
 	return cases.Title(language.English).String(strings.ReplaceAll(text, "_", " "))
 }
 
-// extractColumnForUniqueViolation tries to infer the column name from a unique constraint name.
+// partialIndexSuffixes are name suffixes conventionally given to partial
+// unique indexes used with soft deletes (CREATE UNIQUE INDEX users_email_active_key
+// ON users (email) WHERE deleted_at IS NULL). They describe the predicate, not
+// the column, so they are stripped before inferring the column name.
+var partialIndexSuffixes = []string{"_not_deleted", "_undeleted", "_active", "_live"}
+
+// extractColumnsForUniqueViolation returns all the columns of a unique
+// violation, in index order, so composite keys name every field involved.
+//
+// Sources, most precise first:
+//
+//  1. Postgres' detail, exact for composite, partial and expression indexes:
+//     Key (org_id, slug)=(42, home) already exists. -> ["org_id", "slug"]
+//     Key (lower(email::text))=(a@b.c) already exists. -> ["email"]
+//
+//  2. The columns the driver reported (SQLite names them in its message).
+//
+//  3. The constraint name, see columnFromConstraintName.
+func extractColumnsForUniqueViolation(sqlErr *Error) []string {
+	if m := uniqueDetailPattern.FindStringSubmatch(sqlErr.Detail); m != nil {
+		columns := splitKeyList(m[1])
+		// Expression indexes render as e.g. "lower(email::text)"; keep the bare column.
+		for i, column := range columns {
+			columns[i] = expressionColumn(column)
+		}
+		return columns
+	}
+
+	if len(sqlErr.Columns) > 0 {
+		return sqlErr.Columns
+	}
+
+	if column := columnFromConstraintName(sqlErr.TableName, sqlErr.ConstraintName); column != "" {
+		return []string{column}
+	}
+	return nil
+}
+
+// columnFromConstraintName tries to infer the column name from a unique constraint name.
 //
 // It supports two conventions:
 //
@@ -192,11 +231,28 @@ func humanizeText(text string) string {
 //
 //  2. "<table>_<column>_(key|ukey)"
 //     Example: users_email_key -> "email"
-func extractColumnForUniqueViolation(constraintName string) string {
+//     With the table known, multi-word columns survive:
+//     users_external_id_key -> "external_id"
+//
+// Both may carry a partial-index suffix before the key suffix, e.g.
+// users_email_active_key -> "email".
+//
+// A name can't tell the columns of a composite key apart (users_org_id_slug_key),
+// which is why the detail is preferred.
+func columnFromConstraintName(tableName, constraintName string) string {
 	if constraintName == "" {
 		return ""
 	}
 
+	for _, keySuffix := range []string{"_key", "_ukey", ""} {
+		for _, suffix := range partialIndexSuffixes {
+			if trimmed, ok := strings.CutSuffix(constraintName, suffix+keySuffix); ok {
+				constraintName = trimmed + keySuffix
+				break
+			}
+		}
+	}
+
 	// Convention 1: unique_table_column
 	if strings.HasPrefix(constraintName, "unique_") {
 		parts := strings.Split(constraintName, "_")
@@ -206,9 +262,19 @@ func extractColumnForUniqueViolation(constraintName string) string {
 		}
 	}
 
+	// Convention 2 with the table known: strip it and the key suffix.
+	if tableName != "" {
+		if rest, ok := strings.CutPrefix(constraintName, tableName+"_"); ok {
+			for _, keySuffix := range []string{"_key", "_ukey"} {
+				if column, ok := strings.CutSuffix(rest, keySuffix); ok && column != "" {
+					return column
+				}
+			}
+		}
+	}
+
 	// Convention 2: table_column_key or table_column_ukey
-	re := regexp.MustCompile(`_([^_]+)_(?:key|ukey)$`)
-	matches := re.FindStringSubmatch(constraintName)
+	matches := constraintColumnPattern.FindStringSubmatch(constraintName)
 	if len(matches) > 1 {
 		return matches[1]
 	}
@@ -216,14 +282,25 @@ func extractColumnForUniqueViolation(constraintName string) string {
 	return ""
 }
 
+// constraintColumnPattern matches the last word before a key suffix.
+var constraintColumnPattern = regexp.MustCompile(`_([^_]+)_(?:key|ukey)$`)
+
 // HandleError converts a low-level database error into an application-level error.
 //
 // Output:
 //   - If already *errs.HTTPError: returned unchanged
-//   - If pgconn.PgError: mapped into a specific errs.NewBadRequestError or errs.NewInternalServerError
-//   - If ErrNoRows: mapped to errs.NewNotFoundError
+//   - If a database error (see Convert): mapped into a specific errs.NewBadRequestError or errs.NewInternalServerError,
+//     using the registered mapping of the violated constraint if any (see RegisterConstraint)
+//   - If ErrNoRows: mapped to errs.NewNotFoundError (see HandleErrorFor to name the entity)
+//   - If an exclusion violation: 409 Conflict
+//   - If a deadlock, serialization failure, connection failure or too many
+//     connections: 503 with a retry_after action (see IsRetryable)
+//   - If a query timeout (57014 or context deadline): errs.NewDeadlineExceededError
 //   - Otherwise: errs.NewInternalServerError
 //
+// The converted error carries err as its cause (errs.HTTPError.WithCause), so
+// the global error handler can still log what the database said.
+//
 // This function is intended to be called in repositories/services after a DB call fails.
 func HandleError(err error) error {
 	// If it's already an HTTPError, don't re-wrap it.
@@ -233,14 +310,25 @@ func HandleError(err error) error {
 		return err
 	}
 
-	// Handle Postgres server errors (constraint violations, etc.)
+	return withCause(convertError(err), err)
+}
+
+// withCause attaches cause to converted, an *errs.HTTPError.
+func withCause(converted, cause error) error {
+	var httpErr *errs.HTTPError
+	if errors.As(converted, &httpErr) {
+		return httpErr.WithCause(cause)
+	}
+	return converted
+}
+
+// convertError implements HandleError for errors that aren't HTTPErrors yet.
+func convertError(err error) error {
+	// Handle database server errors (constraint violations, etc.)
 	//
-	// pgconn.PgError is the primary Postgres error type from pgx.
-	// It includes SQLSTATE and metadata.
-	var pgerr *pgconn.PgError
-	if errors.As(err, &pgerr) {
-		// Convert into our structured error.
-		sqlErr := ConvertPgError(pgerr)
+	// Convert detects the driver (pgx's pgconn.PgError, MySQL, SQLite) and
+	// maps its error into our structured error.
+	if sqlErr, ok := Convert(err); ok {
 
 		// Create:
 		// - a machine-friendly error code (e.g. USER_ALREADY_EXISTS)
@@ -248,6 +336,14 @@ func HandleError(err error) error {
 		errorCode := generateErrorCode(sqlErr.TableName, sqlErr.Code)
 		userMessage := formatUserFriendlyMessage(sqlErr)
 
+		// Registered constraints (see RegisterConstraint) take precedence
+		// over the name heuristics.
+		if _, violation := defaultFieldErrors[sqlErr.Code]; violation {
+			if c, ok := LookupConstraint(sqlErr.ConstraintName); ok {
+				return constraintError(sqlErr, c, errorCode, userMessage)
+			}
+		}
+
 		switch sqlErr.Code {
 		case ForeignKeyViolation:
 			// Foreign key violation usually means reference doesn't exist.
@@ -256,14 +352,24 @@ func HandleError(err error) error {
 
 		case UniqueViolation:
 			// Unique violation means already exists.
-			// Try to infer which column caused it and inject into message.
-			columnName := extractColumnForUniqueViolation(sqlErr.ConstraintName)
-			if columnName != "" {
-				// Replace "identifier" placeholder with actual field name.
-				userMessage = strings.ReplaceAll(userMessage, "identifier", humanizeText(columnName))
+			// Name the conflicting column(s), one field error each, so forms
+			// highlight every field of a composite key.
+			columnNames := extractColumnsForUniqueViolation(sqlErr)
+			var fieldErrors []errs.FieldError
+			if len(columnNames) > 0 {
+				// Replace "identifier" placeholder with actual field name(s).
+				humanized := make([]string, len(columnNames))
+				for i, column := range columnNames {
+					humanized[i] = humanizeText(column)
+					fieldErrors = append(fieldErrors, errs.FieldError{
+						Field: strings.ToLower(column),
+						Error: defaultFieldErrors[UniqueViolation],
+					})
+				}
+				userMessage = strings.ReplaceAll(userMessage, "identifier", strings.Join(humanized, " and "))
 			}
 			// override=true here suggests you want client UI to show this message directly.
-			return errs.NewBadRequestError(userMessage, true, &errorCode, nil, nil)
+			return errs.NewBadRequestError(userMessage, true, &errorCode, fieldErrors, nil)
 
 		case NotNullViolation:
 			// Not-null violation maps nicely to field-level errors for forms.
@@ -279,25 +385,46 @@ func HandleError(err error) error {
 			// CHECK constraint failures are also usually bad request.
 			return errs.NewBadRequestError(userMessage, true, &errorCode, nil, nil)
 
+		case ExcludeViolation:
+			// The row overlaps an existing one: a conflict with current state.
+			return errs.NewConflictError(userMessage, true, &errorCode)
+
+		case DeadlockDetected, SerializationFailure:
+			// A concurrent transaction won (repository.WithRetry already
+			// retried, if used); the request itself is fine.
+			return transientError("TRANSACTION_CONFLICT", transactionConflictMessage, conflictRetryAfter)
+
+		case TooManyConnections, ConnectionFailure:
+			return transientError("DATABASE_UNAVAILABLE", databaseUnavailableMessage, unavailableRetryAfter)
+
+		case QueryCanceled:
+			// statement_timeout (or the query timeout) ran out.
+			return errs.NewDeadlineExceededError("The database took too long to respond, please retry later")
+
 		default:
 			// Unknown/other DB errors should not leak details to clients.
 			return errs.NewInternalServerError()
 		}
 	}
 
+	// A query timeout (repository.Base.Querier) ends the query on the client
+	// side before Postgres reports anything.
+	if errors.Is(err, context.DeadlineExceeded) {
+		return errs.NewDeadlineExceededError("The database took too long to respond, please retry later")
+	}
+
 	// Handle "no rows found" errors (common for SELECT queries).
 	// Both pgx and database/sql define ErrNoRows.
 	switch {
 	case errors.Is(err, pgx.ErrNoRows), errors.Is(err, sql.ErrNoRows):
-		// The code tries to infer entity name from the error message.
-		// This is brittle: error strings are not a stable API.
+		// Legacy: infer the entity from a "table:<name>:" marker in the
+		// error message. Error strings are not a stable API; repositories
+		// should call HandleErrorFor or NotFound with the table they queried.
 		errMsg := err.Error()
 		tablePrefix := "table:"
 		if strings.Contains(errMsg, tablePrefix) {
-			// If error message includes "table:<name>:", extract the name.
 			table := strings.Split(strings.Split(errMsg, tablePrefix)[1], ":")[0]
-			entityName := getEntityName(table, "")
-			return errs.NewNotFoundError(fmt.Sprintf("%s not found", entityName), true, nil)
+			return NotFound(table)
 		}
 		// Generic not found fallback.
 		return errs.NewNotFoundError("Resource not found", false, nil)
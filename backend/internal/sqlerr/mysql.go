@@ -0,0 +1,99 @@
+package sqlerr
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// mysqlPackage declares *mysql.MySQLError{Number uint16, SQLState [5]byte,
+// Message string}.
+const mysqlPackage = "github.com/go-sql-driver/mysql"
+
+// MySQL server error numbers mapped to a Code.
+var mysqlCodes = map[int64]Code{
+	1048: NotNullViolation,     // ER_BAD_NULL_ERROR
+	1364: NotNullViolation,     // ER_NO_DEFAULT_FOR_FIELD
+	1062: UniqueViolation,      // ER_DUP_ENTRY
+	1586: UniqueViolation,      // ER_DUP_ENTRY_WITH_KEY_NAME
+	1216: ForeignKeyViolation,  // ER_NO_REFERENCED_ROW
+	1217: ForeignKeyViolation,  // ER_ROW_IS_REFERENCED
+	1451: ForeignKeyViolation,  // ER_ROW_IS_REFERENCED_2
+	1452: ForeignKeyViolation,  // ER_NO_REFERENCED_ROW_2
+	3819: CheckViolation,       // ER_CHECK_CONSTRAINT_VIOLATED
+	1213: DeadlockDetected,     // ER_LOCK_DEADLOCK
+	1040: TooManyConnections,   // ER_CON_COUNT_ERROR
+	1317: QueryCanceled,        // ER_QUERY_INTERRUPTED
+	3024: QueryCanceled,        // ER_QUERY_TIMEOUT (max_execution_time)
+	1180: TransactionFailed,    // ER_ERROR_DURING_COMMIT
+	1637: TransactionFailed,    // ER_TOO_MANY_CONCURRENT_TRXS
+	1614: SerializationFailure, // ER_XA_RBDEADLOCK
+	1053: ConnectionFailure,    // ER_SERVER_SHUTDOWN
+}
+
+// MySQL puts the objects of a violation in the message only:
+//
+//	Duplicate entry 'a@example.com' for key 'users.users_email_key'
+//	Column 'email' cannot be null
+//	Cannot add or update a child row: a foreign key constraint fails
+//	  (`app`.`posts`, CONSTRAINT `posts_user_id_fkey` FOREIGN KEY (`user_id`) REFERENCES ...)
+//	Check constraint 'users_age_check' is violated.
+var (
+	mysqlDuplicateKey = regexp.MustCompile(`for key '(?:([^'.]+)\.)?([^']+)'`)
+	mysqlColumn       = regexp.MustCompile(`Column '([^']+)'|Field '([^']+)'`)
+	mysqlForeignKey   = regexp.MustCompile("\\(`[^`]+`\\.`([^`]+)`, CONSTRAINT `([^`]+)` FOREIGN KEY \\(`([^`]+)`\\)")
+	mysqlCheck        = regexp.MustCompile(`[Cc]heck constraint '([^']+)'`)
+)
+
+// mysqlDriver converts go-sql-driver/mysql errors.
+type mysqlDriver struct{}
+
+func (mysqlDriver) Name() string { return "mysql" }
+
+func (mysqlDriver) Convert(err error) *Error {
+	driverErr, v, ok := findDriverError(err, mysqlPackage)
+	if !ok {
+		return nil
+	}
+	number, ok := intField(v, "Number")
+	if !ok {
+		return nil
+	}
+
+	code, known := mysqlCodes[number]
+	if !known {
+		code = Other
+	}
+
+	message := ""
+	if f := v.FieldByName("Message"); f.IsValid() {
+		message = f.String()
+	}
+
+	sqlErr := &Error{
+		Code:         code,
+		Severity:     SeverityError,
+		DatabaseCode: strconv.FormatInt(number, 10),
+		Message:      message,
+		driverErr:    driverErr,
+	}
+
+	switch code {
+	case UniqueViolation:
+		if m := mysqlDuplicateKey.FindStringSubmatch(message); m != nil {
+			sqlErr.TableName, sqlErr.ConstraintName = m[1], m[2]
+		}
+	case NotNullViolation:
+		if m := mysqlColumn.FindStringSubmatch(message); m != nil {
+			sqlErr.ColumnName = m[1] + m[2]
+		}
+	case ForeignKeyViolation:
+		if m := mysqlForeignKey.FindStringSubmatch(message); m != nil {
+			sqlErr.TableName, sqlErr.ConstraintName, sqlErr.ColumnName = m[1], m[2], m[3]
+		}
+	case CheckViolation:
+		if m := mysqlCheck.FindStringSubmatch(message); m != nil {
+			sqlErr.ConstraintName = m[1]
+		}
+	}
+	return sqlErr
+}
@@ -0,0 +1,62 @@
+package sqlerr
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/lib/inflect"
+	"github.com/jackc/pgx/v5"
+)
+
+// NotFound returns the 404 for a missing row of entity, which may be a table
+// name or an entity name:
+//
+//	sqlerr.NotFound("users")     // 404 USER_NOT_FOUND "User not found"
+//	sqlerr.NotFound("companies") // 404 COMPANY_NOT_FOUND "Company not found"
+//
+// Repositories know what they queried; use it rather than leaving
+// HandleError to guess the entity from the error text.
+func NotFound(entity string) *errs.HTTPError {
+	if entity == "" {
+		return errs.NewNotFoundError("Resource not found", false, nil)
+	}
+
+	code := strings.ToUpper(inflect.Singular(entity)) + "_NOT_FOUND"
+	return errs.NewNotFoundError(fmt.Sprintf("%s not found", getEntityName(entity, "")), true, &code)
+}
+
+// HandleErrorFor is HandleError for an error of a query on table:
+//
+//	user, err := r.Queries(ctx).GetUser(ctx, id)
+//	if err != nil {
+//		return nil, sqlerr.HandleErrorFor(err, "users")
+//	}
+//
+// No rows becomes NotFound(table). Database errors that don't name their
+// table (MySQL and SQLite report few of them) are handled as if they did, so
+// codes read USER_ALREADY_EXISTS rather than RECORD_ALREADY_EXISTS.
+func HandleErrorFor(err error, table string) error {
+	if err == nil {
+		return nil
+	}
+
+	var httpErr *errs.HTTPError
+	if errors.As(err, &httpErr) {
+		return err
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+		return NotFound(table).WithCause(err)
+	}
+
+	if sqlErr, ok := Convert(err); ok && sqlErr.TableName == "" {
+		withTable := *sqlErr
+		withTable.TableName = table
+		return withCause(convertError(&withTable), err)
+	}
+
+	return HandleError(err)
+}
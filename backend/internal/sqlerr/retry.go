@@ -0,0 +1,71 @@
+package sqlerr
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Retry hints sent with the 503s of transient database errors.
+const (
+	// conflictRetryAfter: the competing transaction is usually done by then.
+	conflictRetryAfter = time.Second
+
+	// unavailableRetryAfter: enough for a failover or a pool to free up.
+	unavailableRetryAfter = 5 * time.Second
+)
+
+// IsRetryable reports whether running the failed statement (or the whole
+// transaction) again may succeed:
+//   - serialization failures (40001) and deadlocks (40P01, MySQL 1213): the
+//     database aborted this transaction in favour of a concurrent one
+//   - too many connections (53300): the connection was refused, so nothing ran
+//   - connection failures where nothing was sent: a failed connect, or a
+//     drop pgx marks safe (pgconn.SafeToRetry), so the statement can't have
+//     taken effect
+//
+// A connection lost mid-statement is not retryable: whether it was applied
+// is unknown. Neither is a canceled query: it would most likely time out
+// again.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if sqlErr, ok := Convert(err); ok {
+		switch sqlErr.Code {
+		case SerializationFailure, DeadlockDetected, TooManyConnections:
+			return true
+		case ConnectionFailure:
+			var connectErr *pgconn.ConnectError
+			return errors.As(err, &connectErr) || pgconn.SafeToRetry(err)
+		}
+		return false
+	}
+
+	return pgconn.SafeToRetry(err)
+}
+
+// transientError is the 503 for a database error the client may retry,
+// with a retry_after action telling it when.
+func transientError(code, message string, retryAfter time.Duration) *errs.HTTPError {
+	return errs.NewServiceUnavailableError(message, &code, retryAfter)
+}
+
+// Messages of the transient errors.
+const (
+	transactionConflictMessage = "The request conflicted with a concurrent update, please retry"
+	databaseUnavailableMessage = "The database is temporarily unavailable, please retry later"
+)
+
+func init() {
+	for _, entry := range []errs.CatalogEntry{
+		{Code: "TRANSACTION_CONFLICT", Status: http.StatusServiceUnavailable, Description: "The request lost a race with a concurrent one (deadlock or serialization failure); retry after the hint.", Message: transactionConflictMessage},
+		{Code: "DATABASE_UNAVAILABLE", Status: http.StatusServiceUnavailable, Description: "The database can't be reached or has no free connections; retry after the hint.", Message: databaseUnavailableMessage},
+	} {
+		errs.RegisterCode(entry)
+	}
+}
@@ -0,0 +1,93 @@
+package sqlerr
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SQLite drivers: mattn's declares sqlite3.Error{Code, ExtendedCode int},
+// modernc's *sqlite.Error with a Code() int method returning the extended
+// code.
+const (
+	mattnSQLitePackage   = "github.com/mattn/go-sqlite3"
+	moderncSQLitePackage = "modernc.org/sqlite"
+)
+
+// SQLite extended result codes mapped to a Code.
+var sqliteCodes = map[int64]Code{
+	1299: NotNullViolation,     // SQLITE_CONSTRAINT_NOTNULL
+	2067: UniqueViolation,      // SQLITE_CONSTRAINT_UNIQUE
+	1555: UniqueViolation,      // SQLITE_CONSTRAINT_PRIMARYKEY
+	787:  ForeignKeyViolation,  // SQLITE_CONSTRAINT_FOREIGNKEY
+	275:  CheckViolation,       // SQLITE_CONSTRAINT_CHECK
+	9:    QueryCanceled,        // SQLITE_INTERRUPT
+	517:  SerializationFailure, // SQLITE_BUSY_SNAPSHOT
+}
+
+// SQLite names the columns of a violation in the message:
+//
+//	UNIQUE constraint failed: users.org_id, users.slug
+//	NOT NULL constraint failed: users.email
+//	CHECK constraint failed: users_age_check
+var (
+	sqliteColumns = regexp.MustCompile(`(?:UNIQUE|NOT NULL) constraint failed: ([^()]+)`)
+	sqliteCheck   = regexp.MustCompile(`CHECK constraint failed: ([A-Za-z0-9_]+)`)
+)
+
+// sqliteDriver converts mattn/go-sqlite3 and modernc.org/sqlite errors.
+type sqliteDriver struct{}
+
+func (sqliteDriver) Name() string { return "sqlite" }
+
+func (sqliteDriver) Convert(err error) *Error {
+	var (
+		code int64
+		ok   bool
+	)
+
+	driverErr, v, found := findDriverError(err, mattnSQLitePackage)
+	if found {
+		code, ok = intField(v, "ExtendedCode")
+	} else if driverErr, _, found = findDriverError(err, moderncSQLitePackage); found {
+		if coder, isCoder := driverErr.(interface{ Code() int }); isCoder {
+			code, ok = int64(coder.Code()), true
+		}
+	}
+	if !ok {
+		return nil
+	}
+
+	mapped, known := sqliteCodes[code]
+	if !known {
+		mapped = Other
+	}
+
+	message := driverErr.Error()
+	sqlErr := &Error{
+		Code:         mapped,
+		Severity:     SeverityError,
+		DatabaseCode: strconv.FormatInt(code, 10),
+		Message:      message,
+		driverErr:    driverErr,
+	}
+
+	switch mapped {
+	case UniqueViolation, NotNullViolation:
+		if m := sqliteColumns.FindStringSubmatch(message); m != nil {
+			for _, qualified := range splitKeyList(strings.TrimSpace(m[1])) {
+				table, column, _ := strings.Cut(qualified, ".")
+				sqlErr.TableName = table
+				sqlErr.Columns = append(sqlErr.Columns, column)
+			}
+			if len(sqlErr.Columns) == 1 {
+				sqlErr.ColumnName = sqlErr.Columns[0]
+			}
+		}
+	case CheckViolation:
+		if m := sqliteCheck.FindStringSubmatch(message); m != nil {
+			sqlErr.ConstraintName = m[1]
+		}
+	}
+	return sqlErr
+}
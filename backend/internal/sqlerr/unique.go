@@ -0,0 +1,74 @@
+package sqlerr
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// uniqueDetailPattern matches Postgres' unique violation detail:
+//
+//	Key (email)=(a@example.com) already exists.
+//	Key (org_id, slug)=(42, home) already exists.
+var uniqueDetailPattern = regexp.MustCompile(`^Key \((.+)\)=\((.*)\) already exists\.?$`)
+
+// UniqueConflict describes the row a unique violation collided with.
+type UniqueConflict struct {
+	// Table and Constraint (index name) reported by Postgres.
+	Table      string
+	Constraint string
+
+	// Columns and Values of the conflicting key, in index order. Values are
+	// Postgres' text rendering; empty when the detail was not available
+	// (e.g. the role lacks permission to see the values).
+	Columns []string
+	Values  []string
+}
+
+// ParseUniqueConflict extracts the conflicting key from a unique violation.
+func ParseUniqueConflict(err error) (*UniqueConflict, bool) {
+	var pgerr *pgconn.PgError
+	if !errors.As(err, &pgerr) || MapCode(pgerr.Code) != UniqueViolation {
+		return nil, false
+	}
+
+	conflict := &UniqueConflict{
+		Table:      pgerr.TableName,
+		Constraint: pgerr.ConstraintName,
+	}
+
+	if m := uniqueDetailPattern.FindStringSubmatch(pgerr.Detail); m != nil {
+		conflict.Columns = splitKeyList(m[1])
+		values := splitKeyList(m[2])
+		// Values containing ", " can't be split unambiguously; only keep
+		// them when they line up with the columns.
+		if len(values) == len(conflict.Columns) {
+			conflict.Values = values
+		}
+	}
+
+	return conflict, true
+}
+
+// splitKeyList splits "a, b" key lists from the violation detail.
+func splitKeyList(list string) []string {
+	parts := strings.Split(list, ", ")
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+	return parts
+}
+
+// expressionColumnPattern finds the innermost identifier of an index expression.
+var expressionColumnPattern = regexp.MustCompile(`\(([a-zA-Z_][a-zA-Z0-9_]*)(?:::[a-z ]+)?\)`)
+
+// expressionColumn reduces "lower(email::text)" to "email"; plain column
+// names are returned unchanged.
+func expressionColumn(column string) string {
+	if m := expressionColumnPattern.FindStringSubmatch(column); m != nil {
+		return m[1]
+	}
+	return column
+}
@@ -0,0 +1,66 @@
+package sqlerr
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestParseUniqueConflict(t *testing.T) {
+	tests := []struct {
+		name    string
+		detail  string
+		columns []string
+		values  []string
+	}{
+		{"single column", "Key (email)=(a@example.com) already exists.", []string{"email"}, []string{"a@example.com"}},
+		{"composite", "Key (org_id, slug)=(42, home) already exists.", []string{"org_id", "slug"}, []string{"42", "home"}},
+		{"expression index", "Key (lower(email::text))=(a@example.com) already exists.", []string{"lower(email::text)"}, []string{"a@example.com"}},
+		// ", " inside a value makes the split ambiguous: columns only.
+		{"ambiguous values", "Key (org_id, name)=(42, Smith, John) already exists.", []string{"org_id", "name"}, nil},
+		// Roles without SELECT on the table get no detail at all.
+		{"no detail", "", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pgErr := &pgconn.PgError{Code: "23505", TableName: "users", ConstraintName: "users_email_key", Detail: tt.detail}
+
+			conflict, ok := ParseUniqueConflict(fmt.Errorf("insert user: %w", pgErr))
+			if !ok {
+				t.Fatal("unique violation not recognized")
+			}
+			if conflict.Table != "users" || conflict.Constraint != "users_email_key" {
+				t.Fatalf("got table %q constraint %q", conflict.Table, conflict.Constraint)
+			}
+			if !slices.Equal(conflict.Columns, tt.columns) || !slices.Equal(conflict.Values, tt.values) {
+				t.Fatalf("got columns %q values %q, want %q %q", conflict.Columns, conflict.Values, tt.columns, tt.values)
+			}
+		})
+	}
+}
+
+func TestParseUniqueConflictIgnoresOtherErrors(t *testing.T) {
+	for _, err := range []error{
+		errors.New("connection reset"),
+		&pgconn.PgError{Code: "23503", Detail: "Key (user_id)=(1) is not present in table \"users\"."},
+	} {
+		if _, ok := ParseUniqueConflict(err); ok {
+			t.Errorf("ParseUniqueConflict(%v) reported a unique violation", err)
+		}
+	}
+}
+
+func TestExpressionColumn(t *testing.T) {
+	for column, want := range map[string]string{
+		"email":                "email",
+		"lower(email::text)":   "email",
+		"lower((email)::text)": "email",
+	} {
+		if got := expressionColumn(column); got != want {
+			t.Errorf("expressionColumn(%q) = %q, want %q", column, got, want)
+		}
+	}
+}
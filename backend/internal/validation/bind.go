@@ -0,0 +1,137 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/labstack/echo/v4"
+)
+
+// bindError converts an error of c.Bind into an errs.HTTPError.
+//
+// Echo wraps the decoder's error in an echo.HTTPError (Internal), so the
+// typed error is found with errors.As and turned into field errors, instead
+// of parsing Echo's message:
+//
+//	{"age": "x"}  => 400, errors: [{"field": "age", "error": "must be of type integer"}]
+//	{"age": 1,}   => 400, errors: [{"field": "body", "error": "malformed JSON at byte N"}]
+func bindError(err error) error {
+	// The body was cut off by http.MaxBytesReader (see handler.WithBodyLimit).
+	// Report it as 413 rather than a confusing 400 parse error.
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return errs.NewPayloadTooLargeError(
+			fmt.Sprintf("Request body must not exceed %d bytes", maxBytesErr.Limit), true)
+	}
+
+	if errors.Is(err, echo.ErrUnsupportedMediaType) {
+		return errs.NewUnsupportedMediaTypeError("Unsupported Content-Type, send application/json")
+	}
+
+	var (
+		typeErr    *json.UnmarshalTypeError
+		syntaxErr  *json.SyntaxError
+		bindingErr *echo.BindingError
+		numErr     *strconv.NumError
+		timeErr    *time.ParseError
+	)
+	switch {
+	case errors.As(err, &typeErr):
+		expected := jsonTypeName(typeErr.Type)
+		field := typeErr.Field
+		if field == "" {
+			field = "body"
+		}
+		return invalidRequest(errs.FieldError{
+			Field:  field,
+			Error:  "must be of type " + expected,
+			Key:    "bind.type",
+			Params: map[string]any{"type": expected, "got": typeErr.Value},
+		})
+
+	case errors.As(err, &syntaxErr):
+		return invalidRequest(errs.FieldError{
+			Field:  "body",
+			Error:  fmt.Sprintf("malformed JSON at byte %d", syntaxErr.Offset),
+			Key:    "bind.syntax",
+			Params: map[string]any{"offset": syntaxErr.Offset},
+		})
+
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		return invalidRequest(errs.FieldError{
+			Field: "body",
+			Error: "is incomplete JSON",
+			Key:   "bind.incomplete",
+		})
+
+	case errors.As(err, &bindingErr):
+		// Echo's fluent binders (echo.QueryParamsBinder...) name the parameter.
+		return invalidRequest(errs.FieldError{
+			Field:  bindingErr.Field,
+			Error:  "has an invalid value",
+			Key:    "bind.invalid",
+			Params: map[string]any{"value": bindingErr.Values},
+		})
+
+	case errors.As(err, &numErr):
+		// The default binder doesn't say which query/path parameter failed,
+		// only the value.
+		return invalidRequest(errs.FieldError{
+			Field:  "params",
+			Error:  fmt.Sprintf("%q is not a valid number", numErr.Num),
+			Key:    "bind.number",
+			Params: map[string]any{"value": numErr.Num},
+		})
+
+	case errors.As(err, &timeErr):
+		return invalidRequest(errs.FieldError{
+			Field:  "params",
+			Error:  fmt.Sprintf("%q is not a valid time", timeErr.Value),
+			Key:    "bind.time",
+			Params: map[string]any{"value": timeErr.Value},
+		})
+	}
+
+	return errs.NewBadRequestError("Invalid request", false, nil, nil, nil).
+		WithMessageKey("bind.failed", nil)
+}
+
+// invalidRequest is the 400 of a request that couldn't be bound.
+func invalidRequest(fieldErr errs.FieldError) *errs.HTTPError {
+	return errs.NewBadRequestError("Invalid request", true, nil, []errs.FieldError{fieldErr}, nil).
+		WithMessageKey("bind.failed", nil)
+}
+
+// jsonTypeName names the JSON type of t ("number").
+func jsonTypeName(t reflect.Type) string {
+	if t == nil {
+		return "value"
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	}
+	return "value"
+}
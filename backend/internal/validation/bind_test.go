@@ -0,0 +1,77 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/labstack/echo/v4"
+)
+
+func TestBindError(t *testing.T) {
+	var target struct {
+		Age int `json:"age"`
+	}
+	typeErr := json.Unmarshal([]byte(`{"age": "x"}`), &target)
+	syntaxErr := json.Unmarshal([]byte(`{"age": 1,}`), &target)
+
+	// Echo wraps decoder errors in an echo.HTTPError.
+	wrap := func(err error) error {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		field  string
+		key    string
+	}{
+		{"type mismatch", wrap(typeErr), http.StatusBadRequest, "age", "bind.type"},
+		{"syntax", wrap(syntaxErr), http.StatusBadRequest, "body", "bind.syntax"},
+		{"truncated", wrap(io.ErrUnexpectedEOF), http.StatusBadRequest, "body", "bind.incomplete"},
+		{"empty body", wrap(io.EOF), http.StatusBadRequest, "body", "bind.incomplete"},
+		{"query binder", echo.NewBindingError("limit", []string{"x"}, "invalid", nil), http.StatusBadRequest, "limit", "bind.invalid"},
+		{"number param", wrap(&strconv.NumError{Func: "ParseInt", Num: "x", Err: strconv.ErrSyntax}), http.StatusBadRequest, "params", "bind.number"},
+		{"time param", wrap(&time.ParseError{Value: "yesterday"}), http.StatusBadRequest, "params", "bind.time"},
+		{"body limit", wrap(&http.MaxBytesError{Limit: 10}), http.StatusRequestEntityTooLarge, "", ""},
+		{"content type", echo.ErrUnsupportedMediaType, http.StatusUnsupportedMediaType, "", ""},
+		{"anything else", errors.New("boom"), http.StatusBadRequest, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var httpErr *errs.HTTPError
+			if !errors.As(bindError(tt.err), &httpErr) {
+				t.Fatalf("bindError(%v) is not an errs.HTTPError", tt.err)
+			}
+			if httpErr.Status != tt.status {
+				t.Fatalf("status %d, want %d", httpErr.Status, tt.status)
+			}
+			if tt.field == "" {
+				if len(httpErr.Errors) != 0 {
+					t.Fatalf("unexpected field errors %+v", httpErr.Errors)
+				}
+				return
+			}
+			if len(httpErr.Errors) != 1 {
+				t.Fatalf("got field errors %+v, want one", httpErr.Errors)
+			}
+			if got := httpErr.Errors[0]; got.Field != tt.field || got.Key != tt.key {
+				t.Fatalf("got %s (%s), want %s (%s)", got.Field, got.Key, tt.field, tt.key)
+			}
+		})
+	}
+}
+
+func TestBindErrorBodyLimitMessage(t *testing.T) {
+	err := bindError(fmt.Errorf("read: %w", &http.MaxBytesError{Limit: 1024}))
+	if got, want := err.Error(), "Request body must not exceed 1024 bytes"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
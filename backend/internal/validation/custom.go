@@ -0,0 +1,267 @@
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // timezone must not depend on the image shipping zoneinfo
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Custom is a validation tag added to every validator NewValidator returns,
+// together with the message extractValidationError shows when it fails.
+//
+// A tag is implemented one of three ways:
+//   - Func set: the tag runs Func (validator.RegisterValidation), replacing
+//     validator's own tag of that name if there is one;
+//   - Alias set: the tag expands to other tags, e.g. "currency" -> "iso4217"
+//     (validator.RegisterAlias), so errors still report "currency";
+//   - neither: the tag is one of validator's own and only the message is added.
+type Custom struct {
+	Tag   string
+	Func  validator.Func
+	Alias string
+
+	// Message is the English field error. "{param}" is replaced by the tag
+	// parameter (`validate:"password=12"` -> "12"), or DefaultParam when the
+	// tag has none. Translations live under "validation.<tag>" in lib/i18n.
+	Message      string
+	DefaultParam string
+}
+
+var (
+	customMu sync.RWMutex
+	customs  = map[string]Custom{}
+)
+
+// RegisterCustom adds a validation tag. Call it from an init function, before
+// the first NewValidator:
+//
+//	func init() {
+//		validation.RegisterCustom(validation.Custom{
+//			Tag:     "sku",
+//			Func:    func(fl validator.FieldLevel) bool { return skuRegex.MatchString(fl.Field().String()) },
+//			Message: "must be a valid SKU",
+//		})
+//	}
+//
+// It panics on an empty or already registered tag: two packages claiming the
+// same tag would silently validate with whichever registered last.
+func RegisterCustom(c Custom) {
+	if c.Tag == "" {
+		panic("validation: custom validator without tag")
+	}
+	if c.Func != nil && c.Alias != "" {
+		panic(fmt.Sprintf("validation: custom validator %q has both Func and Alias", c.Tag))
+	}
+
+	customMu.Lock()
+	defer customMu.Unlock()
+
+	if _, exists := customs[c.Tag]; exists {
+		panic(fmt.Sprintf("validation: custom validator %q registered twice", c.Tag))
+	}
+	customs[c.Tag] = c
+}
+
+// LookupCustom returns the custom validator registered for tag.
+func LookupCustom(tag string) (Custom, bool) {
+	customMu.RLock()
+	defer customMu.RUnlock()
+
+	c, ok := customs[tag]
+	return c, ok
+}
+
+// CustomTags returns the registered tags, sorted.
+func CustomTags() []string {
+	customMu.RLock()
+	defer customMu.RUnlock()
+
+	tags := make([]string, 0, len(customs))
+	for tag := range customs {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// registerCustoms installs every custom validator on v.
+func registerCustoms(v *validator.Validate) {
+	customMu.RLock()
+	defer customMu.RUnlock()
+
+	for _, c := range customs {
+		switch {
+		case c.Func != nil:
+			// Registration only fails for an empty tag or nil func, which
+			// RegisterCustom already rules out.
+			_ = v.RegisterValidation(c.Tag, c.Func)
+		case c.Alias != "":
+			v.RegisterAlias(c.Tag, c.Alias)
+		}
+	}
+}
+
+// customMessage returns the message of a failed custom tag and the
+// parameter it was rendered with.
+func customMessage(tag, param string) (msg, usedParam string, ok bool) {
+	c, found := LookupCustom(tag)
+	if !found || c.Message == "" {
+		return "", "", false
+	}
+	if param == "" {
+		param = c.DefaultParam
+	}
+	return strings.ReplaceAll(c.Message, "{param}", param), param, true
+}
+
+// Built-in business validators. Empty values pass all of them: whether a
+// field must be set is `required`'s job.
+func init() {
+	RegisterCustom(Custom{
+		Tag:          "password",
+		Func:         isStrongPassword,
+		Message:      "must be at least {param} characters and contain upper and lower case letters, a digit and a symbol",
+		DefaultParam: strconv.Itoa(defaultPasswordLength),
+	})
+	RegisterCustom(Custom{
+		Tag:     "slug",
+		Func:    isSlug,
+		Message: "must contain only lowercase letters, digits and single hyphens",
+	})
+	RegisterCustom(Custom{
+		Tag:     "timezone",
+		Func:    isTimeZone,
+		Message: "must be a valid IANA time zone, such as Europe/Berlin",
+	})
+	RegisterCustom(Custom{
+		Tag:     "currency",
+		Alias:   "omitempty,iso4217",
+		Message: "must be a valid ISO 4217 currency code, such as EUR",
+	})
+	RegisterCustom(Custom{
+		Tag:     "country",
+		Alias:   "omitempty,iso3166_1_alpha2",
+		Message: "must be a valid ISO 3166-1 alpha-2 country code, such as DE",
+	})
+	RegisterCustom(Custom{
+		Tag:          "urlscheme",
+		Func:         hasURLScheme,
+		Message:      "must be an absolute URL with scheme: {param}",
+		DefaultParam: strings.Join(defaultURLSchemes, " "),
+	})
+	RegisterCustom(Custom{
+		Tag:     "nohtml",
+		Func:    hasNoHTML,
+		Message: "must not contain HTML",
+	})
+}
+
+// defaultPasswordLength is the minimum length of `validate:"password"`
+// without parameter.
+const defaultPasswordLength = 8
+
+// isStrongPassword implements `password` and `password=<min length>`.
+func isStrongPassword(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	if value == "" {
+		return true
+	}
+
+	minLength := defaultPasswordLength
+	if param := fl.Param(); param != "" {
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return false
+		}
+		minLength = n
+	}
+	if len([]rune(value)) < minLength {
+		return false
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range value {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	return upper && lower && digit && symbol
+}
+
+var slugRegex = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// isSlug implements `slug`: "my-first-post", not "My Post" or "-post-".
+func isSlug(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	return value == "" || slugRegex.MatchString(value)
+}
+
+// isTimeZone implements `timezone`. It replaces validator's version, which
+// rejects empty values, and like it refuses "Local": the server's zone is
+// not something a client can mean.
+func isTimeZone(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	if value == "" {
+		return true
+	}
+	if strings.EqualFold(value, "local") {
+		return false
+	}
+
+	_, err := time.LoadLocation(value)
+	return err == nil
+}
+
+var defaultURLSchemes = []string{"http", "https"}
+
+// hasURLScheme implements `urlscheme` (http or https) and
+// `urlscheme=https`, `urlscheme=https ftp` for other schemes. Unlike
+// validator's "url", a host is required, so "mailto:x" or "https:/path" fail.
+func hasURLScheme(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	if value == "" {
+		return true
+	}
+
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	schemes := strings.Fields(fl.Param())
+	if len(schemes) == 0 {
+		schemes = defaultURLSchemes
+	}
+	for _, scheme := range schemes {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// htmlRegex matches what a browser would parse as markup: tags, comments,
+// doctypes and character references. A lone "<" as in "a < b" is allowed.
+var htmlRegex = regexp.MustCompile(`<[a-zA-Z/!?][^>]*>|&(?:[a-zA-Z][a-zA-Z0-9]*|#[0-9]+|#[xX][0-9a-fA-F]+);`)
+
+// hasNoHTML implements `nohtml`, for free text that is rendered elsewhere
+// (emails, admin UIs) and shouldn't carry markup.
+func hasNoHTML(fl validator.FieldLevel) bool {
+	return !htmlRegex.MatchString(fl.Field().String())
+}
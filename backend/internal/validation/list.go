@@ -0,0 +1,120 @@
+package validation
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/lib/pagination"
+	"github.com/go-playground/validator/v10"
+)
+
+// Building blocks for list endpoint query strings. Embed them in a request
+// next to the endpoint's own filters:
+//
+//	type ListTodosRequest struct {
+//		validation.PageQuery
+//		validation.DateRange
+//		Sort   validation.Sort `query:"sort" validate:"omitempty,sort=created_at title priority"`
+//		Status []string        `query:"status" validate:"omitempty,dive,enum=todo_status"`
+//	}
+//
+//	GET /todos?page=2&per_page=50&sort=-priority,title&status=todo,done&from=2024-05-01
+//
+// Bind decodes them (see DecodeQuery) and the validator tags check them, so a
+// bad request answers with one field error per offending parameter.
+
+// PageQuery is offset pagination (?page=&per_page=), for lists that show
+// page numbers. Prefer pagination.Params (cursors) for feeds and large
+// tables: offsets get slower with every page.
+type PageQuery struct {
+	Page    int `query:"page" json:"page,omitempty" validate:"omitempty,min=1"`
+	PerPage int `query:"per_page" json:"per_page,omitempty" validate:"omitempty,min=1,max=100"`
+}
+
+// GetPage returns the 1-based page number, 1 when unset.
+func (q PageQuery) GetPage() int {
+	return max(q.Page, 1)
+}
+
+// GetPerPage returns the effective page size, bounded like cursor pages.
+func (q PageQuery) GetPerPage() int {
+	switch {
+	case q.PerPage <= 0:
+		return pagination.DefaultLimit
+	case q.PerPage > pagination.MaxLimit:
+		return pagination.MaxLimit
+	default:
+		return q.PerPage
+	}
+}
+
+// Offset returns the number of rows before the page, for OFFSET.
+func (q PageQuery) Offset() int {
+	return (q.GetPage() - 1) * q.GetPerPage()
+}
+
+// DateRange filters on a time column (?from=&to=). Values are RFC 3339 or
+// dates; a date is midnight UTC, so ?to=2024-05-31 excludes that day:
+// filter with column >= From AND column < To.
+type DateRange struct {
+	From time.Time `query:"from" json:"from,omitzero"`
+	To   time.Time `query:"to" json:"to,omitzero" validate:"omitempty,gtfield=From"`
+}
+
+// IsZero reports whether neither bound was given.
+func (r DateRange) IsZero() bool {
+	return r.From.IsZero() && r.To.IsZero()
+}
+
+// Sort is a sort order (?sort=-created_at,title): comma-separated fields,
+// each descending when prefixed with "-". Allow fields with the sort tag,
+// which also rejects a field listed twice:
+//
+//	Sort validation.Sort `query:"sort" validate:"omitempty,sort=created_at title"`
+//
+// Map the field names to columns yourself; they are API names, not SQL.
+type Sort string
+
+// SortField is one field of a Sort.
+type SortField struct {
+	Name string
+	Desc bool
+}
+
+// Fields returns the fields of s in order.
+func (s Sort) Fields() []SortField {
+	var fields []SortField
+	for _, part := range strings.Split(string(s), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, desc := strings.CutPrefix(part, "-")
+		fields = append(fields, SortField{Name: name, Desc: desc})
+	}
+	return fields
+}
+
+func init() {
+	RegisterCustom(Custom{
+		Tag:     "sort",
+		Func:    isAllowedSort,
+		Message: "must be a comma-separated list of {param}, each optionally prefixed with -",
+	})
+}
+
+// isAllowedSort implements `sort=<allowed fields>`.
+func isAllowedSort(fl validator.FieldLevel) bool {
+	allowed := strings.Fields(fl.Param())
+
+	var seen []string
+	for _, field := range Sort(fl.Field().String()).Fields() {
+		if !slices.Contains(allowed, field.Name) || slices.Contains(seen, field.Name) {
+			return false
+		}
+		seen = append(seen, field.Name)
+	}
+	return true
+}
@@ -0,0 +1,197 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// NormalizeTag is the struct tag listing the normalizers Bind applies to a
+// field, in order, before it is validated:
+//
+//	Email string `json:"email" normalize:"trim,lower" validate:"required,email"`
+//	Phone string `json:"phone" normalize:"e164" validate:"omitempty,e164"`
+//
+// It is opt-in: untagged fields are stored exactly as they were sent.
+const NormalizeTag = "normalize"
+
+// Normalizer rewrites a string field value.
+type Normalizer func(string) string
+
+var (
+	normalizersMu sync.RWMutex
+	normalizers   = map[string]Normalizer{}
+)
+
+// RegisterNormalizer makes fn available as name in normalize tags. It panics
+// if name is already registered.
+func RegisterNormalizer(name string, fn Normalizer) {
+	normalizersMu.Lock()
+	defer normalizersMu.Unlock()
+
+	if _, exists := normalizers[name]; exists {
+		panic(fmt.Sprintf("validation: normalizer %q registered twice", name))
+	}
+	normalizers[name] = fn
+}
+
+// LookupNormalizer returns the normalizer registered as name.
+func LookupNormalizer(name string) (Normalizer, bool) {
+	normalizersMu.RLock()
+	defer normalizersMu.RUnlock()
+
+	fn, ok := normalizers[name]
+	return fn, ok
+}
+
+// NormalizerNames returns the registered normalizer names, sorted.
+func NormalizerNames() []string {
+	normalizersMu.RLock()
+	defer normalizersMu.RUnlock()
+
+	names := make([]string, 0, len(normalizers))
+	for name := range normalizers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	RegisterNormalizer("trim", strings.TrimSpace)
+	RegisterNormalizer("lower", strings.ToLower)
+	RegisterNormalizer("upper", strings.ToUpper)
+	RegisterNormalizer("collapse", collapseSpaces)
+	RegisterNormalizer("strip_control", stripControl)
+	RegisterNormalizer("e164", canonicalPhone)
+}
+
+// Normalize applies the normalize tags of payload, which must be a pointer.
+// Nested structs, pointers and slices are walked, so items of a bulk request
+// are normalized along with their envelope; a tag on a []string field
+// applies to every element.
+//
+// An unknown normalizer name is a programming error and is returned as such
+// rather than skipped, so the typo surfaces on the first request.
+func Normalize(payload any) error {
+	return normalizeValue(reflect.ValueOf(payload), nil)
+}
+
+func normalizeValue(v reflect.Value, fns []Normalizer) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return normalizeValue(v.Elem(), fns)
+
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			fieldFns, err := parseNormalizeTag(field.Tag.Get(NormalizeTag))
+			if err != nil {
+				return fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+			}
+			if err := normalizeValue(v.Field(i), fieldFns); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := normalizeValue(v.Index(i), fns); err != nil {
+				return err
+			}
+		}
+
+	case reflect.String:
+		if len(fns) == 0 || !v.CanSet() {
+			return nil
+		}
+		s := v.String()
+		for _, fn := range fns {
+			s = fn(s)
+		}
+		v.SetString(s)
+	}
+
+	return nil
+}
+
+func parseNormalizeTag(tag string) ([]Normalizer, error) {
+	if tag == "" {
+		return nil, nil
+	}
+
+	var fns []Normalizer
+	for _, name := range strings.Split(tag, ",") {
+		name = strings.TrimSpace(name)
+		fn, ok := LookupNormalizer(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown normalizer %q (registered: %s)", name, strings.Join(NormalizerNames(), ", "))
+		}
+		fns = append(fns, fn)
+	}
+	return fns, nil
+}
+
+// collapseSpaces trims s and turns every run of whitespace, newlines
+// included, into a single space: "  Jane \n Doe " -> "Jane Doe".
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// stripControl removes control characters (NUL, terminal escapes, carriage
+// returns) and the bidi overrides used to disguise text, but keeps tabs and
+// newlines, so it is safe on multi-line text. Zero-width joiners stay: emoji
+// sequences need them.
+func stripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return r
+		case unicode.IsControl(r), unicode.Is(unicode.Bidi_Control, r):
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// canonicalPhone rewrites a phone number written with the country code into
+// E.164: "+49 (30) 1234-567" and "0049 30 1234567" become "+49301234567".
+//
+// Numbers it can't canonicalize without guessing (letters, no country code)
+// are returned trimmed but otherwise unchanged, for `e164` to reject.
+func canonicalPhone(s string) string {
+	s = strings.TrimSpace(s)
+
+	var digits strings.Builder
+	for i, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')' || r == '/':
+		default:
+			return s
+		}
+	}
+
+	number := digits.String()
+	switch {
+	case strings.HasPrefix(s, "+"):
+		return "+" + number
+	case strings.HasPrefix(number, "00"):
+		return "+" + strings.TrimPrefix(number, "00")
+	default:
+		return s
+	}
+}
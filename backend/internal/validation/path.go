@@ -0,0 +1,58 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// nameTags are the struct tags a field's client-facing name is read from, in
+// order: the body name first, then the names Echo binds path, query and form
+// values by.
+var nameTags = []string{"json", "param", "query", "form"}
+
+// embeddedSegment names untagged embedded structs. encoding/json flattens
+// their fields into the parent object, so fieldPath drops the segment again.
+const embeddedSegment = "~"
+
+// fieldName is the validator's tag name func: errors report a field by the
+// name the client sent it under instead of its Go name.
+//
+//	Quantity int `json:"quantity" validate:"min=1"` // -> "quantity"
+//	PerPage  int `query:"per_page" validate:"max=100"` // -> "per_page"
+//
+// Untagged fields keep the old behaviour of lowercasing the Go name.
+func fieldName(fld reflect.StructField) string {
+	for _, tag := range nameTags {
+		name, _, _ := strings.Cut(fld.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+
+	if fld.Anonymous {
+		return embeddedSegment
+	}
+	return strings.ToLower(fld.Name)
+}
+
+// fieldPath returns the full path of the failed field within the payload,
+// such as "items[2].quantity" or "address.zip".
+//
+// The validator's namespace starts with the Go name of the validated struct
+// ("CreateOrderRequest.items[2].quantity"), which the client never sees.
+func fieldPath(err validator.FieldError) string {
+	segments := strings.Split(err.Namespace(), ".")
+	if len(segments) > 1 {
+		segments = segments[1:]
+	}
+
+	path := segments[:0]
+	for _, segment := range segments {
+		if segment != embeddedSegment {
+			path = append(path, segment)
+		}
+	}
+	return strings.Join(path, ".")
+}
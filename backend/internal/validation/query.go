@@ -0,0 +1,281 @@
+package validation
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/labstack/echo/v4"
+)
+
+// DecodeQuery decodes query parameters into the `query`-tagged fields of
+// dst, a pointer to a struct. Bind uses it for GET, HEAD and DELETE requests
+// in place of Echo's query binding.
+//
+// Compared to Echo it
+//   - reports every parameter that failed, by name, as a field error
+//     (Echo stops at the first and doesn't say which it was);
+//   - splits lists on commas as well as repeated parameters, so
+//     ?status=todo,done and ?status=todo&status=done both give
+//     []string{"todo", "done"};
+//   - accepts dates (2024-05-01) as well as RFC 3339 for time.Time fields
+//     without a `format` tag;
+//   - treats an empty value (?limit=) as absent instead of zero.
+//
+// Untagged struct fields, such as an embedded pagination.Params or a
+// DateRange, are decoded into. Fields implementing echo.BindUnmarshaler or
+// encoding.TextUnmarshaler decode themselves.
+func DecodeQuery(values url.Values, dst any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("validation: DecodeQuery needs a pointer to a struct, got %T", dst)
+	}
+
+	var fieldErrors []errs.FieldError
+	decodeQueryStruct(values, v.Elem(), &fieldErrors)
+	if len(fieldErrors) > 0 {
+		return errs.NewBadRequestError("Invalid query parameters", true, nil, fieldErrors, nil).
+			WithMessageKey("bind.query", nil)
+	}
+	return nil
+}
+
+// bindRequest is echo.DefaultBinder's Bind with DecodeQuery for the query
+// string: path parameters, then the query for GET/HEAD/DELETE, then the body.
+func bindRequest(c echo.Context, payload any) error {
+	binder := &echo.DefaultBinder{}
+	if err := binder.BindPathParams(c, payload); err != nil {
+		return err
+	}
+
+	switch c.Request().Method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete:
+		if err := DecodeQuery(c.QueryParams(), payload); err != nil {
+			return err
+		}
+	}
+
+	return binder.BindBody(c, payload)
+}
+
+func decodeQueryStruct(values url.Values, v reflect.Value, fieldErrors *[]errs.FieldError) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+
+		name, _, _ := strings.Cut(field.Tag.Get("query"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			if fv.Kind() == reflect.Struct && !decodesItself(fv) {
+				decodeQueryStruct(values, fv, fieldErrors)
+			}
+			continue
+		}
+
+		raw := nonEmpty(values[name])
+		if len(raw) == 0 {
+			continue
+		}
+
+		if err := setQueryField(fv, raw, field.Tag.Get("format")); err != nil {
+			*fieldErrors = append(*fieldErrors, queryFieldError(name, err))
+		}
+	}
+}
+
+// queryValueError is a value that doesn't convert to the field's type.
+type queryValueError struct {
+	Type  reflect.Type
+	Value string
+	Err   error
+}
+
+func (e *queryValueError) Error() string {
+	return fmt.Sprintf("%q is not a valid %s: %v", e.Value, e.Type, e.Err)
+}
+
+func (e *queryValueError) Unwrap() error { return e.Err }
+
+func setQueryField(fv reflect.Value, raw []string, format string) error {
+	if fv.Kind() == reflect.Pointer {
+		elem := reflect.New(fv.Type().Elem())
+		if err := setQueryField(elem.Elem(), raw, format); err != nil {
+			return err
+		}
+		fv.Set(elem)
+		return nil
+	}
+
+	if u, ok := fv.Addr().Interface().(interface{ UnmarshalParams([]string) error }); ok {
+		if err := u.UnmarshalParams(raw); err != nil {
+			return &queryValueError{Type: fv.Type(), Value: strings.Join(raw, ","), Err: err}
+		}
+		return nil
+	}
+
+	if fv.Kind() == reflect.Slice && !decodesItself(fv) {
+		var items []string
+		for _, value := range raw {
+			items = append(items, nonEmpty(strings.Split(value, ","))...)
+		}
+
+		slice := reflect.MakeSlice(fv.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setQueryValue(slice.Index(i), strings.TrimSpace(item), format); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	}
+
+	return setQueryValue(fv, raw[0], format)
+}
+
+func setQueryValue(fv reflect.Value, value, format string) error {
+	if fv.Kind() == reflect.Pointer {
+		elem := reflect.New(fv.Type().Elem())
+		if err := setQueryValue(elem.Elem(), value, format); err != nil {
+			return err
+		}
+		fv.Set(elem)
+		return nil
+	}
+
+	var err error
+	switch target := fv.Addr().Interface().(type) {
+	case echo.BindUnmarshaler:
+		err = target.UnmarshalParam(value)
+	case *time.Time:
+		*target, err = parseQueryTime(value, format)
+	case encoding.TextUnmarshaler:
+		err = target.UnmarshalText([]byte(value))
+	default:
+		err = setKind(fv, value)
+	}
+
+	if err != nil {
+		return &queryValueError{Type: fv.Type(), Value: value, Err: err}
+	}
+	return nil
+}
+
+func setKind(fv reflect.Value, value string) error {
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported query parameter type %s", fv.Type())
+	}
+	return nil
+}
+
+// queryDateLayout is the date-only form accepted for time.Time parameters.
+const queryDateLayout = "2006-01-02"
+
+func parseQueryTime(value, format string) (time.Time, error) {
+	if format != "" {
+		return time.Parse(format, value)
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(queryDateLayout, value)
+}
+
+// queryFieldError names the parameter and, where it helps, the type the
+// client should have sent.
+func queryFieldError(name string, err error) errs.FieldError {
+	var valueErr *queryValueError
+	if !errors.As(err, &valueErr) {
+		return errs.FieldError{Field: name, Error: "has an invalid value", Key: "bind.invalid"}
+	}
+
+	if valueErr.Type == reflect.TypeFor[time.Time]() {
+		return errs.FieldError{
+			Field:  name,
+			Error:  fmt.Sprintf("%q is not a valid time, use RFC 3339 or YYYY-MM-DD", valueErr.Value),
+			Key:    "bind.query_time",
+			Params: map[string]any{"value": valueErr.Value},
+		}
+	}
+
+	switch expected := jsonTypeName(valueErr.Type); expected {
+	case "integer", "number", "boolean":
+		return errs.FieldError{
+			Field:  name,
+			Error:  "must be of type " + expected,
+			Key:    "bind.type",
+			Params: map[string]any{"type": expected, "got": valueErr.Value},
+		}
+	}
+
+	return errs.FieldError{
+		Field:  name,
+		Error:  "has an invalid value",
+		Key:    "bind.invalid",
+		Params: map[string]any{"value": valueErr.Value},
+	}
+}
+
+// decodesItself reports whether the field is decoded from a single value by
+// its own methods (time.Time, uuid.UUID, ...) rather than field by field or
+// item by item.
+func decodesItself(fv reflect.Value) bool {
+	if fv.Type() == reflect.TypeFor[time.Time]() {
+		return true
+	}
+	switch fv.Addr().Interface().(type) {
+	case echo.BindUnmarshaler, encoding.TextUnmarshaler:
+		return true
+	}
+	return false
+}
+
+// nonEmpty drops empty strings from values.
+func nonEmpty(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
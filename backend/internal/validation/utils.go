@@ -8,12 +8,14 @@
 package validation
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
 	"strings"
 
 	"github.com/deppfellow/go-boilerplate/internal/errs"
+	"github.com/deppfellow/go-boilerplate/internal/lib/enum"
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 )
@@ -42,30 +44,69 @@ func (c CustomValidationErrors) Error() string {
 	return "Validation failed"
 }
 
+// NewValidator returns a validator with the app's custom tags installed
+// ("enum", see lib/enum, and everything registered with RegisterCustom) that
+// names fields by their json/query/param tags. Request types should use it
+// in their Validate methods instead of validator.New().
+func NewValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(fieldName)
+	_ = enum.RegisterValidation(v)
+	registerCustoms(v)
+	return v
+}
+
 // BindAndValidate binds request data into payload and validates it.
 //
 // Flow:
-// 1) c.Bind(payload) populates request struct from the incoming request body/params.
-// 2) payload.Validate() applies validation rules.
-// 3) Returns *errs.HTTPError (400) with field-level errors if validation fails.
+// 1) Bind populates request struct from path params, query string and body.
+// 2) Normalize(payload) applies `normalize` tags (trim, lower, e164, ...).
+// 3) payload.Validate() applies validation rules.
+// 4) Returns *errs.HTTPError (400) with field-level errors if validation fails.
 //
-// NOTE: c.Bind expects a pointer to a struct. If payload is not a pointer,
+// NOTE: Bind expects a pointer to a struct. If payload is not a pointer,
 // binding will fail or behave unexpectedly.
 func BindAndValidate(c echo.Context, payload Validatable) error {
-	// Bind request body into payload.
-	// Echo returns an error when JSON is malformed or types mismatch.
-	if err := c.Bind(payload); err != nil {
-		// This parsing is brittle: it depends on Echo's bind error formatting.
-		// Consider replacing this with a safer parser or a fixed message if needed.
-		message := strings.Split(strings.Split(err.Error(), ",")[1], "message=")[1]
-		return errs.NewBadRequestError(message, false, nil, nil, nil)
+	if err := Bind(c, payload); err != nil {
+		return err
 	}
 
 	// Validate struct and return field errors if any.
-	if msg, fieldErrors := validateStruct(payload); fieldErrors != nil {
-		return errs.NewBadRequestError(msg, true, nil, fieldErrors, nil)
+	return ValidatePayload(payload)
+}
+
+// Bind populates payload from the request (body, path, query) and normalizes
+// it (see NormalizeTag) without validating it, converting bind failures into
+// 400/413 errs.HTTPError.
+//
+// It is the first half of BindAndValidate, exposed so pipelines can run
+// code between binding and validation (see handler.WithBeforeValidate).
+func Bind(c echo.Context, payload Validatable) error {
+	// Bind path params, query (see DecodeQuery) and body into payload.
+	// Query errors are already field errors; the rest come from Echo when
+	// JSON is malformed or types mismatch.
+	if err := bindRequest(c, payload); err != nil {
+		var httpErr *errs.HTTPError
+		if errors.As(err, &httpErr) {
+			return err
+		}
+		return bindError(err)
 	}
 
+	// A failure here is a bad normalize tag, not bad input: let it be a 500.
+	return Normalize(payload)
+}
+
+// ValidatePayload runs payload.Validate() without binding and converts a
+// failure into the same 400 errs.HTTPError BindAndValidate would return.
+//
+// Useful when items arrive nested inside another payload (bulk requests)
+// and each one must be validated on its own.
+func ValidatePayload(payload Validatable) error {
+	if msg, fieldErrors := validateStruct(payload); fieldErrors != nil {
+		return errs.NewBadRequestError(msg, true, nil, fieldErrors, nil).
+			WithMessageKey("validation.failed", nil)
+	}
 	return nil
 }
 
@@ -96,9 +137,13 @@ func extractValidationError(err error) (string, []errs.FieldError) {
 	}
 
 	// Convert validator.ValidationErrors into user-friendly messages.
+	// Each one also gets the key of its translation (see lib/i18n): the tag,
+	// with the parameter available as {param}.
 	for _, err := range validationErrors {
-		field := strings.ToLower(err.Field())
+		field := fieldPath(err)
 		var msg string
+		key := "validation." + err.Tag()
+		params := map[string]any{"param": err.Param()}
 
 		switch err.Tag() {
 		case "required":
@@ -110,6 +155,7 @@ func extractValidationError(err error) (string, []errs.FieldError) {
 			// - for numbers: minimum value
 			if err.Type().Kind() == reflect.String {
 				msg = fmt.Sprintf("must be at least %s characters", err.Param())
+				key += ".string"
 			} else {
 				msg = fmt.Sprintf("must be at least %s", err.Param())
 			}
@@ -120,6 +166,7 @@ func extractValidationError(err error) (string, []errs.FieldError) {
 			// - for numbers: maximum value
 			if err.Type().Kind() == reflect.String {
 				msg = fmt.Sprintf("must not exceed %s characters", err.Param())
+				key += ".string"
 			} else {
 				msg = fmt.Sprintf("must not exceed %s", err.Param())
 			}
@@ -139,11 +186,43 @@ func extractValidationError(err error) (string, []errs.FieldError) {
 		case "uuidList":
 			msg = "must be a comma-separated list of valid UUIDs"
 
+		case enum.ValidationTag:
+			if def, ok := enum.Lookup(err.Param()); ok {
+				msg = fmt.Sprintf("must be one of: %s", strings.Join(def.Strings(), ", "))
+				params["values"] = strings.Join(def.Strings(), ", ")
+			} else {
+				msg = fmt.Sprintf("unknown enum %q", err.Param())
+				key = ""
+			}
+
+		case "gtfield", "gtefield", "ltfield", "ltefield":
+			// Cross-field comparisons (DateRange's to > from). The param is
+			// the other field's Go name; its lowercase is the client's name
+			// for the common single-word case.
+			other := strings.ToLower(err.Param())
+			params["param"] = other
+			switch err.Tag() {
+			case "gtfield":
+				msg = "must be greater than " + other
+			case "gtefield":
+				msg = "must be greater than or equal to " + other
+			case "ltfield":
+				msg = "must be less than " + other
+			default:
+				msg = "must be less than or equal to " + other
+			}
+
 		case "dive":
 			// dive is used when validating slices/arrays and one of the nested items fails.
 			msg = "some items are invalid"
 
 		default:
+			if custom, param, ok := customMessage(err.Tag(), err.Param()); ok {
+				msg = custom
+				params["param"] = param
+				break
+			}
+
 			// Fallback for tags not explicitly handled above.
 			// Includes tag name and param (if any) to help debugging.
 			if err.Param() != "" {
@@ -154,8 +233,10 @@ func extractValidationError(err error) (string, []errs.FieldError) {
 		}
 
 		fieldErrors = append(fieldErrors, errs.FieldError{
-			Field: strings.ToLower(err.Field()),
-			Error: msg,
+			Field:  field,
+			Error:  msg,
+			Key:    key,
+			Params: params,
 		})
 	}
 
@@ -0,0 +1,23 @@
+// Package static embeds the API documentation assets and the admin
+// dashboard page into the binary.
+//
+// Files in this directory (the OpenAPI spec and the docs UI pages) are
+// compiled in with go:embed, so the server no longer depends on being started
+// from a working directory that contains ./static.
+package static
+
+import "embed"
+
+// FS holds the admin dashboard page and, under docs/, what /static serves:
+//   - docs/openapi.json -> the OpenAPI spec
+//   - docs/openapi.html -> Scalar
+//   - docs/swagger.html -> Swagger UI (swagger-init.js boots it)
+//   - docs/redoc.html   -> Redoc
+//   - docs/vendor/      -> the UI bundles (see docs/vendor/README.md)
+//   - admin.html        -> admin dashboard (served at /admin only)
+//
+//go:embed admin.html docs
+var FS embed.FS
+
+// DocsDir is the subtree of FS served at /static.
+const DocsDir = "docs"